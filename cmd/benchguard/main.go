@@ -0,0 +1,129 @@
+// Command benchguard compares two `go test -bench -benchmem` outputs and
+// exits non-zero when any benchmark's allocs/op grew past a threshold.
+// Timings are left to benchstat; allocation counts are stable enough across
+// machines to gate CI on.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// procSuffix is the -GOMAXPROCS suffix go test appends to benchmark names
+var procSuffix = regexp.MustCompile(`-\d+$`)
+
+// Regression is a benchmark whose allocs/op exceeded the allowed growth
+type Regression struct {
+	Name     string
+	Baseline float64
+	Current  float64
+}
+
+func main() {
+	threshold := flag.Float64("threshold", 10, "allowed allocs/op growth in percent")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: benchguard [-threshold pct] baseline.txt current.txt")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	baseline, err := parseFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	current, err := parseFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	regressions := Compare(baseline, current, *threshold)
+	for _, r := range regressions {
+		fmt.Printf("REGRESSION %s: allocs/op %.0f -> %.0f\n", r.Name, r.Baseline, r.Current)
+	}
+	if len(regressions) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("benchguard: %d benchmarks within %.0f%% of baseline allocs/op\n", len(current), *threshold)
+}
+
+func parseFile(path string) (map[string]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open benchmark file: %w", err)
+	}
+	defer f.Close()
+
+	allocs, err := ParseAllocs(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return allocs, nil
+}
+
+// ParseAllocs reads benchmark output and returns the mean allocs/op of each
+// benchmark, keyed by name without the GOMAXPROCS suffix
+func ParseAllocs(r io.Reader) (map[string]float64, error) {
+	sums := map[string]float64{}
+	counts := map[string]int{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		name := procSuffix.ReplaceAllString(fields[0], "")
+		for i := 2; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "allocs/op" {
+				continue
+			}
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("benchmark %s: invalid allocs/op %q", name, fields[i])
+			}
+			sums[name] += value
+			counts[name]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	allocs := make(map[string]float64, len(sums))
+	for name, sum := range sums {
+		allocs[name] = sum / float64(counts[name])
+	}
+	return allocs, nil
+}
+
+// Compare returns the benchmarks in current whose allocs/op grew more than
+// threshold percent over baseline. Benchmarks missing from the baseline are
+// new and never count as regressions.
+func Compare(baseline, current map[string]float64, threshold float64) []Regression {
+	var regressions []Regression
+	for name, cur := range current {
+		base, ok := baseline[name]
+		if !ok {
+			continue
+		}
+		if cur > base*(1+threshold/100) {
+			regressions = append(regressions, Regression{Name: name, Baseline: base, Current: cur})
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool { return regressions[i].Name < regressions[j].Name })
+	return regressions
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const benchOutput = `goos: linux
+pkg: example
+BenchmarkGetProductByID-8      	 1000000	        64.68 ns/op	     128 B/op	       1 allocs/op
+BenchmarkListProducts/limit=10-8  	  100	    265781 ns/op	  132672 B/op	      14 allocs/op
+BenchmarkListProducts/limit=10-8  	  100	    265781 ns/op	  132672 B/op	      16 allocs/op
+BenchmarkNoMem-8  	  100	    265781 ns/op
+PASS
+`
+
+func TestParseAllocs(t *testing.T) {
+	allocs, err := ParseAllocs(strings.NewReader(benchOutput))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"BenchmarkGetProductByID":        1,
+		"BenchmarkListProducts/limit=10": 15,
+	}, allocs)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := map[string]float64{"BenchmarkA": 10, "BenchmarkB": 0, "BenchmarkC": 100}
+
+	t.Run("should flag growth beyond the threshold", func(t *testing.T) {
+		current := map[string]float64{"BenchmarkA": 12, "BenchmarkB": 1, "BenchmarkC": 105}
+		assert.Equal(t, []Regression{
+			{Name: "BenchmarkA", Baseline: 10, Current: 12},
+			{Name: "BenchmarkB", Baseline: 0, Current: 1},
+		}, Compare(baseline, current, 10))
+	})
+
+	t.Run("should ignore improvements and new benchmarks", func(t *testing.T) {
+		current := map[string]float64{"BenchmarkA": 5, "BenchmarkC": 110, "BenchmarkNew": 50}
+		assert.Empty(t, Compare(baseline, current, 10))
+	})
+}
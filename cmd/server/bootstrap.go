@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/config"
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/middleware"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // registers the "postgres" driver
+)
+
+// Route timeouts, capped by MAX_REQUEST_TIMEOUT. Single-row reads either
+// answer quickly or not usefully at all, while batches write up to
+// MAX_BATCH_SIZE rows in one transaction.
+const (
+	pointReadTimeout = 5 * time.Second
+	batchTimeout     = 2 * time.Minute
+	// writeTimeoutMargin is how long past the longest request timeout the
+	// server still lets the timed out request's error response be written
+	writeTimeoutMargin = 5 * time.Second
+)
+
+// repos are the repositories backing the handlers. db is nil for the
+// memory backend. priceLocker elects the replica that applies scheduled
+// price changes.
+type repos struct {
+	db             *sqlx.DB
+	categories     datalayer.CategoryRepoInterface
+	products       datalayer.ProductRepoInterface
+	reservations   datalayer.ReservationRepoInterface
+	inventory      datalayer.InventoryRepoInterface
+	priceSchedules datalayer.PriceScheduleRepoInterface
+	definitions    datalayer.AttributeDefinitionRepoInterface
+	priceLocker    datalayer.Locker
+}
+
+// newRepos builds the repositories for the configured storage backend
+func newRepos(cfg config.Config) (repos, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		categories := datalayer.NewMemoryCategoryRepo()
+		products := datalayer.NewMemoryProductRepo()
+		return repos{
+			categories:     categories,
+			products:       products,
+			reservations:   datalayer.NewMemoryReservationRepo(products),
+			inventory:      datalayer.NewMemoryInventoryRepo(products),
+			priceSchedules: datalayer.NewMemoryPriceScheduleRepo(products),
+			definitions:    datalayer.NewMemoryAttributeDefinitionRepo(categories),
+			priceLocker:    datalayer.NewLocalLocker(),
+		}, nil
+	case config.StoragePostgres:
+		db, err := sqlx.Open(cfg.DB.Driver, cfg.DB.DSN())
+		if err != nil {
+			return repos{}, fmt.Errorf("newRepos: open database failed: %w", err)
+		}
+		// database/sql keeps two idle connections by default, fewer than a
+		// larger warmup opens
+		if cfg.DB.MinIdleConns > 2 {
+			db.SetMaxIdleConns(cfg.DB.MinIdleConns)
+		}
+		categories, products := datalayer.NewCategoryRepo(db), datalayer.NewProductRepo(db)
+		if cfg.DB.BreakerThreshold > 0 {
+			breaker := datalayer.NewBreaker(cfg.DB.BreakerThreshold, cfg.DB.BreakerCooldown, time.Now)
+			categories = datalayer.NewBreakerCategoryRepo(categories, breaker)
+			products = datalayer.NewBreakerProductRepo(products, breaker)
+		}
+		return repos{
+			db:             db,
+			categories:     categories,
+			products:       products,
+			reservations:   datalayer.NewReservationRepo(db),
+			inventory:      datalayer.NewInventoryRepo(db),
+			priceSchedules: datalayer.NewPriceScheduleRepo(db),
+			definitions:    datalayer.NewAttributeDefinitionRepo(db),
+			priceLocker:    datalayer.NewAdvisoryLocker(db, priceSchedulerLockKey),
+		}, nil
+	default:
+		return repos{}, fmt.Errorf("newRepos: unsupported storage `%s`", cfg.Storage)
+	}
+}
+
+// warmup is the component opening and warming up conns idle database
+// connections at startup. It does nothing for the memory backend.
+func warmup(r repos, conns int) component {
+	return component{
+		name: "database warmup",
+		start: func(ctx context.Context) error {
+			if r.db == nil {
+				return nil
+			}
+			return datalayer.Warmup(ctx, r.db, conns)
+		},
+		stop: func(context.Context) error { return nil },
+	}
+}
+
+// routerOptions returns the handler options the server configuration sets
+func routerOptions(cfg config.ServerConfig) (handlers.Options, error) {
+	naming, err := handlers.ParseNamingStrategy(cfg.JSONNaming)
+	if err != nil {
+		return handlers.Options{}, err
+	}
+	deleteStyle, err := handlers.ParseDeleteStyle(cfg.DeleteResponse)
+	if err != nil {
+		return handlers.Options{}, err
+	}
+	return handlers.Options{
+		Naming:              naming,
+		OmitSuccessMessages: !cfg.SuccessMessages,
+		DeleteStyle:         deleteStyle,
+		AlreadyDeletedOK:    cfg.DeleteAlreadyDeletedOK,
+		UUIDVersion:         cfg.UUIDVersion,
+		MaxBatchSize:        cfg.MaxBatchSize,
+	}, nil
+}
+
+// newRouter registers every handler and middleware on a new router with
+// opts. It also returns the product event stream, for workers changing
+// products to publish on.
+func newRouter(
+	r repos,
+	cfg config.Config,
+	opts handlers.Options,
+	logger handlers.LoggerInterface,
+) (*handlers.Router, *service.ProductEventStream) {
+	router := handlers.NewRouter(opts)
+	router.SetLogger(logger)
+	maintenance := &handlers.MaintenanceMode{}
+	router.Use(
+		middleware.DiscardHeadBody("/products/stream"),
+		middleware.LogRequests(logger),
+		middleware.CacheControl(router),
+		middleware.Recover(logger),
+		middleware.RequestTimeout(router, cfg.Server.MaxRequestTimeout, "/products/stream"),
+		middleware.MaxInFlight(cfg.Server.MaxInFlight, logger, "/products/stream"),
+		middleware.RejectWritesInMaintenance(maintenance, logger, "/maintenance"),
+		middleware.RequireJSONAccept("/products/stream"),
+		middleware.RequireJSONContent(logger),
+		middleware.IdentifyAdmin(cfg.Server.AdminToken),
+	)
+
+	handlers.NewCategoryHandler(r.categories, cfg.Server.CursorSkew, cfg.Server.CategoryCacheTTL, logger).RegisterRoutes(router)
+	products := handlers.NewProductHandler(r.products, r.categories, r.definitions, cfg.Server.CursorSkew, cfg.Server.MaxEventStreams, logger)
+	products.RegisterRoutes(router)
+	handlers.NewAttributeDefinitionHandler(r.definitions, logger).RegisterRoutes(router)
+	handlers.NewReservationHandler(r.reservations, cfg.Stock.ReservationTTL, logger).RegisterRoutes(router)
+	handlers.NewInventoryHandler(r.inventory, logger).RegisterRoutes(router)
+	handlers.NewPriceScheduleHandler(r.priceSchedules, time.Now, logger).RegisterRoutes(router)
+
+	// a nil *sqlx.DB must not become a non-nil DBStatser or VersionQuerier
+	var db handlers.DBStatser
+	var versions handlers.VersionQuerier
+	if r.db != nil {
+		db, versions = r.db, r.db
+	}
+	handlers.NewStatsHandler(db, logger).RegisterRoutes(router)
+	handlers.NewReadinessHandler(versions, maintenance, time.Now, logger).RegisterRoutes(router)
+	handlers.NewMaintenanceHandler(maintenance, logger).RegisterRoutes(router)
+
+	setCachePolicies(router, cfg)
+	setTimeouts(router)
+	return router, products.Events()
+}
+
+// setTimeouts gives single-row reads a short timeout and batches a long
+// one. Other routes only get MAX_REQUEST_TIMEOUT.
+func setTimeouts(router *handlers.Router) {
+	router.SetTimeout(pointReadTimeout, "GET /categories/{id}", "GET /products/{id}", "GET /products/{id}/availability")
+	router.SetTimeout(batchTimeout, "POST /categories/bulk", "POST /categories/batch-get", "POST /products/batch")
+}
+
+// writeTimeout returns the http.Server WriteTimeout for maxRequestTimeout.
+// It leaves room past the longest request timeout so requests time out
+// with an error response rather than a cut connection. Without a maximum
+// routes may run unbounded, so neither is the write.
+func writeTimeout(maxRequestTimeout time.Duration) time.Duration {
+	if maxRequestTimeout <= 0 {
+		return 0
+	}
+	return maxRequestTimeout + writeTimeoutMargin
+}
+
+// setCachePolicies lets clients reuse list and get responses for a while.
+// Stats are admin only, so no cache may keep them. Readiness and
+// maintenance mode must always be read afresh.
+func setCachePolicies(router *handlers.Router, cfg config.Config) {
+	for _, pattern := range handlers.ListRoutes(router) {
+		if strings.HasPrefix(pattern, http.MethodGet+" ") {
+			router.SetCachePolicy(handlers.CachePolicy{MaxAge: cfg.Server.CacheMaxAge}, pattern)
+		}
+	}
+	router.SetCachePolicy(handlers.NoStore, "GET /stats", "GET /ready", "GET /maintenance", "GET /products/stream")
+}
+
+// newPriceScheduler builds the worker applying r's due price schedules and
+// publishing the price changes on events
+func newPriceScheduler(r repos, events *service.ProductEventStream, logger handlers.LoggerInterface) *priceScheduler {
+	return &priceScheduler{
+		schedules: r.priceSchedules,
+		locker:    r.priceLocker,
+		publish:   events.Publish,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
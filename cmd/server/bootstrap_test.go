@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/config"
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepos(t *testing.T) {
+	t.Run("should build memory repos", func(t *testing.T) {
+		repos, err := newRepos(config.Config{Storage: config.StorageMemory})
+		assert.NoError(t, err)
+		assert.IsType(t, &datalayer.MemoryCategoryRepo{}, repos.categories)
+		assert.IsType(t, &datalayer.MemoryProductRepo{}, repos.products)
+		assert.IsType(t, &datalayer.MemoryReservationRepo{}, repos.reservations)
+		assert.IsType(t, &datalayer.MemoryInventoryRepo{}, repos.inventory)
+		assert.IsType(t, &datalayer.MemoryPriceScheduleRepo{}, repos.priceSchedules)
+		assert.IsType(t, &datalayer.MemoryAttributeDefinitionRepo{}, repos.definitions)
+		assert.IsType(t, &datalayer.LocalLocker{}, repos.priceLocker)
+	})
+
+	t.Run("should return error if database driver is not registered", func(t *testing.T) {
+		cfg := config.Config{Storage: config.StoragePostgres, DB: config.DBConfig{Driver: "unregistered"}}
+		_, err := newRepos(cfg)
+		assert.ErrorContains(t, err, "newRepos: open database failed")
+	})
+
+	t.Run("should return error for unsupported storage", func(t *testing.T) {
+		_, err := newRepos(config.Config{Storage: "redis"})
+		assert.EqualError(t, err, "newRepos: unsupported storage `redis`")
+	})
+}
+
+func TestNewRouter(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	router, _ := newRouter(repos, config.Config{Stock: config.StockConfig{ReservationTTL: time.Minute}}, handlers.Options{}, mocks.NewLogger())
+
+	assert.Equal(t, []string{
+		"GET /categories",
+		"POST /categories",
+		"POST /categories/bulk",
+		"POST /categories/batch-get",
+		"GET /categories/{id}",
+		"PATCH /categories/{id}",
+		"DELETE /categories/{id}",
+		"GET /products",
+		"POST /products",
+		"POST /products/batch",
+		"GET /products/count",
+		"GET /products/duplicates",
+		"GET /products/grouped-by-category",
+		"GET /products/schema",
+		"GET /products/stream",
+		"GET /products/{id}",
+		"GET /products/{id}/availability",
+		"GET /products/{id}/related",
+		"PUT /products/{id}/related",
+		"PATCH /products/{id}",
+		"DELETE /products/{id}",
+		"GET /categories/{id}/attribute-definitions",
+		"POST /categories/{id}/attribute-definitions",
+		"PUT /attribute-definitions/{id}",
+		"DELETE /attribute-definitions/{id}",
+		"POST /products/{id}/reservations",
+		"DELETE /reservations/{id}",
+		"POST /reservations/{id}/commit",
+		"POST /products/{id}/stock-adjustments",
+		"GET /products/{id}/movements",
+		"POST /products/{id}/price-schedules",
+		"GET /products/{id}/price-schedules",
+		"DELETE /price-schedules/{id}",
+		"GET /stats",
+		"GET /ready",
+		"GET /maintenance",
+		"PUT /maintenance",
+	}, handlers.ListRoutes(router))
+}
+
+func TestRouterOptions(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	router, _ := newRouter(repos, config.Config{Stock: config.StockConfig{ReservationTTL: time.Minute}}, handlers.Options{}, mocks.NewLogger())
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/categories", "GET, HEAD, POST, OPTIONS"},
+		{"/categories/bulk", "GET, HEAD, POST, PATCH, DELETE, OPTIONS"},
+		{"/categories/f2aa335f-6f91-4d4d-8057-53b0009bc376", "GET, HEAD, PATCH, DELETE, OPTIONS"},
+		{"/products", "GET, HEAD, POST, OPTIONS"},
+		{"/products/batch", "GET, HEAD, POST, PATCH, DELETE, OPTIONS"},
+		{"/products/count", "GET, HEAD, PATCH, DELETE, OPTIONS"},
+		{"/products/duplicates", "GET, HEAD, PATCH, DELETE, OPTIONS"},
+		{"/products/stream", "GET, HEAD, PATCH, DELETE, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376", "GET, HEAD, PATCH, DELETE, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376/reservations", "POST, OPTIONS"},
+		{"/reservations/f2aa335f-6f91-4d4d-8057-53b0009bc376", "DELETE, OPTIONS"},
+		{"/reservations/f2aa335f-6f91-4d4d-8057-53b0009bc376/commit", "POST, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376/availability", "GET, HEAD, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376/related", "GET, HEAD, PUT, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376/stock-adjustments", "POST, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376/movements", "GET, HEAD, OPTIONS"},
+		{"/products/f2aa335f-6f91-4d4d-8057-53b0009bc376/price-schedules", "GET, HEAD, POST, OPTIONS"},
+		{"/price-schedules/f2aa335f-6f91-4d4d-8057-53b0009bc376", "DELETE, OPTIONS"},
+		{"/categories/f2aa335f-6f91-4d4d-8057-53b0009bc376/attribute-definitions", "GET, HEAD, POST, OPTIONS"},
+		{"/attribute-definitions/f2aa335f-6f91-4d4d-8057-53b0009bc376", "PUT, DELETE, OPTIONS"},
+		{"/stats", "GET, HEAD, OPTIONS"},
+		{"/ready", "GET, HEAD, OPTIONS"},
+		{"/maintenance", "GET, HEAD, PUT, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, tt.path, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.allow, rec.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestRouterCachePolicies(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	cfg := config.Config{
+		Server: config.ServerConfig{CacheMaxAge: 30 * time.Second},
+		Stock:  config.StockConfig{ReservationTTL: time.Minute},
+	}
+	router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+	missing := "/categories/f2aa335f-6f91-4d4d-8057-53b0009bc376"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		admin  bool
+		status int
+		want   string
+	}{
+		{"list", http.MethodGet, "/categories", false, http.StatusOK, "private, max-age=30"},
+		{"get miss", http.MethodGet, missing, false, http.StatusNotFound, "no-store"},
+		{"mutation error", http.MethodDelete, missing, false, http.StatusNotFound, "no-store"},
+		{"stats", http.MethodGet, "/stats", true, http.StatusOK, "no-store"},
+		{"stats forbidden", http.MethodGet, "/stats", false, http.StatusForbidden, "no-store"},
+		{"readiness", http.MethodGet, "/ready", false, http.StatusOK, "no-store"},
+		{"maintenance", http.MethodGet, "/maintenance", true, http.StatusOK, "no-store"},
+		{"event stream", http.MethodHead, "/products/stream", false, http.StatusOK, "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.admin {
+				req = req.WithContext(handlers.WithAdmin(req.Context()))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.want, rec.Header().Get("Cache-Control"))
+		})
+	}
+
+	t.Run("should not store lists with a zero max age", func(t *testing.T) {
+		cfg.Server.CacheMaxAge = 0
+		router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	})
+}
+
+func TestRouterStatsNotPublic(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	cfg := config.Config{
+		Server: config.ServerConfig{AdminToken: "secret", CacheMaxAge: 30 * time.Second},
+		Stock:  config.StockConfig{ReservationTTL: time.Minute},
+	}
+	router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Header().Get("Cache-Control"), "public")
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+}
+
+func TestRouterEventStreams(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	cfg := config.Config{
+		Server: config.ServerConfig{MaxInFlight: 1, MaxEventStreams: 1},
+		Stock:  config.StockConfig{ReservationTTL: time.Minute},
+	}
+	router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	openStream := func() *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products/stream", nil)
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	stream := openStream()
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	resp, err := server.Client().Get(server.URL + "/categories")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the open stream must not hold the only MAX_IN_FLIGHT slot")
+
+	second := openStream()
+	defer second.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, second.StatusCode, "streams have their own cap")
+}
+
+func TestRouterTimeouts(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	cfg := config.Config{
+		Server: config.ServerConfig{MaxRequestTimeout: 30 * time.Second},
+		Stock:  config.StockConfig{ReservationTTL: time.Minute},
+	}
+	router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   string
+	}{
+		{"single-row read", http.MethodGet, "/products/f2aa335f-6f91-4d4d-8057-53b0009bc376", "5000"},
+		{"batch capped by the maximum", http.MethodPost, "/products/batch", "30000"},
+		{"route without a timeout", http.MethodGet, "/products", "30000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Header().Get("X-Request-Timeout-Ms"))
+		})
+	}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	assert.Equal(t, 35*time.Second, writeTimeout(30*time.Second))
+	assert.Zero(t, writeTimeout(0), "uncapped requests must not have their writes cut")
+}
+
+func TestRouterHead(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	ctx := context.Background()
+	category := &datalayer.Category{Name: "Lighting"}
+	require.NoError(t, repos.categories.CreateCategory(ctx, category))
+	product := &datalayer.Product{Name: "Lamp", CategoryID: category.ID, Price: 12.5, Quantity: 3, Status: datalayer.ProductActive}
+	require.NoError(t, repos.products.CreateProduct(ctx, product))
+
+	cfg := config.Config{
+		Server: config.ServerConfig{CacheMaxAge: 30 * time.Second},
+		Stock:  config.StockConfig{ReservationTTL: time.Minute},
+	}
+	router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+
+	paths := []string{
+		"/categories?limit=1",
+		"/categories/" + category.ID.String(),
+		"/categories/f2aa335f-6f91-4d4d-8057-53b0009bc376",
+		"/products",
+		"/products/" + product.ID.String(),
+		"/products/" + product.ID.String() + "/availability",
+		"/products/" + product.ID.String() + "/related",
+		"/ready",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			do := func(method string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, path, nil)
+				req.Header.Set("X-Request-ID", "head-test")
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				return rec
+			}
+			get, head := do(http.MethodGet), do(http.MethodHead)
+
+			assert.Equal(t, get.Code, head.Code)
+			assert.Equal(t, get.Header(), head.Header())
+			assert.Equal(t, strconv.Itoa(get.Body.Len()), head.Header().Get("Content-Length"))
+			assert.Empty(t, head.Body.String())
+		})
+	}
+
+	t.Run("/products/stream", func(t *testing.T) {
+		server := httptest.NewServer(router)
+		defer server.Close()
+		client := &http.Client{Timeout: time.Second}
+
+		resp, err := client.Head(server.URL + "/products/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	})
+}
+
+func TestRouterMaintenance(t *testing.T) {
+	repos, _ := newRepos(config.Config{Storage: config.StorageMemory})
+	cfg := config.Config{
+		Server: config.ServerConfig{AdminToken: "secret"},
+		Stock:  config.StockConfig{ReservationTTL: time.Minute},
+	}
+	router, _ := newRouter(repos, cfg, handlers.Options{}, mocks.NewLogger())
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/categories", `{"name":"Lighting"}`).Code)
+
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/maintenance", `{"enabled":true}`).Code)
+	rec := do(http.MethodPost, "/categories", `{"name":"Garden"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/categories", "").Code)
+	assert.Contains(t, do(http.MethodGet, "/ready", "").Body.String(), `"maintenance":true`)
+
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/maintenance", `{"enabled":false}`).Code)
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/categories", `{"name":"Garden"}`).Code)
+	assert.Contains(t, do(http.MethodGet, "/ready", "").Body.String(), `"maintenance":false`)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// runReservationJanitor releases expired reservations back to stock every
+// interval until ctx is done. Failures are logged and retried on the next
+// tick.
+func runReservationJanitor(
+	ctx context.Context,
+	repo datalayer.ReservationRepoInterface,
+	interval time.Duration,
+	logger handlers.LoggerInterface,
+) {
+	const op = "main.runReservationJanitor"
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := repo.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				logger.LogError(op, err)
+				continue
+			}
+			if released > 0 {
+				logger.LogInfo(op, fmt.Sprintf("released %d expired reservations", released))
+			}
+		}
+	}
+}
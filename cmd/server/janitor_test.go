@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReservationJanitor(t *testing.T) {
+	t.Run("should release expired holds on every tick until cancelled", func(t *testing.T) {
+		var calls atomic.Int32
+		repo := &mocks.MockReservationRepo{
+			ReleaseExpiredReservationsFunc: func(context.Context) (int, error) {
+				if calls.Add(1) == 1 {
+					return 0, errors.New("releaseExpiredReservations: select query failed: query error")
+				}
+				return 2, nil
+			},
+		}
+		logger := handlermocks.NewLogger()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			runReservationJanitor(ctx, repo, time.Millisecond, logger)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+
+		assert.Equal(t, "main.runReservationJanitor", logger.LogErrorCalls()[0].Op)
+		assert.Equal(t, "released 2 expired reservations", logger.LogInfoCalls()[0].Msg)
+	})
+}
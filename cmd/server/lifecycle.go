@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// component is a background part of the server. Start must return once
+// the component is running; Stop must return once it has stopped taking
+// new work and finished what it was doing, or when ctx is done.
+type component struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// lifecycle starts components in the order they were registered and stops
+// them in reverse, so a component can rely on those registered before it
+type lifecycle struct {
+	components  []component
+	started     int
+	stopTimeout time.Duration
+	now         func() time.Time
+	logger      handlers.LoggerInterface
+}
+
+// newLifecycle creates a lifecycle giving each component stopTimeout to
+// stop
+func newLifecycle(stopTimeout time.Duration, logger handlers.LoggerInterface) *lifecycle {
+	return &lifecycle{stopTimeout: stopTimeout, now: time.Now, logger: logger}
+}
+
+// register adds c to the components started by start
+func (l *lifecycle) register(c component) {
+	l.components = append(l.components, c)
+}
+
+// start starts every registered component in order. If one fails, the
+// ones already started are stopped again and its error is returned.
+func (l *lifecycle) start(ctx context.Context) error {
+	const op = "main.lifecycle.start"
+
+	for _, c := range l.components[l.started:] {
+		if err := c.start(ctx); err != nil {
+			err = fmt.Errorf("start %s: %w", c.name, err)
+			if stopErr := l.stop(context.WithoutCancel(ctx)); stopErr != nil {
+				return errors.Join(err, stopErr)
+			}
+			return err
+		}
+		l.started++
+		l.logger.LogInfo(op, fmt.Sprintf("started %s", c.name))
+	}
+	return nil
+}
+
+// stop stops the started components in reverse order, giving each up to
+// stopTimeout. A component that fails or times out is logged and the rest
+// are still stopped; their errors are joined.
+func (l *lifecycle) stop(ctx context.Context) error {
+	const op = "main.lifecycle.stop"
+
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		c := l.components[l.started-1]
+		stopCtx, cancel := context.WithTimeout(ctx, l.stopTimeout)
+		begin := l.now()
+		err := c.stop(stopCtx)
+		cancel()
+		took := l.now().Sub(begin)
+
+		if err != nil {
+			err = fmt.Errorf("stop %s: %w", c.name, err)
+			l.logger.LogError(op, fmt.Errorf("%w (after %s)", err, took))
+			errs = append(errs, err)
+			continue
+		}
+		l.logger.LogInfo(op, fmt.Sprintf("stopped %s in %s", c.name, took))
+	}
+	return errors.Join(errs...)
+}
+
+// worker is a component running run in its own goroutine until stopped.
+// Stopping cancels run's context and waits for it to return.
+func worker(name string, run func(ctx context.Context)) component {
+	var cancel context.CancelFunc
+	var done chan struct{}
+
+	return component{
+		name: name,
+		start: func(ctx context.Context) error {
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				run(runCtx)
+			}()
+			return nil
+		},
+		stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the start and stop calls of fake components in order
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// fake returns a component recording its calls that fails to start with
+// startErr and takes stopDelay to stop
+func (r *recorder) fake(name string, startErr error, stopDelay time.Duration) component {
+	return component{
+		name: name,
+		start: func(context.Context) error {
+			r.record("start " + name)
+			return startErr
+		},
+		stop: func(ctx context.Context) error {
+			r.record("stop " + name)
+			select {
+			case <-time.After(stopDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should start in order and stop in reverse", func(t *testing.T) {
+		rec := &recorder{}
+		logger := mocks.NewLogger()
+		l := newLifecycle(time.Second, logger)
+		l.register(rec.fake("db", nil, 0))
+		l.register(rec.fake("janitor", nil, 0))
+		l.register(rec.fake("scheduler", nil, 0))
+
+		require.NoError(t, l.start(ctx))
+		require.NoError(t, l.stop(ctx))
+
+		assert.Equal(t, []string{
+			"start db", "start janitor", "start scheduler",
+			"stop scheduler", "stop janitor", "stop db",
+		}, rec.calls)
+		require.Len(t, logger.LogInfoCalls(), 6)
+		assert.Equal(t, "started db", logger.LogInfoCalls()[0].Msg)
+		assert.Contains(t, logger.LogInfoCalls()[3].Msg, "stopped scheduler in ")
+	})
+
+	t.Run("should stop what started if a component fails to start", func(t *testing.T) {
+		rec := &recorder{}
+		l := newLifecycle(time.Second, mocks.NewLogger())
+		l.register(rec.fake("db", nil, 0))
+		l.register(rec.fake("janitor", nil, 0))
+		l.register(rec.fake("scheduler", errors.New("lock unavailable"), 0))
+		l.register(rec.fake("metrics", nil, 0))
+
+		err := l.start(ctx)
+		assert.EqualError(t, err, "start scheduler: lock unavailable")
+
+		assert.Equal(t, []string{
+			"start db", "start janitor", "start scheduler",
+			"stop janitor", "stop db",
+		}, rec.calls)
+		assert.NoError(t, l.stop(ctx), "nothing is left to stop")
+		assert.Len(t, rec.calls, 5)
+	})
+
+	t.Run("should time out a slow stop and still stop the rest", func(t *testing.T) {
+		rec := &recorder{}
+		logger := mocks.NewLogger()
+		l := newLifecycle(10*time.Millisecond, logger)
+		l.register(rec.fake("db", nil, 0))
+		l.register(rec.fake("janitor", nil, time.Hour))
+
+		require.NoError(t, l.start(ctx))
+		err := l.stop(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "stop janitor")
+
+		assert.Equal(t, []string{"start db", "start janitor", "stop janitor", "stop db"}, rec.calls)
+		require.Len(t, logger.LogErrorCalls(), 1)
+		assert.Equal(t, "main.lifecycle.stop", logger.LogErrorCalls()[0].Op)
+		assert.Contains(t, logger.LogErrorCalls()[0].Err.Error(), "(after ")
+	})
+}
+
+func TestWorker(t *testing.T) {
+	t.Run("should run until stopped", func(t *testing.T) {
+		running := make(chan struct{})
+		var stopped bool
+		w := worker("janitor", func(ctx context.Context) {
+			close(running)
+			<-ctx.Done()
+			stopped = true
+		})
+
+		startCtx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, w.start(startCtx))
+		cancel() // the worker outlives the context it was started with
+		<-running
+
+		require.NoError(t, w.stop(context.Background()))
+		assert.True(t, stopped)
+	})
+
+	t.Run("should give up waiting when the stop context is done", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		w := worker("stuck", func(context.Context) { <-release })
+
+		require.NoError(t, w.start(context.Background()))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, w.stop(ctx), context.DeadlineExceeded)
+	})
+}
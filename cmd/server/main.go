@@ -1,4 +1,84 @@
 package main
 
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/config"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
 func main() {
+	const op = "main"
+
+	logger := handlers.NewLogger(os.Stdout)
+	cfg, err := config.Load()
+	if err != nil {
+		logger.LogError(op, err)
+		os.Exit(1)
+	}
+
+	opts, err := routerOptions(cfg.Server)
+	if err != nil {
+		logger.LogError(op, err)
+		os.Exit(1)
+	}
+
+	repos, err := newRepos(cfg)
+	if err != nil {
+		logger.LogError(op, err)
+		os.Exit(1)
+	}
+	router, productEvents := newRouter(repos, cfg, opts, logger)
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	components := newLifecycle(cfg.Server.ShutdownTimeout, logger)
+	components.register(warmup(repos, cfg.DB.MinIdleConns))
+	components.register(worker("reservation janitor", func(ctx context.Context) {
+		runReservationJanitor(ctx, repos.reservations, cfg.Stock.JanitorInterval, logger)
+	}))
+	components.register(worker("price scheduler", func(ctx context.Context) {
+		runPriceScheduler(ctx, newPriceScheduler(repos, productEvents, logger), cfg.Pricing.ScheduleInterval)
+	}))
+	if err := components.start(ctx); err != nil {
+		logger.LogError(op, err)
+		os.Exit(1)
+	}
+
+	LogStartup(logger, cfg.Server.Addr, handlers.ListRoutes(router), cfg.DB.Host)
+
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      writeTimeout(cfg.Server.MaxRequestTimeout),
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.ListenAndServe() }()
+
+	select {
+	case err := <-served:
+		logger.LogError(op, err)
+		_ = components.stop(context.Background())
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// Workers stop taking new work before in-flight requests are drained;
+	// stop logs each component's outcome itself
+	logger.LogInfo(op, "shutting down")
+	_ = components.stop(context.Background())
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(drainCtx); err != nil {
+		logger.LogError(op, fmt.Errorf("drain requests: %w", err))
+	}
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+)
+
+// priceSchedulerLockKey identifies the advisory lock that elects the one
+// replica applying scheduled price changes
+const priceSchedulerLockKey int64 = 0x7072696365 // "price"
+
+// priceScheduleBatch caps how many due schedules one tick applies
+const priceScheduleBatch = 100
+
+// priceScheduler applies due price schedules to their products. Only the
+// holder of locker runs a batch, so replicas sharing a database do not
+// apply the same change twice. Each applied change is passed to publish,
+// if set, as a product update event.
+type priceScheduler struct {
+	schedules datalayer.PriceScheduleRepoInterface
+	locker    datalayer.Locker
+	publish   func(service.ProductEvent)
+	now       func() time.Time
+	logger    handlers.LoggerInterface
+}
+
+// applyDue applies every schedule due by now and returns how many were
+// applied. It returns 0 without error if another replica holds the lock.
+func (s *priceScheduler) applyDue(ctx context.Context) (int, error) {
+	applied := 0
+	_, err := s.locker.WithLock(ctx, func(ctx context.Context) error {
+		due, err := s.schedules.ListDuePriceSchedules(ctx, s.now(), priceScheduleBatch)
+		if err != nil {
+			return err
+		}
+		for _, schedule := range due {
+			ok, err := s.apply(ctx, schedule)
+			if err != nil {
+				return err
+			}
+			if ok {
+				applied++
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// apply sets the product's price and marks the schedule applied in one
+// repo call, reporting false if the schedule is gone, as it is once its
+// product was deleted. Only the price is written, so reservations and
+// stock movements made since the product was last read are kept.
+func (s *priceScheduler) apply(ctx context.Context, schedule *datalayer.PriceSchedule) (bool, error) {
+	product, err := s.schedules.ApplyPriceSchedule(ctx, schedule.ID)
+	if errors.Is(err, datalayer.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if s.publish != nil {
+		s.publish(service.ProductEvent{
+			Type:       service.ProductUpdated,
+			ProductID:  product.ID,
+			Product:    product,
+			OccurredAt: s.now().UTC(),
+		})
+	}
+	return true, nil
+}
+
+// runPriceScheduler applies due price schedules every interval until ctx is
+// done. Failures are logged and retried on the next tick.
+func runPriceScheduler(ctx context.Context, s *priceScheduler, interval time.Duration) {
+	const op = "main.runPriceScheduler"
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			applied, err := s.applyDue(ctx)
+			if err != nil {
+				s.logger.LogError(op, err)
+				continue
+			}
+			if applied > 0 {
+				s.logger.LogInfo(op, fmt.Sprintf("applied %d scheduled price changes", applied))
+			}
+		}
+	}
+}
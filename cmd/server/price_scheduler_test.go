@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// heldLocker is a Locker whose lock another replica always holds
+type heldLocker struct{}
+
+func (heldLocker) WithLock(context.Context, func(context.Context) error) (bool, error) {
+	return false, nil
+}
+
+// interleavedScheduleRepo runs afterList once the due schedules are listed,
+// standing in for a write landing while the scheduler works through them
+type interleavedScheduleRepo struct {
+	datalayer.PriceScheduleRepoInterface
+	afterList func()
+}
+
+func (r *interleavedScheduleRepo) ListDuePriceSchedules(ctx context.Context, asOf time.Time, limit int) ([]*datalayer.PriceSchedule, error) {
+	schedules, err := r.PriceScheduleRepoInterface.ListDuePriceSchedules(ctx, asOf, limit)
+	r.afterList()
+	return schedules, err
+}
+
+// newTestPriceScheduler wires a scheduler over memory repos whose clock
+// reads *now
+func newTestPriceScheduler(now *time.Time) (*priceScheduler, *datalayer.MemoryProductRepo, *datalayer.MemoryPriceScheduleRepo) {
+	products := datalayer.NewMemoryProductRepo()
+	schedules := datalayer.NewMemoryPriceScheduleRepo(products)
+	return &priceScheduler{
+		schedules: schedules,
+		locker:    datalayer.NewLocalLocker(),
+		now:       func() time.Time { return *now },
+		logger:    handlermocks.NewLogger(),
+	}, products, schedules
+}
+
+func TestPriceSchedulerApplyDue(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should apply schedules only once they are due", func(t *testing.T) {
+		now := start
+		s, products, schedules := newTestPriceScheduler(&now)
+		product := &datalayer.Product{Name: "Lamp", CategoryID: uuid.New(), Price: 10, Status: datalayer.ProductActive, CreatedAt: start}
+		require.NoError(t, products.CreateProduct(ctx, product))
+		require.NoError(t, schedules.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 12, EffectiveAt: start.Add(time.Hour)}))
+		require.NoError(t, schedules.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 15, EffectiveAt: start.Add(2 * time.Hour)}))
+
+		applied, err := s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+
+		now = start.Add(90 * time.Minute)
+		applied, err = s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+		got, _ := products.GetProductByID(ctx, product.ID)
+		assert.Equal(t, 12.0, got.Price)
+
+		applied, err = s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied, "an applied schedule must not be applied again")
+
+		now = start.Add(3 * time.Hour)
+		applied, err = s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+		got, _ = products.GetProductByID(ctx, product.ID)
+		assert.Equal(t, 15.0, got.Price)
+		assert.Equal(t, datalayer.ProductActive, got.Status)
+	})
+
+	t.Run("should drop schedules of deleted products", func(t *testing.T) {
+		now := start
+		s, products, schedules := newTestPriceScheduler(&now)
+		product := &datalayer.Product{Name: "Lamp", CategoryID: uuid.New(), Price: 10, Status: datalayer.ProductActive, CreatedAt: start}
+		require.NoError(t, products.CreateProduct(ctx, product))
+		schedule := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 12, EffectiveAt: start}
+		require.NoError(t, schedules.CreatePriceSchedule(ctx, schedule))
+		require.NoError(t, products.DeleteProduct(ctx, product.ID))
+
+		applied, err := s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+		due, _ := schedules.ListDuePriceSchedules(ctx, now, 10)
+		assert.Empty(t, due)
+	})
+
+	t.Run("should keep stock reserved while the price change is applied", func(t *testing.T) {
+		now := start
+		s, products, schedules := newTestPriceScheduler(&now)
+		reservations := datalayer.NewMemoryReservationRepo(products)
+		var events []service.ProductEvent
+		s.publish = func(event service.ProductEvent) { events = append(events, event) }
+		product := &datalayer.Product{Name: "Lamp", CategoryID: uuid.New(), Price: 10, Quantity: 5, Status: datalayer.ProductActive, CreatedAt: start}
+		require.NoError(t, products.CreateProduct(ctx, product))
+		require.NoError(t, schedules.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 12, EffectiveAt: start}))
+
+		// a customer reserves stock after the due schedules were listed
+		s.schedules = &interleavedScheduleRepo{
+			PriceScheduleRepoInterface: schedules,
+			afterList: func() {
+				reservation := &datalayer.Reservation{ProductID: product.ID, Quantity: 2, ExpiresAt: start.Add(time.Hour)}
+				require.NoError(t, reservations.CreateReservation(ctx, reservation))
+			},
+		}
+
+		applied, err := s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+		got, _ := products.GetProductByID(ctx, product.ID)
+		assert.Equal(t, 12.0, got.Price)
+		assert.Equal(t, 3, got.Quantity, "the reserved stock must stay reserved")
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, service.ProductUpdated, events[0].Type)
+			assert.Equal(t, got, events[0].Product)
+		}
+	})
+
+	t.Run("should leave schedules to the replica holding the lock", func(t *testing.T) {
+		now := start
+		s, products, schedules := newTestPriceScheduler(&now)
+		s.locker = heldLocker{}
+		product := &datalayer.Product{Name: "Lamp", CategoryID: uuid.New(), Price: 10, Status: datalayer.ProductActive, CreatedAt: start}
+		require.NoError(t, products.CreateProduct(ctx, product))
+		require.NoError(t, schedules.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 12, EffectiveAt: start}))
+
+		applied, err := s.applyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+		got, _ := products.GetProductByID(ctx, product.ID)
+		assert.Equal(t, 10.0, got.Price)
+	})
+}
+
+func TestRunPriceScheduler(t *testing.T) {
+	t.Run("should apply due schedules on every tick until cancelled", func(t *testing.T) {
+		var calls atomic.Int32
+		repo := &mocks.MockPriceScheduleRepo{
+			ListDuePriceSchedulesFunc: func(context.Context, time.Time, int) ([]*datalayer.PriceSchedule, error) {
+				calls.Add(1)
+				return nil, errors.New("listDuePriceSchedules: select query failed: query error")
+			},
+		}
+		logger := handlermocks.NewLogger()
+		s := &priceScheduler{schedules: repo, locker: datalayer.NewLocalLocker(), now: time.Now, logger: logger}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			runPriceScheduler(ctx, s, time.Millisecond)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+
+		assert.Equal(t, "main.runPriceScheduler", logger.LogErrorCalls()[0].Op)
+	})
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// LogStartup logs every configured route followed by a summary line
+func LogStartup(logger handlers.LoggerInterface, addr string, routes []string, dbHost string) {
+	const op = "main.LogStartup"
+
+	for _, route := range routes {
+		logger.LogInfo(op, fmt.Sprintf("route registered: %s", route))
+	}
+	logger.LogInfo(op, fmt.Sprintf("server starting: addr=%s routes=%d db_host=%s", addr, len(routes), dbHost))
+}
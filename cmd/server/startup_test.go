@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogStartup(t *testing.T) {
+	t.Run("should log each route and a summary", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		LogStartup(logger, ":8080", []string{"GET /categories", "GET /categories/{id}"}, "localhost")
+
+		expected := []struct{ Op, Msg string }{
+			{Op: "main.LogStartup", Msg: "route registered: GET /categories"},
+			{Op: "main.LogStartup", Msg: "route registered: GET /categories/{id}"},
+			{Op: "main.LogStartup", Msg: "server starting: addr=:8080 routes=2 db_host=localhost"},
+		}
+		assert.Equal(t, expected, logger.LogInfoCalls())
+		assert.Empty(t, logger.LogErrorCalls())
+	})
+
+	t.Run("should log summary if no routes", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		LogStartup(logger, ":8080", []string{}, "localhost")
+
+		assert.Len(t, logger.LogInfoCalls(), 1)
+		assert.Equal(t, "server starting: addr=:8080 routes=0 db_host=localhost", logger.LogInfoCalls()[0].Msg)
+	})
+}
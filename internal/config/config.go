@@ -0,0 +1,290 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxBatchSize is the largest MAX_BATCH_SIZE, the repositories' own cap
+// datalayer.MaxBatchSize
+const maxBatchSize = 1000
+
+// Storage backends selectable with STORAGE
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+type Config struct {
+	Storage string
+	Server  ServerConfig
+	DB      DBConfig
+	Stock   StockConfig
+	Pricing PricingConfig
+}
+
+type ServerConfig struct {
+	Addr string
+	// JSONNaming is the key casing of response bodies: camel or snake
+	JSONNaming string
+	// AdminToken is the bearer token granting the admin role. Empty
+	// disables admin access.
+	AdminToken string
+	// MaxInFlight caps concurrently served requests. Zero disables the cap.
+	MaxInFlight int
+	// MaxEventStreams caps the open product event streams, which stay open
+	// and so are left out of MaxInFlight. Zero disables the cap.
+	MaxEventStreams int
+	// CursorSkew is how far list cursors are rewound to tolerate clock
+	// drift between the servers stamping created_at
+	CursorSkew time.Duration
+	// CategoryCacheTTL is how long identical category list responses are
+	// served from memory. Zero disables the cache.
+	CategoryCacheTTL time.Duration
+	// CacheMaxAge is the Cache-Control max-age of list and get responses.
+	// Zero sends no-store so clients always fetch afresh.
+	CacheMaxAge time.Duration
+	// SuccessMessages keeps the message of success responses. Turning it
+	// off shrinks responses for clients that only read the data.
+	SuccessMessages bool
+	// DeleteResponse is how deletes answer: no_content for an empty 204
+	// or envelope for a 200 success envelope
+	DeleteResponse string
+	// DeleteAlreadyDeletedOK answers a delete of a missing product or
+	// category as deleted instead of 404, so clients can retry deletes that
+	// timed out. Keep it off when a 404 should flag a wrong ID.
+	DeleteAlreadyDeletedOK bool
+	// UUIDVersion is the version ID parameters must have, 4 when every ID
+	// is issued by the service. Zero accepts any version.
+	UUIDVersion int
+	// MaxBatchSize is the most items a bulk create, batch get or product
+	// batch request may hold
+	MaxBatchSize int
+	// MaxRequestTimeout caps the timeout of every request, including
+	// routes given a longer one of their own. Zero leaves requests
+	// uncapped, bounded only by their route's own timeout. The server's
+	// write timeout is derived from it.
+	MaxRequestTimeout time.Duration
+	// ShutdownTimeout bounds how long each background component, and then
+	// the draining of in-flight requests, may take on shutdown
+	ShutdownTimeout time.Duration
+}
+
+type DBConfig struct {
+	Driver   string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+	// BreakerThreshold is how many consecutive failed reads open the
+	// circuit breaker. Zero disables the breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long an open breaker fails reads fast before
+	// letting one through to probe the database
+	BreakerCooldown time.Duration
+	// MinIdleConns is how many connections are opened and warmed up at
+	// startup and kept idle in the pool. Zero disables the warmup.
+	MinIdleConns int
+}
+
+// StockConfig controls stock reservations: how long a hold lasts and how
+// often expired holds are released back to stock
+type StockConfig struct {
+	ReservationTTL  time.Duration
+	JanitorInterval time.Duration
+}
+
+// PricingConfig controls how often due scheduled price changes are applied
+type PricingConfig struct {
+	ScheduleInterval time.Duration
+}
+
+// Load reads the configuration from environment variables, falling back to
+// defaults suitable for local development
+func Load() (Config, error) {
+	maxInFlight, err := getEnvInt("MAX_IN_FLIGHT", 200)
+	if err != nil {
+		return Config{}, err
+	}
+	maxEventStreams, err := getEnvInt("MAX_EVENT_STREAMS", 100)
+	if err != nil {
+		return Config{}, err
+	}
+	reservationTTL, err := getEnvDuration("RESERVATION_TTL", 15*time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	janitorInterval, err := getEnvDuration("RESERVATION_JANITOR_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	scheduleInterval, err := getEnvDuration("PRICE_SCHEDULE_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	cursorSkew, err := getEnvDuration("CURSOR_SKEW", 2*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	categoryCacheTTL, err := getEnvNonNegativeDuration("CATEGORY_CACHE_TTL", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	cacheMaxAge, err := getEnvNonNegativeDuration("CACHE_MAX_AGE", 30*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	successMessages, err := getEnvBool("SUCCESS_MESSAGES", true)
+	if err != nil {
+		return Config{}, err
+	}
+	deleteAlreadyDeletedOK, err := getEnvBool("DELETE_ALREADY_DELETED_OK", false)
+	if err != nil {
+		return Config{}, err
+	}
+	uuidVersion, err := getEnvInt("UUID_VERSION", 0)
+	if err != nil {
+		return Config{}, err
+	}
+	if uuidVersion > 8 {
+		return Config{}, fmt.Errorf("config: UUID_VERSION must be from 0 to 8, got `%d`", uuidVersion)
+	}
+	maxBatch, err := getEnvInt("MAX_BATCH_SIZE", 100)
+	if err != nil {
+		return Config{}, err
+	}
+	if maxBatch < 1 || maxBatch > maxBatchSize {
+		return Config{}, fmt.Errorf("config: MAX_BATCH_SIZE must be from 1 to %d, got `%d`", maxBatchSize, maxBatch)
+	}
+	maxRequestTimeout, err := getEnvNonNegativeDuration("MAX_REQUEST_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	shutdownTimeout, err := getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	breakerThreshold, err := getEnvInt("DB_BREAKER_THRESHOLD", 5)
+	if err != nil {
+		return Config{}, err
+	}
+	breakerCooldown, err := getEnvDuration("DB_BREAKER_COOLDOWN", 10*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	minIdleConns, err := getEnvInt("DB_MIN_IDLE_CONNS", 0)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		Storage: getEnv("STORAGE", StoragePostgres),
+		Server: ServerConfig{
+			Addr:                   getEnv("SERVER_ADDR", ":8080"),
+			JSONNaming:             getEnv("JSON_NAMING", "camel"),
+			AdminToken:             getEnv("ADMIN_TOKEN", ""),
+			MaxInFlight:            maxInFlight,
+			MaxEventStreams:        maxEventStreams,
+			CursorSkew:             cursorSkew,
+			CategoryCacheTTL:       categoryCacheTTL,
+			CacheMaxAge:            cacheMaxAge,
+			SuccessMessages:        successMessages,
+			DeleteResponse:         getEnv("DELETE_RESPONSE", "no_content"),
+			DeleteAlreadyDeletedOK: deleteAlreadyDeletedOK,
+			UUIDVersion:            uuidVersion,
+			MaxBatchSize:           maxBatch,
+			MaxRequestTimeout:      maxRequestTimeout,
+			ShutdownTimeout:        shutdownTimeout,
+		},
+		DB: DBConfig{
+			Driver:           getEnv("DB_DRIVER", "postgres"),
+			Host:             getEnv("DB_HOST", "localhost"),
+			Port:             getEnv("DB_PORT", "5432"),
+			User:             getEnv("DB_USER", "postgres"),
+			Password:         getEnv("DB_PASSWORD", ""),
+			Name:             getEnv("DB_NAME", "products"),
+			SSLMode:          getEnv("DB_SSLMODE", "disable"),
+			BreakerThreshold: breakerThreshold,
+			BreakerCooldown:  breakerCooldown,
+			MinIdleConns:     minIdleConns,
+		},
+		Stock: StockConfig{
+			ReservationTTL:  reservationTTL,
+			JanitorInterval: janitorInterval,
+		},
+		Pricing: PricingConfig{
+			ScheduleInterval: scheduleInterval,
+		},
+	}, nil
+}
+
+// DSN returns the key/value connection string for the database
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("config: %s must be a non-negative integer, got `%s`", key, value)
+	}
+	return n, nil
+}
+
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("config: %s must be a boolean, got `%s`", key, value)
+	}
+	return b, nil
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("config: %s must be a positive duration, got `%s`", key, value)
+	}
+	return d, nil
+}
+
+// getEnvNonNegativeDuration is getEnvDuration for settings where zero
+// turns the feature off
+func getEnvNonNegativeDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("config: %s must be a non-negative duration, got `%s`", key, value)
+	}
+	return d, nil
+}
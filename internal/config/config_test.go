@@ -0,0 +1,155 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("should use defaults if env not set", func(t *testing.T) {
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, StoragePostgres, cfg.Storage)
+		assert.Equal(t, ":8080", cfg.Server.Addr)
+		assert.Empty(t, cfg.Server.AdminToken)
+		assert.Equal(t, 200, cfg.Server.MaxInFlight)
+		assert.Equal(t, 100, cfg.Server.MaxEventStreams)
+		assert.Equal(t, 2*time.Second, cfg.Server.CursorSkew)
+		assert.Equal(t, 5*time.Second, cfg.Server.CategoryCacheTTL)
+		assert.Equal(t, 30*time.Second, cfg.Server.CacheMaxAge)
+		assert.True(t, cfg.Server.SuccessMessages)
+		assert.Equal(t, "no_content", cfg.Server.DeleteResponse)
+		assert.False(t, cfg.Server.DeleteAlreadyDeletedOK)
+		assert.Zero(t, cfg.Server.UUIDVersion)
+		assert.Equal(t, 100, cfg.Server.MaxBatchSize)
+		assert.Equal(t, 30*time.Second, cfg.Server.MaxRequestTimeout)
+		assert.Equal(t, 10*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, 5, cfg.DB.BreakerThreshold)
+		assert.Equal(t, 10*time.Second, cfg.DB.BreakerCooldown)
+		assert.Zero(t, cfg.DB.MinIdleConns)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, StockConfig{ReservationTTL: 15 * time.Minute, JanitorInterval: time.Minute}, cfg.Stock)
+		assert.Equal(t, time.Minute, cfg.Pricing.ScheduleInterval)
+	})
+
+	t.Run("should read values from env", func(t *testing.T) {
+		t.Setenv("SERVER_ADDR", ":9090")
+		t.Setenv("DB_HOST", "db.internal")
+		t.Setenv("STORAGE", StorageMemory)
+		t.Setenv("RESERVATION_TTL", "5m")
+		t.Setenv("ADMIN_TOKEN", "secret")
+		t.Setenv("PRICE_SCHEDULE_INTERVAL", "30s")
+		t.Setenv("MAX_IN_FLIGHT", "0")
+		t.Setenv("MAX_EVENT_STREAMS", "10")
+		t.Setenv("CURSOR_SKEW", "500ms")
+		t.Setenv("CATEGORY_CACHE_TTL", "1m")
+		t.Setenv("CACHE_MAX_AGE", "10s")
+		t.Setenv("DB_BREAKER_THRESHOLD", "0")
+		t.Setenv("DB_BREAKER_COOLDOWN", "1m")
+		t.Setenv("DB_MIN_IDLE_CONNS", "4")
+		t.Setenv("SUCCESS_MESSAGES", "false")
+		t.Setenv("SHUTDOWN_TIMEOUT", "30s")
+		t.Setenv("DELETE_RESPONSE", "envelope")
+		t.Setenv("DELETE_ALREADY_DELETED_OK", "true")
+		t.Setenv("UUID_VERSION", "4")
+		t.Setenv("MAX_BATCH_SIZE", "500")
+		t.Setenv("MAX_REQUEST_TIMEOUT", "5s")
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, StorageMemory, cfg.Storage)
+		assert.Equal(t, ":9090", cfg.Server.Addr)
+		assert.Equal(t, "db.internal", cfg.DB.Host)
+		assert.Equal(t, 5*time.Minute, cfg.Stock.ReservationTTL)
+		assert.Equal(t, "secret", cfg.Server.AdminToken)
+		assert.Equal(t, 30*time.Second, cfg.Pricing.ScheduleInterval)
+		assert.Zero(t, cfg.Server.MaxInFlight)
+		assert.Equal(t, 10, cfg.Server.MaxEventStreams)
+		assert.Equal(t, 500*time.Millisecond, cfg.Server.CursorSkew)
+		assert.Equal(t, time.Minute, cfg.Server.CategoryCacheTTL)
+		assert.Equal(t, 10*time.Second, cfg.Server.CacheMaxAge)
+		assert.Zero(t, cfg.DB.BreakerThreshold)
+		assert.Equal(t, time.Minute, cfg.DB.BreakerCooldown)
+		assert.Equal(t, 4, cfg.DB.MinIdleConns)
+		assert.False(t, cfg.Server.SuccessMessages)
+		assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, "envelope", cfg.Server.DeleteResponse)
+		assert.True(t, cfg.Server.DeleteAlreadyDeletedOK)
+		assert.Equal(t, 4, cfg.Server.UUIDVersion)
+		assert.Equal(t, 500, cfg.Server.MaxBatchSize)
+		assert.Equal(t, 5*time.Second, cfg.Server.MaxRequestTimeout)
+	})
+
+	t.Run("should return error if an integer is not a number", func(t *testing.T) {
+		t.Setenv("MAX_IN_FLIGHT", "lots")
+		_, err := Load()
+		assert.EqualError(t, err, "config: MAX_IN_FLIGHT must be a non-negative integer, got `lots`")
+	})
+
+	t.Run("should return error if a flag is not a boolean", func(t *testing.T) {
+		t.Setenv("SUCCESS_MESSAGES", "maybe")
+		_, err := Load()
+		assert.EqualError(t, err, "config: SUCCESS_MESSAGES must be a boolean, got `maybe`")
+	})
+
+	t.Run("should return error if duration is invalid", func(t *testing.T) {
+		t.Setenv("RESERVATION_JANITOR_INTERVAL", "0s")
+		_, err := Load()
+		assert.EqualError(t, err, "config: RESERVATION_JANITOR_INTERVAL must be a positive duration, got `0s`")
+	})
+
+	t.Run("should return error if cursor skew is zero", func(t *testing.T) {
+		t.Setenv("CURSOR_SKEW", "0")
+		_, err := Load()
+		assert.EqualError(t, err, "config: CURSOR_SKEW must be a positive duration, got `0`")
+	})
+
+	t.Run("should accept a zero request timeout cap and cache ttl", func(t *testing.T) {
+		t.Setenv("MAX_REQUEST_TIMEOUT", "0")
+		t.Setenv("CATEGORY_CACHE_TTL", "0s")
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Zero(t, cfg.Server.MaxRequestTimeout)
+		assert.Zero(t, cfg.Server.CategoryCacheTTL)
+	})
+
+	t.Run("should accept a zero cache max age", func(t *testing.T) {
+		t.Setenv("CACHE_MAX_AGE", "0")
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Zero(t, cfg.Server.CacheMaxAge)
+	})
+
+	t.Run("should return error if cache max age is negative", func(t *testing.T) {
+		t.Setenv("CACHE_MAX_AGE", "-30s")
+		_, err := Load()
+		assert.EqualError(t, err, "config: CACHE_MAX_AGE must be a non-negative duration, got `-30s`")
+	})
+
+	t.Run("should return error if cursor skew is negative", func(t *testing.T) {
+		t.Setenv("CURSOR_SKEW", "-1s")
+		_, err := Load()
+		assert.EqualError(t, err, "config: CURSOR_SKEW must be a positive duration, got `-1s`")
+	})
+
+	t.Run("should return error if uuid version is out of range", func(t *testing.T) {
+		t.Setenv("UUID_VERSION", "9")
+		_, err := Load()
+		assert.EqualError(t, err, "config: UUID_VERSION must be from 0 to 8, got `9`")
+	})
+
+	t.Run("should return error if max batch size is out of range", func(t *testing.T) {
+		for _, size := range []string{"0", "1001"} {
+			t.Setenv("MAX_BATCH_SIZE", size)
+			_, err := Load()
+			assert.EqualError(t, err, "config: MAX_BATCH_SIZE must be from 1 to 1000, got `"+size+"`")
+		}
+	})
+}
+
+func TestDSN(t *testing.T) {
+	cfg := DBConfig{Host: "db", Port: "5432", User: "app", Password: "secret", Name: "products", SSLMode: "require"}
+	expected := "host=db port=5432 user=app password=secret dbname=products sslmode=require"
+	assert.Equal(t, expected, cfg.DSN())
+}
@@ -0,0 +1,264 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrAttributeExists = errors.New("attribute is already defined for the category")
+
+// AttributeType is the value type a product attribute must have
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "bool"
+	AttributeEnum   AttributeType = "enum"
+)
+
+// AttributeTypes lists every accepted attribute type
+var AttributeTypes = []AttributeType{AttributeString, AttributeNumber, AttributeBool, AttributeEnum}
+
+// Valid reports whether t is one of AttributeTypes
+func (t AttributeType) Valid() bool {
+	for _, attributeType := range AttributeTypes {
+		if t == attributeType {
+			return true
+		}
+	}
+	return false
+}
+
+// EnumValues are the values an enum attribute accepts, stored as a JSONB
+// array
+type EnumValues []string
+
+// Value encodes the values as a JSON array, writing nil as an empty one
+func (v EnumValues) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(v))
+}
+
+// Scan decodes the JSONB column into the values
+func (v *EnumValues) Scan(src any) error {
+	var raw []byte
+	switch s := src.(type) {
+	case nil:
+		*v = EnumValues{}
+		return nil
+	case []byte:
+		raw = s
+	case string:
+		raw = []byte(s)
+	default:
+		return fmt.Errorf("scan enum values: unsupported type %T", src)
+	}
+
+	values := EnumValues{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return fmt.Errorf("scan enum values: %w", err)
+	}
+	*v = values
+	return nil
+}
+
+// AttributeDefinition declares a typed attribute that products of a
+// category may or, if Required, must carry. AllowedValues is only set for
+// enum attributes.
+type AttributeDefinition struct {
+	ID            uuid.UUID     `db:"id" json:"id"`
+	CategoryID    uuid.UUID     `db:"category_id" json:"categoryId"`
+	Name          string        `db:"name" json:"name"`
+	Type          AttributeType `db:"type" json:"type"`
+	Required      bool          `db:"required" json:"required"`
+	AllowedValues EnumValues    `db:"allowed_values" json:"allowedValues"`
+	CreatedAt     time.Time     `db:"created_at" json:"createdAt"`
+}
+
+type AttributeDefinitionRepo struct {
+	db  *sqlx.DB
+	now func() time.Time
+}
+
+type AttributeDefinitionRepoInterface interface {
+	CreateAttributeDefinition(ctx context.Context, definition *AttributeDefinition) error
+	ListAttributeDefinitions(ctx context.Context, categoryID uuid.UUID) ([]*AttributeDefinition, error)
+	GetAttributeDefinition(ctx context.Context, id uuid.UUID) (*AttributeDefinition, error)
+	UpdateAttributeDefinition(ctx context.Context, definition *AttributeDefinition) error
+	DeleteAttributeDefinition(ctx context.Context, id uuid.UUID) error
+}
+
+// NewAttributeDefinitionRepo creates a new repository instance
+func NewAttributeDefinitionRepo(db *sqlx.DB) AttributeDefinitionRepoInterface {
+	return &AttributeDefinitionRepo{db: db, now: time.Now}
+}
+
+// CreateAttributeDefinition stores a definition for an existing category,
+// failing with ErrAttributeExists if the category already defines Name.
+// The category row is locked so concurrent creates for it are serialized.
+// ID and CreatedAt are set when empty.
+func (r *AttributeDefinitionRepo) CreateAttributeDefinition(ctx context.Context, definition *AttributeDefinition) error {
+	const op = "createAttributeDefinition"
+	const categoryQuery = `SELECT id FROM categories WHERE id = $1 FOR UPDATE`
+	const conflictQuery = `SELECT EXISTS(SELECT 1 FROM attribute_definitions WHERE category_id = $1 AND name = $2)`
+	const insertQuery = `
+		INSERT INTO attribute_definitions(id, category_id, name, type, required, allowed_values, created_at)
+		VALUES(:id, :category_id, :name, :type, :required, :allowed_values, :created_at)`
+
+	if definition.ID == uuid.Nil {
+		definition.ID = uuid.New()
+	}
+	if definition.CreatedAt.IsZero() {
+		definition.CreatedAt = r.now()
+	}
+	toUTC(&definition.CreatedAt)
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = EnumValues{}
+	}
+
+	return withTx(ctx, r.db, op, EntityAttributeDefinition, func(tx *sqlx.Tx) error {
+		var categoryID uuid.UUID
+		if err := tx.GetContext(ctx, &categoryID, categoryQuery, definition.CategoryID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: category id `%s`", ErrNotFound, definition.CategoryID))
+			}
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+		}
+
+		var conflict bool
+		if err := tx.GetContext(ctx, &conflict, conflictQuery, definition.CategoryID, definition.Name); err != nil {
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+		}
+		if conflict {
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: name `%s`", ErrAttributeExists, definition.Name))
+		}
+
+		result, err := tx.NamedExecContext(ctx, insertQuery, definition)
+		if err != nil {
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("insert query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityAttributeDefinition)
+	})
+}
+
+// ListAttributeDefinitions fetches a category's definitions in name order.
+// The category must exist.
+func (r *AttributeDefinitionRepo) ListAttributeDefinitions(ctx context.Context, categoryID uuid.UUID) ([]*AttributeDefinition, error) {
+	const op = "listAttributeDefinitions"
+	const categoryQuery = `SELECT id FROM categories WHERE id = $1`
+	const selectQuery = `
+		SELECT id, category_id, name, type, required, allowed_values, created_at
+		FROM attribute_definitions
+		WHERE category_id = $1
+		ORDER BY name ASC`
+
+	var id uuid.UUID
+	if err := r.db.GetContext(ctx, &id, categoryQuery, categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: category id `%s`", ErrNotFound, categoryID))
+		}
+		return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+	}
+
+	definitions := []*AttributeDefinition{}
+	if err := r.db.SelectContext(ctx, &definitions, selectQuery, categoryID); err != nil {
+		return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+	}
+	for _, definition := range definitions {
+		toUTC(&definition.CreatedAt)
+	}
+	return definitions, nil
+}
+
+// GetAttributeDefinition fetches a definition by its ID
+func (r *AttributeDefinitionRepo) GetAttributeDefinition(ctx context.Context, id uuid.UUID) (*AttributeDefinition, error) {
+	const op = "getAttributeDefinition"
+	const query = `
+		SELECT id, category_id, name, type, required, allowed_values, created_at
+		FROM attribute_definitions
+		WHERE id = $1`
+
+	var definition AttributeDefinition
+	if err := r.db.GetContext(ctx, &definition, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+		}
+		return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+	}
+	toUTC(&definition.CreatedAt)
+	return &definition, nil
+}
+
+// UpdateAttributeDefinition replaces the name, type, required flag and
+// allowed values of a definition. CategoryID and CreatedAt are filled in
+// from the stored row. Renaming onto another definition of the category
+// fails with ErrAttributeExists.
+func (r *AttributeDefinitionRepo) UpdateAttributeDefinition(ctx context.Context, definition *AttributeDefinition) error {
+	const op = "updateAttributeDefinition"
+	const selectQuery = `SELECT category_id, created_at FROM attribute_definitions WHERE id = $1 FOR UPDATE`
+	const conflictQuery = `SELECT EXISTS(SELECT 1 FROM attribute_definitions WHERE category_id = $1 AND name = $2 AND id <> $3)`
+	const updateQuery = `
+		UPDATE attribute_definitions
+		SET name=:name, type=:type, required=:required, allowed_values=:allowed_values
+		WHERE id=:id`
+
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = EnumValues{}
+	}
+
+	return withTx(ctx, r.db, op, EntityAttributeDefinition, func(tx *sqlx.Tx) error {
+		var stored struct {
+			CategoryID uuid.UUID `db:"category_id"`
+			CreatedAt  time.Time `db:"created_at"`
+		}
+		if err := tx.GetContext(ctx, &stored, selectQuery, definition.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: id `%s`", ErrNotFound, definition.ID))
+			}
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+		}
+
+		var conflict bool
+		if err := tx.GetContext(ctx, &conflict, conflictQuery, stored.CategoryID, definition.Name, definition.ID); err != nil {
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("select query failed: %w", err))
+		}
+		if conflict {
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: name `%s`", ErrAttributeExists, definition.Name))
+		}
+
+		result, err := tx.NamedExecContext(ctx, updateQuery, definition)
+		if err != nil {
+			return repoError(op, EntityAttributeDefinition, fmt.Errorf("update query failed: %w", err))
+		}
+		if err := checkRowsAffected(result, op, EntityAttributeDefinition); err != nil {
+			return err
+		}
+		definition.CategoryID = stored.CategoryID
+		definition.CreatedAt = stored.CreatedAt.UTC()
+		return nil
+	})
+}
+
+// DeleteAttributeDefinition removes a definition. Products keep any value
+// they hold for it.
+func (r *AttributeDefinitionRepo) DeleteAttributeDefinition(ctx context.Context, id uuid.UUID) error {
+	const op = "deleteAttributeDefinition"
+	const query = `DELETE FROM attribute_definitions WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return repoError(op, EntityAttributeDefinition, fmt.Errorf("delete query failed: %w", err))
+	}
+	return checkRowsAffected(result, op, EntityAttributeDefinition)
+}
@@ -0,0 +1,255 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testDefinition = AttributeDefinition{
+	ID:            uuid.MustParse("3f9d1c2e-5b7a-4e8f-9a6d-2c4b8e1f7a3d"),
+	CategoryID:    testCategoryOne.ID,
+	Name:          "finish",
+	Type:          AttributeEnum,
+	Required:      true,
+	AllowedValues: EnumValues{"matte", "gloss"},
+	CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var definitionColumns = []string{"id", "category_id", "name", "type", "required", "allowed_values", "created_at"}
+
+func definitionRow(definition AttributeDefinition) *sqlmock.Rows {
+	values, _ := definition.AllowedValues.Value()
+	return sqlmock.NewRows(definitionColumns).
+		AddRow(definition.ID, definition.CategoryID, definition.Name, definition.Type, definition.Required, values, definition.CreatedAt)
+}
+
+func newTestAttributeDefinitionRepo(t *testing.T) (*AttributeDefinitionRepo, sqlmock.Sqlmock) {
+	mockDB, mock, _ := sqlmock.New()
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	now := func() time.Time { return testDefinition.CreatedAt }
+	return &AttributeDefinitionRepo{db: db, now: now}, mock
+}
+
+func TestCreateAttributeDefinition(t *testing.T) {
+	ctx := context.Background()
+	categoryQuery := regexp.QuoteMeta(`SELECT id FROM categories WHERE id = $1 FOR UPDATE`)
+	conflictQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM attribute_definitions WHERE category_id = $1 AND name = $2)`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO attribute_definitions(id, category_id, name, type, required, allowed_values, created_at)`)
+
+	t.Run("should lock the category and insert the definition", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(categoryQuery).WithArgs(testCategoryOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testCategoryOne.ID))
+		mock.ExpectQuery(conflictQuery).WithArgs(testCategoryOne.ID, "finish").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(insertQuery).
+			WithArgs(testDefinition.ID, testCategoryOne.ID, "finish", AttributeEnum, true, []byte(`["matte","gloss"]`), testDefinition.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		definition := &AttributeDefinition{
+			ID:            testDefinition.ID,
+			CategoryID:    testCategoryOne.ID,
+			Name:          "finish",
+			Type:          AttributeEnum,
+			Required:      true,
+			AllowedValues: EnumValues{"matte", "gloss"},
+		}
+		err := repo.CreateAttributeDefinition(ctx, definition)
+		assert.NoError(t, err)
+		assert.Equal(t, &testDefinition, definition)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the category already defines the name", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(categoryQuery).WithArgs(testCategoryOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testCategoryOne.ID))
+		mock.ExpectQuery(conflictQuery).WithArgs(testCategoryOne.ID, "finish").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		err := repo.CreateAttributeDefinition(ctx, &AttributeDefinition{CategoryID: testCategoryOne.ID, Name: "finish", Type: AttributeString})
+		assert.True(t, errors.Is(err, ErrAttributeExists))
+		assert.Equal(t, "createAttributeDefinition: attribute is already defined for the category: name `finish`", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrNotFound if the category does not exist", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(categoryQuery).WithArgs(testCategoryOne.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := repo.CreateAttributeDefinition(ctx, &AttributeDefinition{CategoryID: testCategoryOne.ID, Name: "finish", Type: AttributeString})
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListAttributeDefinitions(t *testing.T) {
+	ctx := context.Background()
+	categoryQuery := regexp.QuoteMeta(`SELECT id FROM categories WHERE id = $1`)
+	selectQuery := regexp.QuoteMeta(`
+		SELECT id, category_id, name, type, required, allowed_values, created_at
+		FROM attribute_definitions
+		WHERE category_id = $1
+		ORDER BY name ASC`)
+
+	t.Run("should return the category's definitions", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectQuery(categoryQuery).WithArgs(testCategoryOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testCategoryOne.ID))
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).WillReturnRows(definitionRow(testDefinition))
+
+		definitions, err := repo.ListAttributeDefinitions(ctx, testCategoryOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []*AttributeDefinition{&testDefinition}, definitions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return an empty list if none are defined", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectQuery(categoryQuery).WithArgs(testCategoryOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testCategoryOne.ID))
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).WillReturnRows(sqlmock.NewRows(definitionColumns))
+
+		definitions, err := repo.ListAttributeDefinitions(ctx, testCategoryOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []*AttributeDefinition{}, definitions)
+	})
+
+	t.Run("should return ErrNotFound if the category does not exist", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectQuery(categoryQuery).WithArgs(testCategoryOne.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		definitions, err := repo.ListAttributeDefinitions(ctx, testCategoryOne.ID)
+		assert.Nil(t, definitions)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestGetAttributeDefinition(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(`
+		SELECT id, category_id, name, type, required, allowed_values, created_at
+		FROM attribute_definitions
+		WHERE id = $1`)
+
+	t.Run("should return the definition", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectQuery(selectQuery).WithArgs(testDefinition.ID).WillReturnRows(definitionRow(testDefinition))
+
+		definition, err := repo.GetAttributeDefinition(ctx, testDefinition.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &testDefinition, definition)
+	})
+
+	t.Run("should return ErrNotFound if no row", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectQuery(selectQuery).WithArgs(testDefinition.ID).WillReturnRows(sqlmock.NewRows(definitionColumns))
+
+		definition, err := repo.GetAttributeDefinition(ctx, testDefinition.ID)
+		assert.Nil(t, definition)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestUpdateAttributeDefinition(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(`SELECT category_id, created_at FROM attribute_definitions WHERE id = $1 FOR UPDATE`)
+	conflictQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM attribute_definitions WHERE category_id = $1 AND name = $2 AND id <> $3)`)
+	updateQuery := regexp.QuoteMeta(`UPDATE attribute_definitions`)
+	storedRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_id", "created_at"}).AddRow(testCategoryOne.ID, testDefinition.CreatedAt)
+	}
+
+	t.Run("should update the definition and fill in stored fields", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testDefinition.ID).WillReturnRows(storedRow())
+		mock.ExpectQuery(conflictQuery).WithArgs(testCategoryOne.ID, "finish", testDefinition.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(updateQuery).
+			WithArgs("finish", AttributeEnum, true, []byte(`["matte","gloss"]`), testDefinition.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		definition := &AttributeDefinition{
+			ID:            testDefinition.ID,
+			Name:          "finish",
+			Type:          AttributeEnum,
+			Required:      true,
+			AllowedValues: EnumValues{"matte", "gloss"},
+		}
+		err := repo.UpdateAttributeDefinition(ctx, definition)
+		assert.NoError(t, err)
+		assert.Equal(t, &testDefinition, definition)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if renamed onto another definition", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testDefinition.ID).WillReturnRows(storedRow())
+		mock.ExpectQuery(conflictQuery).WithArgs(testCategoryOne.ID, "color", testDefinition.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		err := repo.UpdateAttributeDefinition(ctx, &AttributeDefinition{ID: testDefinition.ID, Name: "color", Type: AttributeString})
+		assert.True(t, errors.Is(err, ErrAttributeExists))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrNotFound if no row", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testDefinition.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id", "created_at"}))
+		mock.ExpectRollback()
+
+		err := repo.UpdateAttributeDefinition(ctx, &AttributeDefinition{ID: testDefinition.ID, Name: "finish", Type: AttributeString})
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteAttributeDefinition(t *testing.T) {
+	ctx := context.Background()
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM attribute_definitions WHERE id = $1`)
+
+	t.Run("should delete the definition", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectExec(deleteQuery).WithArgs(testDefinition.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.DeleteAttributeDefinition(ctx, testDefinition.ID))
+	})
+
+	t.Run("should return ErrNotFound if nothing was deleted", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectExec(deleteQuery).WithArgs(testDefinition.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteAttributeDefinition(ctx, testDefinition.ID)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("should return error if delete query fails", func(t *testing.T) {
+		repo, mock := newTestAttributeDefinitionRepo(t)
+		mock.ExpectExec(deleteQuery).WillReturnError(errors.New("query error"))
+
+		err := repo.DeleteAttributeDefinition(ctx, testDefinition.ID)
+		assert.EqualError(t, err, "deleteAttributeDefinition: delete query failed: query error")
+	})
+}
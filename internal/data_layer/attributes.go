@@ -0,0 +1,104 @@
+package datalayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// marshalJSONObject encodes an attribute map for a JSONB column or a
+// response body, writing nil as an empty object
+func marshalJSONObject(m map[string]any) ([]byte, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// scanJSONObject decodes a JSONB column into an attribute map. NULL reads
+// as an empty map.
+func scanJSONObject(src any) (map[string]any, error) {
+	m := map[string]any{}
+	if err := scanJSONColumn(src, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// scanJSONColumn decodes a JSON or JSONB column into dst, leaving dst as
+// it is for NULL
+func scanJSONColumn(src any, dst any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T", src)
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// attributeCandidates returns the JSON values an attribute filter value
+// matches. Query strings carry no type, so "true" matches both the string
+// and the bool and "5" both the string and the number.
+func attributeCandidates(raw string) []any {
+	candidates := []any{raw}
+	if raw == "true" || raw == "false" {
+		candidates = append(candidates, raw == "true")
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		candidates = append(candidates, f)
+	}
+	return candidates
+}
+
+// whereAttributes adds one JSONB containment condition on the attributes
+// column per filter entry to b. Keys are sorted so built queries are
+// deterministic.
+func whereAttributes(b *QueryBuilder, filter map[string]string) *QueryBuilder {
+	for _, key := range slices.Sorted(maps.Keys(filter)) {
+		candidates := attributeCandidates(filter[key])
+		condition := "("
+		args := make([]any, len(candidates))
+		for i, candidate := range candidates {
+			if i > 0 {
+				condition += " OR "
+			}
+			condition += "attributes @> ?::jsonb"
+			doc, _ := json.Marshal(map[string]any{key: candidate})
+			args[i] = string(doc)
+		}
+		b.Where(condition+")", args...)
+	}
+	return b
+}
+
+// attributesMatch reports whether stored passes every filter entry. It
+// mirrors the containment query for the in-memory repos by comparing JSON
+// encodings.
+func attributesMatch(stored map[string]any, filter map[string]string) bool {
+	for key, raw := range filter {
+		value, ok := stored[key]
+		if !ok {
+			return false
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return false
+		}
+		if !slices.ContainsFunc(attributeCandidates(raw), func(candidate any) bool {
+			want, _ := json.Marshal(candidate)
+			return string(want) == string(encoded)
+		}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package datalayer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+// benchProducts is the size of the seeded product table, large enough to
+// fill the biggest page
+const benchProducts = 1000
+
+func BenchmarkGetProductByID(b *testing.B) {
+	repo, products := seedBenchProducts(b)
+	ctx := context.Background()
+	id := products[len(products)/2].ID
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetProductByID(ctx, id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListProducts(b *testing.B) {
+	repo, _ := seedBenchProducts(b)
+	ctx := context.Background()
+
+	for _, limit := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, limit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func seedBenchProducts(b *testing.B) (*datalayer.MemoryProductRepo, []*datalayer.Product) {
+	b.Helper()
+
+	repo := datalayer.NewMemoryProductRepo()
+	categoryID := uuid.New()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	products := make([]*datalayer.Product, benchProducts)
+	for i := range products {
+		products[i] = &datalayer.Product{
+			Name:       fmt.Sprintf("Product %04d", i),
+			CategoryID: categoryID,
+			Price:      9.99,
+			Quantity:   i,
+			Status:     datalayer.ProductActive,
+			CreatedAt:  base.Add(time.Duration(i) * time.Second),
+		}
+		if err := repo.CreateProduct(context.Background(), products[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo, products
+}
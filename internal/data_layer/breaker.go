@@ -0,0 +1,233 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCircuitOpen is returned without touching the database while a Breaker
+// is open
+var ErrCircuitOpen = errors.New("database circuit open")
+
+// Breaker fails calls fast once the database looks down. After threshold
+// consecutive failures it opens for cooldown, then lets a single probe
+// through: success closes it again, failure reopens it for another
+// cooldown. Not found errors and cancelled contexts are answers, not
+// outages, and do not count.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewBreaker creates a Breaker opening after threshold consecutive failures
+func NewBreaker(threshold int, cooldown time.Duration, now func() time.Time) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: now}
+}
+
+// Do runs fn unless the breaker is open and records its outcome
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// allow reports whether a call may go through, claiming the probe once the
+// cooldown has passed
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts err towards opening the breaker, or closes it on success
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	switch {
+	case errors.Is(err, context.Canceled):
+		// the caller gave up, which says nothing about the database
+		return
+	case !isOutage(err):
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// isOutage reports whether err suggests the database itself is failing
+func isOutage(err error) bool {
+	return err != nil && !errors.Is(err, ErrNotFound)
+}
+
+// breakerCall runs fn through b, returning its result
+func breakerCall[T any](b *Breaker, fn func() (T, error)) (T, error) {
+	var result T
+	err := b.Do(func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
+
+// BreakerCategoryRepo guards the read methods of a category repo with a
+// Breaker. Writes go straight through so they fail on their own terms.
+type BreakerCategoryRepo struct {
+	CategoryRepoInterface
+	breaker *Breaker
+}
+
+// NewBreakerCategoryRepo wraps repo's reads in breaker
+func NewBreakerCategoryRepo(repo CategoryRepoInterface, breaker *Breaker) *BreakerCategoryRepo {
+	return &BreakerCategoryRepo{CategoryRepoInterface: repo, breaker: breaker}
+}
+
+func (r *BreakerCategoryRepo) GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error) {
+	return breakerCall(r.breaker, func() (*Category, error) {
+		return r.CategoryRepoInterface.GetCategoryByID(ctx, id)
+	})
+}
+
+func (r *BreakerCategoryRepo) GetCategoriesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Category, error) {
+	return breakerCall(r.breaker, func() (map[uuid.UUID]*Category, error) {
+		return r.CategoryRepoInterface.GetCategoriesByIDs(ctx, ids)
+	})
+}
+
+func (r *BreakerCategoryRepo) CategoryExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return breakerCall(r.breaker, func() (bool, error) {
+		return r.CategoryRepoInterface.CategoryExists(ctx, id)
+	})
+}
+
+func (r *BreakerCategoryRepo) GetCategoryByName(ctx context.Context, name string) (*Category, error) {
+	return breakerCall(r.breaker, func() (*Category, error) {
+		return r.CategoryRepoInterface.GetCategoryByName(ctx, name)
+	})
+}
+
+func (r *BreakerCategoryRepo) ListCategories(
+	ctx context.Context,
+	filter CategoryFilter,
+	createdAfter time.Time,
+	limit int,
+) (*CategoryPage, error) {
+	return breakerCall(r.breaker, func() (*CategoryPage, error) {
+		return r.CategoryRepoInterface.ListCategories(ctx, filter, createdAfter, limit)
+	})
+}
+
+func (r *BreakerCategoryRepo) CountCategories(ctx context.Context, filter CategoryFilter, createdAfter time.Time) (int64, error) {
+	return breakerCall(r.breaker, func() (int64, error) {
+		return r.CategoryRepoInterface.CountCategories(ctx, filter, createdAfter)
+	})
+}
+
+// BreakerProductRepo guards the read methods of a product repo with a
+// Breaker. Writes go straight through so they fail on their own terms.
+type BreakerProductRepo struct {
+	ProductRepoInterface
+	breaker *Breaker
+}
+
+// NewBreakerProductRepo wraps repo's reads in breaker
+func NewBreakerProductRepo(repo ProductRepoInterface, breaker *Breaker) *BreakerProductRepo {
+	return &BreakerProductRepo{ProductRepoInterface: repo, breaker: breaker}
+}
+
+func (r *BreakerProductRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*Product, error) {
+	return breakerCall(r.breaker, func() (*Product, error) {
+		return r.ProductRepoInterface.GetProductByID(ctx, id)
+	})
+}
+
+func (r *BreakerProductRepo) ProductExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return breakerCall(r.breaker, func() (bool, error) {
+		return r.ProductRepoInterface.ProductExists(ctx, id)
+	})
+}
+
+func (r *BreakerProductRepo) GetProductAvailability(ctx context.Context, id uuid.UUID) (int, error) {
+	return breakerCall(r.breaker, func() (int, error) {
+		return r.ProductRepoInterface.GetProductAvailability(ctx, id)
+	})
+}
+
+func (r *BreakerProductRepo) ListProducts(
+	ctx context.Context,
+	filter ProductFilter,
+	createdAfter time.Time,
+	limit int,
+) ([]*Product, error) {
+	return breakerCall(r.breaker, func() ([]*Product, error) {
+		return r.ProductRepoInterface.ListProducts(ctx, filter, createdAfter, limit)
+	})
+}
+
+func (r *BreakerProductRepo) CountAllProducts(ctx context.Context, filter ProductCountFilter) (int64, error) {
+	return breakerCall(r.breaker, func() (int64, error) {
+		return r.ProductRepoInterface.CountAllProducts(ctx, filter)
+	})
+}
+
+func (r *BreakerProductRepo) ListRelatedProducts(ctx context.Context, productID uuid.UUID, limit int) ([]*Product, error) {
+	return breakerCall(r.breaker, func() ([]*Product, error) {
+		return r.ProductRepoInterface.ListRelatedProducts(ctx, productID, limit)
+	})
+}
+
+func (r *BreakerProductRepo) ListProductsGroupedByCategory(ctx context.Context, limitPerCategory int) (map[uuid.UUID][]*Product, error) {
+	return breakerCall(r.breaker, func() (map[uuid.UUID][]*Product, error) {
+		return r.ProductRepoInterface.ListProductsGroupedByCategory(ctx, limitPerCategory)
+	})
+}
+
+func (r *BreakerProductRepo) ListDuplicateGroups(
+	ctx context.Context,
+	filter DuplicateFilter,
+	after DuplicateGroupKey,
+	limit int,
+) ([]*DuplicateGroup, error) {
+	return breakerCall(r.breaker, func() ([]*DuplicateGroup, error) {
+		return r.ProductRepoInterface.ListDuplicateGroups(ctx, filter, after, limit)
+	})
+}
+
+func (r *BreakerProductRepo) GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]*Product, error) {
+	return breakerCall(r.breaker, func() ([]*Product, error) {
+		return r.ProductRepoInterface.GetProductsByIDs(ctx, ids)
+	})
+}
+
+func (r *BreakerProductRepo) ListProductRelations(ctx context.Context, productID uuid.UUID) ([]ProductRelation, error) {
+	return breakerCall(r.breaker, func() ([]ProductRelation, error) {
+		return r.ProductRepoInterface.ListProductRelations(ctx, productID)
+	})
+}
@@ -0,0 +1,165 @@
+package datalayer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDown = errors.New("dial tcp: connection refused")
+
+func TestBreaker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newBreaker := func() (*datalayer.Breaker, *time.Time) {
+		now := start
+		return datalayer.NewBreaker(3, 10*time.Second, func() time.Time { return now }), &now
+	}
+	fail := func() error { return errDown }
+
+	t.Run("should fail fast after consecutive failures", func(t *testing.T) {
+		breaker, _ := newBreaker()
+		for range 3 {
+			assert.Equal(t, errDown, breaker.Do(fail))
+		}
+
+		calls := 0
+		err := breaker.Do(func() error { calls++; return nil })
+		assert.ErrorIs(t, err, datalayer.ErrCircuitOpen)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("should only count consecutive failures", func(t *testing.T) {
+		breaker, _ := newBreaker()
+		breaker.Do(fail)
+		breaker.Do(fail)
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+		breaker.Do(fail)
+		breaker.Do(fail)
+
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+	})
+
+	t.Run("should not count not found or cancelled calls", func(t *testing.T) {
+		breaker, _ := newBreaker()
+		notFound := fmt.Errorf("getCategoryByID: %w", datalayer.ErrNotFound)
+		for range 5 {
+			assert.ErrorIs(t, breaker.Do(func() error { return notFound }), datalayer.ErrNotFound)
+			assert.ErrorIs(t, breaker.Do(func() error { return context.Canceled }), context.Canceled)
+		}
+
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+	})
+
+	t.Run("should count timeouts", func(t *testing.T) {
+		breaker, _ := newBreaker()
+		for range 3 {
+			breaker.Do(func() error { return context.DeadlineExceeded })
+		}
+
+		assert.ErrorIs(t, breaker.Do(func() error { return nil }), datalayer.ErrCircuitOpen)
+	})
+
+	t.Run("should close again after a successful probe", func(t *testing.T) {
+		breaker, now := newBreaker()
+		for range 3 {
+			breaker.Do(fail)
+		}
+
+		*now = start.Add(9 * time.Second)
+		assert.ErrorIs(t, breaker.Do(func() error { return nil }), datalayer.ErrCircuitOpen)
+
+		*now = start.Add(10 * time.Second)
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+	})
+
+	t.Run("should reopen after a failed probe", func(t *testing.T) {
+		breaker, now := newBreaker()
+		for range 3 {
+			breaker.Do(fail)
+		}
+
+		*now = start.Add(10 * time.Second)
+		assert.Equal(t, errDown, breaker.Do(fail))
+		assert.ErrorIs(t, breaker.Do(func() error { return nil }), datalayer.ErrCircuitOpen)
+
+		*now = start.Add(20 * time.Second)
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+	})
+
+	t.Run("should let a single probe through at a time", func(t *testing.T) {
+		breaker, now := newBreaker()
+		for range 3 {
+			breaker.Do(fail)
+		}
+		*now = start.Add(10 * time.Second)
+
+		var concurrent error
+		err := breaker.Do(func() error {
+			concurrent = breaker.Do(func() error { return nil })
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, concurrent, datalayer.ErrCircuitOpen)
+	})
+
+	t.Run("should probe again after a cancelled probe", func(t *testing.T) {
+		breaker, now := newBreaker()
+		for range 3 {
+			breaker.Do(fail)
+		}
+		*now = start.Add(10 * time.Second)
+
+		assert.ErrorIs(t, breaker.Do(func() error { return context.Canceled }), context.Canceled)
+		assert.NoError(t, breaker.Do(func() error { return nil }))
+	})
+}
+
+// flakyCategoryRepo fails every read while down
+type flakyCategoryRepo struct {
+	datalayer.CategoryRepoInterface
+	down  bool
+	reads int
+}
+
+func (r *flakyCategoryRepo) GetCategoryByID(ctx context.Context, id uuid.UUID) (*datalayer.Category, error) {
+	r.reads++
+	if r.down {
+		return nil, errDown
+	}
+	return r.CategoryRepoInterface.GetCategoryByID(ctx, id)
+}
+
+func TestBreakerCategoryRepo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	inner := &flakyCategoryRepo{CategoryRepoInterface: datalayer.NewMemoryCategoryRepo()}
+	repo := datalayer.NewBreakerCategoryRepo(inner, datalayer.NewBreaker(2, time.Minute, func() time.Time { return now }))
+
+	category := &datalayer.Category{ID: uuid.New(), Name: "Books", CreatedAt: now}
+	require.NoError(t, repo.CreateCategory(ctx, category))
+
+	inner.down = true
+	for range 2 {
+		_, err := repo.GetCategoryByID(ctx, category.ID)
+		assert.Equal(t, errDown, err)
+	}
+	_, err := repo.GetCategoryByID(ctx, category.ID)
+	assert.ErrorIs(t, err, datalayer.ErrCircuitOpen)
+	assert.Equal(t, 2, inner.reads, "an open breaker must not reach the repo")
+
+	inner.down = false
+	now = now.Add(time.Minute)
+	got, err := repo.GetCategoryByID(ctx, category.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Books", got.Name)
+}
@@ -0,0 +1,55 @@
+package datalayer
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CategoryAttributes is free-form display metadata on a category, e.g. an
+// icon or SEO title. Values are strings, numbers or bools. It is stored as
+// a JSONB object and is never nil once read back.
+type CategoryAttributes map[string]any
+
+// Value encodes the attributes as a JSON object for the JSONB column
+func (a CategoryAttributes) Value() (driver.Value, error) {
+	return marshalJSONObject(a)
+}
+
+// Scan decodes the JSONB column into the attributes
+func (a *CategoryAttributes) Scan(src any) error {
+	attributes, err := scanJSONObject(src)
+	if err != nil {
+		return fmt.Errorf("scan category attributes: %w", err)
+	}
+	*a = attributes
+	return nil
+}
+
+// MarshalJSON encodes nil attributes as an empty object
+func (a CategoryAttributes) MarshalJSON() ([]byte, error) {
+	return marshalJSONObject(a)
+}
+
+// CategoryFilter narrows a category listing. Attributes maps keys to the
+// raw value a category's attribute must equal; see attributeCandidates.
+// ExcludeIDs drops categories already served, and a non-Nil AfterID makes
+// the created_after bound of a listing the (created_at, id) keyset of the
+// last category served, see Cursor.
+type CategoryFilter struct {
+	Attributes map[string]string
+	ExcludeIDs []uuid.UUID
+	AfterID    uuid.UUID
+}
+
+// where adds one JSONB containment condition per attribute and the id
+// exclusion to b
+func (f CategoryFilter) where(b *QueryBuilder) *QueryBuilder {
+	return whereNotIDs(whereAttributes(b, f.Attributes), f.ExcludeIDs)
+}
+
+// matches reports whether category passes the filter
+func (f CategoryFilter) matches(category Category) bool {
+	return attributesMatch(category.Attributes, f.Attributes) && !excluded(f.ExcludeIDs, category.ID)
+}
@@ -5,112 +5,435 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+// MaxCategoriesByIDs caps the distinct IDs of one GetCategoriesByIDs call
+const MaxCategoriesByIDs = MaxBatchSize
+
+// ErrTooManyIDs is returned for a GetCategoriesByIDs call over
+// MaxCategoriesByIDs
+var ErrTooManyIDs = errors.New("too many ids")
+
+// Category is a product category. ParentID, set when the category is
+// created, places it under another category; nil is a top-level category.
 type Category struct {
-	ID          uuid.UUID `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	CreatedAt   time.Time `db:"created_at"`
+	ID          uuid.UUID          `db:"id" json:"id"`
+	Name        string             `db:"name" json:"name"`
+	Description string             `db:"description" json:"description"`
+	Attributes  CategoryAttributes `db:"attributes" json:"attributes"`
+	ParentID    *uuid.UUID         `db:"parent_id" json:"parentId,omitempty"`
+	CreatedAt   time.Time          `db:"created_at" json:"createdAt"`
+}
+
+// CategoryPage is one page of categories in created_at order. NextCursor is
+// only set when HasMore is true.
+type CategoryPage struct {
+	Categories []*Category
+	NextCursor time.Time
+	HasMore    bool
+}
+
+// CategoryPatch holds the category fields to change in a partial update.
+// Nil fields are left untouched; Attributes replaces the whole set. A
+// non-nil Precondition is called with the category as stored, locked
+// against concurrent writes, and its error aborts the update.
+type CategoryPatch struct {
+	Name         *string                       `json:"name"`
+	Description  *string                       `json:"description"`
+	Attributes   *CategoryAttributes           `json:"attributes"`
+	Precondition func(current *Category) error `json:"-"`
+}
+
+// IsEmpty reports whether the patch changes no fields
+func (p CategoryPatch) IsEmpty() bool {
+	return p.Name == nil && p.Description == nil && p.Attributes == nil
 }
 
 type CategoryRepo struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	now    func() time.Time
+	limits limitRange
 }
 
+//go:generate go run github.com/matryer/moq@v0.5.3 -pkg mocks -out mocks/category_repo_mock.go . CategoryRepoInterface
+
+// CategoryRepoInterface is the category storage. CreateCategory and
+// UpdateCategory overwrite the category passed in with the row as stored.
 type CategoryRepoInterface interface {
 	GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error)
-	ListCategories(ctx context.Context, createdAfter time.Time, limit int) ([]*Category, error)
+	GetCategoriesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Category, error)
+	CategoryExists(ctx context.Context, id uuid.UUID) (bool, error)
+	GetCategoryByName(ctx context.Context, name string) (*Category, error)
+	ListCategories(ctx context.Context, filter CategoryFilter, createdAfter time.Time, limit int) (*CategoryPage, error)
+	CountCategories(ctx context.Context, filter CategoryFilter, createdAfter time.Time) (int64, error)
 	CreateCategory(ctx context.Context, category *Category) error
+	BulkCreateCategories(ctx context.Context, categories []*Category) error
 	UpdateCategory(ctx context.Context, category *Category) error
+	PatchCategory(ctx context.Context, id uuid.UUID, patch CategoryPatch) (*Category, error)
 	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	DeleteCategoryReturning(ctx context.Context, id uuid.UUID) (*Category, error)
 }
 
 // NewCategoryRepo creates a new repository instance
 func NewCategoryRepo(db *sqlx.DB) CategoryRepoInterface {
-	return &CategoryRepo{db: db}
+	return &CategoryRepo{db: db, now: time.Now, limits: mustLimitRange(minLimit, defaultLimit, maxLimit)}
 }
 
+// categoryColumns are the columns of a category row, in Category's order
+const categoryColumns = `id, name, description, attributes, parent_id, created_at`
+
+const getCategoryQuery = `SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id = $1`
+
 // GetCategoryByID fetches a category by its ID
 func (r *CategoryRepo) GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error) {
-	const query = `SELECT id, name, description FROM categories WHERE id = $1`
-
 	var category Category
-	err := r.db.GetContext(ctx, &category, query, id)
+	err := r.db.GetContext(ctx, &category, getCategoryQuery, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("getCategoryByID: %w: id `%s`", ErrNotFound, id)
+			return nil, repoError("getCategoryByID", EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
 		}
-		return nil, fmt.Errorf("getCategoryByID: select query failed: %w", err)
+		return nil, repoError("getCategoryByID", EntityCategory, fmt.Errorf("select query failed: %w", err))
 	}
+	toUTC(&category.CreatedAt)
 
 	return &category, nil
 }
 
-// ListCategories fetches all categories from the database
-func (r *CategoryRepo) ListCategories(
-	ctx context.Context,
-	createdAfter time.Time, // pagination cursor
-	limit int,
-) ([]*Category, error) {
-	limit = checkLimit(limit)
+// GetCategoriesByIDs fetches the categories with the given IDs keyed by
+// ID, for joining them onto other rows. IDs without a category are absent
+// from the map and repeated IDs are queried once, in as many queries as
+// the parameter limit needs. More than MaxCategoriesByIDs distinct IDs
+// fail with ErrTooManyIDs.
+func (r *CategoryRepo) GetCategoriesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Category, error) {
+	const op = "getCategoriesByIDs"
+
+	ids, err := distinctIDs(op, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return map[uuid.UUID]*Category{}, nil
+	}
+
+	var found []Category
+	for _, batch := range chunk(ids, 1) {
+		query, args, err := sqlx.In(`SELECT `+categoryColumns+` FROM categories WHERE id IN (?)`, batch)
+		if err != nil {
+			return nil, repoError(op, EntityCategory, fmt.Errorf("build query failed: %w", err))
+		}
+		var batchFound []Category
+		if err := r.db.SelectContext(ctx, &batchFound, r.db.Rebind(query), args...); err != nil {
+			return nil, repoError(op, EntityCategory, fmt.Errorf("select query failed: %w", err))
+		}
+		found = append(found, batchFound...)
+	}
+
+	categories := make(map[uuid.UUID]*Category, len(found))
+	for i := range found {
+		toUTC(&found[i].CreatedAt)
+		categories[found[i].ID] = &found[i]
+	}
+	return categories, nil
+}
+
+// distinctIDs returns ids without repeats, in order, failing with
+// ErrTooManyIDs when more than MaxCategoriesByIDs remain
+func distinctIDs(op string, ids []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	distinct := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	if len(distinct) > MaxCategoriesByIDs {
+		return nil, repoError(op, EntityCategory, fmt.Errorf("%w: got %d, at most %d", ErrTooManyIDs, len(distinct), MaxCategoriesByIDs))
+	}
+	return distinct, nil
+}
+
+// CategoryExists reports whether a category with id exists without
+// fetching the row
+func (r *CategoryRepo) CategoryExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	const query = `SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+		return false, repoError("categoryExists", EntityCategory, fmt.Errorf("select query failed: %w", err))
+	}
+	return exists, nil
+}
+
+// GetCategoryByName fetches a category by its name, ignoring case
+func (r *CategoryRepo) GetCategoryByName(ctx context.Context, name string) (*Category, error) {
 	args := map[string]any{
-		"created_at": createdAfter,
-		"limit":      limit,
+		"name": name,
 	}
 
 	const query = `
-		SELECT id, name, description, created_at
+		SELECT id, name, description, attributes, parent_id, created_at
 		FROM categories
-		WHERE created_at > :created_at
-		ORDER BY created_at ASC
-		LIMIT :limit
+		WHERE lower(name) = lower(:name)
+		LIMIT 1
 	`
 
 	stmt, err := r.db.NamedQueryContext(ctx, query, args)
 	if err != nil {
-		return nil, fmt.Errorf("listCategories: select query failed: %w", err)
+		return nil, repoError("getCategoryByName", EntityCategory, fmt.Errorf("select query failed: %w", err))
 	}
 	defer stmt.Close()
 
-	var categories []*Category
-	for stmt.Next() {
+	if !stmt.Next() {
+		if err := stmt.Err(); err != nil {
+			return nil, repoError("getCategoryByName", EntityCategory, fmt.Errorf("select query failed: %w", err))
+		}
+		return nil, repoError("getCategoryByName", EntityCategory, fmt.Errorf("%w: name `%s`", ErrNotFound, name))
+	}
+
+	var category Category
+	if err := stmt.StructScan(&category); err != nil {
+		return nil, repoError("getCategoryByName", EntityCategory, fmt.Errorf("scan failed: %w", err))
+	}
+	toUTC(&category.CreatedAt)
+
+	return &category, nil
+}
+
+// listCategoriesQuery builds the query listing up to limit categories
+// matching filter created after createdAfter
+func listCategoriesQuery(filter CategoryFilter, createdAfter time.Time, limit int) (string, []any) {
+	return filter.where(whereAfter(NewQueryBuilder(`SELECT id, name, description, attributes, parent_id, created_at FROM categories`),
+		createdAfter, filter.AfterID)).
+		OrderBy("created_at ASC, id ASC").
+		Limit(limit).
+		Build()
+}
+
+// ListCategories fetches a page of categories matching filter created after
+// the cursor. One extra row is fetched to tell whether another page follows.
+func (r *CategoryRepo) ListCategories(
+	ctx context.Context,
+	filter CategoryFilter,
+	createdAfter time.Time, // pagination cursor
+	limit int,
+) (*CategoryPage, error) {
+	limit = r.limits.clamp(limit)
+	query, args := listCategoriesQuery(filter, createdAfter, limit+1)
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, repoError("listCategories", EntityCategory, fmt.Errorf("select query failed: %w", err))
+	}
+	defer rows.Close()
+
+	categories := []*Category{}
+	for rows.Next() {
 		var category Category
-		if err := stmt.StructScan(&category); err != nil {
-			return nil, fmt.Errorf("listCategories: scan failed: %w", err)
+		if err := rows.StructScan(&category); err != nil {
+			return nil, repoError("listCategories", EntityCategory, fmt.Errorf("scan failed: %w", err))
 		}
+		toUTC(&category.CreatedAt)
 		categories = append(categories, &category)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, repoError("listCategories", EntityCategory, fmt.Errorf("row iteration failed: %w", err))
+	}
 
-	if len(categories) == 0 {
-		return []*Category{}, nil
+	return newCategoryPage(categories, limit), nil
+}
+
+// newCategoryPage trims categories fetched with limit+1 down to limit and
+// sets the cursor for the next page if the extra row was present
+func newCategoryPage(categories []*Category, limit int) *CategoryPage {
+	page := &CategoryPage{Categories: categories}
+	if len(categories) > limit {
+		page.Categories = categories[:limit]
+		page.NextCursor = page.Categories[limit-1].CreatedAt
+		page.HasMore = true
+	}
+	return page
+}
+
+// CountCategories counts the categories matching filter created after the
+// given cursor
+func (r *CategoryRepo) CountCategories(ctx context.Context, filter CategoryFilter, createdAfter time.Time) (int64, error) {
+	query, args := filter.where(whereAfter(NewQueryBuilder(`SELECT COUNT(*) FROM categories`),
+		createdAfter, filter.AfterID)).
+		Build()
+
+	var count int64
+	if err := r.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, repoError("countCategories", EntityCategory, fmt.Errorf("count query failed: %w", err))
 	}
 
-	return categories, nil
+	return count, nil
 }
 
-// CreateCategory inserts a new category into the database
+// CreateCategory inserts a new category into the database, generating an ID and
+// stamping CreatedAt with the current time when they are not set. category
+// is then filled from the stored row.
 func (r *CategoryRepo) CreateCategory(ctx context.Context, category *Category) error {
-	const query = `INSERT INTO categories(id, name, description, created_at) VALUES(:id, :name, :description, :created_at)`
-	result, err := r.db.NamedExecContext(ctx, query, category)
-	if err != nil {
-		return fmt.Errorf("createCategory: insert query failed: %w", err)
+	setCategoryDefaults(category, r.now)
+
+	write := rowWrite{query: insertCategoryQuery, verb: "insert", table: "categories", columns: categoryColumns, entity: EntityCategory, id: category.ID}
+	if err := writeReturning(ctx, r.db, "createCategory", write, category); err != nil {
+		return err
+	}
+	toUTC(&category.CreatedAt)
+	return nil
+}
+
+// BulkCreateCategories inserts categories with one multi-row insert in a
+// transaction, so either all of them are stored or none. IDs and creation
+// times are assigned like CreateCategory.
+func (r *CategoryRepo) BulkCreateCategories(ctx context.Context, categories []*Category) error {
+	const op = "bulkCreateCategories"
+
+	if len(categories) == 0 {
+		return nil
+	}
+	for _, category := range categories {
+		setCategoryDefaults(category, r.now)
 	}
-	return checkRowsAffected(result, "createCategory")
+
+	return withTx(ctx, r.db, op, EntityCategory, func(tx *sqlx.Tx) error {
+		var inserted int64
+		for _, batch := range chunk(categories, insertCategoryParams) {
+			result, err := tx.NamedExecContext(ctx, insertCategoryQuery, batch)
+			if err != nil {
+				return repoError(op, EntityCategory, fmt.Errorf("insert query failed: %w", err))
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return repoError(op, EntityCategory, fmt.Errorf("failed to get rows affected: %w", err))
+			}
+			inserted += rows
+		}
+		if inserted != int64(len(categories)) {
+			return repoError(op, EntityCategory, fmt.Errorf("inserted %d of %d categories", inserted, len(categories)))
+		}
+		return nil
+	})
 }
 
-// UpdateCategory modifies an existing category
+const insertCategoryQuery = `INSERT INTO categories(id, name, description, attributes, parent_id, created_at) VALUES(:id, :name, :description, :attributes, :parent_id, :created_at)`
+
+// insertCategoryParams is the number of parameters insertCategoryQuery
+// binds per category
+const insertCategoryParams = 6
+
+// setCategoryDefaults assigns the ID, creation time and attributes of a
+// new category when they are not set
+func setCategoryDefaults(category *Category, now func() time.Time) {
+	if category.ID == uuid.Nil {
+		category.ID = uuid.New()
+	}
+	if category.CreatedAt.IsZero() {
+		category.CreatedAt = now()
+	}
+	toUTC(&category.CreatedAt)
+	if category.Attributes == nil {
+		category.Attributes = CategoryAttributes{}
+	}
+}
+
+// UpdateCategory modifies an existing category and fills category from the
+// stored row
 func (r *CategoryRepo) UpdateCategory(ctx context.Context, category *Category) error {
-	const query = `UPDATE categories SET name=:name, description=:description WHERE id=:id`
-	result, err := r.db.NamedExecContext(ctx, query, category)
+	const query = `UPDATE categories SET name=:name, description=:description, attributes=:attributes WHERE id=:id`
+	write := rowWrite{query: query, verb: "update", table: "categories", columns: categoryColumns, entity: EntityCategory, id: category.ID}
+	if err := writeReturning(ctx, r.db, "updateCategory", write, category); err != nil {
+		return err
+	}
+	toUTC(&category.CreatedAt)
+	return nil
+}
+
+// PatchCategory updates only the fields set in patch and returns the
+// category as stored afterwards. With a Precondition the row is read FOR
+// UPDATE and checked in the transaction of the update.
+func (r *CategoryRepo) PatchCategory(ctx context.Context, id uuid.UUID, patch CategoryPatch) (*Category, error) {
+	const op = "patchCategory"
+	if patch.IsEmpty() {
+		return nil, repoError(op, EntityCategory, ErrEmptyPatch)
+	}
+	if patch.Precondition == nil {
+		return patchCategoryRow(ctx, r.db, id, patch)
+	}
+
+	var category *Category
+	err := withTx(ctx, r.db, op, EntityCategory, func(tx *sqlx.Tx) error {
+		var current Category
+		if err := tx.GetContext(ctx, &current, getCategoryQuery+" FOR UPDATE", id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityCategory, fmt.Errorf("select query failed: %w", err))
+		}
+		toUTC(&current.CreatedAt)
+		if err := patch.Precondition(&current); err != nil {
+			return repoError(op, EntityCategory, err)
+		}
+
+		var err error
+		category, err = patchCategoryRow(ctx, tx, id, patch)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("updateCategory: update query failed: %w", err)
+		return nil, err
 	}
-	return checkRowsAffected(result, "updateCategory")
+	return category, nil
+}
+
+// patchCategoryRow runs the UPDATE of PatchCategory on db or a transaction
+func patchCategoryRow(ctx context.Context, db sqlx.ExtContext, id uuid.UUID, patch CategoryPatch) (*Category, error) {
+	const op = "patchCategory"
+	args := map[string]any{
+		"id": id,
+	}
+	var sets []string
+	if patch.Name != nil {
+		sets = append(sets, "name=:name")
+		args["name"] = *patch.Name
+	}
+	if patch.Description != nil {
+		sets = append(sets, "description=:description")
+		args["description"] = *patch.Description
+	}
+	if patch.Attributes != nil {
+		sets = append(sets, "attributes=:attributes")
+		args["attributes"] = *patch.Attributes
+	}
+
+	query := "UPDATE categories SET " + strings.Join(sets, ", ") +
+		" WHERE id=:id RETURNING id, name, description, attributes, parent_id, created_at"
+
+	stmt, err := sqlx.NamedQueryContext(ctx, db, query, args)
+	if err != nil {
+		return nil, repoError(op, EntityCategory, fmt.Errorf("update query failed: %w", err))
+	}
+	defer stmt.Close()
+
+	if !stmt.Next() {
+		if err := stmt.Err(); err != nil {
+			return nil, repoError(op, EntityCategory, fmt.Errorf("update query failed: %w", err))
+		}
+		return nil, repoError(op, EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+
+	var category Category
+	if err := stmt.StructScan(&category); err != nil {
+		return nil, repoError(op, EntityCategory, fmt.Errorf("scan failed: %w", err))
+	}
+	toUTC(&category.CreatedAt)
+
+	return &category, nil
 }
 
 // DeleteCategory removes a category by its ID
@@ -118,7 +441,37 @@ func (r *CategoryRepo) DeleteCategory(ctx context.Context, id uuid.UUID) error {
 	const query = `DELETE FROM categories WHERE id = $1`
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("deleteCategory: delete query failed: %w", err)
+		return repoError("deleteCategory", EntityCategory, fmt.Errorf("delete query failed: %w", err))
 	}
-	return checkRowsAffected(result, "deleteCategory")
+	return checkRowsAffected(result, "deleteCategory", EntityCategory)
+}
+
+// DeleteCategoryReturning removes a category by its ID and returns the row as
+// it was before deletion. The read and delete share one transaction.
+func (r *CategoryRepo) DeleteCategoryReturning(ctx context.Context, id uuid.UUID) (*Category, error) {
+	const op = "deleteCategoryReturning"
+	const selectQuery = `SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id = $1 FOR UPDATE`
+	const deleteQuery = `DELETE FROM categories WHERE id = $1`
+
+	var category Category
+	err := withTx(ctx, r.db, op, EntityCategory, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &category, selectQuery, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityCategory, fmt.Errorf("select query failed: %w", err))
+		}
+		toUTC(&category.CreatedAt)
+
+		result, err := tx.ExecContext(ctx, deleteQuery, id)
+		if err != nil {
+			return repoError(op, EntityCategory, fmt.Errorf("delete query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityCategory)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
 }
@@ -2,6 +2,7 @@ package datalayer
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
@@ -17,6 +18,7 @@ var testCategoryOne = Category{
 	ID:          uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376"),
 	Name:        "Test Category A",
 	Description: "Test category a description",
+	Attributes:  CategoryAttributes{"icon": "book", "featured": true},
 	CreatedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 }
 
@@ -24,9 +26,25 @@ var testCategoryTwo = Category{
 	ID:          uuid.MustParse("b12f2176-28ca-4acf-85b9-cc97ca1b3cf6"),
 	Name:        "Test Category B",
 	Description: "Test category B description",
+	Attributes:  CategoryAttributes{},
 	CreatedAt:   time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC),
 }
 
+// attributesJSON encodes attributes the way the JSONB column returns them
+func attributesJSON(attributes CategoryAttributes) []byte {
+	raw, _ := attributes.Value()
+	return raw.([]byte)
+}
+
+// expectCategoryReadBack expects the read of the category with id after a
+// write without RETURNING and answers it with stored
+func expectCategoryReadBack(mock sqlmock.Sqlmock, id driver.Value, stored Category) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + categoryColumns + ` FROM categories WHERE id = ?`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(stored.ID, stored.Name, stored.Description, attributesJSON(stored.Attributes), stored.CreatedAt))
+}
+
 func TestGetCategoryByID(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -35,10 +53,10 @@ func TestGetCategoryByID(t *testing.T) {
 	repo := NewCategoryRepo(db)
 	ctx := context.Background()
 
-	selectQuery := regexp.QuoteMeta(`SELECT id, name, description FROM categories WHERE id = $1`)
+	selectQuery := regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id = $1`)
 	t.Run("should return category", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
-			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
 		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).WillReturnRows(mockRows)
 		category, err := repo.GetCategoryByID(ctx, testCategoryOne.ID)
 		assert.NoError(t, err)
@@ -57,7 +75,7 @@ func TestGetCategoryByID(t *testing.T) {
 	})
 
 	t.Run("should return error if no row", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"})
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"})
 		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).WillReturnRows(mockRows)
 		category, err := repo.GetCategoryByID(ctx, testCategoryOne.ID)
 		assert.Nil(t, category)
@@ -68,6 +86,170 @@ func TestGetCategoryByID(t *testing.T) {
 	})
 }
 
+func TestGetCategoriesByIDs(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewCategoryRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+	columns := []string{"id", "name", "description", "attributes", "created_at"}
+	query := "^" + regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id IN (?, ?, ?)`) + "$"
+
+	t.Run("should query each id once and key found categories by id", func(t *testing.T) {
+		missing := uuid.New()
+		mockRows := sqlmock.NewRows(columns).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
+		mock.ExpectQuery(query).WithArgs(testCategoryTwo.ID, missing, testCategoryOne.ID).WillReturnRows(mockRows)
+
+		categories, err := repo.GetCategoriesByIDs(ctx, []uuid.UUID{testCategoryTwo.ID, missing, testCategoryTwo.ID, testCategoryOne.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]*Category{
+			testCategoryOne.ID: &testCategoryOne,
+			testCategoryTwo.ID: &testCategoryTwo,
+		}, categories)
+	})
+
+	t.Run("should return error if select query error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(errors.New("query error"))
+
+		categories, err := repo.GetCategoriesByIDs(ctx, []uuid.UUID{testCategoryOne.ID, testCategoryTwo.ID, uuid.New()})
+		assert.Nil(t, categories)
+		assert.EqualError(t, err, "getCategoriesByIDs: select query failed: query error")
+	})
+
+	t.Run("should not query for no ids", func(t *testing.T) {
+		categories, err := repo.GetCategoriesByIDs(ctx, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, categories)
+		assert.Empty(t, categories)
+	})
+
+	t.Run("should split ids over the parameter limit into several queries", func(t *testing.T) {
+		queryParamLimit = 2
+		t.Cleanup(func() { queryParamLimit = maxQueryParams })
+		missing := uuid.New()
+		mock.ExpectQuery("^"+regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id IN (?, ?)`)+"$").
+			WithArgs(testCategoryOne.ID, missing).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt))
+		mock.ExpectQuery("^" + regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id IN (?)`) + "$").
+			WithArgs(testCategoryTwo.ID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt))
+
+		categories, err := repo.GetCategoriesByIDs(ctx, []uuid.UUID{testCategoryOne.ID, missing, testCategoryTwo.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]*Category{
+			testCategoryOne.ID: &testCategoryOne,
+			testCategoryTwo.ID: &testCategoryTwo,
+		}, categories)
+	})
+
+	t.Run("should not query for more ids than the cap", func(t *testing.T) {
+		ids := make([]uuid.UUID, MaxCategoriesByIDs+1)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		categories, err := repo.GetCategoriesByIDs(ctx, ids)
+		assert.Nil(t, categories)
+		assert.ErrorIs(t, err, ErrTooManyIDs)
+		assert.EqualError(t, err, "getCategoriesByIDs: too many ids: got 1001, at most 1000")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryExists(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewCategoryRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	existsQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)`)
+	t.Run("should report an existing category", func(t *testing.T) {
+		mock.ExpectQuery(existsQuery).WithArgs(testCategoryOne.ID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		exists, err := repo.CategoryExists(ctx, testCategoryOne.ID)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("should report a missing category", func(t *testing.T) {
+		mock.ExpectQuery(existsQuery).WithArgs(testCategoryOne.ID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		exists, err := repo.CategoryExists(ctx, testCategoryOne.ID)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should return error if select query error", func(t *testing.T) {
+		mock.ExpectQuery(existsQuery).WithArgs(testCategoryOne.ID).WillReturnError(errors.New("query error"))
+		exists, err := repo.CategoryExists(ctx, testCategoryOne.ID)
+		assert.False(t, exists)
+		assert.EqualError(t, err, "categoryExists: select query failed: query error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCategoryByName(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewCategoryRepo(db)
+	ctx := context.Background()
+
+	selectQuery := regexp.QuoteMeta(`
+		SELECT id, name, description, attributes, parent_id, created_at
+		FROM categories
+		WHERE lower(name) = lower(?)
+		LIMIT 1
+	`)
+
+	t.Run("should return category on exact match", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.Name).WillReturnRows(mockRows)
+		category, err := repo.GetCategoryByName(ctx, testCategoryOne.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, &testCategoryOne, category)
+	})
+
+	t.Run("should return category on differently cased match", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+		mock.ExpectQuery(selectQuery).WithArgs("tEST cATEGORY a").WillReturnRows(mockRows)
+		category, err := repo.GetCategoryByName(ctx, "tEST cATEGORY a")
+		assert.NoError(t, err)
+		assert.Equal(t, &testCategoryOne, category)
+	})
+
+	t.Run("should return error if no row", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"})
+		mock.ExpectQuery(selectQuery).WithArgs("Missing").WillReturnRows(mockRows)
+		category, err := repo.GetCategoryByName(ctx, "Missing")
+		assert.Nil(t, category)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		expectedErrMsg := "getCategoryByName: not found: name `Missing`"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	t.Run("should return error if select query error", func(t *testing.T) {
+		dbErr := errors.New("query error")
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.Name).WillReturnError(dbErr)
+		category, err := repo.GetCategoryByName(ctx, testCategoryOne.Name)
+		assert.Nil(t, category)
+		assert.Error(t, err)
+		expectedErrMsg := "getCategoryByName: select query failed: query error"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestListCategories(t *testing.T) {
 	var createdAfter time.Time
 	limit := 10
@@ -79,89 +261,189 @@ func TestListCategories(t *testing.T) {
 	repo := NewCategoryRepo(db)
 	ctx := context.Background()
 
-	selectQuery := regexp.QuoteMeta(`
-			SELECT id, name, description, created_at
-			FROM categories
-			WHERE created_at > ?
-			ORDER BY created_at ASC
-			LIMIT ?
-		`)
+	selectQuery := regexp.QuoteMeta(
+		`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE created_at > $1 ORDER BY created_at ASC, id ASC LIMIT $2`,
+	)
 
 	t.Run("should return list of categories", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
-			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
-			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, testCategoryTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
 
-		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnRows(mockRows)
-		categories, err := repo.ListCategories(ctx, createdAfter, limit)
+		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit+1).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{}, createdAfter, limit)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, categories)
-		assert.Equal(t, []*Category{&testCategoryOne, &testCategoryTwo}, categories)
+		assert.NotNil(t, page)
+		assert.Equal(t, []*Category{&testCategoryOne, &testCategoryTwo}, page.Categories)
+		assert.False(t, page.HasMore)
+		assert.True(t, page.NextCursor.IsZero())
 	})
 
 	t.Run("should use minimum limit if limit is less than minimum limit", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
-			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
-			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, testCategoryTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
 
-		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, 1).WillReturnRows(mockRows)
-		categories, err := repo.ListCategories(ctx, createdAfter, -1)
+		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, 2).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{}, createdAfter, -1)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, categories)
-		assert.Equal(t, []*Category{&testCategoryOne, &testCategoryTwo}, categories)
+		assert.NotNil(t, page)
+		assert.Equal(t, []*Category{&testCategoryOne}, page.Categories)
+		assert.True(t, page.HasMore)
+		assert.Equal(t, testCategoryOne.CreatedAt, page.NextCursor)
 	})
 
 	t.Run("should use maximum limit if limit is greater than maximum limit", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"}).
-			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
-			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, testCategoryTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
 
-		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, 1000).WillReturnRows(mockRows)
-		categories, err := repo.ListCategories(ctx, createdAfter, 100009)
+		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, 1001).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{}, createdAfter, 100009)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, categories)
-		assert.Equal(t, []*Category{&testCategoryOne, &testCategoryTwo}, categories)
+		assert.NotNil(t, page)
+		assert.Equal(t, []*Category{&testCategoryOne, &testCategoryTwo}, page.Categories)
 	})
 
 	t.Run("should return empty list if categories length is zero", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "created_at"})
-		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnRows(mockRows)
-		categories, err := repo.ListCategories(ctx, createdAfter, limit)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"})
+		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit+1).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{}, createdAfter, limit)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, categories)
-		assert.Equal(t, []*Category{}, categories)
+		assert.NotNil(t, page)
+		assert.Equal(t, []*Category{}, page.Categories)
+		assert.False(t, page.HasMore)
+		assert.True(t, page.NextCursor.IsZero())
 	})
 
 	t.Run("should return error if select query fails", func(t *testing.T) {
 		dbErr := errors.New("query error")
-		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnError(dbErr)
-		categories, err := repo.ListCategories(ctx, createdAfter, limit)
+		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit+1).WillReturnError(dbErr)
+		page, err := repo.ListCategories(ctx, CategoryFilter{}, createdAfter, limit)
 
-		assert.Nil(t, categories)
+		assert.Nil(t, page)
 		assert.Error(t, err)
 		expectedErrMsg := "listCategories: select query failed: query error"
 		assert.Equal(t, expectedErrMsg, err.Error())
 	})
 
+	t.Run("should add a containment condition per attribute filter", func(t *testing.T) {
+		filterQuery := regexp.QuoteMeta(
+			`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE created_at > $1` +
+				` AND (attributes @> $2::jsonb OR attributes @> $3::jsonb)` +
+				` AND (attributes @> $4::jsonb) ORDER BY created_at ASC, id ASC LIMIT $5`,
+		)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+
+		mock.ExpectQuery(filterQuery).
+			WithArgs(createdAfter, `{"featured":"true"}`, `{"featured":true}`, `{"icon":"book"}`, limit+1).
+			WillReturnRows(mockRows)
+		filter := CategoryFilter{Attributes: map[string]string{"icon": "book", "featured": "true"}}
+		page, err := repo.ListCategories(ctx, filter, createdAfter, limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*Category{&testCategoryOne}, page.Categories)
+	})
+
+	t.Run("should exclude the ids a cursor has already served", func(t *testing.T) {
+		excludeQuery := regexp.QuoteMeta(
+			`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE created_at > $1` +
+				` AND id NOT IN ($2, $3) ORDER BY created_at ASC, id ASC LIMIT $4`,
+		)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
+
+		seen := []uuid.UUID{testCategoryOne.ID, uuid.New()}
+		mock.ExpectQuery(excludeQuery).WithArgs(createdAfter, seen[0], seen[1], limit+1).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{ExcludeIDs: seen}, createdAfter, limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*Category{&testCategoryTwo}, page.Categories)
+	})
+
+	t.Run("should resume after the created_at and id of a cursor", func(t *testing.T) {
+		keysetQuery := regexp.QuoteMeta(
+			`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE (created_at, id) > ($1, $2)` +
+				` ORDER BY created_at ASC, id ASC LIMIT $3`,
+		)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
+
+		mock.ExpectQuery(keysetQuery).WithArgs(createdAfter, testCategoryOne.ID, limit+1).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{AfterID: testCategoryOne.ID}, createdAfter, limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*Category{&testCategoryTwo}, page.Categories)
+	})
+
 	t.Run("should return error if scan fails", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "createdAt"}).
-			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
-			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, testCategoryTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "createdAt"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt)
 
-		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnRows(mockRows)
-		categories, err := repo.ListCategories(ctx, createdAfter, limit)
+		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit+1).WillReturnRows(mockRows)
+		page, err := repo.ListCategories(ctx, CategoryFilter{}, createdAfter, limit)
 
-		assert.Nil(t, categories)
+		assert.Nil(t, page)
 		assert.Error(t, err)
 		expectedErrMsg := "listCategories: scan failed: missing destination name createdAt in *datalayer.Category"
 		assert.Equal(t, expectedErrMsg, err.Error())
 	})
 }
 
+func TestCountCategories(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewCategoryRepo(db)
+	ctx := context.Background()
+
+	countQuery := regexp.QuoteMeta(`SELECT COUNT(*) FROM categories WHERE created_at > $1`)
+
+	t.Run("should return count of categories created after cursor", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"count"}).AddRow(42)
+		mock.ExpectQuery(countQuery).WithArgs(testCategoryOne.CreatedAt).WillReturnRows(mockRows)
+		count, err := repo.CountCategories(ctx, CategoryFilter{}, testCategoryOne.CreatedAt)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), count)
+	})
+
+	t.Run("should count the categories after the created_at and id of a cursor", func(t *testing.T) {
+		keysetQuery := regexp.QuoteMeta(`SELECT COUNT(*) FROM categories WHERE (created_at, id) > ($1, $2)`)
+		mockRows := sqlmock.NewRows([]string{"count"}).AddRow(41)
+		mock.ExpectQuery(keysetQuery).WithArgs(testCategoryOne.CreatedAt, testCategoryOne.ID).WillReturnRows(mockRows)
+		count, err := repo.CountCategories(ctx, CategoryFilter{AfterID: testCategoryOne.ID}, testCategoryOne.CreatedAt)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(41), count)
+	})
+
+	t.Run("should bind zero time if no cursor", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+		mock.ExpectQuery(countQuery).WithArgs(time.Time{}).WillReturnRows(mockRows)
+		count, err := repo.CountCategories(ctx, CategoryFilter{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("should return error if count query fails", func(t *testing.T) {
+		dbErr := errors.New("query error")
+		mock.ExpectQuery(countQuery).WithArgs(testCategoryOne.CreatedAt).WillReturnError(dbErr)
+		count, err := repo.CountCategories(ctx, CategoryFilter{}, testCategoryOne.CreatedAt)
+		assert.Error(t, err)
+		assert.Equal(t, int64(0), count)
+		expectedErrMsg := "countCategories: count query failed: query error"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCreateCategory(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -171,22 +453,87 @@ func TestCreateCategory(t *testing.T) {
 	ctx := context.Background()
 
 	insertQuery := regexp.QuoteMeta(
-		`INSERT INTO categories(id, name, description, created_at) VALUES(?, ?, ?, ?)`,
+		`INSERT INTO categories(id, name, description, attributes, parent_id, created_at) VALUES(?, ?, ?, ?, ?, ?)`,
 	)
 
 	t.Run("should create valid category", func(t *testing.T) {
 		mock.ExpectExec(insertQuery).
-			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
+			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), nil, testCategoryOne.CreatedAt).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectCategoryReadBack(mock, testCategoryOne.ID, testCategoryOne)
 
 		err := repo.CreateCategory(ctx, &testCategoryOne)
 		assert.NoError(t, err)
 	})
 
+	t.Run("should default created at to now if not set", func(t *testing.T) {
+		now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+		clockRepo := &CategoryRepo{db: db, now: func() time.Time { return now }}
+		category := testCategoryOne
+		category.CreatedAt = time.Time{}
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(category.ID, category.Name, category.Description, attributesJSON(category.Attributes), nil, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		row := category
+		row.CreatedAt = now
+		expectCategoryReadBack(mock, category.ID, row)
+
+		err := clockRepo.CreateCategory(ctx, &category)
+		assert.NoError(t, err)
+		assert.Equal(t, now, category.CreatedAt)
+	})
+
+	t.Run("should generate id if nil", func(t *testing.T) {
+		category := testCategoryOne
+		category.ID = uuid.Nil
+
+		generated := &sameArg{}
+		row := category
+		row.ID = testCategoryTwo.ID
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(generated, category.Name, category.Description, attributesJSON(category.Attributes), nil, category.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectCategoryReadBack(mock, generated, row)
+
+		err := repo.CreateCategory(ctx, &category)
+		assert.NoError(t, err)
+		assertGeneratedID(t, generated)
+		assert.Equal(t, row.ID, category.ID, "the stored row is read back")
+	})
+
+	t.Run("should keep explicit id", func(t *testing.T) {
+		category := testCategoryOne
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(testCategoryOne.ID, category.Name, category.Description, attributesJSON(category.Attributes), nil, category.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectCategoryReadBack(mock, testCategoryOne.ID, category)
+
+		err := repo.CreateCategory(ctx, &category)
+		assert.NoError(t, err)
+		assert.Equal(t, testCategoryOne.ID, category.ID)
+	})
+
+	t.Run("should keep explicit created at", func(t *testing.T) {
+		clockRepo := &CategoryRepo{db: db, now: time.Now}
+		category := testCategoryOne
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(category.ID, category.Name, category.Description, attributesJSON(category.Attributes), nil, testCategoryOne.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectCategoryReadBack(mock, category.ID, category)
+
+		err := clockRepo.CreateCategory(ctx, &category)
+		assert.NoError(t, err)
+		assert.Equal(t, testCategoryOne.CreatedAt, category.CreatedAt)
+	})
+
 	t.Run("should return error if insert query fails", func(t *testing.T) {
 		dbErr := errors.New("database error")
 		mock.ExpectExec(insertQuery).
-			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
+			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), nil, testCategoryOne.CreatedAt).
 			WillReturnError(dbErr)
 
 		err := repo.CreateCategory(ctx, &testCategoryOne)
@@ -197,7 +544,7 @@ func TestCreateCategory(t *testing.T) {
 
 	t.Run("should return not found if no rows affected", func(t *testing.T) {
 		mock.ExpectExec(insertQuery).
-			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
+			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), nil, testCategoryOne.CreatedAt).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		err := repo.CreateCategory(ctx, &testCategoryOne)
@@ -210,7 +557,7 @@ func TestCreateCategory(t *testing.T) {
 	t.Run("should return error if rows affected fails", func(t *testing.T) {
 		dbErr := errors.New("rows affected error")
 		mock.ExpectExec(insertQuery).
-			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.CreatedAt).
+			WithArgs(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), nil, testCategoryOne.CreatedAt).
 			WillReturnResult(sqlmock.NewErrorResult(dbErr))
 
 		err := repo.CreateCategory(ctx, &testCategoryOne)
@@ -220,6 +567,84 @@ func TestCreateCategory(t *testing.T) {
 	})
 }
 
+func TestBulkCreateCategories(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewCategoryRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	insertQuery := regexp.QuoteMeta(
+		`INSERT INTO categories(id, name, description, attributes, parent_id, created_at) VALUES(?, ?, ?, ?, ?, ?),(?, ?, ?, ?, ?, ?)`,
+	)
+	args := func(categories ...Category) []driver.Value {
+		var values []driver.Value
+		for _, c := range categories {
+			values = append(values, c.ID, c.Name, c.Description, attributesJSON(c.Attributes), c.ParentID, c.CreatedAt)
+		}
+		return values
+	}
+
+	t.Run("should insert every category in one statement", func(t *testing.T) {
+		one, two := testCategoryOne, testCategoryTwo
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(args(one, two)...).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := repo.BulkCreateCategories(ctx, []*Category{&one, &two})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should bind the parent of a child category", func(t *testing.T) {
+		parent, child := testCategoryOne, testCategoryTwo
+		child.ParentID = &parent.ID
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(args(parent, child)...).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.BulkCreateCategories(ctx, []*Category{&parent, &child}))
+	})
+
+	t.Run("should roll back if the insert fails", func(t *testing.T) {
+		one, two := testCategoryOne, testCategoryTwo
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(args(one, two)...).WillReturnError(errors.New("duplicate key"))
+		mock.ExpectRollback()
+
+		err := repo.BulkCreateCategories(ctx, []*Category{&one, &two})
+		assert.EqualError(t, err, "bulkCreateCategories: insert query failed: duplicate key")
+	})
+
+	t.Run("should roll back if not every row was inserted", func(t *testing.T) {
+		one, two := testCategoryOne, testCategoryTwo
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(args(one, two)...).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := repo.BulkCreateCategories(ctx, []*Category{&one, &two})
+		assert.EqualError(t, err, "bulkCreateCategories: inserted 1 of 2 categories")
+	})
+
+	t.Run("should not touch the database for an empty batch", func(t *testing.T) {
+		assert.NoError(t, repo.BulkCreateCategories(ctx, nil))
+	})
+
+	t.Run("should split a batch over the parameter limit into several inserts", func(t *testing.T) {
+		queryParamLimit = 2 * insertCategoryParams
+		t.Cleanup(func() { queryParamLimit = maxQueryParams })
+		one, two, three := testCategoryOne, testCategoryTwo, testCategoryOne
+		three.ID = uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(args(one, two)...).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO categories(id, name, description, attributes, parent_id, created_at) VALUES(?, ?, ?, ?, ?, ?)`)).
+			WithArgs(args(three)...).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.BulkCreateCategories(ctx, []*Category{&one, &two, &three}))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 func TestUpdateCategory(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -228,12 +653,13 @@ func TestUpdateCategory(t *testing.T) {
 	repo := NewCategoryRepo(db)
 	ctx := context.Background()
 
-	updateQuery := regexp.QuoteMeta(`UPDATE categories SET name=?, description=? WHERE id=?`)
+	updateQuery := regexp.QuoteMeta(`UPDATE categories SET name=?, description=?, attributes=? WHERE id=?`)
 
 	t.Run("should update valid category", func(t *testing.T) {
 		mock.ExpectExec(updateQuery).
-			WithArgs(testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.ID).
+			WithArgs(testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.ID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectCategoryReadBack(mock, testCategoryOne.ID, testCategoryOne)
 
 		err := repo.UpdateCategory(ctx, &testCategoryOne)
 		assert.NoError(t, err)
@@ -242,7 +668,7 @@ func TestUpdateCategory(t *testing.T) {
 	t.Run("should return error if update query fails", func(t *testing.T) {
 		dbErr := errors.New("database error")
 		mock.ExpectExec(updateQuery).
-			WithArgs(testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.ID).
+			WithArgs(testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.ID).
 			WillReturnError(dbErr)
 
 		err := repo.UpdateCategory(ctx, &testCategoryOne)
@@ -253,7 +679,7 @@ func TestUpdateCategory(t *testing.T) {
 
 	t.Run("should return not found if no rows affected", func(t *testing.T) {
 		mock.ExpectExec(updateQuery).
-			WithArgs(testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.ID).
+			WithArgs(testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.ID).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		err := repo.UpdateCategory(ctx, &testCategoryOne)
@@ -266,7 +692,7 @@ func TestUpdateCategory(t *testing.T) {
 	t.Run("should return error if rows affected fails", func(t *testing.T) {
 		dbErr := errors.New("rows affected error")
 		mock.ExpectExec(updateQuery).
-			WithArgs(testCategoryOne.Name, testCategoryOne.Description, testCategoryOne.ID).
+			WithArgs(testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.ID).
 			WillReturnResult(sqlmock.NewErrorResult(dbErr))
 
 		err := repo.UpdateCategory(ctx, &testCategoryOne)
@@ -276,6 +702,190 @@ func TestUpdateCategory(t *testing.T) {
 	})
 }
 
+func TestCategoryWritesReturning(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "postgres")
+	repo := NewCategoryRepo(db)
+	ctx := context.Background()
+
+	returning := regexp.QuoteMeta(` RETURNING ` + categoryColumns)
+	rows := func(category Category) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(category.ID, category.Name, category.Description, attributesJSON(category.Attributes), category.CreatedAt)
+	}
+
+	t.Run("should create category from the returned row", func(t *testing.T) {
+		category := testCategoryOne
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO categories(id, name, description, attributes, parent_id, created_at) VALUES($1, $2, $3, $4, $5, $6)`)+returning).
+			WithArgs(category.ID, category.Name, category.Description, attributesJSON(category.Attributes), nil, category.CreatedAt).
+			WillReturnRows(rows(category))
+
+		err := repo.CreateCategory(ctx, &category)
+		assert.NoError(t, err)
+		assert.Equal(t, testCategoryOne, category)
+	})
+
+	t.Run("should update category and return its creation time", func(t *testing.T) {
+		category := testCategoryOne
+		category.CreatedAt = time.Time{}
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE categories SET name=$1, description=$2, attributes=$3 WHERE id=$4`)+returning).
+			WithArgs(category.Name, category.Description, attributesJSON(category.Attributes), category.ID).
+			WillReturnRows(rows(testCategoryOne))
+
+		err := repo.UpdateCategory(ctx, &category)
+		assert.NoError(t, err)
+		assert.Equal(t, testCategoryOne, category)
+	})
+
+	t.Run("should return not found if no row is returned", func(t *testing.T) {
+		category := testCategoryOne
+		mock.ExpectQuery(`^UPDATE categories`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		err := repo.UpdateCategory(ctx, &category)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchCategory(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewCategoryRepo(db)
+	ctx := context.Background()
+
+	name := "Patched name"
+	description := "Patched description"
+	columns := []string{"id", "name", "description", "attributes", "created_at"}
+
+	tests := []struct {
+		name  string
+		patch CategoryPatch
+		query string
+		args  []driver.Value
+	}{
+		{
+			name:  "should only set name",
+			patch: CategoryPatch{Name: &name},
+			query: `UPDATE categories SET name=? WHERE id=? RETURNING id, name, description, attributes, parent_id, created_at`,
+			args:  []driver.Value{name, testCategoryOne.ID},
+		},
+		{
+			name:  "should only set description",
+			patch: CategoryPatch{Description: &description},
+			query: `UPDATE categories SET description=? WHERE id=? RETURNING id, name, description, attributes, parent_id, created_at`,
+			args:  []driver.Value{description, testCategoryOne.ID},
+		},
+		{
+			name:  "should set both fields",
+			patch: CategoryPatch{Name: &name, Description: &description},
+			query: `UPDATE categories SET name=?, description=? WHERE id=? RETURNING id, name, description, attributes, parent_id, created_at`,
+			args:  []driver.Value{name, description, testCategoryOne.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRows := sqlmock.NewRows(columns).
+				AddRow(testCategoryOne.ID, name, description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+			mock.ExpectQuery("^" + regexp.QuoteMeta(tt.query) + "$").WithArgs(tt.args...).WillReturnRows(mockRows)
+
+			category, err := repo.PatchCategory(ctx, testCategoryOne.ID, tt.patch)
+			assert.NoError(t, err)
+			assert.Equal(t, &Category{
+				ID:          testCategoryOne.ID,
+				Name:        name,
+				Description: description,
+				Attributes:  testCategoryOne.Attributes,
+				CreatedAt:   testCategoryOne.CreatedAt,
+			}, category)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("should return error without querying if patch is empty", func(t *testing.T) {
+		category, err := repo.PatchCategory(ctx, testCategoryOne.ID, CategoryPatch{})
+		assert.Nil(t, category)
+		assert.True(t, errors.Is(err, ErrEmptyPatch))
+		assert.Equal(t, "patchCategory: patch has no fields to update", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found if no row updated", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE categories SET name=?`)).WillReturnRows(sqlmock.NewRows(columns))
+
+		category, err := repo.PatchCategory(ctx, testCategoryOne.ID, CategoryPatch{Name: &name})
+		assert.Nil(t, category)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.Equal(t, "patchCategory: not found: id `f2aa335f-6f91-4d4d-8057-53b0009bc376`", err.Error())
+	})
+
+	t.Run("should return error if update query fails", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE categories SET name=?`)).WillReturnError(errors.New("database error"))
+
+		category, err := repo.PatchCategory(ctx, testCategoryOne.ID, CategoryPatch{Name: &name})
+		assert.Nil(t, category)
+		assert.Equal(t, "patchCategory: update query failed: database error", err.Error())
+	})
+
+	selectForUpdate := regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id = $1 FOR UPDATE`)
+	storedRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+	}
+
+	t.Run("should check the precondition against the locked row before updating", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectForUpdate).WithArgs(testCategoryOne.ID).WillReturnRows(storedRow())
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE categories SET name=? WHERE id=?`)).
+			WithArgs(name, testCategoryOne.ID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(testCategoryOne.ID, name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt))
+		mock.ExpectCommit()
+
+		var checked *Category
+		patch := CategoryPatch{Name: &name, Precondition: func(current *Category) error {
+			checked = current
+			return nil
+		}}
+		category, err := repo.PatchCategory(ctx, testCategoryOne.ID, patch)
+		assert.NoError(t, err)
+		assert.Equal(t, &testCategoryOne, checked)
+		assert.Equal(t, name, category.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the precondition fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectForUpdate).WithArgs(testCategoryOne.ID).WillReturnRows(storedRow())
+		mock.ExpectRollback()
+
+		patch := CategoryPatch{Name: &name, Precondition: func(*Category) error { return ErrPreconditionFailed }}
+		category, err := repo.PatchCategory(ctx, testCategoryOne.ID, patch)
+		assert.Nil(t, category)
+		assert.True(t, errors.Is(err, ErrPreconditionFailed))
+		assert.Equal(t, KindConflict, KindOf(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found before checking the precondition", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectForUpdate).WithArgs(testCategoryOne.ID).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		patch := CategoryPatch{Name: &name, Precondition: func(*Category) error {
+			t.Error("precondition called for a missing row")
+			return nil
+		}}
+		_, err := repo.PatchCategory(ctx, testCategoryOne.ID, patch)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestDeleteCategory(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -328,3 +938,66 @@ func TestDeleteCategory(t *testing.T) {
 		assert.Equal(t, expectedErrMsg, err.Error())
 	})
 }
+
+func TestDeleteCategoryReturning(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewCategoryRepo(db)
+	ctx := context.Background()
+
+	selectQuery := regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id = $1 FOR UPDATE`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM categories WHERE id = $1`)
+
+	t.Run("should delete and return category in one transaction", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).WillReturnRows(mockRows)
+		mock.ExpectExec(deleteQuery).WithArgs(testCategoryOne.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		category, err := repo.DeleteCategoryReturning(ctx, testCategoryOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &testCategoryOne, category)
+	})
+
+	t.Run("should return not found and roll back if no row", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}))
+		mock.ExpectRollback()
+
+		category, err := repo.DeleteCategoryReturning(ctx, testCategoryOne.ID)
+		assert.Nil(t, category)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		expectedErrMsg := "deleteCategoryReturning: not found: id `f2aa335f-6f91-4d4d-8057-53b0009bc376`"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	t.Run("should roll back if delete query fails", func(t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testCategoryOne.ID).WillReturnRows(mockRows)
+		mock.ExpectExec(deleteQuery).WithArgs(testCategoryOne.ID).WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		category, err := repo.DeleteCategoryReturning(ctx, testCategoryOne.ID)
+		assert.Nil(t, category)
+		expectedErrMsg := "deleteCategoryReturning: delete query failed: database error"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	t.Run("should return error if begin fails", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(errors.New("connection refused"))
+
+		category, err := repo.DeleteCategoryReturning(ctx, testCategoryOne.ID)
+		assert.Nil(t, category)
+		expectedErrMsg := "deleteCategoryReturning: begin transaction failed: connection refused"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,34 +1,224 @@
 package datalayer
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	maxLimit     = 1000
+	defaultLimit = 20
+	minLimit     = 1
 )
 
+// MinLimit and MaxLimit bound the page size a caller may ask for
+// explicitly. The repos clamp to them; handlers reject limits outside.
 const (
-	maxLimit = 1000
-	minLimit = 1
+	MinLimit = minLimit
+	MaxLimit = maxLimit
 )
 
-var ErrNotFound = errors.New("not found")
+// MaxBatchSize caps the items of one batch call, such as a bulk create or
+// a fetch by IDs. The API may be configured to accept fewer.
+const MaxBatchSize = 1000
+
+// maxQueryParams is the most bind parameters Postgres accepts in one
+// statement
+const maxQueryParams = 65535
+
+// queryParamLimit is the parameter budget chunk splits batches by. Tests
+// lower it to exercise chunking with small batches.
+var queryParamLimit = maxQueryParams
+
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrEmptyPatch = errors.New("patch has no fields to update")
+	// ErrPreconditionFailed is returned by the Precondition of a patch
+	// that rejects the row as currently stored
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
 
-func checkLimit(limit int) int {
-	if limit < minLimit {
-		limit = minLimit
-	} else if limit > maxLimit {
-		limit = maxLimit
+// chunk splits items into runs small enough that a statement binding
+// paramsPerItem parameters for each item stays within queryParamLimit
+func chunk[T any](items []T, paramsPerItem int) [][]T {
+	size := max(queryParamLimit/paramsPerItem, 1)
+	chunks := make([][]T, 0, (len(items)+size-1)/size)
+	for size < len(items) {
+		chunks = append(chunks, items[:size:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
+
+// limitRange bounds the page size a caller may request. def is served
+// when the caller asks for no particular size.
+type limitRange struct {
+	min int
+	def int
+	max int
+}
+
+// newLimitRange validates that min is positive and that def lies within
+// [min, max]
+func newLimitRange(minLimit, defaultLimit, maxLimit int) (limitRange, error) {
+	if minLimit < 1 {
+		return limitRange{}, fmt.Errorf("newLimitRange: min limit must be positive: got %d", minLimit)
+	}
+	if minLimit > maxLimit {
+		return limitRange{}, fmt.Errorf("newLimitRange: min limit %d exceeds max limit %d", minLimit, maxLimit)
+	}
+	if defaultLimit < minLimit || defaultLimit > maxLimit {
+		return limitRange{}, fmt.Errorf("newLimitRange: default limit %d outside [%d, %d]", defaultLimit, minLimit, maxLimit)
+	}
+	return limitRange{min: minLimit, def: defaultLimit, max: maxLimit}, nil
+}
+
+// mustLimitRange is newLimitRange for ranges fixed at compile time
+func mustLimitRange(minLimit, defaultLimit, maxLimit int) limitRange {
+	limits, err := newLimitRange(minLimit, defaultLimit, maxLimit)
+	if err != nil {
+		panic(err)
+	}
+	return limits
+}
+
+// clamp returns the page size served for limit: def when limit is 0,
+// meaning none was asked for, otherwise limit bounded to [min, max]
+func (l limitRange) clamp(limit int) int {
+	if limit == 0 {
+		return l.def
+	}
+	if limit < l.min {
+		return l.min
+	}
+	if limit > l.max {
+		return l.max
 	}
 	return limit
 }
 
-func checkRowsAffected(result sql.Result, op string) error {
+// ClampLimit returns the page size the repos serve for a requested limit,
+// so callers can report it without a round trip
+func ClampLimit(limit int) int {
+	return mustLimitRange(minLimit, defaultLimit, maxLimit).clamp(limit)
+}
+
+// checkContext reports a cancelled or expired context for repos that do not
+// hand ctx to a driver that would notice on its own
+func checkContext(ctx context.Context, op, entity string) error {
+	if err := ctx.Err(); err != nil {
+		return repoError(op, entity, err)
+	}
+	return nil
+}
+
+// toUTC moves each timestamp to UTC in place. Client-supplied times keep
+// the zone they were sent in and the driver returns TIMESTAMPTZ values in
+// the session's zone, so repos normalize before writing and after reading.
+// Times only compared against, such as list cursors, are bound as UTC too,
+// so the comparison does not depend on whether the column has a zone.
+func toUTC(times ...*time.Time) {
+	for _, t := range times {
+		*t = t.UTC()
+	}
+}
+
+func checkRowsAffected(result sql.Result, op, entity string) error {
 	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+		return repoError(op, entity, fmt.Errorf("failed to get rows affected: %w", err))
 	}
 	if rows == 0 {
-		return fmt.Errorf("%s: no rows affected: %w", op, ErrNotFound)
+		return errNoRowsAffected(op, entity)
+	}
+	return nil
+}
+
+func errNoRowsAffected(op, entity string) error {
+	return repoError(op, entity, fmt.Errorf("no rows affected: %w", ErrNotFound))
+}
+
+// rowWrite is a named INSERT or UPDATE of the row of table with id
+type rowWrite struct {
+	query   string
+	verb    string // insert or update, naming the query in errors
+	table   string
+	columns string // columns read back after the write
+	entity  string
+	id      uuid.UUID
+}
+
+// supportsReturning reports whether db's driver takes a RETURNING clause on
+// INSERT and UPDATE. The drivers binding $n placeholders are Postgres
+// ones, which do.
+func supportsReturning(db *sqlx.DB) bool {
+	return sqlx.BindType(db.DriverName()) == sqlx.DOLLAR
+}
+
+// writeReturning runs w with the fields of row and scans the row as stored
+// back into it, so values set by the database replace the caller's. The
+// stored row comes from a RETURNING clause where the driver supports one
+// and from reading it by id afterwards otherwise. A write matching no row
+// returns ErrNotFound.
+func writeReturning(ctx context.Context, db *sqlx.DB, op string, w rowWrite, row any) error {
+	if !supportsReturning(db) {
+		result, err := db.NamedExecContext(ctx, w.query, row)
+		if err != nil {
+			return repoError(op, w.entity, fmt.Errorf("%s query failed: %w", w.verb, err))
+		}
+		if err := checkRowsAffected(result, op, w.entity); err != nil {
+			return err
+		}
+		query := db.Rebind("SELECT " + w.columns + " FROM " + w.table + " WHERE id = ?")
+		if err := db.GetContext(ctx, row, query, w.id); err != nil {
+			return repoError(op, w.entity, fmt.Errorf("select query failed: %w", err))
+		}
+		return nil
+	}
+
+	rows, err := db.NamedQueryContext(ctx, w.query+" RETURNING "+w.columns, row)
+	if err != nil {
+		return repoError(op, w.entity, fmt.Errorf("%s query failed: %w", w.verb, err))
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return repoError(op, w.entity, fmt.Errorf("%s query failed: %w", w.verb, err))
+		}
+		return errNoRowsAffected(op, w.entity)
+	}
+	if err := rows.StructScan(row); err != nil {
+		return repoError(op, w.entity, fmt.Errorf("scan failed: %w", err))
+	}
+	return nil
+}
+
+// withTx runs fn inside a transaction, committing when fn succeeds and
+// rolling back otherwise
+func withTx(ctx context.Context, db *sqlx.DB, op, entity string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return repoError(op, entity, fmt.Errorf("begin transaction failed: %w", err))
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w: rollback failed: %v", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return repoError(op, entity, fmt.Errorf("commit failed: %w", err))
 	}
 	return nil
 }
@@ -0,0 +1,132 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AttributeDefinitionRepoFactory returns an empty attribute definition
+// repository and the category repository its definitions belong to
+type AttributeDefinitionRepoFactory func(t *testing.T) (datalayer.AttributeDefinitionRepoInterface, datalayer.CategoryRepoInterface)
+
+// RunAttributeDefinitionRepoTests runs the behavior every
+// AttributeDefinitionRepoInterface implementation must share against repos
+// built by newRepo
+func RunAttributeDefinitionRepoTests(t *testing.T, newRepo AttributeDefinitionRepoFactory) {
+	ctx := context.Background()
+
+	t.Run("should list a category's definitions in name order", func(t *testing.T) {
+		repo, categories := newRepo(t)
+		lighting := createCategories(t, categories, 2)
+		finish := &datalayer.AttributeDefinition{
+			CategoryID:    lighting[0].ID,
+			Name:          "finish",
+			Type:          datalayer.AttributeEnum,
+			Required:      true,
+			AllowedValues: datalayer.EnumValues{"matte", "gloss"},
+			CreatedAt:     baseTime,
+		}
+		color := &datalayer.AttributeDefinition{CategoryID: lighting[0].ID, Name: "color", Type: datalayer.AttributeString, CreatedAt: baseTime}
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, finish))
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, color))
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, &datalayer.AttributeDefinition{CategoryID: lighting[1].ID, Name: "color", Type: datalayer.AttributeString}))
+
+		definitions, err := repo.ListAttributeDefinitions(ctx, lighting[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.AttributeDefinition{color, finish}, definitions)
+		assert.Equal(t, datalayer.EnumValues{}, definitions[0].AllowedValues)
+
+		got, err := repo.GetAttributeDefinition(ctx, finish.ID)
+		require.NoError(t, err)
+		assert.Equal(t, finish, got)
+	})
+
+	t.Run("should return empty non-nil list if none are defined", func(t *testing.T) {
+		repo, categories := newRepo(t)
+		category := createCategories(t, categories, 1)[0]
+
+		definitions, err := repo.ListAttributeDefinitions(ctx, category.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, definitions)
+		assert.Empty(t, definitions)
+	})
+
+	t.Run("should return not found for a missing category or definition", func(t *testing.T) {
+		repo, _ := newRepo(t)
+
+		_, err := repo.ListAttributeDefinitions(ctx, uuid.New())
+		assertNotFound(t, err)
+		err = repo.CreateAttributeDefinition(ctx, &datalayer.AttributeDefinition{CategoryID: uuid.New(), Name: "color", Type: datalayer.AttributeString})
+		assertNotFound(t, err)
+		_, err = repo.GetAttributeDefinition(ctx, uuid.New())
+		assertNotFound(t, err)
+		err = repo.UpdateAttributeDefinition(ctx, &datalayer.AttributeDefinition{ID: uuid.New(), Name: "color", Type: datalayer.AttributeString})
+		assertNotFound(t, err)
+		assertNotFound(t, repo.DeleteAttributeDefinition(ctx, uuid.New()))
+	})
+
+	t.Run("should reject a second definition with the same name", func(t *testing.T) {
+		repo, categories := newRepo(t)
+		category := createCategories(t, categories, 1)[0]
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, &datalayer.AttributeDefinition{CategoryID: category.ID, Name: "color", Type: datalayer.AttributeString}))
+
+		err := repo.CreateAttributeDefinition(ctx, &datalayer.AttributeDefinition{CategoryID: category.ID, Name: "color", Type: datalayer.AttributeNumber})
+		assert.True(t, errors.Is(err, datalayer.ErrAttributeExists), "expected ErrAttributeExists, got %v", err)
+	})
+
+	t.Run("should update a definition keeping its category", func(t *testing.T) {
+		repo, categories := newRepo(t)
+		category := createCategories(t, categories, 1)[0]
+		definition := &datalayer.AttributeDefinition{CategoryID: category.ID, Name: "color", Type: datalayer.AttributeString, CreatedAt: baseTime}
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, definition))
+		other := &datalayer.AttributeDefinition{CategoryID: category.ID, Name: "size", Type: datalayer.AttributeString}
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, other))
+
+		updated := &datalayer.AttributeDefinition{
+			ID:            definition.ID,
+			Name:          "shade",
+			Type:          datalayer.AttributeEnum,
+			Required:      true,
+			AllowedValues: datalayer.EnumValues{"warm", "cool"},
+		}
+		require.NoError(t, repo.UpdateAttributeDefinition(ctx, updated))
+		assert.Equal(t, category.ID, updated.CategoryID)
+		assert.Equal(t, baseTime, updated.CreatedAt)
+
+		got, err := repo.GetAttributeDefinition(ctx, definition.ID)
+		require.NoError(t, err)
+		assert.Equal(t, updated, got)
+
+		err = repo.UpdateAttributeDefinition(ctx, &datalayer.AttributeDefinition{ID: other.ID, Name: "shade", Type: datalayer.AttributeString})
+		assert.True(t, errors.Is(err, datalayer.ErrAttributeExists), "expected ErrAttributeExists, got %v", err)
+	})
+
+	t.Run("should delete a definition", func(t *testing.T) {
+		repo, categories := newRepo(t)
+		category := createCategories(t, categories, 1)[0]
+		definition := &datalayer.AttributeDefinition{CategoryID: category.ID, Name: "color", Type: datalayer.AttributeString}
+		require.NoError(t, repo.CreateAttributeDefinition(ctx, definition))
+
+		require.NoError(t, repo.DeleteAttributeDefinition(ctx, definition.ID))
+		_, err := repo.GetAttributeDefinition(ctx, definition.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should return context error when context is cancelled", func(t *testing.T) {
+		repo, categories := newRepo(t)
+		category := createCategories(t, categories, 1)[0]
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := repo.CreateAttributeDefinition(cancelled, &datalayer.AttributeDefinition{CategoryID: category.ID, Name: "color", Type: datalayer.AttributeString})
+		assertCancelled(t, err)
+		_, err = repo.ListAttributeDefinitions(cancelled, category.ID)
+		assertCancelled(t, err)
+	})
+}
@@ -0,0 +1,573 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// CategoryRepoFactory returns an empty repository for a single subtest
+type CategoryRepoFactory func(t *testing.T) datalayer.CategoryRepoInterface
+
+// RunCategoryRepoTests runs the behavior every CategoryRepoInterface
+// implementation must share against repos built by newRepo
+func RunCategoryRepoTests(t *testing.T, newRepo CategoryRepoFactory) {
+	ctx := context.Background()
+
+	t.Run("should get created category by id", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Books", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		got, err := repo.GetCategoryByID(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, category, got)
+	})
+
+	t.Run("should return not found for missing id", func(t *testing.T) {
+		repo := newRepo(t)
+
+		got, err := repo.GetCategoryByID(ctx, uuid.New())
+		assert.Nil(t, got)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should get categories by ids keyed by id skipping missing ones", func(t *testing.T) {
+		repo := newRepo(t)
+		categories := createCategories(t, repo, 3)
+
+		found, err := repo.GetCategoriesByIDs(ctx, []uuid.UUID{categories[2].ID, uuid.New(), categories[0].ID, categories[2].ID})
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]*datalayer.Category{
+			categories[0].ID: categories[0],
+			categories[2].ID: categories[2],
+		}, found)
+
+		found, err = repo.GetCategoriesByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("should reject more distinct ids than the cap", func(t *testing.T) {
+		repo := newRepo(t)
+		ids := make([]uuid.UUID, datalayer.MaxCategoriesByIDs+1)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		found, err := repo.GetCategoriesByIDs(ctx, ids)
+		assert.Nil(t, found)
+		assert.ErrorIs(t, err, datalayer.ErrTooManyIDs)
+
+		repeated := make([]uuid.UUID, datalayer.MaxCategoriesByIDs+1)
+		for i := range repeated {
+			repeated[i] = ids[0]
+		}
+		_, err = repo.GetCategoriesByIDs(ctx, repeated)
+		assert.NoError(t, err, "repeated ids count once")
+	})
+
+	t.Run("should bulk create every category", func(t *testing.T) {
+		repo := newRepo(t)
+		categories := []*datalayer.Category{newCategory("Fiction", baseTime), newCategory("Poetry", baseTime.Add(time.Hour))}
+		require.NoError(t, repo.BulkCreateCategories(ctx, categories))
+
+		for _, category := range categories {
+			got, err := repo.GetCategoryByID(ctx, category.ID)
+			require.NoError(t, err)
+			assert.Equal(t, category, got)
+		}
+	})
+
+	t.Run("should bulk create nothing if one category fails", func(t *testing.T) {
+		repo := newRepo(t)
+		existing := newCategory("Existing", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, existing))
+
+		fresh := newCategory("Fresh", baseTime)
+		clash := newCategory("Clash", baseTime)
+		clash.ID = existing.ID
+		assert.Error(t, repo.BulkCreateCategories(ctx, []*datalayer.Category{fresh, clash}))
+
+		exists, err := repo.CategoryExists(ctx, fresh.ID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should bulk create a child after its parent in the same batch", func(t *testing.T) {
+		repo := newRepo(t)
+		parent := newCategory("Books", baseTime)
+		child := newCategory("Fiction", baseTime)
+		child.ParentID = &parent.ID
+		require.NoError(t, repo.BulkCreateCategories(ctx, []*datalayer.Category{parent, child}))
+
+		got, err := repo.GetCategoryByID(ctx, child.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.ParentID)
+		assert.Equal(t, parent.ID, *got.ParentID)
+	})
+
+	t.Run("should bulk create nothing if a parent does not exist", func(t *testing.T) {
+		repo := newRepo(t)
+		fresh := newCategory("Fresh", baseTime)
+		orphan := newCategory("Orphan", baseTime)
+		missing := uuid.New()
+		orphan.ParentID = &missing
+		assert.Error(t, repo.BulkCreateCategories(ctx, []*datalayer.Category{fresh, orphan}))
+
+		exists, err := repo.CategoryExists(ctx, fresh.ID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should make the children of a deleted category top level", func(t *testing.T) {
+		repo := newRepo(t)
+		parent := newCategory("Music", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, parent))
+		child := newCategory("Jazz", baseTime)
+		child.ParentID = &parent.ID
+		require.NoError(t, repo.CreateCategory(ctx, child))
+
+		require.NoError(t, repo.DeleteCategory(ctx, parent.ID))
+
+		got, err := repo.GetCategoryByID(ctx, child.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.ParentID)
+	})
+
+	t.Run("should report whether a category exists", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Maps", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		exists, err := repo.CategoryExists(ctx, category.ID)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.CategoryExists(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should get category by name ignoring case", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Garden Tools", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		got, err := repo.GetCategoryByName(ctx, "garden TOOLS")
+		require.NoError(t, err)
+		assert.Equal(t, category.ID, got.ID)
+
+		_, err = repo.GetCategoryByName(ctx, "garden")
+		assertNotFound(t, err)
+	})
+
+	t.Run("should list categories after cursor in created_at order", func(t *testing.T) {
+		repo := newRepo(t)
+		first := newCategory("First", baseTime)
+		second := newCategory("Second", baseTime.Add(time.Hour))
+		third := newCategory("Third", baseTime.Add(2*time.Hour))
+		for _, category := range []*datalayer.Category{third, first, second} {
+			require.NoError(t, repo.CreateCategory(ctx, category))
+		}
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Category{first, second}, page.Categories)
+		assert.True(t, page.HasMore)
+		assert.Equal(t, second.CreatedAt, page.NextCursor)
+
+		page, err = repo.ListCategories(ctx, datalayer.CategoryFilter{}, page.NextCursor, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Category{third}, page.Categories)
+		assert.False(t, page.HasMore)
+		assert.True(t, page.NextCursor.IsZero())
+
+		count, err := repo.CountCategories(ctx, datalayer.CategoryFilter{}, first.CreatedAt)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("should resume within the skew window without skipping or repeating", func(t *testing.T) {
+		const skew = 2 * time.Second
+		repo := newRepo(t)
+		served := newCategory("Served", baseTime)
+		next := newCategory("Next", baseTime.Add(time.Hour))
+		require.NoError(t, repo.CreateCategory(ctx, served))
+		require.NoError(t, repo.CreateCategory(ctx, next))
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, 1)
+		require.NoError(t, err)
+		require.Equal(t, []*datalayer.Category{served}, page.Categories)
+		cursor := datalayer.NextCursor(datalayer.Cursor{}, page.Categories, datalayer.CategoryKey, skew)
+
+		// a writer with a lagging clock stamps rows just inside and just
+		// outside the window after the first page was served
+		inside := newCategory("Inside", baseTime.Add(-skew+time.Millisecond))
+		outside := newCategory("Outside", baseTime.Add(-skew))
+		require.NoError(t, repo.CreateCategory(ctx, inside))
+		require.NoError(t, repo.CreateCategory(ctx, outside))
+
+		createdAfter, afterID := cursor.Rewind(skew)
+		filter := datalayer.CategoryFilter{ExcludeIDs: cursor.Seen, AfterID: afterID}
+		page, err = repo.ListCategories(ctx, filter, createdAfter, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Category{inside, next}, page.Categories)
+	})
+
+	t.Run("should not skip categories tied on created_at at a page boundary", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			skew     time.Duration
+			n        int
+			pageSize int
+		}{
+			{name: "without a skew", n: 5, pageSize: 2},
+			// the second page carries more seen ids than a cursor holds
+			{name: "with seen ids overflowing", skew: 2 * time.Second, n: 600, pageSize: datalayer.MaxCursorSeen/2 + 1},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				repo := newRepo(t)
+				for i := range tc.n {
+					require.NoError(t, repo.CreateCategory(ctx, newCategory(fmt.Sprintf("Category %04d", i), baseTime)))
+				}
+				all, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, tc.n)
+				require.NoError(t, err)
+
+				assert.Equal(t, all.Categories, pageCategories(t, repo, tc.skew, tc.pageSize))
+			})
+		}
+	})
+
+	t.Run("should return empty non-nil list if no categories", func(t *testing.T) {
+		repo := newRepo(t)
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Category{}, page.Categories)
+		assert.False(t, page.HasMore)
+		assert.True(t, page.NextCursor.IsZero())
+	})
+
+	t.Run("should end pagination exactly at a page boundary", func(t *testing.T) {
+		repo := newRepo(t)
+		categories := createCategories(t, repo, 4)
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, categories[:2], page.Categories)
+		assert.True(t, page.HasMore)
+
+		page, err = repo.ListCategories(ctx, datalayer.CategoryFilter{}, page.NextCursor, 2)
+		require.NoError(t, err)
+		assert.Equal(t, categories[2:], page.Categories)
+		assert.False(t, page.HasMore)
+
+		page, err = repo.ListCategories(ctx, datalayer.CategoryFilter{}, categories[3].CreatedAt, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page.Categories)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("should clamp limit below minimum", func(t *testing.T) {
+		repo := newRepo(t)
+		categories := createCategories(t, repo, 2)
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, -5)
+		require.NoError(t, err)
+		assert.Equal(t, categories[:1], page.Categories)
+		assert.True(t, page.HasMore)
+	})
+
+	t.Run("should serve the default page size without a limit", func(t *testing.T) {
+		repo := newRepo(t)
+		createCategories(t, repo, defaultLimit+1)
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, 0)
+		require.NoError(t, err)
+		assert.Len(t, page.Categories, defaultLimit)
+		assert.True(t, page.HasMore)
+	})
+
+	t.Run("should clamp limit above maximum", func(t *testing.T) {
+		repo := newRepo(t)
+		createCategories(t, repo, maxLimit+1)
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, maxLimit*10)
+		require.NoError(t, err)
+		assert.Len(t, page.Categories, maxLimit)
+		assert.True(t, page.HasMore)
+	})
+
+	t.Run("should filter by attribute equality", func(t *testing.T) {
+		repo := newRepo(t)
+		books := newCategory("Books", baseTime)
+		books.Attributes = datalayer.CategoryAttributes{"icon": "book", "featured": true, "order": 1.0}
+		music := newCategory("Music", baseTime.Add(time.Hour))
+		music.Attributes = datalayer.CategoryAttributes{"icon": "note", "featured": "true", "order": 2.0}
+		plain := newCategory("Plain", baseTime.Add(2*time.Hour))
+		for _, category := range []*datalayer.Category{books, music, plain} {
+			require.NoError(t, repo.CreateCategory(ctx, category))
+		}
+
+		tests := []struct {
+			name       string
+			attributes map[string]string
+			want       []*datalayer.Category
+		}{
+			{name: "string", attributes: map[string]string{"icon": "book"}, want: []*datalayer.Category{books}},
+			{name: "bool or string", attributes: map[string]string{"featured": "true"}, want: []*datalayer.Category{books, music}},
+			{name: "number", attributes: map[string]string{"order": "2"}, want: []*datalayer.Category{music}},
+			{name: "all keys must match", attributes: map[string]string{"icon": "book", "order": "2"}, want: []*datalayer.Category{}},
+			{name: "missing key", attributes: map[string]string{"banner": "red"}, want: []*datalayer.Category{}},
+		}
+		for _, tt := range tests {
+			filter := datalayer.CategoryFilter{Attributes: tt.attributes}
+			page, err := repo.ListCategories(ctx, filter, time.Time{}, 10)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.want, page.Categories, tt.name)
+
+			count, err := repo.CountCategories(ctx, filter, time.Time{})
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, int64(len(tt.want)), count, tt.name)
+		}
+	})
+
+	t.Run("should replace attributes on patch", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Books", baseTime)
+		category.Attributes = datalayer.CategoryAttributes{"icon": "book"}
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		attributes := datalayer.CategoryAttributes{"banner": "red"}
+		got, err := repo.PatchCategory(ctx, category.ID, datalayer.CategoryPatch{Attributes: &attributes})
+		require.NoError(t, err)
+		assert.Equal(t, attributes, got.Attributes)
+
+		stored, err := repo.GetCategoryByID(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, attributes, stored.Attributes)
+	})
+
+	t.Run("should update name and description", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Old", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		category.Name = "New"
+		category.Description = "New description"
+		createdAt := category.CreatedAt
+		category.CreatedAt = time.Time{}
+		require.NoError(t, repo.UpdateCategory(ctx, category))
+		assert.Equal(t, createdAt, category.CreatedAt, "the stored row is read back")
+
+		got, err := repo.GetCategoryByID(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, category, got)
+	})
+
+	t.Run("should return not found when updating or deleting missing category", func(t *testing.T) {
+		repo := newRepo(t)
+		missing := newCategory("Missing", baseTime)
+
+		assertNotFound(t, repo.UpdateCategory(ctx, missing))
+		assertNotFound(t, repo.DeleteCategory(ctx, missing.ID))
+		_, err := repo.DeleteCategoryReturning(ctx, missing.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should delete category", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Deleted", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		deleted, err := repo.DeleteCategoryReturning(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, category, deleted)
+
+		_, err = repo.GetCategoryByID(ctx, category.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should patch only the provided fields", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Patched", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		description := "new description"
+		patched, err := repo.PatchCategory(ctx, category.ID, datalayer.CategoryPatch{Description: &description})
+		require.NoError(t, err)
+		assert.Equal(t, category.Name, patched.Name)
+		assert.Equal(t, description, patched.Description)
+
+		name := "Renamed"
+		patched, err = repo.PatchCategory(ctx, category.ID, datalayer.CategoryPatch{Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, name, patched.Name)
+		assert.Equal(t, description, patched.Description)
+
+		got, err := repo.GetCategoryByID(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, patched, got)
+	})
+
+	t.Run("should patch only while the precondition holds", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Guarded", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		description := "checked"
+		var checked *datalayer.Category
+		_, err := repo.PatchCategory(ctx, category.ID, datalayer.CategoryPatch{
+			Description: &description,
+			Precondition: func(current *datalayer.Category) error {
+				checked = current
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, category, checked)
+
+		name := "Rejected"
+		_, err = repo.PatchCategory(ctx, category.ID, datalayer.CategoryPatch{
+			Name:         &name,
+			Precondition: func(*datalayer.Category) error { return datalayer.ErrPreconditionFailed },
+		})
+		assert.ErrorIs(t, err, datalayer.ErrPreconditionFailed)
+
+		got, err := repo.GetCategoryByID(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, category.Name, got.Name)
+		assert.Equal(t, description, got.Description)
+	})
+
+	t.Run("should let one of concurrent patches with the same precondition through", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Contended", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		const writers = 8
+		errs := make([]error, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				description := fmt.Sprintf("written by %d", i)
+				_, errs[i] = repo.PatchCategory(ctx, category.ID, datalayer.CategoryPatch{
+					Description: &description,
+					Precondition: func(current *datalayer.Category) error {
+						if current.Description != category.Description {
+							return datalayer.ErrPreconditionFailed
+						}
+						return nil
+					},
+				})
+			}()
+		}
+		wg.Wait()
+
+		winner := -1
+		for i, err := range errs {
+			if err == nil {
+				assert.Equal(t, -1, winner, "more than one patch went through")
+				winner = i
+				continue
+			}
+			assert.ErrorIs(t, err, datalayer.ErrPreconditionFailed)
+		}
+		require.NotEqual(t, -1, winner, "no patch went through")
+		got, err := repo.GetCategoryByID(ctx, category.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("written by %d", winner), got.Description)
+	})
+
+	t.Run("should reject empty patch and missing category", func(t *testing.T) {
+		repo := newRepo(t)
+		name := "Missing"
+
+		_, err := repo.PatchCategory(ctx, uuid.New(), datalayer.CategoryPatch{})
+		assert.True(t, errors.Is(err, datalayer.ErrEmptyPatch), "expected ErrEmptyPatch, got %v", err)
+
+		_, err = repo.PatchCategory(ctx, uuid.New(), datalayer.CategoryPatch{Name: &name})
+		assertNotFound(t, err)
+	})
+
+	t.Run("should return context error when context is cancelled", func(t *testing.T) {
+		repo := newRepo(t)
+		category := newCategory("Cancelled", baseTime)
+		require.NoError(t, repo.CreateCategory(ctx, category))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := repo.GetCategoryByID(cancelled, category.ID)
+		assertCancelled(t, err)
+		_, err = repo.GetCategoriesByIDs(cancelled, []uuid.UUID{category.ID})
+		assertCancelled(t, err)
+		_, err = repo.GetCategoryByName(cancelled, category.Name)
+		assertCancelled(t, err)
+		_, err = repo.ListCategories(cancelled, datalayer.CategoryFilter{}, time.Time{}, 10)
+		assertCancelled(t, err)
+		_, err = repo.CountCategories(cancelled, datalayer.CategoryFilter{}, time.Time{})
+		assertCancelled(t, err)
+		assertCancelled(t, repo.CreateCategory(cancelled, newCategory("New", baseTime)))
+		assertCancelled(t, repo.UpdateCategory(cancelled, category))
+		_, err = repo.PatchCategory(cancelled, category.ID, datalayer.CategoryPatch{Name: &category.Name})
+		assertCancelled(t, err)
+		assertCancelled(t, repo.DeleteCategory(cancelled, category.ID))
+		_, err = repo.DeleteCategoryReturning(cancelled, category.ID)
+		assertCancelled(t, err)
+
+		_, err = repo.GetCategoryByID(ctx, category.ID)
+		assert.NoError(t, err, "cancelled calls must not modify the repo")
+	})
+}
+
+// createCategories stores n categories one second apart and returns them in
+// created_at order
+func createCategories(t *testing.T, repo datalayer.CategoryRepoInterface, n int) []*datalayer.Category {
+	categories := make([]*datalayer.Category, n)
+	for i := range categories {
+		categories[i] = newCategory(fmt.Sprintf("Category %04d", i), baseTime.Add(time.Duration(i)*time.Second))
+		require.NoError(t, repo.CreateCategory(context.Background(), categories[i]))
+	}
+	return categories
+}
+
+// pageCategories lists every category of repo pageSize at a time, resuming
+// each page from the cursor of the previous one
+func pageCategories(t *testing.T, repo datalayer.CategoryRepoInterface, skew time.Duration, pageSize int) []*datalayer.Category {
+	served := []*datalayer.Category{}
+	var cursor datalayer.Cursor
+	for range 100 {
+		createdAfter, afterID := cursor.Rewind(skew)
+		filter := datalayer.CategoryFilter{ExcludeIDs: cursor.Seen, AfterID: afterID}
+		page, err := repo.ListCategories(context.Background(), filter, createdAfter, pageSize)
+		require.NoError(t, err)
+		served = append(served, page.Categories...)
+		if !page.HasMore {
+			return served
+		}
+		cursor = datalayer.NextCursor(cursor, page.Categories, datalayer.CategoryKey, skew)
+	}
+	t.Fatal("pagination did not end")
+	return nil
+}
+
+func newCategory(name string, createdAt time.Time) *datalayer.Category {
+	return &datalayer.Category{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: name + " description",
+		CreatedAt:   createdAt,
+	}
+}
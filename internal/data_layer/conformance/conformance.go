@@ -0,0 +1,56 @@
+// Package conformance holds the behavior shared by every repository
+// implementation. Each implementation runs RunCategoryRepoTests and
+// RunProductRepoTests with a factory that builds an empty repo for a subtest
+// and registers any teardown with t.Cleanup.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// maxLimit mirrors the repo page size ceiling
+	maxLimit = 1000
+	// defaultLimit mirrors the page size served when none is asked for
+	defaultLimit = 20
+)
+
+// baseTime is a fixed UTC timestamp with no sub-microsecond precision so it
+// survives a round trip through Postgres
+var baseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// assertNotFound checks err wraps ErrNotFound with context rather than
+// returning the bare sentinel
+func assertNotFound(t *testing.T, err error) {
+	t.Helper()
+	assert.True(t, errors.Is(err, datalayer.ErrNotFound), "expected ErrNotFound, got %v", err)
+	assert.NotEqual(t, datalayer.ErrNotFound, err, "ErrNotFound should be wrapped with the operation")
+	assertKind(t, err, datalayer.KindNotFound)
+}
+
+// assertCancelled checks err wraps context.Canceled so callers can tell an
+// aborted request from a failed query
+func assertCancelled(t *testing.T, err error) {
+	t.Helper()
+	assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+	assertKind(t, err, datalayer.KindTimeout)
+}
+
+// assertKind checks err is a RepoError naming its op and entity and
+// classified as kind
+func assertKind(t *testing.T, err error, kind datalayer.RepoErrorKind) {
+	t.Helper()
+	var repoErr *datalayer.RepoError
+	if !assert.True(t, errors.As(err, &repoErr), "expected a RepoError, got %T: %v", err, err) {
+		return
+	}
+	assert.NotEmpty(t, repoErr.Op)
+	assert.NotEmpty(t, repoErr.Entity)
+	assert.Equal(t, kind, repoErr.Kind)
+}
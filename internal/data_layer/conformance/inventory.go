@@ -0,0 +1,93 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// InventoryRepoFactory returns an empty inventory repository, the product
+// repository whose stock it adjusts and a category ID for new products
+type InventoryRepoFactory func(t *testing.T) (datalayer.InventoryRepoInterface, datalayer.ProductRepoInterface, uuid.UUID)
+
+// RunInventoryRepoTests runs the behavior every InventoryRepoInterface
+// implementation must share against repos built by newRepo
+func RunInventoryRepoTests(t *testing.T, newRepo InventoryRepoFactory) {
+	ctx := context.Background()
+
+	t.Run("should adjust stock and record the movement", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 10)
+		note := "pallet 7"
+
+		movement := &datalayer.InventoryMovement{ProductID: product.ID, Delta: -3, Reason: datalayer.ReasonDamaged, Note: &note}
+		require.NoError(t, repo.AdjustStock(ctx, movement))
+		assert.NotEqual(t, uuid.Nil, movement.ID)
+		assert.Equal(t, 7, movement.QuantityAfter)
+		assertStock(t, products, product.ID, 7)
+
+		page, err := repo.ListMovements(ctx, product.ID, datalayer.MovementFilter{}, baseTime, 10)
+		require.NoError(t, err)
+		require.Len(t, page.Movements, 1)
+		assert.Equal(t, movement.ID, page.Movements[0].ID)
+		assert.Equal(t, &note, page.Movements[0].Note)
+		assert.Equal(t, datalayer.ReasonDamaged, page.Movements[0].Reason)
+	})
+
+	t.Run("should reject adjustments below zero without recording them", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 2)
+
+		err := repo.AdjustStock(ctx, &datalayer.InventoryMovement{ProductID: product.ID, Delta: -3, Reason: datalayer.ReasonSold})
+		assert.True(t, errors.Is(err, datalayer.ErrInsufficientStock), "expected ErrInsufficientStock, got %v", err)
+		assertStock(t, products, product.ID, 2)
+
+		page, err := repo.ListMovements(ctx, product.ID, datalayer.MovementFilter{}, baseTime, 10)
+		require.NoError(t, err)
+		assert.Empty(t, page.Movements)
+
+		err = repo.AdjustStock(ctx, &datalayer.InventoryMovement{ProductID: uuid.New(), Delta: 1, Reason: datalayer.ReasonReceived})
+		assertNotFound(t, err)
+	})
+
+	t.Run("should filter by reason and paginate", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 0)
+		other := createStockedProduct(t, products, categoryID, 0)
+
+		reasons := []datalayer.MovementReason{
+			datalayer.ReasonReceived, datalayer.ReasonSold, datalayer.ReasonReceived, datalayer.ReasonReceived,
+		}
+		for _, reason := range reasons {
+			delta := 5
+			if reason == datalayer.ReasonSold {
+				delta = -1
+			}
+			require.NoError(t, repo.AdjustStock(ctx, &datalayer.InventoryMovement{ProductID: product.ID, Delta: delta, Reason: reason}))
+		}
+		require.NoError(t, repo.AdjustStock(ctx, &datalayer.InventoryMovement{ProductID: other.ID, Delta: 1, Reason: datalayer.ReasonReceived}))
+		assertStock(t, products, product.ID, 14)
+
+		filter := datalayer.MovementFilter{Reason: datalayer.ReasonReceived}
+		first, err := repo.ListMovements(ctx, product.ID, filter, baseTime, 2)
+		require.NoError(t, err)
+		require.Len(t, first.Movements, 2)
+		assert.True(t, first.HasMore)
+
+		second, err := repo.ListMovements(ctx, product.ID, filter, first.NextCursor, 2)
+		require.NoError(t, err)
+		require.Len(t, second.Movements, 1)
+		assert.False(t, second.HasMore)
+
+		for _, movement := range append(first.Movements, second.Movements...) {
+			assert.Equal(t, product.ID, movement.ProductID)
+			assert.Equal(t, datalayer.ReasonReceived, movement.Reason)
+		}
+		assert.Equal(t, 14, second.Movements[0].QuantityAfter)
+	})
+}
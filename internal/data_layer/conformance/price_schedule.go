@@ -0,0 +1,144 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PriceScheduleRepoFactory returns an empty price schedule repository, the
+// product repository its schedules belong to and a category ID for new
+// products
+type PriceScheduleRepoFactory func(t *testing.T) (datalayer.PriceScheduleRepoInterface, datalayer.ProductRepoInterface, uuid.UUID)
+
+// RunPriceScheduleRepoTests runs the behavior every
+// PriceScheduleRepoInterface implementation must share against repos built
+// by newRepo
+func RunPriceScheduleRepoTests(t *testing.T, newRepo PriceScheduleRepoFactory) {
+	ctx := context.Background()
+
+	t.Run("should list a product's schedules in effective order", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 1)
+		other := createStockedProduct(t, products, categoryID, 1)
+
+		later := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 20, EffectiveAt: baseTime.Add(2 * time.Hour)}
+		sooner := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 15, EffectiveAt: baseTime.Add(time.Hour)}
+		require.NoError(t, repo.CreatePriceSchedule(ctx, later))
+		require.NoError(t, repo.CreatePriceSchedule(ctx, sooner))
+		require.NoError(t, repo.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: other.ID, NewPrice: 5, EffectiveAt: baseTime}))
+
+		schedules, err := repo.ListPriceSchedules(ctx, product.ID)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		assert.Equal(t, sooner.ID, schedules[0].ID)
+		assert.Equal(t, later.ID, schedules[1].ID)
+		assert.Equal(t, 20.0, schedules[1].NewPrice)
+		assert.False(t, schedules[1].Applied)
+
+		_, err = repo.ListPriceSchedules(ctx, uuid.New())
+		assertNotFound(t, err)
+	})
+
+	t.Run("should reject a second schedule for the same instant", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 1)
+		effectiveAt := baseTime.Add(time.Hour)
+
+		require.NoError(t, repo.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 10, EffectiveAt: effectiveAt}))
+		err := repo.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 11, EffectiveAt: effectiveAt})
+		assert.True(t, errors.Is(err, datalayer.ErrScheduleConflict), "expected ErrScheduleConflict, got %v", err)
+
+		err = repo.CreatePriceSchedule(ctx, &datalayer.PriceSchedule{ProductID: uuid.New(), NewPrice: 10, EffectiveAt: effectiveAt})
+		assertNotFound(t, err)
+	})
+
+	t.Run("should return due schedules until they are applied", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 1)
+
+		due := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 10, EffectiveAt: baseTime}
+		future := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 11, EffectiveAt: baseTime.Add(time.Hour)}
+		require.NoError(t, repo.CreatePriceSchedule(ctx, due))
+		require.NoError(t, repo.CreatePriceSchedule(ctx, future))
+
+		schedules, err := repo.ListDuePriceSchedules(ctx, baseTime, 10)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.Equal(t, due.ID, schedules[0].ID)
+
+		_, err = repo.ApplyPriceSchedule(ctx, due.ID)
+		require.NoError(t, err)
+		schedules, err = repo.ListDuePriceSchedules(ctx, baseTime, 10)
+		require.NoError(t, err)
+		assert.Empty(t, schedules)
+	})
+
+	t.Run("should apply only the price, keeping stock changed meanwhile", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 5)
+		schedule := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 15, EffectiveAt: baseTime}
+		require.NoError(t, repo.CreatePriceSchedule(ctx, schedule))
+
+		// stock moves after the schedule was read but before it is applied
+		stocked, err := products.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		stocked.Quantity = 2
+		require.NoError(t, products.UpdateProduct(ctx, stocked))
+
+		applied, err := repo.ApplyPriceSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 15.0, applied.Price)
+		assert.Equal(t, 2, applied.Quantity)
+		stored, err := products.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, applied, stored)
+
+		_, err = repo.ApplyPriceSchedule(ctx, schedule.ID)
+		assert.True(t, errors.Is(err, datalayer.ErrScheduleApplied), "expected ErrScheduleApplied, got %v", err)
+		_, err = repo.ApplyPriceSchedule(ctx, uuid.New())
+		assertNotFound(t, err)
+	})
+
+	t.Run("should not apply schedules of deleted products", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 1)
+		schedule := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 15, EffectiveAt: baseTime}
+		require.NoError(t, repo.CreatePriceSchedule(ctx, schedule))
+		require.NoError(t, products.DeleteProduct(ctx, product.ID))
+
+		_, err := repo.ApplyPriceSchedule(ctx, schedule.ID)
+		assertNotFound(t, err)
+		schedules, err := repo.ListDuePriceSchedules(ctx, baseTime, 10)
+		require.NoError(t, err)
+		assert.Empty(t, schedules)
+	})
+
+	t.Run("should cancel only pending schedules", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 1)
+
+		pending := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 10, EffectiveAt: baseTime.Add(time.Hour)}
+		applied := &datalayer.PriceSchedule{ProductID: product.ID, NewPrice: 11, EffectiveAt: baseTime}
+		require.NoError(t, repo.CreatePriceSchedule(ctx, pending))
+		require.NoError(t, repo.CreatePriceSchedule(ctx, applied))
+		_, err := repo.ApplyPriceSchedule(ctx, applied.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.CancelPriceSchedule(ctx, pending.ID))
+		err = repo.CancelPriceSchedule(ctx, applied.ID)
+		assert.True(t, errors.Is(err, datalayer.ErrScheduleApplied), "expected ErrScheduleApplied, got %v", err)
+		assertNotFound(t, repo.CancelPriceSchedule(ctx, pending.ID))
+
+		schedules, err := repo.ListPriceSchedules(ctx, product.ID)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.True(t, schedules[0].Applied)
+	})
+}
@@ -0,0 +1,1036 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ProductRepoFactory returns an empty repository for a single subtest. The
+// category returned must exist so products can reference it.
+type ProductRepoFactory func(t *testing.T) (datalayer.ProductRepoInterface, uuid.UUID)
+
+// RunProductRepoTests runs the behavior every ProductRepoInterface
+// implementation must share against repos built by newRepo
+func RunProductRepoTests(t *testing.T, newRepo ProductRepoFactory) {
+	ctx := context.Background()
+
+	t.Run("should get created product by id", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Pen", categoryID, baseTime)
+		weight := 0.02
+		product.Weight = &weight
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, product, got)
+	})
+
+	t.Run("should assign id and created at if not set", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Pencil", categoryID, time.Time{})
+		product.ID = uuid.Nil
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		assert.NotEqual(t, uuid.Nil, product.ID)
+		assert.False(t, product.CreatedAt.IsZero())
+	})
+
+	t.Run("should default currency and keep an explicit one", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		dollars := newProduct("Dollars", categoryID, baseTime)
+		euros := newProduct("Euros", categoryID, baseTime)
+		euros.Currency = "EUR"
+		require.NoError(t, repo.CreateProduct(ctx, dollars))
+		require.NoError(t, repo.CreateProduct(ctx, euros))
+
+		got, err := repo.GetProductByID(ctx, dollars.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.DefaultCurrency, got.Currency)
+		got, err = repo.GetProductByID(ctx, euros.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", got.Currency)
+	})
+
+	t.Run("should store metadata and read none back as empty", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		tagged := newProduct("Tagged", categoryID, baseTime)
+		tagged.Metadata = datalayer.ProductMetadata{"brand": "Acme", "color": "red"}
+		plain := newProduct("Plain", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, tagged))
+		require.NoError(t, repo.CreateProduct(ctx, plain))
+
+		got, err := repo.GetProductByID(ctx, tagged.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductMetadata{"brand": "Acme", "color": "red"}, got.Metadata)
+		got, err = repo.GetProductByID(ctx, plain.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductMetadata{}, got.Metadata)
+
+		got.Metadata = datalayer.ProductMetadata{"brand": "Globex"}
+		require.NoError(t, repo.UpdateProduct(ctx, got))
+		got, err = repo.GetProductByID(ctx, plain.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductMetadata{"brand": "Globex"}, got.Metadata)
+	})
+
+	t.Run("should store created at in UTC", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Marker", categoryID, baseTime.In(time.FixedZone("UTC-7", -7*60*60)))
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, baseTime.UTC(), got.CreatedAt)
+		assert.Equal(t, time.UTC, got.CreatedAt.Location())
+	})
+
+	t.Run("should return not found for missing id", func(t *testing.T) {
+		repo, _ := newRepo(t)
+
+		got, err := repo.GetProductByID(ctx, uuid.New())
+		assert.Nil(t, got)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should report whether a product exists", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Eraser", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		exists, err := repo.ProductExists(ctx, product.ID)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.ProductExists(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should report the availability of active products only", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		active := newProduct("Stapler", categoryID, baseTime)
+		draft := newProduct("Hole punch", categoryID, baseTime)
+		draft.Status = datalayer.ProductDraft
+		require.NoError(t, repo.CreateProduct(ctx, active))
+		require.NoError(t, repo.CreateProduct(ctx, draft))
+
+		quantity, err := repo.GetProductAvailability(ctx, active.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, quantity)
+
+		_, err = repo.GetProductAvailability(ctx, draft.ID)
+		assertNotFound(t, err)
+		_, err = repo.GetProductAvailability(ctx, uuid.New())
+		assertNotFound(t, err)
+	})
+
+	t.Run("should count active products by category", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		draft := newProduct("Draft", categoryID, baseTime)
+		draft.Status = datalayer.ProductDraft
+		for _, product := range []*datalayer.Product{
+			newProduct("Pen", categoryID, baseTime),
+			newProduct("Pencil", categoryID, baseTime.Add(time.Hour)),
+			draft,
+		} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		count, err := repo.CountAllProducts(ctx, datalayer.ProductCountFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = repo.CountAllProducts(ctx, datalayer.ProductCountFilter{CategoryID: &categoryID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		otherID := uuid.New()
+		count, err = repo.CountAllProducts(ctx, datalayer.ProductCountFilter{CategoryID: &otherID})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should list products after cursor in created_at order", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		first := newProduct("First", categoryID, baseTime)
+		second := newProduct("Second", categoryID, baseTime.Add(time.Hour))
+		third := newProduct("Third", categoryID, baseTime.Add(2*time.Hour))
+		for _, product := range []*datalayer.Product{second, third, first} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{first, second}, page)
+
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{}, second.CreatedAt, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{third}, page)
+	})
+
+	t.Run("should break created_at ties by id", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		low := newProduct("Low", categoryID, baseTime)
+		low.ID = uuid.MustParse("00000000-0000-4000-8000-000000000001")
+		high := newProduct("High", categoryID, baseTime)
+		high.ID = uuid.MustParse("ffffffff-0000-4000-8000-000000000001")
+		require.NoError(t, repo.CreateProduct(ctx, high))
+		require.NoError(t, repo.CreateProduct(ctx, low))
+
+		for range 3 {
+			page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 10)
+			require.NoError(t, err)
+			assert.Equal(t, []*datalayer.Product{low, high}, page)
+		}
+	})
+
+	t.Run("should resume within the skew window without skipping or repeating", func(t *testing.T) {
+		const skew = 2 * time.Second
+		repo, categoryID := newRepo(t)
+		served := newProduct("Served", categoryID, baseTime)
+		next := newProduct("Next", categoryID, baseTime.Add(time.Hour))
+		require.NoError(t, repo.CreateProduct(ctx, served))
+		require.NoError(t, repo.CreateProduct(ctx, next))
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 1)
+		require.NoError(t, err)
+		require.Equal(t, []*datalayer.Product{served}, page)
+		cursor := datalayer.NextCursor(datalayer.Cursor{}, page, datalayer.ProductKey, skew)
+
+		inside := newProduct("Inside", categoryID, baseTime.Add(-skew+time.Millisecond))
+		outside := newProduct("Outside", categoryID, baseTime.Add(-skew))
+		require.NoError(t, repo.CreateProduct(ctx, inside))
+		require.NoError(t, repo.CreateProduct(ctx, outside))
+
+		createdAfter, afterID := cursor.Rewind(skew)
+		filter := datalayer.ProductFilter{ExcludeIDs: cursor.Seen, AfterID: afterID}
+		page, err = repo.ListProducts(ctx, filter, createdAfter, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{inside, next}, page)
+	})
+
+	t.Run("should not skip products tied on created_at at a page boundary", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			skew     time.Duration
+			n        int
+			pageSize int
+		}{
+			{name: "without a skew", n: 5, pageSize: 2},
+			// the second page carries more seen ids than a cursor holds
+			{name: "with seen ids overflowing", skew: 2 * time.Second, n: 600, pageSize: datalayer.MaxCursorSeen/2 + 1},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				repo, categoryID := newRepo(t)
+				for i := range tc.n {
+					require.NoError(t, repo.CreateProduct(ctx, newProduct(fmt.Sprintf("Product %04d", i), categoryID, baseTime)))
+				}
+				all, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, tc.n)
+				require.NoError(t, err)
+
+				assert.Equal(t, all, pageProducts(t, repo, tc.skew, tc.pageSize))
+			})
+		}
+	})
+
+	t.Run("should reject a product without a status", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Statusless", categoryID, baseTime)
+		product.Status = ""
+
+		err := repo.CreateProduct(ctx, product)
+		assert.ErrorIs(t, err, datalayer.ErrMissingStatus)
+		assertKind(t, err, datalayer.KindInvalid)
+
+		exists, err := repo.ProductExists(ctx, product.ID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should return empty non-nil list if no products", func(t *testing.T) {
+		repo, _ := newRepo(t)
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{}, page)
+	})
+
+	t.Run("should end pagination exactly at a page boundary", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 4)
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, products[:2], page)
+
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{}, page[1].CreatedAt, 2)
+		require.NoError(t, err)
+		assert.Equal(t, products[2:], page)
+
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{}, products[3].CreatedAt, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{}, page)
+	})
+
+	t.Run("should clamp limit below minimum", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 2)
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, -5)
+		require.NoError(t, err)
+		assert.Equal(t, products[:1], page)
+	})
+
+	t.Run("should serve the default page size without a limit", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		createProducts(t, repo, categoryID, defaultLimit+1)
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 0)
+		require.NoError(t, err)
+		assert.Len(t, page, defaultLimit)
+	})
+
+	t.Run("should clamp limit above maximum", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		createProducts(t, repo, categoryID, maxLimit+1)
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, maxLimit*10)
+		require.NoError(t, err)
+		assert.Len(t, page, maxLimit)
+	})
+
+	t.Run("should list related products in the same category", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		source := newProduct("Source", categoryID, baseTime)
+		first := newProduct("First", categoryID, baseTime.Add(time.Hour))
+		second := newProduct("Second", categoryID, baseTime.Add(2*time.Hour))
+		for _, product := range []*datalayer.Product{second, source, first} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		related, err := repo.ListRelatedProducts(ctx, source.ID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{first, second}, related)
+
+		related, err = repo.ListRelatedProducts(ctx, source.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{first}, related)
+	})
+
+	t.Run("should group the newest active products by category", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		oldest := newProduct("Oldest", categoryID, baseTime)
+		middle := newProduct("Middle", categoryID, baseTime.Add(time.Hour))
+		newest := newProduct("Newest", categoryID, baseTime.Add(2*time.Hour))
+		draft := newProduct("Draft", categoryID, baseTime.Add(3*time.Hour))
+		draft.Status = datalayer.ProductDraft
+		for _, product := range []*datalayer.Product{middle, draft, oldest, newest} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		groups, err := repo.ListProductsGroupedByCategory(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID][]*datalayer.Product{categoryID: {newest, middle}}, groups)
+	})
+
+	t.Run("should return empty non-nil list if product has no related products", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		source := newProduct("Alone", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, source))
+
+		related, err := repo.ListRelatedProducts(ctx, source.ID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{}, related)
+	})
+
+	t.Run("should return not found listing related products of missing product", func(t *testing.T) {
+		repo, _ := newRepo(t)
+
+		related, err := repo.ListRelatedProducts(ctx, uuid.New(), 10)
+		assert.Nil(t, related)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should filter listed products by status", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		active := newProduct("Active", categoryID, baseTime)
+		draft := newProduct("Draft", categoryID, baseTime.Add(time.Hour))
+		draft.Status = datalayer.ProductDraft
+		for _, product := range []*datalayer.Product{active, draft} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{Status: datalayer.ProductDraft}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{draft}, page)
+
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{active, draft}, page)
+	})
+
+	t.Run("should group products by normalized name", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		names := []string{
+			"Desk Lamp",
+			"  desk   LAMP ",
+			"DESK\tLamp\n",
+			"Desk\u00a0Lamp",
+			"Desk\u200bLamp", // zero-width space is not white space
+			"Ünïcode Shelf",
+			"Ünïcode\u3000\u2003Shelf",
+			"Café Table",
+			"Cafe\u0301 Table", // combining accent is not composed
+		}
+		products := make([]*datalayer.Product, len(names))
+		for i, name := range names {
+			products[i] = newProduct(name, categoryID, baseTime.Add(time.Duration(i)*time.Second))
+			require.NoError(t, repo.CreateProduct(ctx, products[i]))
+		}
+
+		groups, err := repo.ListDuplicateGroups(ctx, datalayer.DuplicateFilter{}, datalayer.DuplicateGroupKey{}, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, datalayer.DuplicateGroupKey{CategoryID: categoryID, NormalizedName: "desk lamp"}, groups[0].DuplicateGroupKey)
+		assert.Equal(t, products[:4], groups[0].Products)
+		assert.Equal(t, datalayer.DuplicateGroupKey{CategoryID: categoryID, NormalizedName: "ünïcode shelf"}, groups[1].DuplicateGroupKey)
+		assert.Equal(t, products[5:7], groups[1].Products)
+
+		groups, err = repo.ListDuplicateGroups(ctx, datalayer.DuplicateFilter{MinGroupSize: 3}, datalayer.DuplicateGroupKey{}, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "desk lamp", groups[0].NormalizedName)
+	})
+
+	t.Run("should page duplicate groups by key", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		for i, name := range []string{"Alpha", "alpha", "Beta", "BETA", "Gamma", " gamma"} {
+			require.NoError(t, repo.CreateProduct(ctx, newProduct(name, categoryID, baseTime.Add(time.Duration(i)*time.Second))))
+		}
+
+		groups, err := repo.ListDuplicateGroups(ctx, datalayer.DuplicateFilter{}, datalayer.DuplicateGroupKey{}, 2)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "alpha", groups[0].NormalizedName)
+		assert.Equal(t, "beta", groups[1].NormalizedName)
+
+		groups, err = repo.ListDuplicateGroups(ctx, datalayer.DuplicateFilter{}, groups[1].DuplicateGroupKey, 2)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "gamma", groups[0].NormalizedName)
+	})
+
+	t.Run("should only group drafts if asked", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		active := newProduct("Lamp", categoryID, baseTime)
+		draft := newProduct("LAMP", categoryID, baseTime.Add(time.Second))
+		draft.Status = datalayer.ProductDraft
+		for _, product := range []*datalayer.Product{active, draft} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		groups, err := repo.ListDuplicateGroups(ctx, datalayer.DuplicateFilter{}, datalayer.DuplicateGroupKey{}, 10)
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+
+		groups, err = repo.ListDuplicateGroups(ctx, datalayer.DuplicateFilter{IncludeDrafts: true}, datalayer.DuplicateGroupKey{}, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, []*datalayer.Product{active, draft}, groups[0].Products)
+	})
+
+	t.Run("should filter listed products by attribute equality", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		red := newProduct("Red", categoryID, baseTime)
+		blue := newProduct("Blue", categoryID, baseTime.Add(time.Hour))
+		plain := newProduct("Plain", categoryID, baseTime.Add(2*time.Hour))
+		for _, product := range []*datalayer.Product{red, blue, plain} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+		_, err := repo.UpdateProductAttributes(ctx, red.ID, datalayer.ProductAttributes{"color": "red", "wattage": 40.0, "dimmable": true})
+		require.NoError(t, err)
+		_, err = repo.UpdateProductAttributes(ctx, blue.ID, datalayer.ProductAttributes{"color": "blue", "wattage": 60.0, "dimmable": false})
+		require.NoError(t, err)
+
+		tests := []struct {
+			name       string
+			attributes map[string]string
+			want       []uuid.UUID
+		}{
+			{name: "string", attributes: map[string]string{"color": "red"}, want: []uuid.UUID{red.ID}},
+			{name: "number", attributes: map[string]string{"wattage": "60"}, want: []uuid.UUID{blue.ID}},
+			{name: "bool", attributes: map[string]string{"dimmable": "false"}, want: []uuid.UUID{blue.ID}},
+			{name: "all keys must match", attributes: map[string]string{"color": "red", "wattage": "60"}, want: []uuid.UUID{}},
+		}
+		for _, tt := range tests {
+			page, err := repo.ListProducts(ctx, datalayer.ProductFilter{Attributes: tt.attributes}, time.Time{}, 10)
+			require.NoError(t, err, tt.name)
+			ids := []uuid.UUID{}
+			for _, product := range page {
+				ids = append(ids, product.ID)
+			}
+			assert.Equal(t, tt.want, ids, tt.name)
+		}
+	})
+
+	t.Run("should order and page products written with a non-UTC created_at", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		ist := time.FixedZone("IST", 5*60*60+30*60)
+		// 00:30 UTC is 06:00 IST, between the two products stamped in UTC
+		first := newProduct("First", categoryID, baseTime)
+		middle := newProduct("Middle", categoryID, baseTime.Add(30*time.Minute).In(ist))
+		last := newProduct("Last", categoryID, baseTime.Add(time.Hour))
+		for _, product := range []*datalayer.Product{last, middle, first} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+		assert.Equal(t, time.UTC, middle.CreatedAt.Location())
+
+		var names []string
+		var cursor time.Time
+		for {
+			page, err := repo.ListProducts(ctx, datalayer.ProductFilter{}, cursor, 1)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			assert.Equal(t, time.UTC, page[0].CreatedAt.Location(), page[0].Name)
+			names = append(names, page[0].Name)
+			// cursors may come back in any zone
+			cursor = page[0].CreatedAt.In(ist)
+		}
+		assert.Equal(t, []string{"First", "Middle", "Last"}, names)
+
+		stored, err := repo.GetProductByID(ctx, middle.ID)
+		require.NoError(t, err)
+		assert.Equal(t, time.UTC, stored.CreatedAt.Location())
+		assert.True(t, stored.CreatedAt.Equal(baseTime.Add(30*time.Minute)))
+	})
+
+	t.Run("should filter listed products by category ids", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Lamp", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{CategoryIDs: []uuid.UUID{uuid.New(), categoryID}}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{product}, page)
+
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{CategoryIDs: []uuid.UUID{uuid.New()}}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("should filter listed products by whether they have an image", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		pictured := newProduct("Pictured", categoryID, baseTime)
+		bare := newProduct("Bare", categoryID, baseTime.Add(time.Hour))
+		bare.ImageURL = ""
+		alsoPictured := newProduct("AlsoPictured", categoryID, baseTime.Add(2*time.Hour))
+		for _, product := range []*datalayer.Product{pictured, bare, alsoPictured} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		with, without := true, false
+		page, err := repo.ListProducts(ctx, datalayer.ProductFilter{HasImage: &without}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{bare}, page)
+
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{HasImage: &with}, time.Time{}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{pictured}, page)
+		page, err = repo.ListProducts(ctx, datalayer.ProductFilter{HasImage: &with}, pictured.CreatedAt, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []*datalayer.Product{alsoPictured}, page, "pages keep the filter")
+	})
+
+	t.Run("should replace attributes and keep them on update", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Lamp", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+		assert.Equal(t, datalayer.ProductAttributes{}, product.Attributes)
+
+		attributes := datalayer.ProductAttributes{"color": "red", "wattage": 40.0}
+		updated, err := repo.UpdateProductAttributes(ctx, product.ID, attributes)
+		require.NoError(t, err)
+		assert.Equal(t, attributes, updated.Attributes)
+
+		changed := *product
+		changed.Name = "Renamed"
+		changed.Attributes = nil
+		require.NoError(t, repo.UpdateProduct(ctx, &changed))
+		assert.Equal(t, attributes, changed.Attributes, "the stored attributes are read back")
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Name)
+		assert.Equal(t, attributes, got.Attributes)
+
+		_, err = repo.UpdateProductAttributes(ctx, uuid.New(), attributes)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should leave non-active products out of related products", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		source := newProduct("Source", categoryID, baseTime)
+		draft := newProduct("Draft", categoryID, baseTime.Add(time.Hour))
+		draft.Status = datalayer.ProductDraft
+		for _, product := range []*datalayer.Product{source, draft} {
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		related, err := repo.ListRelatedProducts(ctx, source.ID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, related)
+	})
+
+	t.Run("should move through allowed status transitions", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Staged", categoryID, baseTime)
+		product.Status = datalayer.ProductDraft
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		for _, status := range []datalayer.ProductStatus{
+			datalayer.ProductActive,
+			datalayer.ProductDiscontinued,
+			datalayer.ProductActive,
+		} {
+			updated, err := repo.UpdateProductStatus(ctx, product.ID, status)
+			require.NoError(t, err)
+			assert.Equal(t, status, updated.Status)
+		}
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductActive, got.Status)
+	})
+
+	t.Run("should reject invalid status transitions", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Staged", categoryID, baseTime)
+		product.Status = datalayer.ProductDraft
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		updated, err := repo.UpdateProductStatus(ctx, product.ID, datalayer.ProductDiscontinued)
+		assert.Nil(t, updated)
+		assert.ErrorIs(t, err, datalayer.ErrInvalidStatusTransition)
+
+		_, err = repo.UpdateProductStatus(ctx, uuid.New(), datalayer.ProductActive)
+		assertNotFound(t, err)
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductDraft, got.Status)
+	})
+
+	t.Run("should patch status and attributes together", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Patched", categoryID, baseTime)
+		product.Status = datalayer.ProductDraft
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		active := datalayer.ProductActive
+		attributes := datalayer.ProductAttributes{"color": "red"}
+		patched, err := repo.PatchProduct(ctx, product.ID, datalayer.ProductPatch{Status: &active, Attributes: &attributes})
+		require.NoError(t, err)
+		assert.Equal(t, active, patched.Status)
+		assert.Equal(t, attributes, patched.Attributes)
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, patched, got)
+	})
+
+	t.Run("should write nothing if a patch fails", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Unpatched", categoryID, baseTime)
+		product.Status = datalayer.ProductDraft
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		discontinued := datalayer.ProductDiscontinued
+		attributes := datalayer.ProductAttributes{"color": "red"}
+		_, err := repo.PatchProduct(ctx, product.ID, datalayer.ProductPatch{Status: &discontinued, Attributes: &attributes})
+		assert.ErrorIs(t, err, datalayer.ErrInvalidStatusTransition)
+
+		_, err = repo.PatchProduct(ctx, product.ID, datalayer.ProductPatch{
+			Attributes:   &attributes,
+			Precondition: func(*datalayer.Product) error { return datalayer.ErrPreconditionFailed },
+		})
+		assert.ErrorIs(t, err, datalayer.ErrPreconditionFailed)
+
+		_, err = repo.PatchProduct(ctx, uuid.New(), datalayer.ProductPatch{Attributes: &attributes})
+		assertNotFound(t, err)
+		_, err = repo.PatchProduct(ctx, product.ID, datalayer.ProductPatch{})
+		assert.ErrorIs(t, err, datalayer.ErrEmptyPatch)
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, product, got)
+	})
+
+	t.Run("should let one of concurrent patches with the same precondition through", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Contended", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		const writers = 8
+		errs := make([]error, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attributes := datalayer.ProductAttributes{"writer": fmt.Sprint(i)}
+				_, errs[i] = repo.PatchProduct(ctx, product.ID, datalayer.ProductPatch{
+					Attributes: &attributes,
+					Precondition: func(current *datalayer.Product) error {
+						if len(current.Attributes) != 0 {
+							return datalayer.ErrPreconditionFailed
+						}
+						return nil
+					},
+				})
+			}()
+		}
+		wg.Wait()
+
+		winner := -1
+		for i, err := range errs {
+			if err == nil {
+				assert.Equal(t, -1, winner, "more than one patch went through")
+				winner = i
+				continue
+			}
+			assert.ErrorIs(t, err, datalayer.ErrPreconditionFailed)
+		}
+		require.NotEqual(t, -1, winner, "no patch went through")
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductAttributes{"writer": fmt.Sprint(winner)}, got.Attributes)
+	})
+
+	t.Run("should keep status when updating product", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Kept", categoryID, baseTime)
+		product.Status = datalayer.ProductDraft
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		changed := *product
+		changed.Status = datalayer.ProductDiscontinued
+		require.NoError(t, repo.UpdateProduct(ctx, &changed))
+		assert.Equal(t, datalayer.ProductDraft, changed.Status, "the stored status is read back")
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductDraft, got.Status)
+	})
+
+	t.Run("should update product", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Old", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		product.Name = "New"
+		product.Price = 99.5
+		product.Quantity = 0
+		require.NoError(t, repo.UpdateProduct(ctx, product))
+
+		got, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, product, got)
+	})
+
+	t.Run("should return not found when updating or deleting missing product", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		missing := newProduct("Missing", categoryID, baseTime)
+
+		assertNotFound(t, repo.UpdateProduct(ctx, missing))
+		assertNotFound(t, repo.DeleteProduct(ctx, missing.ID))
+		_, err := repo.DeleteProductReturning(ctx, missing.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should delete product", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Deleted", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		deleted, err := repo.DeleteProductReturning(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, product, deleted)
+
+		_, err = repo.GetProductByID(ctx, product.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should get products by IDs in the requested order", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 3)
+
+		found, err := repo.GetProductsByIDs(ctx, []uuid.UUID{products[2].ID, uuid.New(), products[0].ID})
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, products[2], found[0])
+		assert.Equal(t, products[0], found[1])
+
+		found, err = repo.GetProductsByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("should replace relations and keep them in position order", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 4)
+		source := products[0]
+
+		relations := []datalayer.ProductRelation{
+			{RelatedProductID: products[3].ID, RelationType: datalayer.RelationUpsell},
+			{RelatedProductID: products[1].ID, RelationType: datalayer.RelationAccessory},
+		}
+		require.NoError(t, repo.SetProductRelations(ctx, source.ID, relations))
+		assert.Equal(t, 1, relations[1].Position)
+		assert.Equal(t, source.ID, relations[1].ProductID)
+
+		stored, err := repo.ListProductRelations(ctx, source.ID)
+		require.NoError(t, err)
+		assert.Equal(t, relations, stored)
+
+		replacement := []datalayer.ProductRelation{
+			{RelatedProductID: products[2].ID, RelationType: datalayer.RelationRelated},
+			{RelatedProductID: products[3].ID, RelationType: datalayer.RelationRelated},
+		}
+		require.NoError(t, repo.SetProductRelations(ctx, source.ID, replacement))
+		stored, err = repo.ListProductRelations(ctx, source.ID)
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+		assert.Equal(t, products[2].ID, stored[0].RelatedProductID)
+		assert.Equal(t, products[3].ID, stored[1].RelatedProductID)
+
+		require.NoError(t, repo.SetProductRelations(ctx, source.ID, nil))
+		stored, err = repo.ListProductRelations(ctx, source.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+	})
+
+	t.Run("should reject relations to missing products without changing the set", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 2)
+		source := products[0]
+
+		existing := []datalayer.ProductRelation{{RelatedProductID: products[1].ID, RelationType: datalayer.RelationRelated}}
+		require.NoError(t, repo.SetProductRelations(ctx, source.ID, existing))
+
+		err := repo.SetProductRelations(ctx, source.ID, []datalayer.ProductRelation{
+			{RelatedProductID: uuid.New(), RelationType: datalayer.RelationRelated},
+		})
+		assertNotFound(t, err)
+		stored, err := repo.ListProductRelations(ctx, source.ID)
+		require.NoError(t, err)
+		assert.Equal(t, existing, stored)
+
+		assertNotFound(t, repo.SetProductRelations(ctx, uuid.New(), nil))
+		_, err = repo.ListProductRelations(ctx, uuid.New())
+		assertNotFound(t, err)
+	})
+
+	t.Run("should remove relations in both directions when a product is deleted", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 3)
+		source, target, other := products[0], products[1], products[2]
+
+		require.NoError(t, repo.SetProductRelations(ctx, source.ID, []datalayer.ProductRelation{
+			{RelatedProductID: target.ID, RelationType: datalayer.RelationRelated},
+			{RelatedProductID: other.ID, RelationType: datalayer.RelationRelated},
+		}))
+		require.NoError(t, repo.SetProductRelations(ctx, target.ID, []datalayer.ProductRelation{
+			{RelatedProductID: source.ID, RelationType: datalayer.RelationRelated},
+		}))
+		require.NoError(t, repo.SetProductRelations(ctx, other.ID, []datalayer.ProductRelation{
+			{RelatedProductID: target.ID, RelationType: datalayer.RelationRelated},
+		}))
+
+		require.NoError(t, repo.DeleteProduct(ctx, target.ID))
+
+		stored, err := repo.ListProductRelations(ctx, source.ID)
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		assert.Equal(t, other.ID, stored[0].RelatedProductID)
+		stored, err = repo.ListProductRelations(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+
+		_, err = repo.DeleteProductReturning(ctx, other.ID)
+		require.NoError(t, err)
+		stored, err = repo.ListProductRelations(ctx, source.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+	})
+
+	t.Run("should apply a mixed batch in order", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		products := createProducts(t, repo, categoryID, 2)
+		updated, deleted := products[0], products[1]
+		_, err := repo.UpdateProductAttributes(ctx, updated.ID, datalayer.ProductAttributes{"color": "red"})
+		require.NoError(t, err)
+
+		created := newProduct("Created", categoryID, baseTime)
+		replacement := &datalayer.Product{ID: updated.ID, Name: "Renamed", CategoryID: categoryID, Price: 20, Quantity: 7}
+		removed := &datalayer.Product{ID: deleted.ID}
+		itemErrs, err := repo.ApplyProductBatch(ctx, []datalayer.ProductBatchItem{
+			{Op: datalayer.BatchCreate, Product: created},
+			{Op: datalayer.BatchUpdate, Product: replacement},
+			{Op: datalayer.BatchDelete, Product: removed},
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []error{nil, nil, nil}, itemErrs)
+		assert.Equal(t, deleted.Status, removed.Status, "a batch delete reads back the status")
+
+		got, err := repo.GetProductByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, got)
+		got, err = repo.GetProductByID(ctx, updated.ID)
+		require.NoError(t, err)
+		assert.Equal(t, replacement, got)
+		assert.Equal(t, "Renamed", got.Name)
+		assert.Equal(t, datalayer.ProductActive, got.Status, "a batch update keeps the status")
+		assert.Equal(t, datalayer.ProductAttributes{"color": "red"}, got.Attributes, "a batch update keeps the attributes")
+		assert.Equal(t, updated.CreatedAt, got.CreatedAt)
+		_, err = repo.GetProductByID(ctx, deleted.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("should roll back the whole batch when an item fails", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		kept := createProducts(t, repo, categoryID, 1)[0]
+		created := newProduct("Created", categoryID, baseTime)
+		missing := uuid.New()
+
+		_, err := repo.ApplyProductBatch(ctx, []datalayer.ProductBatchItem{
+			{Op: datalayer.BatchCreate, Product: created},
+			{Op: datalayer.BatchDelete, Product: &datalayer.Product{ID: kept.ID}},
+			{Op: datalayer.BatchDelete, Product: &datalayer.Product{ID: missing}},
+		}, false)
+		assertNotFound(t, err)
+		var batchErr *datalayer.ProductBatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Index)
+
+		_, err = repo.GetProductByID(ctx, created.ID)
+		assertNotFound(t, err)
+		_, err = repo.GetProductByID(ctx, kept.ID)
+		assert.NoError(t, err, "a failed batch must not delete anything")
+	})
+
+	t.Run("should apply the other items of a partial batch", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		created := newProduct("Created", categoryID, baseTime)
+		missing := &datalayer.Product{ID: uuid.New(), Name: "Missing", CategoryID: categoryID, Price: 1}
+
+		itemErrs, err := repo.ApplyProductBatch(ctx, []datalayer.ProductBatchItem{
+			{Op: datalayer.BatchUpdate, Product: missing},
+			{Op: datalayer.BatchCreate, Product: created},
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, itemErrs, 2)
+		assertNotFound(t, itemErrs[0])
+		assert.NoError(t, itemErrs[1])
+
+		_, err = repo.GetProductByID(ctx, created.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return context error when context is cancelled", func(t *testing.T) {
+		repo, categoryID := newRepo(t)
+		product := newProduct("Cancelled", categoryID, baseTime)
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := repo.GetProductByID(cancelled, product.ID)
+		assertCancelled(t, err)
+		_, err = repo.ListProducts(cancelled, datalayer.ProductFilter{}, time.Time{}, 10)
+		assertCancelled(t, err)
+		_, err = repo.ListRelatedProducts(cancelled, product.ID, 10)
+		assertCancelled(t, err)
+		_, err = repo.ListProductsGroupedByCategory(cancelled, 5)
+		assertCancelled(t, err)
+		_, err = repo.GetProductsByIDs(cancelled, []uuid.UUID{product.ID})
+		assertCancelled(t, err)
+		_, err = repo.ListProductRelations(cancelled, product.ID)
+		assertCancelled(t, err)
+		assertCancelled(t, repo.SetProductRelations(cancelled, product.ID, nil))
+		assertCancelled(t, repo.CreateProduct(cancelled, newProduct("New", categoryID, baseTime)))
+		assertCancelled(t, repo.UpdateProduct(cancelled, product))
+		_, err = repo.UpdateProductStatus(cancelled, product.ID, datalayer.ProductDiscontinued)
+		assertCancelled(t, err)
+		assertCancelled(t, repo.DeleteProduct(cancelled, product.ID))
+		_, err = repo.DeleteProductReturning(cancelled, product.ID)
+		assertCancelled(t, err)
+		_, err = repo.ApplyProductBatch(cancelled, []datalayer.ProductBatchItem{
+			{Op: datalayer.BatchDelete, Product: &datalayer.Product{ID: product.ID}},
+		}, false)
+		assertCancelled(t, err)
+
+		_, err = repo.GetProductByID(ctx, product.ID)
+		assert.NoError(t, err, "cancelled calls must not modify the repo")
+	})
+}
+
+// createProducts stores n products one second apart and returns them in
+// created_at order
+func createProducts(
+	t *testing.T,
+	repo datalayer.ProductRepoInterface,
+	categoryID uuid.UUID,
+	n int,
+) []*datalayer.Product {
+	products := make([]*datalayer.Product, n)
+	for i := range products {
+		products[i] = newProduct(fmt.Sprintf("Product %04d", i), categoryID, baseTime.Add(time.Duration(i)*time.Second))
+		require.NoError(t, repo.CreateProduct(context.Background(), products[i]))
+	}
+	return products
+}
+
+// pageProducts lists every product of repo pageSize at a time, resuming
+// each page from the cursor of the previous one
+func pageProducts(t *testing.T, repo datalayer.ProductRepoInterface, skew time.Duration, pageSize int) []*datalayer.Product {
+	served := []*datalayer.Product{}
+	var cursor datalayer.Cursor
+	for range 100 {
+		createdAfter, afterID := cursor.Rewind(skew)
+		filter := datalayer.ProductFilter{ExcludeIDs: cursor.Seen, AfterID: afterID}
+		page, err := repo.ListProducts(context.Background(), filter, createdAfter, pageSize)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			return served
+		}
+		served = append(served, page...)
+		cursor = datalayer.NextCursor(cursor, page, datalayer.ProductKey, skew)
+	}
+	t.Fatal("pagination did not end")
+	return nil
+}
+
+func newProduct(name string, categoryID uuid.UUID, createdAt time.Time) *datalayer.Product {
+	return &datalayer.Product{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: name + " description",
+		ImageURL:    "https://example.com/" + name + ".png",
+		CategoryID:  categoryID,
+		Price:       12.5,
+		Quantity:    3,
+		Status:      datalayer.ProductActive,
+		CreatedAt:   createdAt,
+	}
+}
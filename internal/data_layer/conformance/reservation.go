@@ -0,0 +1,167 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ReservationRepoFactory returns an empty reservation repository, the
+// product repository it draws stock from and a category ID for new products
+type ReservationRepoFactory func(t *testing.T) (datalayer.ReservationRepoInterface, datalayer.ProductRepoInterface, uuid.UUID)
+
+// RunReservationRepoTests runs the behavior every ReservationRepoInterface
+// implementation must share against repos built by newRepo
+func RunReservationRepoTests(t *testing.T, newRepo ReservationRepoFactory) {
+	ctx := context.Background()
+
+	t.Run("should take reserved quantity out of stock", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 10)
+
+		reservation := newReservation(product.ID, 3, time.Hour)
+		require.NoError(t, repo.CreateReservation(ctx, reservation))
+		assert.NotEqual(t, uuid.Nil, reservation.ID)
+		assert.Equal(t, datalayer.ReservationHeld, reservation.Status)
+		assertStock(t, products, product.ID, 7)
+	})
+
+	t.Run("should reject reservation beyond available stock", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 2)
+
+		err := repo.CreateReservation(ctx, newReservation(product.ID, 3, time.Hour))
+		assert.True(t, errors.Is(err, datalayer.ErrInsufficientStock), "expected ErrInsufficientStock, got %v", err)
+		assertStock(t, products, product.ID, 2)
+
+		err = repo.CreateReservation(ctx, newReservation(uuid.New(), 1, time.Hour))
+		assertNotFound(t, err)
+	})
+
+	t.Run("should return stock on release", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 5)
+		reservation := newReservation(product.ID, 5, time.Hour)
+		require.NoError(t, repo.CreateReservation(ctx, reservation))
+
+		released, err := repo.ReleaseReservation(ctx, reservation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ReservationReleased, released.Status)
+		assertStock(t, products, product.ID, 5)
+
+		_, err = repo.ReleaseReservation(ctx, reservation.ID)
+		assert.True(t, errors.Is(err, datalayer.ErrReservationClosed), "expected ErrReservationClosed, got %v", err)
+		assertStock(t, products, product.ID, 5)
+	})
+
+	t.Run("should keep stock removed on commit", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 5)
+		reservation := newReservation(product.ID, 2, time.Hour)
+		require.NoError(t, repo.CreateReservation(ctx, reservation))
+
+		committed, err := repo.CommitReservation(ctx, reservation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ReservationCommitted, committed.Status)
+
+		_, err = repo.ReleaseReservation(ctx, reservation.ID)
+		assert.True(t, errors.Is(err, datalayer.ErrReservationClosed), "expected ErrReservationClosed, got %v", err)
+		assertStock(t, products, product.ID, 3)
+
+		_, err = repo.CommitReservation(ctx, uuid.New())
+		assertNotFound(t, err)
+	})
+
+	t.Run("should release expired holds and refuse to commit them", func(t *testing.T) {
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, 10)
+		expired := newReservation(product.ID, 4, -time.Minute)
+		active := newReservation(product.ID, 1, time.Hour)
+		require.NoError(t, repo.CreateReservation(ctx, expired))
+		require.NoError(t, repo.CreateReservation(ctx, active))
+
+		_, err := repo.CommitReservation(ctx, expired.ID)
+		assert.True(t, errors.Is(err, datalayer.ErrReservationExpired), "expected ErrReservationExpired, got %v", err)
+
+		released, err := repo.ReleaseExpiredReservations(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, released)
+		assertStock(t, products, product.ID, 9)
+
+		released, err = repo.ReleaseExpiredReservations(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, released)
+	})
+
+	t.Run("should never oversell under concurrent reservations", func(t *testing.T) {
+		const stock, buyers = 10, 50
+		repo, products, categoryID := newRepo(t)
+		product := createStockedProduct(t, products, categoryID, stock)
+
+		var (
+			wg           sync.WaitGroup
+			mu           sync.Mutex
+			reserved     int
+			insufficient int
+		)
+		for i := 0; i < buyers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := repo.CreateReservation(ctx, newReservation(product.ID, 1, time.Hour))
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					reserved++
+				case errors.Is(err, datalayer.ErrInsufficientStock):
+					insufficient++
+				default:
+					t.Errorf("unexpected reservation error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, stock, reserved)
+		assert.Equal(t, buyers-stock, insufficient)
+		assertStock(t, products, product.ID, 0)
+	})
+}
+
+func createStockedProduct(
+	t *testing.T,
+	products datalayer.ProductRepoInterface,
+	categoryID uuid.UUID,
+	quantity int,
+) *datalayer.Product {
+	t.Helper()
+
+	product := newProduct("Stocked", categoryID, baseTime)
+	product.Quantity = quantity
+	require.NoError(t, products.CreateProduct(context.Background(), product))
+	return product
+}
+
+func newReservation(productID uuid.UUID, quantity int, ttl time.Duration) *datalayer.Reservation {
+	return &datalayer.Reservation{
+		ProductID: productID,
+		Quantity:  quantity,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+}
+
+func assertStock(t *testing.T, products datalayer.ProductRepoInterface, id uuid.UUID, want int) {
+	t.Helper()
+
+	product, err := products.GetProductByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, want, product.Quantity, "product stock")
+}
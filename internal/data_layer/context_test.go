@@ -0,0 +1,165 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLReposHonorCancelledContext(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	categories := NewCategoryRepo(db)
+	products := NewProductRepo(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := []struct {
+		op   string
+		call func() error
+	}{
+		{"getCategoryByID", func() error {
+			_, err := categories.GetCategoryByID(ctx, testCategoryOne.ID)
+			return err
+		}},
+		{"getCategoriesByIDs", func() error {
+			_, err := categories.GetCategoriesByIDs(ctx, []uuid.UUID{testCategoryOne.ID})
+			return err
+		}},
+		{"getCategoryByName", func() error {
+			_, err := categories.GetCategoryByName(ctx, testCategoryOne.Name)
+			return err
+		}},
+		{"listCategories", func() error {
+			_, err := categories.ListCategories(ctx, CategoryFilter{}, time.Time{}, 10)
+			return err
+		}},
+		{"countCategories", func() error {
+			_, err := categories.CountCategories(ctx, CategoryFilter{}, time.Time{})
+			return err
+		}},
+		{"createCategory", func() error {
+			category := testCategoryOne
+			return categories.CreateCategory(ctx, &category)
+		}},
+		{"updateCategory", func() error {
+			category := testCategoryOne
+			return categories.UpdateCategory(ctx, &category)
+		}},
+		{"patchCategory", func() error {
+			_, err := categories.PatchCategory(ctx, testCategoryOne.ID, CategoryPatch{Name: &testCategoryOne.Name})
+			return err
+		}},
+		{"deleteCategory", func() error {
+			return categories.DeleteCategory(ctx, testCategoryOne.ID)
+		}},
+		{"deleteCategoryReturning", func() error {
+			_, err := categories.DeleteCategoryReturning(ctx, testCategoryOne.ID)
+			return err
+		}},
+		{"getProductByID", func() error {
+			_, err := products.GetProductByID(ctx, testProductOne.ID)
+			return err
+		}},
+		{"listProducts", func() error {
+			_, err := products.ListProducts(ctx, ProductFilter{}, time.Time{}, 10)
+			return err
+		}},
+		{"listRelatedProducts", func() error {
+			_, err := products.ListRelatedProducts(ctx, testProductOne.ID, 10)
+			return err
+		}},
+		{"listProductsGroupedByCategory", func() error {
+			_, err := products.ListProductsGroupedByCategory(ctx, 5)
+			return err
+		}},
+		{"getProductsByIDs", func() error {
+			_, err := products.GetProductsByIDs(ctx, []uuid.UUID{testProductOne.ID})
+			return err
+		}},
+		{"listProductRelations", func() error {
+			_, err := products.ListProductRelations(ctx, testProductOne.ID)
+			return err
+		}},
+		{"setProductRelations", func() error {
+			return products.SetProductRelations(ctx, testProductOne.ID, nil)
+		}},
+		{"createProduct", func() error {
+			product := testProductOne
+			return products.CreateProduct(ctx, &product)
+		}},
+		{"updateProduct", func() error {
+			product := testProductOne
+			return products.UpdateProduct(ctx, &product)
+		}},
+		{"updateProductStatus", func() error {
+			_, err := products.UpdateProductStatus(ctx, testProductOne.ID, ProductActive)
+			return err
+		}},
+		{"deleteProduct", func() error {
+			return products.DeleteProduct(ctx, testProductOne.ID)
+		}},
+		{"deleteProductReturning", func() error {
+			_, err := products.DeleteProductReturning(ctx, testProductOne.ID)
+			return err
+		}},
+	}
+
+	for _, tt := range calls {
+		t.Run("should return context error from "+tt.op, func(t *testing.T) {
+			err := tt.call()
+			assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+		})
+	}
+	assert.NoError(t, mock.ExpectationsWereMet(), "no query should reach the driver")
+}
+
+func TestSQLReposAbortSlowQueries(t *testing.T) {
+	const delay = time.Second
+
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewCategoryRepo(db)
+
+	t.Run("should return when the deadline passes instead of waiting for the query", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, description, attributes, parent_id, created_at FROM categories WHERE id = $1`)).
+			WithArgs(testCategoryOne.ID).
+			WillDelayFor(delay).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		category, err := repo.GetCategoryByID(ctx, testCategoryOne.ID)
+		assert.Nil(t, category)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), delay)
+	})
+
+	t.Run("should surface errors raised while iterating rows", func(t *testing.T) {
+		rowErr := errors.New("connection reset")
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "attributes", "created_at"}).
+			AddRow(testCategoryOne.ID, testCategoryOne.Name, testCategoryOne.Description, attributesJSON(testCategoryOne.Attributes), testCategoryOne.CreatedAt).
+			AddRow(testCategoryTwo.ID, testCategoryTwo.Name, testCategoryTwo.Description, attributesJSON(testCategoryTwo.Attributes), testCategoryTwo.CreatedAt).
+			RowError(1, rowErr)
+		mock.ExpectQuery("SELECT id, name, description, attributes, parent_id, created_at FROM categories").WillReturnRows(mockRows)
+
+		page, err := repo.ListCategories(context.Background(), CategoryFilter{}, time.Time{}, 10)
+		assert.Nil(t, page)
+		assert.True(t, errors.Is(err, rowErr))
+		assert.Equal(t, "listCategories: row iteration failed: connection reset", err.Error())
+	})
+}
@@ -0,0 +1,122 @@
+package datalayer
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCursorSeen caps the ids a Cursor carries. A burst of rows created
+// within the skew window can exceed it, in which case the next cursor falls
+// back to its exact (created_at, id) bound.
+const MaxCursorSeen = 500
+
+// Cursor is a position in a (created_at, id) ordered listing. Listing
+// resumes after the row at CreatedAfter and AfterID. With a skew window the
+// cursor is rewound instead, so rows stamped just before it by a server with
+// a lagging clock are not skipped, and Seen holds the ids already served from
+// inside that window, which the next page excludes so they are not served
+// twice.
+type Cursor struct {
+	CreatedAfter time.Time
+	AfterID      uuid.UUID
+	Seen         []uuid.UUID
+}
+
+// Rewind returns the created_at and id bounds to list from, see
+// ProductFilter.AfterID. A cursor without Seen ids is used as is, since
+// rewinding it would serve rows again.
+func (c Cursor) Rewind(skew time.Duration) (time.Time, uuid.UUID) {
+	if len(c.Seen) == 0 || skew <= 0 {
+		return c.CreatedAfter, c.AfterID
+	}
+	return c.CreatedAfter.Add(-skew), uuid.Nil
+}
+
+// NextCursor returns the cursor following a non-empty page listed from
+// prev. key returns a row's id and created_at. The ids of rows within skew
+// of the last one are carried over, as are prev's Seen ids while the window
+// still reaches back to prev.CreatedAfter. A window reaching back to a prev
+// without Seen ids, such as one past MaxCursorSeen, leaves the next cursor
+// exact as well.
+func NextCursor[T any](prev Cursor, rows []T, key func(T) (uuid.UUID, time.Time), skew time.Duration) Cursor {
+	return nextCursor(prev, rows, key, skew, MaxCursorSeen)
+}
+
+// nextCursor is NextCursor carrying at most maxSeen ids, zero meaning no cap
+func nextCursor[T any](prev Cursor, rows []T, key func(T) (uuid.UUID, time.Time), skew time.Duration, maxSeen int) Cursor {
+	lastID, last := key(rows[len(rows)-1])
+	next := Cursor{CreatedAfter: last, AfterID: lastID}
+	if skew <= 0 {
+		return next
+	}
+
+	windowStart := last.Add(-skew)
+	if prev.CreatedAfter.After(windowStart) {
+		if len(prev.Seen) == 0 {
+			// the rows served up to prev are not tracked, so rewinding
+			// over them would serve them again
+			return next
+		}
+		next.Seen = append(next.Seen, prev.Seen...)
+	}
+	for _, row := range rows {
+		id, createdAt := key(row)
+		if createdAt.After(windowStart) {
+			next.Seen = append(next.Seen, id)
+		}
+	}
+	if maxSeen > 0 && len(next.Seen) > maxSeen {
+		next.Seen = nil
+	}
+	return next
+}
+
+// CategoryKey is the NextCursor key of a category
+func CategoryKey(category *Category) (uuid.UUID, time.Time) {
+	return category.ID, category.CreatedAt
+}
+
+// ProductKey is the NextCursor key of a product
+func ProductKey(product *Product) (uuid.UUID, time.Time) {
+	return product.ID, product.CreatedAt
+}
+
+// whereNotIDs excludes the rows with the given ids from b
+func whereNotIDs(b *QueryBuilder, ids []uuid.UUID) *QueryBuilder {
+	if len(ids) == 0 {
+		return b
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	return b.Where("id NOT IN ("+placeholders+")", args...)
+}
+
+// whereAfter keeps the rows after createdAfter in b, or after the
+// (createdAfter, afterID) keyset when afterID is set
+func whereAfter(b *QueryBuilder, createdAfter time.Time, afterID uuid.UUID) *QueryBuilder {
+	if afterID == uuid.Nil {
+		return b.Where("created_at > ?", createdAfter.UTC())
+	}
+	return b.Where("(created_at, id) > (?, ?)", createdAfter.UTC(), afterID)
+}
+
+// after reports whether a row is past the bound of whereAfter, comparing ids
+// bytewise like Postgres does for the in-memory repos
+func after(createdAt time.Time, id uuid.UUID, createdAfter time.Time, afterID uuid.UUID) bool {
+	if afterID == uuid.Nil || !createdAt.Equal(createdAfter) {
+		return createdAt.After(createdAfter)
+	}
+	return slices.Compare(id[:], afterID[:]) > 0
+}
+
+// excluded reports whether id is one of ids, mirroring whereNotIDs for the
+// in-memory repos
+func excluded(ids []uuid.UUID, id uuid.UUID) bool {
+	return slices.Contains(ids, id)
+}
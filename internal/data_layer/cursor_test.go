@@ -0,0 +1,87 @@
+package datalayer_test
+
+import (
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRewind(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should rewind a cursor carrying seen ids", func(t *testing.T) {
+		cursor := datalayer.Cursor{CreatedAfter: createdAt, AfterID: uuid.New(), Seen: []uuid.UUID{uuid.New()}}
+		rewound, afterID := cursor.Rewind(time.Second)
+		assert.Equal(t, createdAt.Add(-time.Second), rewound)
+		assert.Equal(t, uuid.Nil, afterID)
+	})
+
+	t.Run("should not rewind a cursor without seen ids", func(t *testing.T) {
+		cursor := datalayer.Cursor{CreatedAfter: createdAt, AfterID: uuid.New()}
+		rewound, afterID := cursor.Rewind(time.Second)
+		assert.Equal(t, createdAt, rewound)
+		assert.Equal(t, cursor.AfterID, afterID)
+
+		rewound, afterID = datalayer.Cursor{}.Rewind(time.Second)
+		assert.True(t, rewound.IsZero())
+		assert.Equal(t, uuid.Nil, afterID)
+	})
+}
+
+func TestNextCursor(t *testing.T) {
+	const skew = 2 * time.Second
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	category := func(offset time.Duration) *datalayer.Category {
+		return &datalayer.Category{ID: uuid.New(), CreatedAt: base.Add(offset)}
+	}
+
+	t.Run("should carry the ids within the window of the last row", func(t *testing.T) {
+		old, edge, inside, last := category(0), category(time.Second), category(1500*time.Millisecond), category(3*time.Second)
+		page := []*datalayer.Category{old, edge, inside, last}
+
+		cursor := datalayer.NextCursor(datalayer.Cursor{}, page, datalayer.CategoryKey, skew)
+		assert.Equal(t, last.CreatedAt, cursor.CreatedAfter)
+		assert.Equal(t, last.ID, cursor.AfterID)
+		assert.Equal(t, []uuid.UUID{inside.ID, last.ID}, cursor.Seen)
+	})
+
+	t.Run("should keep the previous ids while the window reaches them", func(t *testing.T) {
+		prev := datalayer.Cursor{CreatedAfter: base, Seen: []uuid.UUID{uuid.New()}}
+
+		near := datalayer.NextCursor(prev, []*datalayer.Category{category(time.Second)}, datalayer.CategoryKey, skew)
+		assert.Len(t, near.Seen, 2)
+		assert.Equal(t, prev.Seen[0], near.Seen[0])
+
+		far := datalayer.NextCursor(prev, []*datalayer.Category{category(skew)}, datalayer.CategoryKey, skew)
+		assert.Len(t, far.Seen, 1)
+	})
+
+	t.Run("should stay exact while the window reaches a cursor without ids", func(t *testing.T) {
+		prev := datalayer.Cursor{CreatedAfter: base, AfterID: uuid.New()}
+		row := category(time.Second)
+
+		cursor := datalayer.NextCursor(prev, []*datalayer.Category{row}, datalayer.CategoryKey, skew)
+		assert.Equal(t, datalayer.Cursor{CreatedAfter: row.CreatedAt, AfterID: row.ID}, cursor)
+	})
+
+	t.Run("should carry no ids without a skew", func(t *testing.T) {
+		row := category(0)
+		cursor := datalayer.NextCursor(datalayer.Cursor{}, []*datalayer.Category{row}, datalayer.CategoryKey, 0)
+		assert.Equal(t, datalayer.Cursor{CreatedAfter: base, AfterID: row.ID}, cursor)
+	})
+
+	t.Run("should fall back to an exact cursor past the id cap", func(t *testing.T) {
+		page := make([]*datalayer.Category, datalayer.MaxCursorSeen+1)
+		for i := range page {
+			page[i] = category(time.Duration(i) * time.Microsecond)
+		}
+
+		cursor := datalayer.NextCursor(datalayer.Cursor{}, page, datalayer.CategoryKey, skew)
+		assert.Equal(t, page[len(page)-1].CreatedAt, cursor.CreatedAfter)
+		assert.Equal(t, page[len(page)-1].ID, cursor.AfterID)
+		assert.Empty(t, cursor.Seen)
+	})
+}
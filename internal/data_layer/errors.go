@@ -0,0 +1,87 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+)
+
+// RepoErrorKind classifies a repo failure so callers can count and map
+// failures by class instead of matching error text
+type RepoErrorKind string
+
+const (
+	// KindNotFound means the row asked for does not exist
+	KindNotFound RepoErrorKind = "not_found"
+	// KindConflict means the stored state forbids the change, such as a
+	// taken name, a closed reservation, too little stock or a failed
+	// precondition
+	KindConflict RepoErrorKind = "conflict"
+	// KindInvalid means the call cannot be served as asked, such as an
+	// empty patch, too many IDs or a product without a status
+	KindInvalid RepoErrorKind = "invalid"
+	// KindTimeout means the context was cancelled or expired first
+	KindTimeout RepoErrorKind = "timeout"
+	// KindInternal is any other failure, usually of the database
+	KindInternal RepoErrorKind = "internal"
+)
+
+// Entities name what a repo stores in RepoError.Entity
+const (
+	EntityProduct             = "product"
+	EntityCategory            = "category"
+	EntityAttributeDefinition = "attribute_definition"
+	EntityStockMovement       = "stock_movement"
+	EntityPriceSchedule       = "price_schedule"
+	EntityReservation         = "reservation"
+)
+
+// RepoError is the error repo methods return. Op names the method, Entity
+// what it stores and Kind the class of failure. Err is the cause, so
+// errors.Is still matches sentinels such as ErrNotFound. The text is the
+// "op: cause" the repos have always returned.
+type RepoError struct {
+	Op     string
+	Entity string
+	Kind   RepoErrorKind
+	Err    error
+}
+
+func (e *RepoError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *RepoError) Unwrap() error {
+	return e.Err
+}
+
+// repoError wraps err from op on entity, classifying it by KindOf
+func repoError(op, entity string, err error) error {
+	return &RepoError{Op: op, Entity: entity, Kind: KindOf(err), Err: err}
+}
+
+// KindOf returns the kind of the first RepoError in err's chain. Errors
+// without one, such as those of test doubles, are classified by the
+// sentinel they match the same way repoError does.
+func KindOf(err error) RepoErrorKind {
+	var repoErr *RepoError
+	switch {
+	case errors.As(err, &repoErr):
+		return repoErr.Kind
+	case errors.Is(err, ErrNotFound):
+		return KindNotFound
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, ErrAttributeExists),
+		errors.Is(err, ErrInvalidStatusTransition),
+		errors.Is(err, ErrInsufficientStock),
+		errors.Is(err, ErrReservationClosed),
+		errors.Is(err, ErrReservationExpired),
+		errors.Is(err, ErrScheduleConflict),
+		errors.Is(err, ErrScheduleApplied),
+		errors.Is(err, ErrPreconditionFailed):
+		return KindConflict
+	case errors.Is(err, ErrEmptyPatch), errors.Is(err, ErrTooManyIDs), errors.Is(err, ErrMissingStatus):
+		return KindInvalid
+	}
+	return KindInternal
+}
@@ -0,0 +1,58 @@
+package datalayer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoError(t *testing.T) {
+	cause := fmt.Errorf("%w: id `42`", datalayer.ErrNotFound)
+	err := &datalayer.RepoError{Op: "getProductByID", Entity: datalayer.EntityProduct, Kind: datalayer.KindNotFound, Err: cause}
+
+	t.Run("should prefix the cause with the op", func(t *testing.T) {
+		assert.Equal(t, "getProductByID: not found: id `42`", err.Error())
+	})
+
+	t.Run("should unwrap to the cause", func(t *testing.T) {
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+		assert.Equal(t, cause, errors.Unwrap(err))
+	})
+}
+
+func TestKindOf(t *testing.T) {
+	t.Run("should return the kind of a wrapped repo error", func(t *testing.T) {
+		err := &datalayer.RepoError{Op: "op", Entity: datalayer.EntityCategory, Kind: datalayer.KindConflict, Err: errors.New("taken")}
+		assert.Equal(t, datalayer.KindConflict, datalayer.KindOf(fmt.Errorf("handler: %w", err)))
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want datalayer.RepoErrorKind
+	}{
+		{"not found", datalayer.ErrNotFound, datalayer.KindNotFound},
+		{"cancelled", context.Canceled, datalayer.KindTimeout},
+		{"deadline", context.DeadlineExceeded, datalayer.KindTimeout},
+		{"attribute exists", datalayer.ErrAttributeExists, datalayer.KindConflict},
+		{"status transition", datalayer.ErrInvalidStatusTransition, datalayer.KindConflict},
+		{"insufficient stock", datalayer.ErrInsufficientStock, datalayer.KindConflict},
+		{"reservation closed", datalayer.ErrReservationClosed, datalayer.KindConflict},
+		{"reservation expired", datalayer.ErrReservationExpired, datalayer.KindConflict},
+		{"schedule conflict", datalayer.ErrScheduleConflict, datalayer.KindConflict},
+		{"schedule applied", datalayer.ErrScheduleApplied, datalayer.KindConflict},
+		{"precondition failed", datalayer.ErrPreconditionFailed, datalayer.KindConflict},
+		{"empty patch", datalayer.ErrEmptyPatch, datalayer.KindInvalid},
+		{"too many ids", datalayer.ErrTooManyIDs, datalayer.KindInvalid},
+		{"anything else", errors.New("connection refused"), datalayer.KindInternal},
+	}
+	for _, tt := range tests {
+		t.Run("should classify "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, datalayer.KindOf(fmt.Errorf("op: %w", tt.err)))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// MovementReason explains why a product's stock was adjusted
+type MovementReason string
+
+const (
+	ReasonReceived MovementReason = "received"
+	ReasonDamaged  MovementReason = "damaged"
+	ReasonRecount  MovementReason = "recount"
+	ReasonSold     MovementReason = "sold"
+	ReasonReturned MovementReason = "returned"
+)
+
+// MovementReasons lists every accepted reason code
+var MovementReasons = []MovementReason{ReasonReceived, ReasonDamaged, ReasonRecount, ReasonSold, ReasonReturned}
+
+// Valid reports whether r is one of MovementReasons
+func (r MovementReason) Valid() bool {
+	for _, reason := range MovementReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// InventoryMovement records one stock adjustment and the quantity it left
+type InventoryMovement struct {
+	ID            uuid.UUID      `db:"id" json:"id"`
+	ProductID     uuid.UUID      `db:"product_id" json:"productId"`
+	Delta         int            `db:"delta" json:"delta"`
+	Reason        MovementReason `db:"reason" json:"reason"`
+	Note          *string        `db:"note" json:"note"`
+	QuantityAfter int            `db:"quantity_after" json:"quantityAfter"`
+	CreatedAt     time.Time      `db:"created_at" json:"createdAt"`
+}
+
+// MovementPage is one page of a product's movements in created_at order.
+// NextCursor is only set when HasMore is true.
+type MovementPage struct {
+	Movements  []*InventoryMovement
+	NextCursor time.Time
+	HasMore    bool
+}
+
+// MovementFilter narrows ListMovements. An empty Reason matches every reason.
+type MovementFilter struct {
+	Reason MovementReason
+}
+
+type InventoryRepo struct {
+	db     *sqlx.DB
+	now    func() time.Time
+	limits limitRange
+}
+
+type InventoryRepoInterface interface {
+	AdjustStock(ctx context.Context, movement *InventoryMovement) error
+	ListMovements(
+		ctx context.Context,
+		productID uuid.UUID,
+		filter MovementFilter,
+		createdAfter time.Time,
+		limit int,
+	) (*MovementPage, error)
+}
+
+// NewInventoryRepo creates a new repository instance
+func NewInventoryRepo(db *sqlx.DB) InventoryRepoInterface {
+	return &InventoryRepo{db: db, now: time.Now, limits: mustLimitRange(minLimit, defaultLimit, maxLimit)}
+}
+
+// AdjustStock applies movement.Delta to the product's quantity and records
+// the movement in the same transaction. Stock can not go below zero.
+// ID, QuantityAfter and CreatedAt are set on movement.
+func (r *InventoryRepo) AdjustStock(ctx context.Context, movement *InventoryMovement) error {
+	const op = "adjustStock"
+	const selectQuery = `SELECT quantity FROM products WHERE id = $1 FOR UPDATE`
+	const updateQuery = `UPDATE products SET quantity = $1 WHERE id = $2`
+	const insertQuery = `
+		INSERT INTO inventory_movements(id, product_id, delta, reason, note, quantity_after, created_at)
+		VALUES(:id, :product_id, :delta, :reason, :note, :quantity_after, :created_at)`
+
+	if movement.ID == uuid.Nil {
+		movement.ID = uuid.New()
+	}
+	movement.CreatedAt = r.now().UTC()
+
+	return withTx(ctx, r.db, op, EntityStockMovement, func(tx *sqlx.Tx) error {
+		var quantity int
+		if err := tx.GetContext(ctx, &quantity, selectQuery, movement.ProductID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityStockMovement, fmt.Errorf("%w: product id `%s`", ErrNotFound, movement.ProductID))
+			}
+			return repoError(op, EntityStockMovement, fmt.Errorf("select query failed: %w", err))
+		}
+		if quantity+movement.Delta < 0 {
+			return repoError(op, EntityStockMovement, fmt.Errorf("%w: adjusting %d by %d", ErrInsufficientStock, quantity, movement.Delta))
+		}
+		movement.QuantityAfter = quantity + movement.Delta
+
+		if _, err := tx.ExecContext(ctx, updateQuery, movement.QuantityAfter, movement.ProductID); err != nil {
+			return repoError(op, EntityStockMovement, fmt.Errorf("update query failed: %w", err))
+		}
+		result, err := tx.NamedExecContext(ctx, insertQuery, movement)
+		if err != nil {
+			return repoError(op, EntityStockMovement, fmt.Errorf("insert query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityStockMovement)
+	})
+}
+
+// ListMovements fetches a page of a product's movements created after the
+// cursor. One extra row is fetched to tell whether another page follows.
+func (r *InventoryRepo) ListMovements(
+	ctx context.Context,
+	productID uuid.UUID,
+	filter MovementFilter,
+	createdAfter time.Time, // pagination cursor
+	limit int,
+) (*MovementPage, error) {
+	limit = r.limits.clamp(limit)
+	builder := NewQueryBuilder(
+		`SELECT id, product_id, delta, reason, note, quantity_after, created_at FROM inventory_movements`,
+	).
+		Where("product_id = ?", productID).
+		Where("created_at > ?", createdAfter)
+	if filter.Reason != "" {
+		builder.Where("reason = ?", filter.Reason)
+	}
+	query, args := builder.OrderBy("created_at ASC").Limit(limit + 1).Build()
+
+	movements := []*InventoryMovement{}
+	if err := r.db.SelectContext(ctx, &movements, query, args...); err != nil {
+		return nil, repoError("listMovements", EntityStockMovement, fmt.Errorf("select query failed: %w", err))
+	}
+	for _, movement := range movements {
+		toUTC(&movement.CreatedAt)
+	}
+
+	return newMovementPage(movements, limit), nil
+}
+
+// newMovementPage trims movements fetched with limit+1 down to limit and
+// sets the cursor for the next page if the extra row was present
+func newMovementPage(movements []*InventoryMovement, limit int) *MovementPage {
+	page := &MovementPage{Movements: movements}
+	if len(movements) > limit {
+		page.Movements = movements[:limit]
+		page.NextCursor = page.Movements[limit-1].CreatedAt
+		page.HasMore = true
+	}
+	return page
+}
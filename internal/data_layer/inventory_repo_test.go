@@ -0,0 +1,127 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMovement = InventoryMovement{
+	ID:            uuid.MustParse("6a1f0d3e-9c4b-4c8e-8f57-2d7b5e0c9a14"),
+	ProductID:     testProductOne.ID,
+	Delta:         5,
+	Reason:        ReasonReceived,
+	QuantityAfter: 25,
+	CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var movementColumns = []string{"id", "product_id", "delta", "reason", "note", "quantity_after", "created_at"}
+
+func newTestInventoryRepo(t *testing.T) (*InventoryRepo, sqlmock.Sqlmock) {
+	mockDB, mock, _ := sqlmock.New()
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	now := func() time.Time { return testMovement.CreatedAt }
+	return &InventoryRepo{db: db, now: now, limits: mustLimitRange(minLimit, defaultLimit, maxLimit)}, mock
+}
+
+func TestAdjustStock(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(`SELECT quantity FROM products WHERE id = $1 FOR UPDATE`)
+	updateQuery := regexp.QuoteMeta(`UPDATE products SET quantity = $1 WHERE id = $2`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO inventory_movements(id, product_id, delta, reason, note, quantity_after, created_at)`)
+
+	t.Run("should update quantity and insert movement in one transaction", func(t *testing.T) {
+		repo, mock := newTestInventoryRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(20))
+		mock.ExpectExec(updateQuery).WithArgs(25, testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).
+			WithArgs(testMovement.ID, testProductOne.ID, 5, ReasonReceived, nil, 25, testMovement.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		movement := &InventoryMovement{ID: testMovement.ID, ProductID: testProductOne.ID, Delta: 5, Reason: ReasonReceived}
+		err := repo.AdjustStock(ctx, movement)
+		assert.NoError(t, err)
+		assert.Equal(t, &testMovement, movement)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if stock would go below zero", func(t *testing.T) {
+		repo, mock := newTestInventoryRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+		mock.ExpectRollback()
+
+		err := repo.AdjustStock(ctx, &InventoryMovement{ProductID: testProductOne.ID, Delta: -2, Reason: ReasonSold})
+		assert.True(t, errors.Is(err, ErrInsufficientStock))
+		assert.Equal(t, "adjustStock: insufficient stock: adjusting 1 by -2", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if insert fails", func(t *testing.T) {
+		repo, mock := newTestInventoryRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(20))
+		mock.ExpectExec(updateQuery).WithArgs(25, testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).WillReturnError(errors.New("insert error"))
+		mock.ExpectRollback()
+
+		err := repo.AdjustStock(ctx, &InventoryMovement{ProductID: testProductOne.ID, Delta: 5, Reason: ReasonReceived})
+		assert.Equal(t, "adjustStock: insert query failed: insert error", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListMovements(t *testing.T) {
+	ctx := context.Background()
+	var createdAfter time.Time
+	const base = `SELECT id, product_id, delta, reason, note, quantity_after, created_at FROM inventory_movements ` +
+		`WHERE product_id = $1 AND created_at > $2`
+
+	t.Run("should list every reason without filter", func(t *testing.T) {
+		repo, mock := newTestInventoryRepo(t)
+		mockRows := sqlmock.NewRows(movementColumns).
+			AddRow(testMovement.ID, testMovement.ProductID, testMovement.Delta, testMovement.Reason, nil, testMovement.QuantityAfter, testMovement.CreatedAt)
+		mock.ExpectQuery("^"+regexp.QuoteMeta(base+` ORDER BY created_at ASC LIMIT $3`)+"$").
+			WithArgs(testProductOne.ID, createdAfter, 11).
+			WillReturnRows(mockRows)
+
+		page, err := repo.ListMovements(ctx, testProductOne.ID, MovementFilter{}, createdAfter, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []*InventoryMovement{&testMovement}, page.Movements)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("should filter by reason", func(t *testing.T) {
+		repo, mock := newTestInventoryRepo(t)
+		mock.ExpectQuery("^"+regexp.QuoteMeta(base+` AND reason = $3 ORDER BY created_at ASC LIMIT $4`)+"$").
+			WithArgs(testProductOne.ID, createdAfter, ReasonDamaged, 11).
+			WillReturnRows(sqlmock.NewRows(movementColumns))
+
+		page, err := repo.ListMovements(ctx, testProductOne.ID, MovementFilter{Reason: ReasonDamaged}, createdAfter, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, page.Movements)
+	})
+
+	t.Run("should return error if select query fails", func(t *testing.T) {
+		repo, mock := newTestInventoryRepo(t)
+		mock.ExpectQuery(regexp.QuoteMeta(base)).WillReturnError(errors.New("query error"))
+
+		page, err := repo.ListMovements(ctx, testProductOne.ID, MovementFilter{}, createdAfter, 10)
+		assert.Nil(t, page)
+		assert.Equal(t, "listMovements: select query failed: query error", err.Error())
+	})
+}
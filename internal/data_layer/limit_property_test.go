@@ -0,0 +1,156 @@
+package datalayer
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var propertyConfig = &quick.Config{MaxCount: 200}
+
+func TestLimitRangeProperties(t *testing.T) {
+	t.Run("should reject ranges that are empty, not positive or miss the default", func(t *testing.T) {
+		valid := func(minLimit, defaultLimit, maxLimit int16) bool {
+			_, err := newLimitRange(int(minLimit), int(defaultLimit), int(maxLimit))
+			wantErr := minLimit < 1 || minLimit > maxLimit || defaultLimit < minLimit || defaultLimit > maxLimit
+			return (err != nil) == wantErr
+		}
+		assert.NoError(t, quick.Check(valid, propertyConfig))
+	})
+
+	t.Run("should always clamp into the range", func(t *testing.T) {
+		bounded := func(minLimit, defaultLimit, maxLimit int16, limit int) bool {
+			limits, err := newLimitRange(int(minLimit), int(defaultLimit), int(maxLimit))
+			if err != nil {
+				return true
+			}
+			got := limits.clamp(limit)
+			if got < limits.min || got > limits.max {
+				return false
+			}
+			inRange := limit >= limits.min && limit <= limits.max
+			return !inRange || got == limit
+		}
+		assert.NoError(t, quick.Check(bounded, propertyConfig))
+	})
+
+	t.Run("should serve the default when no limit is asked for", func(t *testing.T) {
+		limits := mustLimitRange(10, 25, 100)
+		assert.Equal(t, 25, limits.clamp(0))
+		assert.Equal(t, 10, limits.clamp(5), "explicit limits keep the floor")
+		assert.Equal(t, 10, limits.clamp(-1))
+	})
+
+	t.Run("should accept the package defaults", func(t *testing.T) {
+		_, err := newLimitRange(minLimit, defaultLimit, maxLimit)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPaginationProperties(t *testing.T) {
+	limits := mustLimitRange(1, 10, 50)
+
+	t.Run("should list every category exactly once", func(t *testing.T) {
+		property := func(size uint8, limit int, seed int64) bool {
+			repo := NewMemoryCategoryRepo()
+			repo.limits = limits
+			want := seedCategories(t, repo, int(size), seed)
+
+			seen := map[uuid.UUID]int{}
+			cursor := time.Time{}
+			for {
+				page, err := repo.ListCategories(context.Background(), CategoryFilter{}, cursor, limit)
+				require.NoError(t, err)
+				if page.HasMore && len(page.Categories) != limits.clamp(limit) {
+					return false
+				}
+				for _, category := range page.Categories {
+					seen[category.ID]++
+				}
+				if !page.HasMore {
+					break
+				}
+				cursor = page.NextCursor
+			}
+			return sameOnce(want, seen)
+		}
+		assert.NoError(t, quick.Check(property, propertyConfig))
+	})
+
+	t.Run("should list every product exactly once", func(t *testing.T) {
+		property := func(size uint8, limit int, seed int64) bool {
+			repo := NewMemoryProductRepo()
+			repo.limits = limits
+			want := seedProducts(t, repo, int(size), seed)
+
+			seen := map[uuid.UUID]int{}
+			cursor := time.Time{}
+			for {
+				products, err := repo.ListProducts(context.Background(), ProductFilter{}, cursor, limit)
+				require.NoError(t, err)
+				for _, product := range products {
+					seen[product.ID]++
+				}
+				if len(products) < limits.clamp(limit) {
+					break
+				}
+				cursor = products[len(products)-1].CreatedAt
+			}
+			return sameOnce(want, seen)
+		}
+		assert.NoError(t, quick.Check(property, propertyConfig))
+	})
+}
+
+// createdAtSpread returns n distinct creation times in random order. The
+// cursor is keyed on created_at alone, so datasets keep it unique.
+func createdAtSpread(n int, seed int64) []time.Time {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := make([]time.Time, n)
+	for i, offset := range rand.New(rand.NewSource(seed)).Perm(n) {
+		times[i] = base.Add(time.Duration(offset+1) * time.Millisecond)
+	}
+	return times
+}
+
+func seedCategories(t *testing.T, repo *MemoryCategoryRepo, n int, seed int64) []uuid.UUID {
+	t.Helper()
+
+	ids := make([]uuid.UUID, 0, n)
+	for _, createdAt := range createdAtSpread(n, seed) {
+		category := &Category{Name: "category", CreatedAt: createdAt}
+		require.NoError(t, repo.CreateCategory(context.Background(), category))
+		ids = append(ids, category.ID)
+	}
+	return ids
+}
+
+func seedProducts(t *testing.T, repo *MemoryProductRepo, n int, seed int64) []uuid.UUID {
+	t.Helper()
+
+	ids := make([]uuid.UUID, 0, n)
+	for _, createdAt := range createdAtSpread(n, seed) {
+		product := &Product{Name: "product", CategoryID: uuid.New(), Status: ProductActive, CreatedAt: createdAt}
+		require.NoError(t, repo.CreateProduct(context.Background(), product))
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func sameOnce(want []uuid.UUID, seen map[uuid.UUID]int) bool {
+	if len(seen) != len(want) {
+		return false
+	}
+	for _, id := range want {
+		if seen[id] != 1 {
+			return false
+		}
+	}
+	return true
+}
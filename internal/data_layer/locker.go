@@ -0,0 +1,76 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Locker elects a single runner for work that must not happen twice across
+// replicas. WithLock runs fn and reports true if the lock was free, or
+// returns false without running fn if another holder has it.
+type Locker interface {
+	WithLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+// AdvisoryLocker is a Locker backed by a Postgres session advisory lock.
+// The lock is taken and released on one pooled connection so it cannot
+// leak onto a connection another caller later borrows.
+type AdvisoryLocker struct {
+	db  *sqlx.DB
+	key int64
+}
+
+// NewAdvisoryLocker creates a Locker for the advisory lock identified by key
+func NewAdvisoryLocker(db *sqlx.DB, key int64) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db, key: key}
+}
+
+// WithLock runs fn while holding the advisory lock, if it is free
+func (l *AdvisoryLocker) WithLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	const op = "advisoryLock"
+
+	conn, err := l.db.Connx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get connection: %w", op, err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.GetContext(ctx, &acquired, `SELECT pg_try_advisory_lock($1)`, l.key); err != nil {
+		return false, fmt.Errorf("%s: lock query failed: %w", op, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		// Unlock even if ctx is done so the session does not keep the lock
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, l.key)
+	}()
+
+	return true, fn(ctx)
+}
+
+// LocalLocker is a Locker for a single process, used with the memory repos
+type LocalLocker struct {
+	mu sync.Mutex
+}
+
+// NewLocalLocker creates an unheld in-process Locker
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{}
+}
+
+// WithLock runs fn while holding the lock, if it is free
+func (l *LocalLocker) WithLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("localLock: %w", err)
+	}
+	if !l.mu.TryLock() {
+		return false, nil
+	}
+	defer l.mu.Unlock()
+	return true, fn(ctx)
+}
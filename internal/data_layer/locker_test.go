@@ -0,0 +1,64 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdvisoryLocker(t *testing.T) {
+	ctx := context.Background()
+	lockQuery := regexp.QuoteMeta(`SELECT pg_try_advisory_lock($1)`)
+	unlockQuery := regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)
+
+	newLocker := func(t *testing.T) (*AdvisoryLocker, sqlmock.Sqlmock) {
+		mockDB, mock, _ := sqlmock.New()
+		t.Cleanup(func() { mockDB.Close() })
+		return NewAdvisoryLocker(sqlx.NewDb(mockDB, "sqlmock"), 42), mock
+	}
+
+	t.Run("should run fn and unlock when the lock is free", func(t *testing.T) {
+		locker, mock := newLocker(t)
+		mock.ExpectQuery(lockQuery).WithArgs(42).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+		mock.ExpectExec(unlockQuery).WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		fnErr := errors.New("apply failed")
+		ran, err := locker.WithLock(ctx, func(context.Context) error { return fnErr })
+		assert.True(t, ran)
+		assert.Equal(t, fnErr, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should skip fn when another session holds the lock", func(t *testing.T) {
+		locker, mock := newLocker(t)
+		mock.ExpectQuery(lockQuery).WithArgs(42).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+
+		ran, err := locker.WithLock(ctx, func(context.Context) error {
+			t.Fatal("fn should not run without the lock")
+			return nil
+		})
+		assert.False(t, ran)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLocalLocker(t *testing.T) {
+	t.Run("should not run fn while the lock is held", func(t *testing.T) {
+		locker := NewLocalLocker()
+		ran, err := locker.WithLock(context.Background(), func(ctx context.Context) error {
+			nested, err := locker.WithLock(ctx, func(context.Context) error { return nil })
+			assert.False(t, nested)
+			return err
+		})
+		assert.True(t, ran)
+		assert.NoError(t, err)
+	})
+}
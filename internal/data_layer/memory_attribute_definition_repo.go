@@ -0,0 +1,185 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryAttributeDefinitionRepo is a map-backed
+// AttributeDefinitionRepoInterface for categories held in a
+// MemoryCategoryRepo. It mirrors the SQL repo's semantics and errors.
+type MemoryAttributeDefinitionRepo struct {
+	mu          sync.Mutex
+	definitions map[uuid.UUID]AttributeDefinition
+	categories  *MemoryCategoryRepo
+	now         func() time.Time
+}
+
+// NewMemoryAttributeDefinitionRepo creates an empty in-memory attribute
+// definition repository for categories
+func NewMemoryAttributeDefinitionRepo(categories *MemoryCategoryRepo) *MemoryAttributeDefinitionRepo {
+	return &MemoryAttributeDefinitionRepo{
+		definitions: map[uuid.UUID]AttributeDefinition{},
+		categories:  categories,
+		now:         time.Now,
+	}
+}
+
+// CreateAttributeDefinition stores a definition for an existing category,
+// failing with ErrAttributeExists if the category already defines Name
+func (r *MemoryAttributeDefinitionRepo) CreateAttributeDefinition(ctx context.Context, definition *AttributeDefinition) error {
+	const op = "createAttributeDefinition"
+	if err := checkContext(ctx, op, EntityAttributeDefinition); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.categoryExists(definition.CategoryID) {
+		return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: category id `%s`", ErrNotFound, definition.CategoryID))
+	}
+	if r.nameTaken(definition.CategoryID, definition.Name, uuid.Nil) {
+		return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: name `%s`", ErrAttributeExists, definition.Name))
+	}
+
+	if definition.ID == uuid.Nil {
+		definition.ID = uuid.New()
+	}
+	if _, ok := r.definitions[definition.ID]; ok {
+		return repoError(op, EntityAttributeDefinition, fmt.Errorf("insert query failed: duplicate id `%s`", definition.ID))
+	}
+	if definition.CreatedAt.IsZero() {
+		definition.CreatedAt = r.now()
+	}
+	toUTC(&definition.CreatedAt)
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = EnumValues{}
+	}
+
+	r.definitions[definition.ID] = cloneDefinition(*definition)
+	return nil
+}
+
+// ListAttributeDefinitions fetches a category's definitions in name order.
+// The category must exist.
+func (r *MemoryAttributeDefinitionRepo) ListAttributeDefinitions(ctx context.Context, categoryID uuid.UUID) ([]*AttributeDefinition, error) {
+	const op = "listAttributeDefinitions"
+	if err := checkContext(ctx, op, EntityAttributeDefinition); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.categoryExists(categoryID) {
+		return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: category id `%s`", ErrNotFound, categoryID))
+	}
+
+	definitions := []*AttributeDefinition{}
+	for _, definition := range r.definitions {
+		if definition.CategoryID == categoryID {
+			definition := cloneDefinition(definition)
+			definitions = append(definitions, &definition)
+		}
+	}
+	slices.SortFunc(definitions, func(a, b *AttributeDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return definitions, nil
+}
+
+// GetAttributeDefinition fetches a definition by its ID
+func (r *MemoryAttributeDefinitionRepo) GetAttributeDefinition(ctx context.Context, id uuid.UUID) (*AttributeDefinition, error) {
+	const op = "getAttributeDefinition"
+	if err := checkContext(ctx, op, EntityAttributeDefinition); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	definition, ok := r.definitions[id]
+	if !ok {
+		return nil, repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	definition = cloneDefinition(definition)
+	return &definition, nil
+}
+
+// UpdateAttributeDefinition replaces the name, type, required flag and
+// allowed values of a definition, filling in CategoryID and CreatedAt from
+// the stored one
+func (r *MemoryAttributeDefinitionRepo) UpdateAttributeDefinition(ctx context.Context, definition *AttributeDefinition) error {
+	const op = "updateAttributeDefinition"
+	if err := checkContext(ctx, op, EntityAttributeDefinition); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.definitions[definition.ID]
+	if !ok {
+		return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: id `%s`", ErrNotFound, definition.ID))
+	}
+	if r.nameTaken(stored.CategoryID, definition.Name, definition.ID) {
+		return repoError(op, EntityAttributeDefinition, fmt.Errorf("%w: name `%s`", ErrAttributeExists, definition.Name))
+	}
+
+	definition.CategoryID = stored.CategoryID
+	definition.CreatedAt = stored.CreatedAt
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = EnumValues{}
+	}
+	r.definitions[definition.ID] = cloneDefinition(*definition)
+	return nil
+}
+
+// DeleteAttributeDefinition removes a definition
+func (r *MemoryAttributeDefinitionRepo) DeleteAttributeDefinition(ctx context.Context, id uuid.UUID) error {
+	const op = "deleteAttributeDefinition"
+	if err := checkContext(ctx, op, EntityAttributeDefinition); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.definitions[id]; !ok {
+		return errNoRowsAffected(op, EntityAttributeDefinition)
+	}
+	delete(r.definitions, id)
+	return nil
+}
+
+// nameTaken reports whether a definition other than except already uses
+// name in the category. Callers must hold the lock.
+func (r *MemoryAttributeDefinitionRepo) nameTaken(categoryID uuid.UUID, name string, except uuid.UUID) bool {
+	for id, definition := range r.definitions {
+		if id != except && definition.CategoryID == categoryID && definition.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryAttributeDefinitionRepo) categoryExists(id uuid.UUID) bool {
+	r.categories.mu.RLock()
+	defer r.categories.mu.RUnlock()
+	_, ok := r.categories.categories[id]
+	return ok
+}
+
+// cloneDefinition copies definition so callers cannot change stored
+// allowed values
+func cloneDefinition(definition AttributeDefinition) AttributeDefinition {
+	definition.AllowedValues = slices.Clone(definition.AllowedValues)
+	return definition
+}
@@ -0,0 +1,353 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryCategoryRepo is a map-backed CategoryRepoInterface for running the
+// API without a database. It mirrors the SQL repo's semantics and errors.
+type MemoryCategoryRepo struct {
+	mu         sync.RWMutex
+	categories map[uuid.UUID]Category
+	now        func() time.Time
+	limits     limitRange
+}
+
+// NewMemoryCategoryRepo creates an empty in-memory category repository
+func NewMemoryCategoryRepo() *MemoryCategoryRepo {
+	return &MemoryCategoryRepo{
+		categories: map[uuid.UUID]Category{},
+		now:        time.Now,
+		limits:     mustLimitRange(minLimit, defaultLimit, maxLimit),
+	}
+}
+
+// GetCategoryByID fetches a category by its ID
+func (r *MemoryCategoryRepo) GetCategoryByID(ctx context.Context, id uuid.UUID) (*Category, error) {
+	if err := checkContext(ctx, "getCategoryByID", EntityCategory); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, repoError("getCategoryByID", EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	return &category, nil
+}
+
+// GetCategoriesByIDs fetches the categories with the given IDs keyed by
+// ID. IDs without a category are absent from the map. More than
+// MaxCategoriesByIDs distinct IDs fail with ErrTooManyIDs.
+func (r *MemoryCategoryRepo) GetCategoriesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*Category, error) {
+	const op = "getCategoriesByIDs"
+	if err := checkContext(ctx, op, EntityCategory); err != nil {
+		return nil, err
+	}
+	ids, err := distinctIDs(op, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make(map[uuid.UUID]*Category, len(ids))
+	for _, id := range ids {
+		if category, ok := r.categories[id]; ok {
+			categories[id] = &category
+		}
+	}
+	return categories, nil
+}
+
+// CategoryExists reports whether a category with id exists
+func (r *MemoryCategoryRepo) CategoryExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	if err := checkContext(ctx, "categoryExists", EntityCategory); err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.categories[id]
+	return ok, nil
+}
+
+// GetCategoryByName fetches a category by its name, ignoring case
+func (r *MemoryCategoryRepo) GetCategoryByName(ctx context.Context, name string) (*Category, error) {
+	if err := checkContext(ctx, "getCategoryByName", EntityCategory); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, category := range r.sorted() {
+		if strings.EqualFold(category.Name, name) {
+			return &category, nil
+		}
+	}
+	return nil, repoError("getCategoryByName", EntityCategory, fmt.Errorf("%w: name `%s`", ErrNotFound, name))
+}
+
+// ListCategories fetches a page of categories matching filter created after
+// the cursor in created_at order. Attribute filters are applied in Go.
+func (r *MemoryCategoryRepo) ListCategories(
+	ctx context.Context,
+	filter CategoryFilter,
+	createdAfter time.Time, // pagination cursor
+	limit int,
+) (*CategoryPage, error) {
+	if err := checkContext(ctx, "listCategories", EntityCategory); err != nil {
+		return nil, err
+	}
+
+	limit = r.limits.clamp(limit)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := []*Category{}
+	for _, category := range r.sorted() {
+		if len(categories) == limit+1 {
+			break
+		}
+		if after(category.CreatedAt, category.ID, createdAfter, filter.AfterID) && filter.matches(category) {
+			categories = append(categories, &category)
+		}
+	}
+	return newCategoryPage(categories, limit), nil
+}
+
+// CountCategories counts the categories matching filter created after the
+// given cursor
+func (r *MemoryCategoryRepo) CountCategories(ctx context.Context, filter CategoryFilter, createdAfter time.Time) (int64, error) {
+	if err := checkContext(ctx, "countCategories", EntityCategory); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, category := range r.categories {
+		if after(category.CreatedAt, category.ID, createdAfter, filter.AfterID) && filter.matches(category) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CreateCategory stores a new category, generating an ID and stamping
+// CreatedAt with the current time when they are not set
+func (r *MemoryCategoryRepo) CreateCategory(ctx context.Context, category *Category) error {
+	if err := checkContext(ctx, "createCategory", EntityCategory); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	setCategoryDefaults(category, r.now)
+	if _, ok := r.categories[category.ID]; ok {
+		return repoError("createCategory", EntityCategory, fmt.Errorf("insert query failed: duplicate id `%s`", category.ID))
+	}
+	if err := r.checkParent(category, nil); err != nil {
+		return repoError("createCategory", EntityCategory, err)
+	}
+
+	r.categories[category.ID] = cloneCategory(*category)
+	return nil
+}
+
+// BulkCreateCategories stores every category or, if any ID is taken or
+// any parent is missing, none of them. A parent may be an earlier category
+// of the same batch.
+func (r *MemoryCategoryRepo) BulkCreateCategories(ctx context.Context, categories []*Category) error {
+	if err := checkContext(ctx, "bulkCreateCategories", EntityCategory); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		setCategoryDefaults(category, r.now)
+		if _, ok := r.categories[category.ID]; ok || seen[category.ID] {
+			return repoError("bulkCreateCategories", EntityCategory, fmt.Errorf("insert query failed: duplicate id `%s`", category.ID))
+		}
+		if err := r.checkParent(category, seen); err != nil {
+			return repoError("bulkCreateCategories", EntityCategory, err)
+		}
+		seen[category.ID] = true
+	}
+
+	for _, category := range categories {
+		r.categories[category.ID] = cloneCategory(*category)
+	}
+	return nil
+}
+
+// checkParent fails like the parent_id foreign key when the parent of
+// category is neither stored nor in batch. Callers must hold the lock.
+func (r *MemoryCategoryRepo) checkParent(category *Category, batch map[uuid.UUID]bool) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if _, ok := r.categories[*category.ParentID]; ok || batch[*category.ParentID] {
+		return nil
+	}
+	return fmt.Errorf("insert query failed: parent category `%s` does not exist", *category.ParentID)
+}
+
+// cloneCategory copies category so the stored one shares no attributes or
+// parent with the caller's
+func cloneCategory(category Category) Category {
+	category.Attributes = maps.Clone(category.Attributes)
+	if category.ParentID != nil {
+		parent := *category.ParentID
+		category.ParentID = &parent
+	}
+	return category
+}
+
+// UpdateCategory modifies the name, description and attributes of an
+// existing category and fills category from the stored one
+func (r *MemoryCategoryRepo) UpdateCategory(ctx context.Context, category *Category) error {
+	if err := checkContext(ctx, "updateCategory", EntityCategory); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categories[category.ID]
+	if !ok {
+		return errNoRowsAffected("updateCategory", EntityCategory)
+	}
+
+	stored.Name = category.Name
+	stored.Description = category.Description
+	stored.Attributes = maps.Clone(category.Attributes)
+	if stored.Attributes == nil {
+		stored.Attributes = CategoryAttributes{}
+	}
+	r.categories[category.ID] = stored
+
+	*category = stored
+	category.Attributes = maps.Clone(stored.Attributes)
+	return nil
+}
+
+// PatchCategory updates only the fields set in patch
+func (r *MemoryCategoryRepo) PatchCategory(ctx context.Context, id uuid.UUID, patch CategoryPatch) (*Category, error) {
+	if err := checkContext(ctx, "patchCategory", EntityCategory); err != nil {
+		return nil, err
+	}
+	if patch.IsEmpty() {
+		return nil, repoError("patchCategory", EntityCategory, ErrEmptyPatch)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categories[id]
+	if !ok {
+		return nil, repoError("patchCategory", EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	if patch.Precondition != nil {
+		current := stored
+		if err := patch.Precondition(&current); err != nil {
+			return nil, repoError("patchCategory", EntityCategory, err)
+		}
+	}
+
+	if patch.Name != nil {
+		stored.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		stored.Description = *patch.Description
+	}
+	if patch.Attributes != nil {
+		stored.Attributes = maps.Clone(*patch.Attributes)
+		if stored.Attributes == nil {
+			stored.Attributes = CategoryAttributes{}
+		}
+	}
+	r.categories[id] = stored
+	return &stored, nil
+}
+
+// DeleteCategory removes a category by its ID
+func (r *MemoryCategoryRepo) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if err := checkContext(ctx, "deleteCategory", EntityCategory); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return errNoRowsAffected("deleteCategory", EntityCategory)
+	}
+	r.delete(id)
+	return nil
+}
+
+// DeleteCategoryReturning removes a category by its ID and returns the row as
+// it was before deletion
+func (r *MemoryCategoryRepo) DeleteCategoryReturning(ctx context.Context, id uuid.UUID) (*Category, error) {
+	if err := checkContext(ctx, "deleteCategoryReturning", EntityCategory); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, repoError("deleteCategoryReturning", EntityCategory, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	r.delete(id)
+	return &category, nil
+}
+
+// delete removes the category with id and, like ON DELETE SET NULL, makes
+// its children top-level categories. Callers must hold the lock.
+func (r *MemoryCategoryRepo) delete(id uuid.UUID) {
+	delete(r.categories, id)
+	for childID, child := range r.categories {
+		if child.ParentID != nil && *child.ParentID == id {
+			child.ParentID = nil
+			r.categories[childID] = child
+		}
+	}
+}
+
+// sorted returns the categories ordered by created_at, then id.
+// Callers must hold the lock.
+func (r *MemoryCategoryRepo) sorted() []Category {
+	categories := make([]Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, category)
+	}
+	slices.SortFunc(categories, func(a, b Category) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return categories
+}
@@ -0,0 +1,104 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryInventoryRepo is a slice-backed InventoryRepoInterface adjusting
+// stock in a MemoryProductRepo. It mirrors the SQL repo's semantics and
+// errors.
+type MemoryInventoryRepo struct {
+	mu        sync.RWMutex
+	movements []InventoryMovement
+	products  *MemoryProductRepo
+	now       func() time.Time
+	limits    limitRange
+}
+
+// NewMemoryInventoryRepo creates an empty in-memory inventory repository
+// adjusting stock in products
+func NewMemoryInventoryRepo(products *MemoryProductRepo) *MemoryInventoryRepo {
+	return &MemoryInventoryRepo{
+		products: products,
+		now:      time.Now,
+		limits:   mustLimitRange(minLimit, defaultLimit, maxLimit),
+	}
+}
+
+// AdjustStock applies movement.Delta to the product's quantity and records
+// the movement
+func (r *MemoryInventoryRepo) AdjustStock(ctx context.Context, movement *InventoryMovement) error {
+	const op = "adjustStock"
+	if err := checkContext(ctx, op, EntityStockMovement); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	product, ok := r.products.products[movement.ProductID]
+	if !ok {
+		return repoError(op, EntityStockMovement, fmt.Errorf("%w: product id `%s`", ErrNotFound, movement.ProductID))
+	}
+	if product.Quantity+movement.Delta < 0 {
+		return repoError(op, EntityStockMovement, fmt.Errorf("%w: adjusting %d by %d", ErrInsufficientStock, product.Quantity, movement.Delta))
+	}
+
+	if movement.ID == uuid.Nil {
+		movement.ID = uuid.New()
+	}
+	movement.CreatedAt = r.now().UTC()
+	movement.QuantityAfter = product.Quantity + movement.Delta
+
+	product.Quantity = movement.QuantityAfter
+	r.products.products[product.ID] = product
+	r.movements = append(r.movements, *movement)
+	return nil
+}
+
+// ListMovements fetches a page of a product's movements created after the
+// cursor in created_at order
+func (r *MemoryInventoryRepo) ListMovements(
+	ctx context.Context,
+	productID uuid.UUID,
+	filter MovementFilter,
+	createdAfter time.Time, // pagination cursor
+	limit int,
+) (*MovementPage, error) {
+	if err := checkContext(ctx, "listMovements", EntityStockMovement); err != nil {
+		return nil, err
+	}
+
+	limit = r.limits.clamp(limit)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := slices.Clone(r.movements)
+	slices.SortStableFunc(sorted, func(a, b InventoryMovement) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	movements := []*InventoryMovement{}
+	for _, movement := range sorted {
+		if len(movements) == limit+1 {
+			break
+		}
+		if movement.ProductID != productID || !movement.CreatedAt.After(createdAfter) {
+			continue
+		}
+		if filter.Reason != "" && movement.Reason != filter.Reason {
+			continue
+		}
+		movements = append(movements, &movement)
+	}
+	return newMovementPage(movements, limit), nil
+}
@@ -0,0 +1,197 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryPriceScheduleRepo is a map-backed PriceScheduleRepoInterface for
+// products held in a MemoryProductRepo. It mirrors the SQL repo's semantics
+// and errors.
+type MemoryPriceScheduleRepo struct {
+	mu        sync.Mutex
+	schedules map[uuid.UUID]PriceSchedule
+	products  *MemoryProductRepo
+	now       func() time.Time
+	limits    limitRange
+}
+
+// NewMemoryPriceScheduleRepo creates an empty in-memory price schedule
+// repository for products
+func NewMemoryPriceScheduleRepo(products *MemoryProductRepo) *MemoryPriceScheduleRepo {
+	return &MemoryPriceScheduleRepo{
+		schedules: map[uuid.UUID]PriceSchedule{},
+		products:  products,
+		now:       time.Now,
+		limits:    mustLimitRange(minLimit, defaultLimit, maxLimit),
+	}
+}
+
+// CreatePriceSchedule records a pending price change, failing with
+// ErrScheduleConflict if the product already has one at EffectiveAt
+func (r *MemoryPriceScheduleRepo) CreatePriceSchedule(ctx context.Context, schedule *PriceSchedule) error {
+	const op = "createPriceSchedule"
+	if err := checkContext(ctx, op, EntityPriceSchedule); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.productExists(schedule.ProductID) {
+		return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: product id `%s`", ErrNotFound, schedule.ProductID))
+	}
+	effectiveAt := schedule.EffectiveAt.UTC()
+	for _, existing := range r.schedules {
+		if existing.ProductID == schedule.ProductID && existing.EffectiveAt.Equal(effectiveAt) {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: %s", ErrScheduleConflict, effectiveAt.Format(time.RFC3339)))
+		}
+	}
+
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	if _, ok := r.schedules[schedule.ID]; ok {
+		return repoError(op, EntityPriceSchedule, fmt.Errorf("insert query failed: duplicate id `%s`", schedule.ID))
+	}
+	schedule.EffectiveAt = effectiveAt
+	schedule.Applied = false
+	schedule.CreatedAt = r.now().UTC()
+
+	r.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+// ListPriceSchedules fetches every schedule of a product in effective_at
+// order. The product must exist.
+func (r *MemoryPriceScheduleRepo) ListPriceSchedules(ctx context.Context, productID uuid.UUID) ([]*PriceSchedule, error) {
+	const op = "listPriceSchedules"
+	if err := checkContext(ctx, op, EntityPriceSchedule); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.productExists(productID) {
+		return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("%w: product id `%s`", ErrNotFound, productID))
+	}
+
+	schedules := []*PriceSchedule{}
+	for _, schedule := range r.sorted() {
+		if schedule.ProductID == productID {
+			schedules = append(schedules, &schedule)
+		}
+	}
+	return schedules, nil
+}
+
+// CancelPriceSchedule deletes a pending schedule. Applied schedules fail
+// with ErrScheduleApplied.
+func (r *MemoryPriceScheduleRepo) CancelPriceSchedule(ctx context.Context, id uuid.UUID) error {
+	const op = "cancelPriceSchedule"
+	if err := checkContext(ctx, op, EntityPriceSchedule); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	if schedule.Applied {
+		return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrScheduleApplied, id))
+	}
+	delete(r.schedules, id)
+	return nil
+}
+
+// ListDuePriceSchedules fetches up to limit pending schedules effective at
+// or before asOf, oldest first
+func (r *MemoryPriceScheduleRepo) ListDuePriceSchedules(ctx context.Context, asOf time.Time, limit int) ([]*PriceSchedule, error) {
+	if err := checkContext(ctx, "listDuePriceSchedules", EntityPriceSchedule); err != nil {
+		return nil, err
+	}
+
+	limit = r.limits.clamp(limit)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedules := []*PriceSchedule{}
+	for _, schedule := range r.sorted() {
+		if len(schedules) == limit {
+			break
+		}
+		if !schedule.Applied && !schedule.EffectiveAt.After(asOf) {
+			schedules = append(schedules, &schedule)
+		}
+	}
+	return schedules, nil
+}
+
+// ApplyPriceSchedule sets the price of a pending schedule's product and
+// marks the schedule applied, returning the product as stored afterwards.
+// A schedule whose product was deleted is dropped and fails with
+// ErrNotFound, as the SQL repo's cascade would have removed it.
+func (r *MemoryPriceScheduleRepo) ApplyPriceSchedule(ctx context.Context, id uuid.UUID) (*Product, error) {
+	const op = "applyPriceSchedule"
+	if err := checkContext(ctx, op, EntityPriceSchedule); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	if schedule.Applied {
+		return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrScheduleApplied, id))
+	}
+
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	product, ok := r.products.products[schedule.ProductID]
+	if !ok {
+		delete(r.schedules, id)
+		return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	product.Price = schedule.NewPrice
+	r.products.products[product.ID] = product
+	schedule.Applied = true
+	r.schedules[id] = schedule
+	return &product, nil
+}
+
+func (r *MemoryPriceScheduleRepo) productExists(id uuid.UUID) bool {
+	r.products.mu.RLock()
+	defer r.products.mu.RUnlock()
+	_, ok := r.products.products[id]
+	return ok
+}
+
+// sorted returns the schedules ordered by effective_at, then id.
+// Callers must hold the lock.
+func (r *MemoryPriceScheduleRepo) sorted() []PriceSchedule {
+	schedules := make([]PriceSchedule, 0, len(r.schedules))
+	for _, schedule := range r.schedules {
+		schedules = append(schedules, schedule)
+	}
+	slices.SortFunc(schedules, func(a, b PriceSchedule) int {
+		if c := a.EffectiveAt.Compare(b.EffectiveAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+	return schedules
+}
@@ -0,0 +1,462 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryProductRepo is a map-backed ProductRepoInterface for running the
+// API without a database. It mirrors the SQL repo's semantics and errors.
+type MemoryProductRepo struct {
+	mu        sync.RWMutex
+	products  map[uuid.UUID]Product
+	relations map[uuid.UUID][]ProductRelation
+	now       func() time.Time
+	limits    limitRange
+}
+
+// NewMemoryProductRepo creates an empty in-memory product repository
+func NewMemoryProductRepo() *MemoryProductRepo {
+	return &MemoryProductRepo{
+		products:  map[uuid.UUID]Product{},
+		relations: map[uuid.UUID][]ProductRelation{},
+		now:       time.Now,
+		limits:    mustLimitRange(minLimit, defaultLimit, maxLimit),
+	}
+}
+
+// GetProductByID fetches a product by its ID
+func (r *MemoryProductRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*Product, error) {
+	if err := checkContext(ctx, "getProductByID", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, repoError("getProductByID", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	return &product, nil
+}
+
+// ProductExists reports whether a product with id exists
+func (r *MemoryProductRepo) ProductExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	if err := checkContext(ctx, "productExists", EntityProduct); err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.products[id]
+	return ok, nil
+}
+
+// GetProductAvailability fetches the quantity in stock of an active product
+func (r *MemoryProductRepo) GetProductAvailability(ctx context.Context, id uuid.UUID) (int, error) {
+	if err := checkContext(ctx, "getProductAvailability", EntityProduct); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Status != ProductActive {
+		return 0, repoError("getProductAvailability", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	return product.Quantity, nil
+}
+
+// ListProducts fetches products matching filter created after the cursor
+// in created_at order
+func (r *MemoryProductRepo) ListProducts(
+	ctx context.Context,
+	filter ProductFilter,
+	createdAfter time.Time, // pagination token
+	limit int,
+) ([]*Product, error) {
+	if err := checkContext(ctx, "listProducts", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	limit = r.limits.clamp(limit)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := []*Product{}
+	for _, product := range r.sorted() {
+		if len(products) == limit {
+			break
+		}
+		if after(product.CreatedAt, product.ID, createdAfter, filter.AfterID) &&
+			(filter.Status == "" || product.Status == filter.Status) &&
+			(filter.HasImage == nil || *filter.HasImage == (product.ImageURL != "")) &&
+			attributesMatch(product.Attributes, filter.Attributes) &&
+			(len(filter.CategoryIDs) == 0 || slices.Contains(filter.CategoryIDs, product.CategoryID)) &&
+			!excluded(filter.ExcludeIDs, product.ID) {
+			products = append(products, &product)
+		}
+	}
+	return products, nil
+}
+
+// CountAllProducts counts the active products matching filter
+func (r *MemoryProductRepo) CountAllProducts(ctx context.Context, filter ProductCountFilter) (int64, error) {
+	if err := checkContext(ctx, "countAllProducts", EntityProduct); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, product := range r.products {
+		if product.Status != ProductActive {
+			continue
+		}
+		if filter.CategoryID != nil && product.CategoryID != *filter.CategoryID {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ListRelatedProducts fetches other active products in the same category
+// as productID in created_at order. The source product must exist.
+func (r *MemoryProductRepo) ListRelatedProducts(ctx context.Context, productID uuid.UUID, limit int) ([]*Product, error) {
+	if err := checkContext(ctx, "listRelatedProducts", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	limit = r.limits.clamp(limit)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	source, ok := r.products[productID]
+	if !ok {
+		return nil, repoError("listRelatedProducts", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, productID))
+	}
+
+	products := []*Product{}
+	for _, product := range r.sorted() {
+		if len(products) == limit {
+			break
+		}
+		if product.CategoryID == source.CategoryID && product.ID != productID && product.Status == ProductActive {
+			products = append(products, &product)
+		}
+	}
+	return products, nil
+}
+
+// ListProductsGroupedByCategory fetches the newest active products of every
+// category, keyed by category ID and newest first. Each category holds up
+// to limitPerCategory products; categories without active products are
+// absent.
+func (r *MemoryProductRepo) ListProductsGroupedByCategory(ctx context.Context, limitPerCategory int) (map[uuid.UUID][]*Product, error) {
+	if err := checkContext(ctx, "listProductsGroupedByCategory", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	limitPerCategory = r.limits.clamp(limitPerCategory)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := r.sorted()
+	groups := map[uuid.UUID][]*Product{}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		product := sorted[i]
+		if product.Status == ProductActive && len(groups[product.CategoryID]) < limitPerCategory {
+			groups[product.CategoryID] = append(groups[product.CategoryID], &product)
+		}
+	}
+	return groups, nil
+}
+
+// GetProductsByIDs fetches the products with the given IDs in the order of
+// ids. IDs without a product are skipped.
+func (r *MemoryProductRepo) GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]*Product, error) {
+	if err := checkContext(ctx, "getProductsByIDs", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := []*Product{}
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			products = append(products, &product)
+		}
+	}
+	return products, nil
+}
+
+// ListProductRelations fetches a product's curated related set in position
+// order. The product must exist.
+func (r *MemoryProductRepo) ListProductRelations(ctx context.Context, productID uuid.UUID) ([]ProductRelation, error) {
+	if err := checkContext(ctx, "listProductRelations", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.products[productID]; !ok {
+		return nil, repoError("listProductRelations", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, productID))
+	}
+	return append([]ProductRelation{}, r.relations[productID]...), nil
+}
+
+// SetProductRelations replaces a product's curated related set with
+// relations, in order. ProductID and Position are set on each relation.
+// The product and every related product must exist.
+func (r *MemoryProductRepo) SetProductRelations(ctx context.Context, productID uuid.UUID, relations []ProductRelation) error {
+	const op = "setProductRelations"
+	if err := checkContext(ctx, op, EntityProduct); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[productID]; !ok {
+		return repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, productID))
+	}
+	for i := range relations {
+		if _, ok := r.products[relations[i].RelatedProductID]; !ok {
+			return repoError(op, EntityProduct, fmt.Errorf("%w: related product id `%s`", ErrNotFound, relations[i].RelatedProductID))
+		}
+		relations[i].ProductID = productID
+		relations[i].Position = i
+	}
+
+	if len(relations) == 0 {
+		delete(r.relations, productID)
+		return nil
+	}
+	r.relations[productID] = append([]ProductRelation{}, relations...)
+	return nil
+}
+
+// CreateProduct stores a new product, generating an ID and stamping
+// CreatedAt with the current time when they are not set. A product without
+// a status is rejected.
+func (r *MemoryProductRepo) CreateProduct(ctx context.Context, product *Product) error {
+	if err := checkContext(ctx, "createProduct", EntityProduct); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(product)
+}
+
+// create stores a new product after setting its defaults. Callers must
+// hold the lock.
+func (r *MemoryProductRepo) create(product *Product) error {
+	if err := checkProductStatus(product); err != nil {
+		return err
+	}
+	setProductDefaults(product, r.now)
+	if _, ok := r.products[product.ID]; ok {
+		return repoError("createProduct", EntityProduct, fmt.Errorf("insert query failed: duplicate id `%s`", product.ID))
+	}
+
+	stored := *product
+	stored.Attributes = maps.Clone(product.Attributes)
+	stored.Metadata = maps.Clone(product.Metadata)
+	r.products[product.ID] = stored
+	return nil
+}
+
+// storeMetadata makes product's metadata empty rather than nil, as it
+// reads back from the database, and returns a copy to store
+func storeMetadata(product *Product) ProductMetadata {
+	if product.Metadata == nil {
+		product.Metadata = ProductMetadata{}
+	}
+	return maps.Clone(product.Metadata)
+}
+
+// UpdateProduct replaces an existing product. Status and attributes are
+// left unchanged, and copied into product; use UpdateProductStatus so
+// transitions are enforced and UpdateProductAttributes for attributes.
+func (r *MemoryProductRepo) UpdateProduct(ctx context.Context, product *Product) error {
+	if err := checkContext(ctx, "updateProduct", EntityProduct); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.products[product.ID]
+	if !ok {
+		return errNoRowsAffected("updateProduct", EntityProduct)
+	}
+	toUTC(&product.CreatedAt)
+	updated := *product
+	updated.Status = existing.Status
+	updated.Attributes = existing.Attributes
+	updated.Metadata = storeMetadata(product)
+	r.products[product.ID] = updated
+
+	product.Status = existing.Status
+	product.Attributes = maps.Clone(existing.Attributes)
+	return nil
+}
+
+// UpdateProductStatus moves a product to status if the transition is
+// allowed and returns the updated product. Setting the current status
+// again is a no-op.
+func (r *MemoryProductRepo) UpdateProductStatus(ctx context.Context, id uuid.UUID, status ProductStatus) (*Product, error) {
+	if err := checkContext(ctx, "updateProductStatus", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, repoError("updateProductStatus", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	if product.Status != status && !product.Status.CanTransitionTo(status) {
+		return nil, repoError("updateProductStatus", EntityProduct, &StatusTransitionError{From: product.Status, To: status})
+	}
+
+	product.Status = status
+	r.products[id] = product
+	return &product, nil
+}
+
+// UpdateProductAttributes replaces a product's attributes and returns the
+// updated product
+func (r *MemoryProductRepo) UpdateProductAttributes(ctx context.Context, id uuid.UUID, attributes ProductAttributes) (*Product, error) {
+	if err := checkContext(ctx, "updateProductAttributes", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, repoError("updateProductAttributes", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+
+	product.Attributes = maps.Clone(attributes)
+	if product.Attributes == nil {
+		product.Attributes = ProductAttributes{}
+	}
+	r.products[id] = product
+	return &product, nil
+}
+
+// PatchProduct changes the status and attributes set in patch, checking
+// the Precondition and status transition under the same lock
+func (r *MemoryProductRepo) PatchProduct(ctx context.Context, id uuid.UUID, patch ProductPatch) (*Product, error) {
+	const op = "patchProduct"
+	if err := checkContext(ctx, op, EntityProduct); err != nil {
+		return nil, err
+	}
+	if patch.IsEmpty() {
+		return nil, repoError(op, EntityProduct, ErrEmptyPatch)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	if err := patch.applyTo(op, &product); err != nil {
+		return nil, err
+	}
+	r.products[id] = product
+	return &product, nil
+}
+
+// DeleteProduct removes a product by its ID
+func (r *MemoryProductRepo) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	if err := checkContext(ctx, "deleteProduct", EntityProduct); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return errNoRowsAffected("deleteProduct", EntityProduct)
+	}
+	r.deleteRelations(id)
+	delete(r.products, id)
+	return nil
+}
+
+// DeleteProductReturning removes a product by its ID and returns the row as
+// it was before deletion
+func (r *MemoryProductRepo) DeleteProductReturning(ctx context.Context, id uuid.UUID) (*Product, error) {
+	if err := checkContext(ctx, "deleteProductReturning", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, repoError("deleteProductReturning", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	r.deleteRelations(id)
+	delete(r.products, id)
+	return &product, nil
+}
+
+// deleteRelations removes id's relations in both directions. Callers must
+// hold the lock.
+func (r *MemoryProductRepo) deleteRelations(id uuid.UUID) {
+	delete(r.relations, id)
+	for productID, relations := range r.relations {
+		kept := slices.DeleteFunc(relations, func(relation ProductRelation) bool {
+			return relation.RelatedProductID == id
+		})
+		if len(kept) == 0 {
+			delete(r.relations, productID)
+			continue
+		}
+		r.relations[productID] = kept
+	}
+}
+
+// sorted returns the products ordered by created_at, then id.
+// Callers must hold the lock.
+func (r *MemoryProductRepo) sorted() []Product {
+	products := make([]Product, 0, len(r.products))
+	for _, product := range r.products {
+		products = append(products, product)
+	}
+	slices.SortFunc(products, func(a, b Product) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return products
+}
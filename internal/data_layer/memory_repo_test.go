@@ -0,0 +1,49 @@
+package datalayer_test
+
+import (
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer/conformance"
+	"github.com/google/uuid"
+)
+
+func TestMemoryCategoryRepoConformance(t *testing.T) {
+	conformance.RunCategoryRepoTests(t, func(*testing.T) datalayer.CategoryRepoInterface {
+		return datalayer.NewMemoryCategoryRepo()
+	})
+}
+
+func TestMemoryProductRepoConformance(t *testing.T) {
+	conformance.RunProductRepoTests(t, func(*testing.T) (datalayer.ProductRepoInterface, uuid.UUID) {
+		return datalayer.NewMemoryProductRepo(), uuid.New()
+	})
+}
+
+func TestMemoryReservationRepoConformance(t *testing.T) {
+	conformance.RunReservationRepoTests(t, func(*testing.T) (datalayer.ReservationRepoInterface, datalayer.ProductRepoInterface, uuid.UUID) {
+		products := datalayer.NewMemoryProductRepo()
+		return datalayer.NewMemoryReservationRepo(products), products, uuid.New()
+	})
+}
+
+func TestMemoryInventoryRepoConformance(t *testing.T) {
+	conformance.RunInventoryRepoTests(t, func(*testing.T) (datalayer.InventoryRepoInterface, datalayer.ProductRepoInterface, uuid.UUID) {
+		products := datalayer.NewMemoryProductRepo()
+		return datalayer.NewMemoryInventoryRepo(products), products, uuid.New()
+	})
+}
+
+func TestMemoryPriceScheduleRepoConformance(t *testing.T) {
+	conformance.RunPriceScheduleRepoTests(t, func(*testing.T) (datalayer.PriceScheduleRepoInterface, datalayer.ProductRepoInterface, uuid.UUID) {
+		products := datalayer.NewMemoryProductRepo()
+		return datalayer.NewMemoryPriceScheduleRepo(products), products, uuid.New()
+	})
+}
+
+func TestMemoryAttributeDefinitionRepoConformance(t *testing.T) {
+	conformance.RunAttributeDefinitionRepoTests(t, func(*testing.T) (datalayer.AttributeDefinitionRepoInterface, datalayer.CategoryRepoInterface) {
+		categories := datalayer.NewMemoryCategoryRepo()
+		return datalayer.NewMemoryAttributeDefinitionRepo(categories), categories
+	})
+}
@@ -0,0 +1,154 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryReservationRepo is a map-backed ReservationRepoInterface that takes
+// stock from a MemoryProductRepo. It mirrors the SQL repo's semantics and
+// errors.
+type MemoryReservationRepo struct {
+	mu           sync.Mutex
+	reservations map[uuid.UUID]Reservation
+	products     *MemoryProductRepo
+	now          func() time.Time
+}
+
+// NewMemoryReservationRepo creates an empty in-memory reservation
+// repository drawing stock from products
+func NewMemoryReservationRepo(products *MemoryProductRepo) *MemoryReservationRepo {
+	return &MemoryReservationRepo{
+		reservations: map[uuid.UUID]Reservation{},
+		products:     products,
+		now:          time.Now,
+	}
+}
+
+// CreateReservation takes Quantity units of the product out of stock and
+// records the hold
+func (r *MemoryReservationRepo) CreateReservation(ctx context.Context, reservation *Reservation) error {
+	const op = "createReservation"
+	if err := checkContext(ctx, op, EntityReservation); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.products.mu.Lock()
+	defer r.products.mu.Unlock()
+
+	product, ok := r.products.products[reservation.ProductID]
+	if !ok {
+		return repoError(op, EntityReservation, fmt.Errorf("%w: product id `%s`", ErrNotFound, reservation.ProductID))
+	}
+	if product.Quantity < reservation.Quantity {
+		return repoError(op, EntityReservation, fmt.Errorf("%w: %d requested, %d available", ErrInsufficientStock, reservation.Quantity, product.Quantity))
+	}
+
+	if reservation.ID == uuid.Nil {
+		reservation.ID = uuid.New()
+	}
+	if _, ok := r.reservations[reservation.ID]; ok {
+		return repoError(op, EntityReservation, fmt.Errorf("insert query failed: duplicate id `%s`", reservation.ID))
+	}
+	reservation.Status = ReservationHeld
+	reservation.CreatedAt = r.now()
+	toUTC(&reservation.ExpiresAt, &reservation.CreatedAt)
+
+	product.Quantity -= reservation.Quantity
+	r.products.products[product.ID] = product
+	r.reservations[reservation.ID] = *reservation
+	return nil
+}
+
+// ReleaseReservation returns a held reservation's stock to the product
+func (r *MemoryReservationRepo) ReleaseReservation(ctx context.Context, id uuid.UUID) (*Reservation, error) {
+	const op = "releaseReservation"
+	if err := checkContext(ctx, op, EntityReservation); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, err := r.held(op, id)
+	if err != nil {
+		return nil, err
+	}
+	r.release(&reservation)
+	return &reservation, nil
+}
+
+// CommitReservation finalizes a held reservation so its stock stays removed
+func (r *MemoryReservationRepo) CommitReservation(ctx context.Context, id uuid.UUID) (*Reservation, error) {
+	const op = "commitReservation"
+	if err := checkContext(ctx, op, EntityReservation); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, err := r.held(op, id)
+	if err != nil {
+		return nil, err
+	}
+	if !reservation.ExpiresAt.After(r.now()) {
+		return nil, repoError(op, EntityReservation, fmt.Errorf("%w: id `%s`", ErrReservationExpired, id))
+	}
+
+	reservation.Status = ReservationCommitted
+	r.reservations[id] = reservation
+	return &reservation, nil
+}
+
+// ReleaseExpiredReservations releases every held reservation past its expiry
+func (r *MemoryReservationRepo) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	if err := checkContext(ctx, "releaseExpiredReservations", EntityReservation); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	released := 0
+	for _, reservation := range r.reservations {
+		if reservation.Status == ReservationHeld && !reservation.ExpiresAt.After(now) {
+			r.release(&reservation)
+			released++
+		}
+	}
+	return released, nil
+}
+
+// held returns a reservation that is still held. Callers must hold r.mu.
+func (r *MemoryReservationRepo) held(op string, id uuid.UUID) (Reservation, error) {
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return Reservation{}, repoError(op, EntityReservation, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+	}
+	if reservation.Status != ReservationHeld {
+		return Reservation{}, repoError(op, EntityReservation, fmt.Errorf("%w: id `%s` is %s", ErrReservationClosed, id, reservation.Status))
+	}
+	return reservation, nil
+}
+
+// release puts a reservation's stock back and marks it released. Callers
+// must hold r.mu.
+func (r *MemoryReservationRepo) release(reservation *Reservation) {
+	r.products.mu.Lock()
+	if product, ok := r.products.products[reservation.ProductID]; ok {
+		product.Quantity += reservation.Quantity
+		r.products.products[product.ID] = product
+	}
+	r.products.mu.Unlock()
+
+	reservation.Status = ReservationReleased
+	r.reservations[reservation.ID] = *reservation
+}
@@ -0,0 +1,41 @@
+package mocks_test
+
+import (
+	"context"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedMocks(t *testing.T) {
+	t.Run("should implement the repository interfaces", func(t *testing.T) {
+		assert.Implements(t, (*datalayer.CategoryRepoInterface)(nil), &mocks.CategoryRepoInterfaceMock{})
+		assert.Implements(t, (*datalayer.ProductRepoInterface)(nil), &mocks.ProductRepoInterfaceMock{})
+	})
+
+	t.Run("should record calls and delegate to the func field", func(t *testing.T) {
+		id := uuid.New()
+		repo := &mocks.ProductRepoInterfaceMock{
+			ProductExistsFunc: func(context.Context, uuid.UUID) (bool, error) {
+				return true, nil
+			},
+		}
+
+		exists, err := repo.ProductExists(context.Background(), id)
+
+		require.NoError(t, err)
+		assert.True(t, exists)
+		require.Len(t, repo.ProductExistsCalls(), 1)
+		assert.Equal(t, id, repo.ProductExistsCalls()[0].ID)
+	})
+
+	t.Run("should panic on a method without a func field", func(t *testing.T) {
+		repo := &mocks.CategoryRepoInterfaceMock{}
+
+		assert.Panics(t, func() { _, _ = repo.CategoryExists(context.Background(), uuid.New()) })
+	})
+}
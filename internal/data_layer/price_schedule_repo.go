@@ -0,0 +1,212 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrScheduleConflict = errors.New("a price change is already scheduled for that time")
+	ErrScheduleApplied  = errors.New("price schedule has already been applied")
+)
+
+// PriceSchedule changes a product's price to NewPrice once EffectiveAt has
+// passed. Applied is set when the scheduler has updated the product.
+type PriceSchedule struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	ProductID   uuid.UUID `db:"product_id" json:"productId"`
+	NewPrice    float64   `db:"new_price" json:"newPrice"`
+	EffectiveAt time.Time `db:"effective_at" json:"effectiveAt"`
+	Applied     bool      `db:"applied" json:"applied"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+}
+
+type PriceScheduleRepo struct {
+	db     *sqlx.DB
+	now    func() time.Time
+	limits limitRange
+}
+
+type PriceScheduleRepoInterface interface {
+	CreatePriceSchedule(ctx context.Context, schedule *PriceSchedule) error
+	ListPriceSchedules(ctx context.Context, productID uuid.UUID) ([]*PriceSchedule, error)
+	CancelPriceSchedule(ctx context.Context, id uuid.UUID) error
+	ListDuePriceSchedules(ctx context.Context, asOf time.Time, limit int) ([]*PriceSchedule, error)
+	ApplyPriceSchedule(ctx context.Context, id uuid.UUID) (*Product, error)
+}
+
+// NewPriceScheduleRepo creates a new repository instance
+func NewPriceScheduleRepo(db *sqlx.DB) PriceScheduleRepoInterface {
+	return &PriceScheduleRepo{db: db, now: time.Now, limits: mustLimitRange(minLimit, defaultLimit, maxLimit)}
+}
+
+// CreatePriceSchedule records a pending price change, failing with
+// ErrScheduleConflict if the product already has one at EffectiveAt. The
+// product row is locked so concurrent creates for it are serialized. ID,
+// Applied and CreatedAt are set on schedule.
+func (r *PriceScheduleRepo) CreatePriceSchedule(ctx context.Context, schedule *PriceSchedule) error {
+	const op = "createPriceSchedule"
+	const productQuery = `SELECT id FROM products WHERE id = $1 FOR UPDATE`
+	const conflictQuery = `SELECT EXISTS(SELECT 1 FROM price_schedules WHERE product_id = $1 AND effective_at = $2)`
+	const insertQuery = `
+		INSERT INTO price_schedules(id, product_id, new_price, effective_at, applied, created_at)
+		VALUES(:id, :product_id, :new_price, :effective_at, :applied, :created_at)`
+
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	schedule.EffectiveAt = schedule.EffectiveAt.UTC()
+	schedule.Applied = false
+	schedule.CreatedAt = r.now().UTC()
+
+	return withTx(ctx, r.db, op, EntityPriceSchedule, func(tx *sqlx.Tx) error {
+		var productID uuid.UUID
+		if err := tx.GetContext(ctx, &productID, productQuery, schedule.ProductID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: product id `%s`", ErrNotFound, schedule.ProductID))
+			}
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+		}
+
+		var conflict bool
+		if err := tx.GetContext(ctx, &conflict, conflictQuery, schedule.ProductID, schedule.EffectiveAt); err != nil {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+		}
+		if conflict {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: %s", ErrScheduleConflict, schedule.EffectiveAt.Format(time.RFC3339)))
+		}
+
+		result, err := tx.NamedExecContext(ctx, insertQuery, schedule)
+		if err != nil {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("insert query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityPriceSchedule)
+	})
+}
+
+// ListPriceSchedules fetches every schedule of a product, applied or not,
+// in effective_at order. The product must exist.
+func (r *PriceScheduleRepo) ListPriceSchedules(ctx context.Context, productID uuid.UUID) ([]*PriceSchedule, error) {
+	const op = "listPriceSchedules"
+	const productQuery = `SELECT id FROM products WHERE id = $1`
+	const selectQuery = `
+		SELECT id, product_id, new_price, effective_at, applied, created_at
+		FROM price_schedules
+		WHERE product_id = $1
+		ORDER BY effective_at ASC`
+
+	var id uuid.UUID
+	if err := r.db.GetContext(ctx, &id, productQuery, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("%w: product id `%s`", ErrNotFound, productID))
+		}
+		return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+	}
+
+	schedules := []*PriceSchedule{}
+	if err := r.db.SelectContext(ctx, &schedules, selectQuery, productID); err != nil {
+		return nil, repoError(op, EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+	}
+	for _, schedule := range schedules {
+		toUTC(&schedule.EffectiveAt, &schedule.CreatedAt)
+	}
+	return schedules, nil
+}
+
+// CancelPriceSchedule deletes a pending schedule. Applied schedules are
+// kept as history and fail with ErrScheduleApplied.
+func (r *PriceScheduleRepo) CancelPriceSchedule(ctx context.Context, id uuid.UUID) error {
+	const op = "cancelPriceSchedule"
+	const selectQuery = `SELECT applied FROM price_schedules WHERE id = $1 FOR UPDATE`
+	const deleteQuery = `DELETE FROM price_schedules WHERE id = $1`
+
+	return withTx(ctx, r.db, op, EntityPriceSchedule, func(tx *sqlx.Tx) error {
+		var applied bool
+		if err := tx.GetContext(ctx, &applied, selectQuery, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+		}
+		if applied {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrScheduleApplied, id))
+		}
+
+		result, err := tx.ExecContext(ctx, deleteQuery, id)
+		if err != nil {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("delete query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityPriceSchedule)
+	})
+}
+
+// ListDuePriceSchedules fetches up to limit pending schedules effective at
+// or before asOf, oldest first
+func (r *PriceScheduleRepo) ListDuePriceSchedules(ctx context.Context, asOf time.Time, limit int) ([]*PriceSchedule, error) {
+	limit = r.limits.clamp(limit)
+	query, args := NewQueryBuilder(`SELECT id, product_id, new_price, effective_at, applied, created_at FROM price_schedules`).
+		Where("applied = ?", false).
+		Where("effective_at <= ?", asOf.UTC()).
+		OrderBy("effective_at ASC").
+		Limit(limit).
+		Build()
+
+	schedules := []*PriceSchedule{}
+	if err := r.db.SelectContext(ctx, &schedules, query, args...); err != nil {
+		return nil, repoError("listDuePriceSchedules", EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+	}
+	for _, schedule := range schedules {
+		toUTC(&schedule.EffectiveAt, &schedule.CreatedAt)
+	}
+	return schedules, nil
+}
+
+// ApplyPriceSchedule sets the price of a pending schedule's product and
+// marks the schedule applied in one transaction, returning the product as
+// stored afterwards. Only the price column is written, so stock changes
+// made meanwhile are kept. A schedule that is gone, such as one whose
+// product was deleted, fails with ErrNotFound and an applied one with
+// ErrScheduleApplied.
+func (r *PriceScheduleRepo) ApplyPriceSchedule(ctx context.Context, id uuid.UUID) (*Product, error) {
+	const op = "applyPriceSchedule"
+	const selectQuery = `SELECT id, product_id, new_price, effective_at, applied, created_at FROM price_schedules WHERE id = $1 FOR UPDATE`
+	const priceQuery = `UPDATE products SET price = $1 WHERE id = $2 RETURNING ` + productColumns
+	const appliedQuery = `UPDATE price_schedules SET applied = true WHERE id = $1`
+
+	var product Product
+	err := withTx(ctx, r.db, op, EntityPriceSchedule, func(tx *sqlx.Tx) error {
+		var schedule PriceSchedule
+		if err := tx.GetContext(ctx, &schedule, selectQuery, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("select query failed: %w", err))
+		}
+		if schedule.Applied {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("%w: id `%s`", ErrScheduleApplied, id))
+		}
+
+		if err := tx.GetContext(ctx, &product, priceQuery, schedule.NewPrice, schedule.ProductID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, schedule.ProductID))
+			}
+			return repoError(op, EntityProduct, fmt.Errorf("update query failed: %w", err))
+		}
+		result, err := tx.ExecContext(ctx, appliedQuery, id)
+		if err != nil {
+			return repoError(op, EntityPriceSchedule, fmt.Errorf("update query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityPriceSchedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	toUTC(&product.CreatedAt)
+	return &product, nil
+}
@@ -0,0 +1,233 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchedule = PriceSchedule{
+	ID:          uuid.MustParse("0b6c2a4e-7d1f-4f3a-9e58-3c1d2b7a8e90"),
+	ProductID:   testProductOne.ID,
+	NewPrice:    12.5,
+	EffectiveAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	CreatedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var scheduleColumns = []string{"id", "product_id", "new_price", "effective_at", "applied", "created_at"}
+
+func newTestPriceScheduleRepo(t *testing.T) (*PriceScheduleRepo, sqlmock.Sqlmock) {
+	mockDB, mock, _ := sqlmock.New()
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	now := func() time.Time { return testSchedule.CreatedAt }
+	return &PriceScheduleRepo{db: db, now: now, limits: mustLimitRange(minLimit, defaultLimit, maxLimit)}, mock
+}
+
+func TestCreatePriceSchedule(t *testing.T) {
+	ctx := context.Background()
+	productQuery := regexp.QuoteMeta(`SELECT id FROM products WHERE id = $1 FOR UPDATE`)
+	conflictQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM price_schedules WHERE product_id = $1 AND effective_at = $2)`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO price_schedules(id, product_id, new_price, effective_at, applied, created_at)`)
+
+	t.Run("should lock the product and insert the schedule", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testProductOne.ID))
+		mock.ExpectQuery(conflictQuery).WithArgs(testProductOne.ID, testSchedule.EffectiveAt).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(insertQuery).
+			WithArgs(testSchedule.ID, testProductOne.ID, 12.5, testSchedule.EffectiveAt, false, testSchedule.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		schedule := &PriceSchedule{ID: testSchedule.ID, ProductID: testProductOne.ID, NewPrice: 12.5, EffectiveAt: testSchedule.EffectiveAt}
+		err := repo.CreatePriceSchedule(ctx, schedule)
+		assert.NoError(t, err)
+		assert.Equal(t, &testSchedule, schedule)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if a schedule exists for the same instant", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testProductOne.ID))
+		mock.ExpectQuery(conflictQuery).WithArgs(testProductOne.ID, testSchedule.EffectiveAt).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		err := repo.CreatePriceSchedule(ctx, &PriceSchedule{ProductID: testProductOne.ID, NewPrice: 12.5, EffectiveAt: testSchedule.EffectiveAt})
+		assert.True(t, errors.Is(err, ErrScheduleConflict))
+		assert.Equal(t, "createPriceSchedule: a price change is already scheduled for that time: 2024-02-01T00:00:00Z", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrNotFound if the product does not exist", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := repo.CreatePriceSchedule(ctx, &PriceSchedule{ProductID: testProductOne.ID, NewPrice: 12.5, EffectiveAt: testSchedule.EffectiveAt})
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListPriceSchedules(t *testing.T) {
+	ctx := context.Background()
+	productQuery := regexp.QuoteMeta(`SELECT id FROM products WHERE id = $1`)
+	selectQuery := regexp.QuoteMeta(`SELECT id, product_id, new_price, effective_at, applied, created_at`)
+
+	t.Run("should list the product's schedules", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testProductOne.ID))
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).
+				AddRow(testSchedule.ID, testSchedule.ProductID, testSchedule.NewPrice, testSchedule.EffectiveAt, false, testSchedule.CreatedAt))
+
+		schedules, err := repo.ListPriceSchedules(ctx, testProductOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []*PriceSchedule{&testSchedule}, schedules)
+	})
+
+	t.Run("should return ErrNotFound if the product does not exist", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		schedules, err := repo.ListPriceSchedules(ctx, testProductOne.ID)
+		assert.Nil(t, schedules)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestCancelPriceSchedule(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(`SELECT applied FROM price_schedules WHERE id = $1 FOR UPDATE`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM price_schedules WHERE id = $1`)
+
+	t.Run("should delete a pending schedule", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testSchedule.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"applied"}).AddRow(false))
+		mock.ExpectExec(deleteQuery).WithArgs(testSchedule.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.CancelPriceSchedule(ctx, testSchedule.ID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to delete an applied schedule", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testSchedule.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"applied"}).AddRow(true))
+		mock.ExpectRollback()
+
+		err := repo.CancelPriceSchedule(ctx, testSchedule.ID)
+		assert.True(t, errors.Is(err, ErrScheduleApplied))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListDuePriceSchedules(t *testing.T) {
+	ctx := context.Background()
+	asOf := testSchedule.EffectiveAt
+
+	t.Run("should select pending schedules effective by asOf", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		const query = `SELECT id, product_id, new_price, effective_at, applied, created_at FROM price_schedules ` +
+			`WHERE applied = $1 AND effective_at <= $2 ORDER BY effective_at ASC LIMIT $3`
+		mock.ExpectQuery("^"+regexp.QuoteMeta(query)+"$").
+			WithArgs(false, asOf, 50).
+			WillReturnRows(sqlmock.NewRows(scheduleColumns).
+				AddRow(testSchedule.ID, testSchedule.ProductID, testSchedule.NewPrice, testSchedule.EffectiveAt, false, testSchedule.CreatedAt))
+
+		schedules, err := repo.ListDuePriceSchedules(ctx, asOf, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, []*PriceSchedule{&testSchedule}, schedules)
+	})
+
+	t.Run("should return error if select query fails", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectQuery("SELECT").WillReturnError(errors.New("query error"))
+
+		schedules, err := repo.ListDuePriceSchedules(ctx, asOf, 50)
+		assert.Nil(t, schedules)
+		assert.Equal(t, "listDuePriceSchedules: select query failed: query error", err.Error())
+	})
+}
+
+func TestApplyPriceSchedule(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(`SELECT id, product_id, new_price, effective_at, applied, created_at FROM price_schedules WHERE id = $1 FOR UPDATE`)
+	priceQuery := regexp.QuoteMeta(`UPDATE products SET price = $1 WHERE id = $2 RETURNING ` + productColumns)
+	appliedQuery := regexp.QuoteMeta(`UPDATE price_schedules SET applied = true WHERE id = $1`)
+	scheduleRow := func(applied bool) *sqlmock.Rows {
+		return sqlmock.NewRows(scheduleColumns).
+			AddRow(testSchedule.ID, testSchedule.ProductID, testSchedule.NewPrice, testSchedule.EffectiveAt, applied, testSchedule.CreatedAt)
+	}
+
+	t.Run("should set only the price and mark the schedule applied in one transaction", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		stored := testProductOne
+		stored.Price = testSchedule.NewPrice
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testSchedule.ID).WillReturnRows(scheduleRow(false))
+		mock.ExpectQuery(priceQuery).WithArgs(testSchedule.NewPrice, testSchedule.ProductID).WillReturnRows(productRow(stored))
+		mock.ExpectExec(appliedQuery).WithArgs(testSchedule.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		product, err := repo.ApplyPriceSchedule(ctx, testSchedule.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &stored, product)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to apply a schedule twice", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testSchedule.ID).WillReturnRows(scheduleRow(true))
+		mock.ExpectRollback()
+
+		product, err := repo.ApplyPriceSchedule(ctx, testSchedule.ID)
+		assert.Nil(t, product)
+		assert.True(t, errors.Is(err, ErrScheduleApplied))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return ErrNotFound for a missing schedule", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testSchedule.ID).WillReturnRows(sqlmock.NewRows(scheduleColumns))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyPriceSchedule(ctx, testSchedule.ID)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if the price update fails", func(t *testing.T) {
+		repo, mock := newTestPriceScheduleRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testSchedule.ID).WillReturnRows(scheduleRow(false))
+		mock.ExpectQuery(priceQuery).WillReturnError(errors.New("query error"))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyPriceSchedule(ctx, testSchedule.ID)
+		assert.EqualError(t, err, "applyPriceSchedule: update query failed: query error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,59 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ProductAttributes are a product's typed attribute values, keyed by the
+// name of an AttributeDefinition of its category. It is stored as a JSONB
+// object and is never nil once read back.
+type ProductAttributes map[string]any
+
+// Value encodes the attributes as a JSON object for the JSONB column
+func (a ProductAttributes) Value() (driver.Value, error) {
+	return marshalJSONObject(a)
+}
+
+// Scan decodes the JSONB column into the attributes
+func (a *ProductAttributes) Scan(src any) error {
+	attributes, err := scanJSONObject(src)
+	if err != nil {
+		return fmt.Errorf("scan product attributes: %w", err)
+	}
+	*a = attributes
+	return nil
+}
+
+// MarshalJSON encodes nil attributes as an empty object
+func (a ProductAttributes) MarshalJSON() ([]byte, error) {
+	return marshalJSONObject(a)
+}
+
+// UpdateProductAttributes replaces a product's attributes and returns the
+// updated product
+func (r *ProductRepo) UpdateProductAttributes(ctx context.Context, id uuid.UUID, attributes ProductAttributes) (*Product, error) {
+	const op = "updateProductAttributes"
+	const query = `
+		UPDATE products SET attributes = $1 WHERE id = $2
+		RETURNING id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at`
+
+	if attributes == nil {
+		attributes = ProductAttributes{}
+	}
+
+	var product Product
+	if err := r.db.GetContext(ctx, &product, query, attributes, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+		}
+		return nil, repoError(op, EntityProduct, fmt.Errorf("update query failed: %w", err))
+	}
+	toUTC(&product.CreatedAt)
+	return &product, nil
+}
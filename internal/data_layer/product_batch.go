@@ -0,0 +1,218 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// BatchOp is the write one item of a product batch makes
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchOps lists the batch ops in the order they are documented
+var BatchOps = []BatchOp{BatchCreate, BatchUpdate, BatchDelete}
+
+// Valid reports whether o is a known batch op
+func (o BatchOp) Valid() bool {
+	return slices.Contains(BatchOps, o)
+}
+
+// ProductBatchItem is one write of a product batch. Create stores Product
+// like CreateProduct. Update replaces its name, description, image,
+// category, price, quantity and weight, leaving status, attributes and
+// created_at alone, and fills Product in as stored. Delete reads
+// Product.ID and fills in the status the product was deleted with.
+type ProductBatchItem struct {
+	Op      BatchOp
+	Product *Product
+}
+
+// ProductBatchError reports the item a batch failed on. Nothing in the
+// batch was applied.
+type ProductBatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *ProductBatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ProductBatchError) Unwrap() error {
+	return e.Err
+}
+
+// insertProductQuery inserts every column of a product
+const insertProductQuery = `
+	INSERT INTO products(id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at)
+	VALUES(:id, :name, :description, :image_url, :category_id, :price, :currency, :quantity, :weight, :status, :attributes, :metadata, :created_at)`
+
+// batchUpdateProductQuery replaces the fields a batch update may change and
+// returns the row as stored
+const batchUpdateProductQuery = `
+	UPDATE products
+	SET name = $2, description = $3, image_url = $4, category_id = $5, price = $6, currency = $7, quantity = $8, weight = $9,
+		metadata = $10
+	WHERE id = $1
+	RETURNING id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at`
+
+// ApplyProductBatch applies items in order inside one transaction. Unless
+// partial, the first failing item rolls the whole batch back and is
+// reported as a ProductBatchError. With partial, each item runs under its
+// own savepoint so a failure only undoes that item; the returned slice
+// holds each item's error, nil for the items applied.
+func (r *ProductRepo) ApplyProductBatch(ctx context.Context, items []ProductBatchItem, partial bool) ([]error, error) {
+	const op = "applyProductBatch"
+
+	itemErrs := make([]error, len(items))
+	err := withTx(ctx, r.db, op, EntityProduct, func(tx *sqlx.Tx) error {
+		for i, item := range items {
+			if !partial {
+				if err := r.applyBatchItem(ctx, tx, item); err != nil {
+					return repoError(op, EntityProduct, &ProductBatchError{Index: i, Err: err})
+				}
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_item`); err != nil {
+				return repoError(op, EntityProduct, fmt.Errorf("savepoint failed: %w", err))
+			}
+			if err := r.applyBatchItem(ctx, tx, item); err != nil {
+				itemErrs[i] = err
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_item`); err != nil {
+					return repoError(op, EntityProduct, fmt.Errorf("rollback to savepoint failed: %w", err))
+				}
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_item`); err != nil {
+				return repoError(op, EntityProduct, fmt.Errorf("release savepoint failed: %w", err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return itemErrs, nil
+}
+
+// applyBatchItem makes one item's write within tx
+func (r *ProductRepo) applyBatchItem(ctx context.Context, tx *sqlx.Tx, item ProductBatchItem) error {
+	product := item.Product
+	switch item.Op {
+	case BatchCreate:
+		if err := checkProductStatus(product); err != nil {
+			return err
+		}
+		setProductDefaults(product, r.now)
+		result, err := tx.NamedExecContext(ctx, insertProductQuery, product)
+		if err != nil {
+			return repoError("createProduct", EntityProduct, fmt.Errorf("insert query failed: %w", err))
+		}
+		return checkRowsAffected(result, "createProduct", EntityProduct)
+	case BatchUpdate:
+		err := tx.GetContext(ctx, product, batchUpdateProductQuery, product.ID, product.Name, product.Description,
+			product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Metadata)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repoError("updateProduct", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, product.ID))
+		}
+		if err != nil {
+			return repoError("updateProduct", EntityProduct, fmt.Errorf("update query failed: %w", err))
+		}
+		toUTC(&product.CreatedAt)
+		return nil
+	case BatchDelete:
+		if _, err := tx.ExecContext(ctx, deleteRelationsQuery, product.ID); err != nil {
+			return repoError("deleteProduct", EntityProduct, fmt.Errorf("delete relations query failed: %w", err))
+		}
+		err := tx.GetContext(ctx, &product.Status, `DELETE FROM products WHERE id = $1 RETURNING status`, product.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errNoRowsAffected("deleteProduct", EntityProduct)
+		}
+		if err != nil {
+			return repoError("deleteProduct", EntityProduct, fmt.Errorf("delete query failed: %w", err))
+		}
+		return nil
+	default:
+		return repoError("applyProductBatch", EntityProduct, fmt.Errorf("unknown op `%s`", item.Op))
+	}
+}
+
+// ApplyProductBatch applies items in order, atomically unless partial. See
+// ProductRepo.ApplyProductBatch.
+func (r *MemoryProductRepo) ApplyProductBatch(ctx context.Context, items []ProductBatchItem, partial bool) ([]error, error) {
+	const op = "applyProductBatch"
+	if err := checkContext(ctx, op, EntityProduct); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// an all-or-nothing batch works on copies that replace the originals
+	// only once every item applied
+	products, relations := r.products, r.relations
+	if !partial {
+		r.products = maps.Clone(products)
+		r.relations = make(map[uuid.UUID][]ProductRelation, len(relations))
+		for id, related := range relations {
+			r.relations[id] = slices.Clone(related)
+		}
+	}
+
+	itemErrs := make([]error, len(items))
+	for i, item := range items {
+		if err := r.applyBatchItem(item); err != nil {
+			if !partial {
+				r.products, r.relations = products, relations
+				return nil, repoError(op, EntityProduct, &ProductBatchError{Index: i, Err: err})
+			}
+			itemErrs[i] = err
+		}
+	}
+	return itemErrs, nil
+}
+
+// applyBatchItem makes one item's write. Callers must hold the lock.
+func (r *MemoryProductRepo) applyBatchItem(item ProductBatchItem) error {
+	product := item.Product
+	switch item.Op {
+	case BatchCreate:
+		return r.create(product)
+	case BatchUpdate:
+		existing, ok := r.products[product.ID]
+		if !ok {
+			return errNoRowsAffected("updateProduct", EntityProduct)
+		}
+		product.Status = existing.Status
+		product.CreatedAt = existing.CreatedAt
+		stored := *product
+		stored.Attributes = existing.Attributes
+		stored.Metadata = storeMetadata(product)
+		r.products[product.ID] = stored
+		product.Attributes = maps.Clone(existing.Attributes)
+	case BatchDelete:
+		existing, ok := r.products[product.ID]
+		if !ok {
+			return errNoRowsAffected("deleteProduct", EntityProduct)
+		}
+		product.Status = existing.Status
+		r.deleteRelations(product.ID)
+		delete(r.products, product.ID)
+	default:
+		return repoError("applyProductBatch", EntityProduct, fmt.Errorf("unknown op `%s`", item.Op))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProductBatch(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	insertQuery := regexp.QuoteMeta(
+		`INSERT INTO products(id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	)
+	updateQuery := regexp.QuoteMeta(`UPDATE products SET name = $2, description = $3, image_url = $4, category_id = $5, price = $6, currency = $7, quantity = $8, weight = $9, metadata = $10 WHERE id = $1`)
+	relationsQuery := regexp.QuoteMeta(`DELETE FROM product_relations WHERE product_id = $1 OR related_product_id = $1`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM products WHERE id = $1 RETURNING status`)
+	columns := []string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+
+	expectCreate := func(product Product) {
+		mock.ExpectExec(insertQuery).
+			WithArgs(product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), product.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	replacement := func() *Product {
+		return &Product{ID: testProductOne.ID, Name: "Renamed", CategoryID: testProductOne.CategoryID, Price: 10, Quantity: 5}
+	}
+	expectUpdate := func() {
+		p := replacement()
+		mock.ExpectQuery(updateQuery).
+			WithArgs(p.ID, p.Name, p.Description, p.ImageURL, p.CategoryID, p.Price, p.Currency, p.Quantity, p.Weight, productMetadataJSON(p.Metadata)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(p.ID, p.Name, p.Description, p.ImageURL, p.CategoryID, p.Price, p.Currency, p.Quantity, p.Weight, ProductActive, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt))
+	}
+
+	t.Run("should apply a mixed batch in one transaction", func(t *testing.T) {
+		created := testProductTwo
+		updated := replacement()
+
+		mock.ExpectBegin()
+		expectCreate(created)
+		expectUpdate()
+		mock.ExpectExec(relationsQuery).WithArgs(testProductTwo.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(deleteQuery).WithArgs(testProductTwo.ID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(testProductTwo.Status))
+		mock.ExpectCommit()
+
+		deleted := &Product{ID: testProductTwo.ID}
+		itemErrs, err := repo.ApplyProductBatch(ctx, []ProductBatchItem{
+			{Op: BatchCreate, Product: &created},
+			{Op: BatchUpdate, Product: updated},
+			{Op: BatchDelete, Product: deleted},
+		}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []error{nil, nil, nil}, itemErrs)
+		assert.Equal(t, testProductTwo.Status, deleted.Status)
+		assert.Equal(t, ProductActive, updated.Status)
+		assert.Equal(t, testProductOne.Attributes, updated.Attributes)
+		assert.Equal(t, testProductOne.CreatedAt, updated.CreatedAt)
+	})
+
+	t.Run("should roll back the batch when an item fails", func(t *testing.T) {
+		created := testProductTwo
+
+		mock.ExpectBegin()
+		expectCreate(created)
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(deleteQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+		mock.ExpectRollback()
+
+		itemErrs, err := repo.ApplyProductBatch(ctx, []ProductBatchItem{
+			{Op: BatchCreate, Product: &created},
+			{Op: BatchDelete, Product: &Product{ID: testProductOne.ID}},
+			{Op: BatchDelete, Product: &Product{ID: testProductTwo.ID}},
+		}, false)
+		assert.Nil(t, itemErrs)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		var batchErr *ProductBatchError
+		assert.True(t, errors.As(err, &batchErr))
+		assert.Equal(t, 1, batchErr.Index)
+		assert.Equal(t, "applyProductBatch: item 1: deleteProduct: no rows affected: not found", err.Error())
+	})
+
+	t.Run("should return not found for a missing product to update", func(t *testing.T) {
+		p := replacement()
+		mock.ExpectBegin()
+		mock.ExpectQuery(updateQuery).
+			WithArgs(p.ID, p.Name, p.Description, p.ImageURL, p.CategoryID, p.Price, p.Currency, p.Quantity, p.Weight, productMetadataJSON(p.Metadata)).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyProductBatch(ctx, []ProductBatchItem{{Op: BatchUpdate, Product: p}}, false)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("should undo only the failed item of a partial batch", func(t *testing.T) {
+		created := testProductTwo
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT batch_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(deleteQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+		mock.ExpectExec(regexp.QuoteMeta(`ROLLBACK TO SAVEPOINT batch_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT batch_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		expectCreate(created)
+		mock.ExpectExec(regexp.QuoteMeta(`RELEASE SAVEPOINT batch_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		itemErrs, err := repo.ApplyProductBatch(ctx, []ProductBatchItem{
+			{Op: BatchDelete, Product: &Product{ID: testProductOne.ID}},
+			{Op: BatchCreate, Product: &created},
+		}, true)
+		assert.NoError(t, err)
+		assert.Len(t, itemErrs, 2)
+		assert.True(t, errors.Is(itemErrs[0], ErrNotFound))
+		assert.NoError(t, itemErrs[1])
+	})
+
+	t.Run("should roll back a partial batch if a savepoint fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT batch_item`)).WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyProductBatch(ctx, []ProductBatchItem{{Op: BatchDelete, Product: &Product{ID: testProductOne.ID}}}, true)
+		assert.EqualError(t, err, "applyProductBatch: savepoint failed: database error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,153 @@
+package datalayer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MinDuplicateGroupSize is the smallest group ListDuplicateGroups returns
+const MinDuplicateGroupSize = 2
+
+// DuplicateGroupKey identifies a group of likely duplicate products: the
+// category and the normalized name they share. Groups are listed in key
+// order and the key of the last group is the cursor for the next page.
+type DuplicateGroupKey struct {
+	CategoryID     uuid.UUID `json:"categoryId"`
+	NormalizedName string    `json:"normalizedName"`
+}
+
+// DuplicateGroup is a set of products of one category whose names differ
+// only in case and white space, in created_at order
+type DuplicateGroup struct {
+	DuplicateGroupKey
+	Products []*Product `json:"products"`
+}
+
+// DuplicateFilter narrows ListDuplicateGroups. Groups smaller than
+// MinGroupSize are skipped. Drafts are only grouped with IncludeDrafts.
+type DuplicateFilter struct {
+	MinGroupSize  int
+	IncludeDrafts bool
+}
+
+// NormalizeProductName lowercases name, trims it and collapses every run of
+// white space to one space. It matches the normalize_product_name SQL
+// function.
+func NormalizeProductName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// duplicateRow is a product joined with the normalized name of its group
+type duplicateRow struct {
+	NormalizedName string `db:"normalized_name"`
+	Product
+}
+
+// ListDuplicateGroups fetches up to limit groups of products sharing a
+// category and normalized name, in key order after the cursor. The zero key
+// starts from the first group.
+func (r *ProductRepo) ListDuplicateGroups(
+	ctx context.Context,
+	filter DuplicateFilter,
+	after DuplicateGroupKey, // pagination cursor
+	limit int,
+) ([]*DuplicateGroup, error) {
+	limit = r.limits.clamp(limit)
+	minSize := max(filter.MinGroupSize, MinDuplicateGroupSize)
+
+	// names compare bytewise, like the in-memory repo and the index
+	const query = `
+		WITH groups AS (
+			SELECT category_id, normalize_product_name(name) COLLATE "C" AS normalized_name
+			FROM products
+			WHERE (category_id, normalize_product_name(name) COLLATE "C") > ($1, $2)
+				AND ($3 OR status <> $4)
+			GROUP BY 1, 2
+			HAVING COUNT(*) >= $5
+			ORDER BY 1, 2
+			LIMIT $6
+		)
+		SELECT g.normalized_name, p.id, p.name, p.description, p.image_url, p.category_id, p.price, p.currency,
+			p.quantity, p.weight, p.status, p.attributes, p.metadata, p.created_at
+		FROM groups g
+		JOIN products p
+			ON p.category_id = g.category_id AND normalize_product_name(p.name) COLLATE "C" = g.normalized_name
+		WHERE $3 OR p.status <> $4
+		ORDER BY g.category_id, g.normalized_name, p.created_at, p.id`
+
+	args := []any{after.CategoryID, after.NormalizedName, filter.IncludeDrafts, ProductDraft, minSize, limit}
+
+	var rows []duplicateRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, repoError("listDuplicateGroups", EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+
+	groups := []*DuplicateGroup{}
+	for _, row := range rows {
+		key := DuplicateGroupKey{CategoryID: row.CategoryID, NormalizedName: row.NormalizedName}
+		if len(groups) == 0 || groups[len(groups)-1].DuplicateGroupKey != key {
+			groups = append(groups, &DuplicateGroup{DuplicateGroupKey: key})
+		}
+		product := row.Product
+		toUTC(&product.CreatedAt)
+		groups[len(groups)-1].Products = append(groups[len(groups)-1].Products, &product)
+	}
+	return groups, nil
+}
+
+// ListDuplicateGroups fetches up to limit groups of products sharing a
+// category and normalized name, in key order after the cursor
+func (r *MemoryProductRepo) ListDuplicateGroups(
+	ctx context.Context,
+	filter DuplicateFilter,
+	after DuplicateGroupKey, // pagination cursor
+	limit int,
+) ([]*DuplicateGroup, error) {
+	if err := checkContext(ctx, "listDuplicateGroups", EntityProduct); err != nil {
+		return nil, err
+	}
+
+	limit = r.limits.clamp(limit)
+	minSize := max(filter.MinGroupSize, MinDuplicateGroupSize)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := map[DuplicateGroupKey][]*Product{}
+	for _, product := range r.sorted() {
+		if product.Status == ProductDraft && !filter.IncludeDrafts {
+			continue
+		}
+		key := DuplicateGroupKey{CategoryID: product.CategoryID, NormalizedName: NormalizeProductName(product.Name)}
+		if compareGroupKeys(key, after) > 0 {
+			members[key] = append(members[key], &product)
+		}
+	}
+
+	groups := []*DuplicateGroup{}
+	for key, products := range members {
+		if len(products) >= minSize {
+			groups = append(groups, &DuplicateGroup{DuplicateGroupKey: key, Products: products})
+		}
+	}
+	slices.SortFunc(groups, func(a, b *DuplicateGroup) int {
+		return compareGroupKeys(a.DuplicateGroupKey, b.DuplicateGroupKey)
+	})
+	if len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups, nil
+}
+
+// compareGroupKeys orders keys like the SQL row comparison: by category id,
+// then by normalized name
+func compareGroupKeys(a, b DuplicateGroupKey) int {
+	if c := strings.Compare(a.CategoryID.String(), b.CategoryID.String()); c != 0 {
+		return c
+	}
+	return strings.Compare(a.NormalizedName, b.NormalizedName)
+}
@@ -0,0 +1,115 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeProductName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Desk Lamp", want: "desk lamp"},
+		{name: "  desk \t\n LAMP  ", want: "desk lamp"},
+		{name: "Desk\u00a0\u2003Lamp", want: "desk lamp"},
+		{name: "Desk\u3000Lamp ", want: "desk lamp"},
+		{name: "Desk\u200bLamp", want: "desk\u200blamp"},
+		{name: "ÜNÏCODE Shelf", want: "ünïcode shelf"},
+		{name: "Café", want: "café"},
+		{name: " \u00a0 ", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NormalizeProductName(tt.name), "%q", tt.name)
+	}
+}
+
+func TestListDuplicateGroups(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	selectQuery := regexp.QuoteMeta(`HAVING COUNT(*) >= $5`)
+	columns := []string{"normalized_name", "id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+	addRow := func(rows *sqlmock.Rows, normalized string, product Product) *sqlmock.Rows {
+		return rows.AddRow(normalized, product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, productAttributesJSON(product.Attributes), productMetadataJSON(product.Metadata), product.CreatedAt)
+	}
+
+	t.Run("should split joined rows into groups", func(t *testing.T) {
+		shouting := testProductOne
+		shouting.ID = testProductTwo.ID
+		shouting.Name = "TEST  PRODUCT A"
+		other := testProductTwo
+		otherDuplicate := testProductTwo
+		otherDuplicate.ID = testProductOne.ID
+
+		rows := sqlmock.NewRows(columns)
+		addRow(rows, "test product a", testProductOne)
+		addRow(rows, "test product a", shouting)
+		addRow(rows, "test product b", other)
+		addRow(rows, "test product b", otherDuplicate)
+
+		after := DuplicateGroupKey{CategoryID: testProductOne.CategoryID, NormalizedName: "a"}
+		mock.ExpectQuery(selectQuery).WithArgs(after.CategoryID, after.NormalizedName, false, ProductDraft, 3, 10).WillReturnRows(rows)
+		groups, err := repo.ListDuplicateGroups(ctx, DuplicateFilter{MinGroupSize: 3}, after, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*DuplicateGroup{
+			{
+				DuplicateGroupKey: DuplicateGroupKey{CategoryID: testProductOne.CategoryID, NormalizedName: "test product a"},
+				Products:          []*Product{&testProductOne, &shouting},
+			},
+			{
+				DuplicateGroupKey: DuplicateGroupKey{CategoryID: testProductTwo.CategoryID, NormalizedName: "test product b"},
+				Products:          []*Product{&other, &otherDuplicate},
+			},
+		}, groups)
+	})
+
+	t.Run("should return created_at in UTC", func(t *testing.T) {
+		stored := testProductOne
+		stored.CreatedAt = testProductOne.CreatedAt.In(time.FixedZone("IST", 5*60*60+30*60))
+		duplicate := stored
+		duplicate.ID = testProductTwo.ID
+		rows := sqlmock.NewRows(columns)
+		addRow(rows, "test product a", stored)
+		addRow(rows, "test product a", duplicate)
+
+		mock.ExpectQuery(selectQuery).WillReturnRows(rows)
+		groups, err := repo.ListDuplicateGroups(ctx, DuplicateFilter{}, DuplicateGroupKey{}, 10)
+
+		assert.NoError(t, err)
+		for _, product := range groups[0].Products {
+			assert.Equal(t, time.UTC, product.CreatedAt.Location())
+			assert.Equal(t, testProductOne.CreatedAt, product.CreatedAt)
+		}
+	})
+
+	t.Run("should raise a group size below two to two", func(t *testing.T) {
+		mock.ExpectQuery(selectQuery).WithArgs(sqlmock.AnyArg(), "", true, ProductDraft, 2, 10).WillReturnRows(sqlmock.NewRows(columns))
+		groups, err := repo.ListDuplicateGroups(ctx, DuplicateFilter{MinGroupSize: 1, IncludeDrafts: true}, DuplicateGroupKey{}, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*DuplicateGroup{}, groups)
+	})
+
+	t.Run("should return error if select query fails", func(t *testing.T) {
+		mock.ExpectQuery(selectQuery).WillReturnError(errors.New("query error"))
+		groups, err := repo.ListDuplicateGroups(ctx, DuplicateFilter{}, DuplicateGroupKey{}, 10)
+
+		assert.Nil(t, groups)
+		assert.EqualError(t, err, "listDuplicateGroups: select query failed: query error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
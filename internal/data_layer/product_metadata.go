@@ -0,0 +1,35 @@
+package datalayer
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ProductMetadata is free-form string data on a product, such as its
+// brand or color, for what has no column of its own. It is stored as a
+// JSONB object and is never nil once read back.
+type ProductMetadata map[string]string
+
+// Value encodes the metadata as a JSON object for the JSONB column
+func (m ProductMetadata) Value() (driver.Value, error) {
+	return m.MarshalJSON()
+}
+
+// Scan decodes the JSONB column into the metadata. NULL reads as empty.
+func (m *ProductMetadata) Scan(src any) error {
+	metadata := ProductMetadata{}
+	if err := scanJSONColumn(src, (*map[string]string)(&metadata)); err != nil {
+		return fmt.Errorf("scan product metadata: %w", err)
+	}
+	*m = metadata
+	return nil
+}
+
+// MarshalJSON encodes nil metadata as an empty object
+func (m ProductMetadata) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]string(m))
+}
@@ -0,0 +1,190 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// MaxProductRelations caps the curated related set of one product
+const MaxProductRelations = 20
+
+// RelationType says how a related product relates to its source
+type RelationType string
+
+const (
+	RelationRelated   RelationType = "related"
+	RelationAccessory RelationType = "accessory"
+	RelationUpsell    RelationType = "upsell"
+)
+
+// RelationTypes lists every accepted relation type
+var RelationTypes = []RelationType{RelationRelated, RelationAccessory, RelationUpsell}
+
+// Valid reports whether t is one of RelationTypes
+func (t RelationType) Valid() bool {
+	for _, relationType := range RelationTypes {
+		if t == relationType {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductRelation links a product to one entry of its curated related set.
+// Position orders the set, starting at 0.
+type ProductRelation struct {
+	ProductID        uuid.UUID    `db:"product_id" json:"productId"`
+	RelatedProductID uuid.UUID    `db:"related_product_id" json:"relatedProductId"`
+	RelationType     RelationType `db:"relation_type" json:"relationType"`
+	Position         int          `db:"position" json:"position"`
+}
+
+// GetProductsByIDs fetches the products with the given IDs in the order of
+// ids. IDs without a product are skipped.
+func (r *ProductRepo) GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]*Product, error) {
+	if len(ids) == 0 {
+		return []*Product{}, nil
+	}
+
+	var found []Product
+	for _, batch := range chunk(ids, 1) {
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		query, args := NewQueryBuilder(`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products`).
+			WhereIn("id", args...).
+			Build()
+
+		var batchFound []Product
+		if err := r.db.SelectContext(ctx, &batchFound, query, args...); err != nil {
+			return nil, repoError("getProductsByIDs", EntityProduct, fmt.Errorf("select query failed: %w", err))
+		}
+		found = append(found, batchFound...)
+	}
+	for i := range found {
+		toUTC(&found[i].CreatedAt)
+	}
+	return orderByIDs(found, ids), nil
+}
+
+// ListProductRelations fetches a product's curated related set in position
+// order. The product must exist.
+func (r *ProductRepo) ListProductRelations(ctx context.Context, productID uuid.UUID) ([]ProductRelation, error) {
+	const op = "listProductRelations"
+	const productQuery = `SELECT id FROM products WHERE id = $1`
+	const selectQuery = `
+		SELECT product_id, related_product_id, relation_type, position
+		FROM product_relations
+		WHERE product_id = $1
+		ORDER BY position ASC`
+
+	var id uuid.UUID
+	if err := r.db.GetContext(ctx, &id, productQuery, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, productID))
+		}
+		return nil, repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+
+	relations := []ProductRelation{}
+	if err := r.db.SelectContext(ctx, &relations, selectQuery, productID); err != nil {
+		return nil, repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+	return relations, nil
+}
+
+// SetProductRelations replaces a product's curated related set with
+// relations, in order. ProductID and Position are set on each relation.
+// The product and every related product must exist. Callers validate the
+// set's size and that it neither repeats a product nor references itself.
+func (r *ProductRepo) SetProductRelations(ctx context.Context, productID uuid.UUID, relations []ProductRelation) error {
+	const op = "setProductRelations"
+	const productQuery = `SELECT id FROM products WHERE id = $1 FOR UPDATE`
+	const deleteQuery = `DELETE FROM product_relations WHERE product_id = $1`
+	const insertQuery = `
+		INSERT INTO product_relations(product_id, related_product_id, relation_type, position)
+		VALUES(:product_id, :related_product_id, :relation_type, :position)`
+
+	for i := range relations {
+		relations[i].ProductID = productID
+		relations[i].Position = i
+	}
+
+	return withTx(ctx, r.db, op, EntityProduct, func(tx *sqlx.Tx) error {
+		var id uuid.UUID
+		if err := tx.GetContext(ctx, &id, productQuery, productID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, productID))
+			}
+			return repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+		}
+
+		if len(relations) > 0 {
+			args := make([]any, len(relations))
+			for i, relation := range relations {
+				args[i] = relation.RelatedProductID
+			}
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(relations)), ", ")
+			query, args := NewQueryBuilder(`SELECT id FROM products`).
+				Where("id IN ("+placeholders+")", args...).
+				Build()
+
+			var existing []uuid.UUID
+			if err := tx.SelectContext(ctx, &existing, query, args...); err != nil {
+				return repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+			}
+			if missing, ok := firstMissing(relations, existing); ok {
+				return repoError(op, EntityProduct, fmt.Errorf("%w: related product id `%s`", ErrNotFound, missing))
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteQuery, productID); err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("delete query failed: %w", err))
+		}
+		for _, relation := range relations {
+			if _, err := tx.NamedExecContext(ctx, insertQuery, relation); err != nil {
+				return repoError(op, EntityProduct, fmt.Errorf("insert query failed: %w", err))
+			}
+		}
+		return nil
+	})
+}
+
+// orderByIDs returns pointers to products in the order of ids, skipping
+// IDs that are not among products
+func orderByIDs(products []Product, ids []uuid.UUID) []*Product {
+	byID := make(map[uuid.UUID]*Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	ordered := make([]*Product, 0, len(products))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			ordered = append(ordered, product)
+		}
+	}
+	return ordered
+}
+
+// firstMissing returns the first related product of relations that is not
+// in existing
+func firstMissing(relations []ProductRelation, existing []uuid.UUID) (uuid.UUID, bool) {
+	found := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	for _, relation := range relations {
+		if !found[relation.RelatedProductID] {
+			return relation.RelatedProductID, true
+		}
+	}
+	return uuid.Nil, false
+}
@@ -0,0 +1,92 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProductsByIDs(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+	columns := []string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+	query := "^" + regexp.QuoteMeta(
+		`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products `+
+			`WHERE id IN ($1, $2, $3)`) + "$"
+
+	t.Run("should return found products in the order of ids", func(t *testing.T) {
+		missing := uuid.New()
+		mockRows := sqlmock.NewRows(columns).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
+			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Currency, testProductTwo.Quantity, testProductTwo.Weight, testProductTwo.Status, productAttributesJSON(testProductTwo.Attributes), productMetadataJSON(testProductTwo.Metadata), testProductTwo.CreatedAt)
+		mock.ExpectQuery(query).WithArgs(testProductTwo.ID, missing, testProductOne.ID).WillReturnRows(mockRows)
+
+		products, err := repo.GetProductsByIDs(ctx, []uuid.UUID{testProductTwo.ID, missing, testProductOne.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, []*Product{&testProductTwo, &testProductOne}, products)
+	})
+
+	t.Run("should not query for no ids", func(t *testing.T) {
+		products, err := repo.GetProductsByIDs(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, products)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetProductRelations(t *testing.T) {
+	ctx := context.Background()
+	productQuery := regexp.QuoteMeta(`SELECT id FROM products WHERE id = $1 FOR UPDATE`)
+	existsQuery := "^" + regexp.QuoteMeta(`SELECT id FROM products WHERE id IN ($1)`) + "$"
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM product_relations WHERE product_id = $1`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO product_relations(product_id, related_product_id, relation_type, position)`)
+
+	newRepo := func(t *testing.T) (ProductRepoInterface, sqlmock.Sqlmock) {
+		mockDB, mock, _ := sqlmock.New()
+		t.Cleanup(func() { mockDB.Close() })
+		return NewProductRepo(sqlx.NewDb(mockDB, "sqlmock")), mock
+	}
+
+	t.Run("should replace the set in one transaction", func(t *testing.T) {
+		repo, mock := newRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testProductOne.ID))
+		mock.ExpectQuery(existsQuery).WithArgs(testProductTwo.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testProductTwo.ID))
+		mock.ExpectExec(deleteQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(insertQuery).WithArgs(testProductOne.ID, testProductTwo.ID, RelationAccessory, 0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		relations := []ProductRelation{{RelatedProductID: testProductTwo.ID, RelationType: RelationAccessory}}
+		err := repo.SetProductRelations(ctx, testProductOne.ID, relations)
+		assert.NoError(t, err)
+		assert.Equal(t, testProductOne.ID, relations[0].ProductID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if a related product does not exist", func(t *testing.T) {
+		repo, mock := newRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(productQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testProductOne.ID))
+		mock.ExpectQuery(existsQuery).WithArgs(testProductTwo.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := repo.SetProductRelations(ctx, testProductOne.ID, []ProductRelation{{RelatedProductID: testProductTwo.ID, RelationType: RelationRelated}})
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.Equal(t, "setProductRelations: not found: related product id `"+testProductTwo.ID.String()+"`", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -5,93 +5,268 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+// ProductStatus is the lifecycle state of a product. Only active products
+// are visible to customers.
+type ProductStatus string
+
+const (
+	ProductDraft        ProductStatus = "draft"
+	ProductActive       ProductStatus = "active"
+	ProductDiscontinued ProductStatus = "discontinued"
+)
+
+// ProductStatuses lists every accepted status
+var ProductStatuses = []ProductStatus{ProductDraft, ProductActive, ProductDiscontinued}
+
+// DefaultProductStatus is the status of products created without one. The
+// service applies it; the repos reject a product without a status.
+const DefaultProductStatus = ProductDraft
+
+// productTransitions maps each status to the statuses it may move to
+var productTransitions = map[ProductStatus][]ProductStatus{
+	ProductDraft:        {ProductActive},
+	ProductActive:       {ProductDiscontinued},
+	ProductDiscontinued: {ProductActive},
+}
+
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+// ErrMissingStatus is returned when a product is created without a status
+var ErrMissingStatus = errors.New("product status is required")
+
+// DefaultCurrency is the currency of products created without one
+const DefaultCurrency = "USD"
+
+// Valid reports whether s is one of ProductStatuses
+func (s ProductStatus) Valid() bool {
+	_, ok := productTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a product in status s may move to next
+func (s ProductStatus) CanTransitionTo(next ProductStatus) bool {
+	for _, allowed := range productTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransitionError is returned when a product may not move from one
+// status to another. It matches ErrInvalidStatusTransition with errors.Is.
+type StatusTransitionError struct {
+	From ProductStatus
+	To   ProductStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change status from %s to %s", e.From, e.To)
+}
+
+func (e *StatusTransitionError) Is(target error) bool {
+	return target == ErrInvalidStatusTransition
+}
+
 type Product struct {
-	ID          uuid.UUID `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	ImageURL    string    `db:"image_url"`
-	CategoryID  uuid.UUID `db:"category_id"`
-	Price       float64   `db:"price"`
-	Quantity    int       `db:"quantity"`
-	CreatedAt   time.Time `db:"created_at"`
+	ID          uuid.UUID         `db:"id" json:"id"`
+	Name        string            `db:"name" json:"name"`
+	Description string            `db:"description" json:"description"`
+	ImageURL    string            `db:"image_url" json:"imageUrl"`
+	CategoryID  uuid.UUID         `db:"category_id" json:"categoryId"`
+	Price       float64           `db:"price" json:"price"`
+	Currency    string            `db:"currency" json:"currency"` // ISO 4217
+	Quantity    int               `db:"quantity" json:"quantity"`
+	Weight      *float64          `db:"weight" json:"weight"` // kg, optional
+	Status      ProductStatus     `db:"status" json:"status"`
+	Attributes  ProductAttributes `db:"attributes" json:"attributes"`
+	Metadata    ProductMetadata   `db:"metadata" json:"metadata"`
+	CreatedAt   time.Time         `db:"created_at" json:"createdAt"`
+}
+
+// ProductFilter narrows ListProducts. An empty Status matches every status.
+// Attributes maps attribute names to the raw value a product's attribute
+// must equal, matched like CategoryFilter.Attributes. A non-empty
+// CategoryIDs keeps products of any of those categories. ExcludeIDs drops
+// products already served, and a non-Nil AfterID makes the created_after
+// bound of a listing the (created_at, id) keyset of the last product served,
+// see Cursor. A non-nil HasImage keeps only products with an image URL when
+// true and only those without when false.
+type ProductFilter struct {
+	Status      ProductStatus
+	Attributes  map[string]string
+	CategoryIDs []uuid.UUID
+	ExcludeIDs  []uuid.UUID
+	AfterID     uuid.UUID
+	HasImage    *bool
+}
+
+// ProductPatch holds the status and attributes a PatchProduct call changes
+// together. Nil fields are left untouched. A non-nil Precondition is called
+// with the product as stored, locked against concurrent writes, and its
+// error aborts the update.
+type ProductPatch struct {
+	Status       *ProductStatus
+	Attributes   *ProductAttributes
+	Precondition func(current *Product) error
+}
+
+// IsEmpty reports whether the patch changes no fields
+func (p ProductPatch) IsEmpty() bool {
+	return p.Status == nil && p.Attributes == nil
+}
+
+// ProductCountFilter narrows CountAllProducts. A nil CategoryID counts
+// products of every category.
+type ProductCountFilter struct {
+	CategoryID *uuid.UUID
 }
 
 type ProductRepo struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	now    func() time.Time
+	limits limitRange
 }
 
+//go:generate go run github.com/matryer/moq@v0.5.3 -pkg mocks -out mocks/product_repo_mock.go . ProductRepoInterface
+
+// ProductRepoInterface is the product storage. CreateProduct and
+// UpdateProduct overwrite the product passed in with the row as stored.
 type ProductRepoInterface interface {
 	GetProductByID(ctx context.Context, id uuid.UUID) (*Product, error)
-	ListProducts(ctx context.Context, createdAfter time.Time, limit int) ([]*Product, error)
+	ProductExists(ctx context.Context, id uuid.UUID) (bool, error)
+	GetProductAvailability(ctx context.Context, id uuid.UUID) (int, error)
+	ListProducts(ctx context.Context, filter ProductFilter, createdAfter time.Time, limit int) ([]*Product, error)
+	CountAllProducts(ctx context.Context, filter ProductCountFilter) (int64, error)
+	ListRelatedProducts(ctx context.Context, productID uuid.UUID, limit int) ([]*Product, error)
+	ListProductsGroupedByCategory(ctx context.Context, limitPerCategory int) (map[uuid.UUID][]*Product, error)
+	ListDuplicateGroups(ctx context.Context, filter DuplicateFilter, after DuplicateGroupKey, limit int) ([]*DuplicateGroup, error)
+	GetProductsByIDs(ctx context.Context, ids []uuid.UUID) ([]*Product, error)
+	ListProductRelations(ctx context.Context, productID uuid.UUID) ([]ProductRelation, error)
+	SetProductRelations(ctx context.Context, productID uuid.UUID, relations []ProductRelation) error
+	ApplyProductBatch(ctx context.Context, items []ProductBatchItem, partial bool) ([]error, error)
 	CreateProduct(ctx context.Context, category *Product) error
 	UpdateProduct(ctx context.Context, category *Product) error
+	UpdateProductStatus(ctx context.Context, id uuid.UUID, status ProductStatus) (*Product, error)
+	UpdateProductAttributes(ctx context.Context, id uuid.UUID, attributes ProductAttributes) (*Product, error)
+	PatchProduct(ctx context.Context, id uuid.UUID, patch ProductPatch) (*Product, error)
 	DeleteProduct(ctx context.Context, id uuid.UUID) error
+	DeleteProductReturning(ctx context.Context, id uuid.UUID) (*Product, error)
 }
 
 // NewProductRepository creates a new repository instance
 func NewProductRepo(db *sqlx.DB) ProductRepoInterface {
-	return &ProductRepo{db: db}
+	return &ProductRepo{db: db, now: time.Now, limits: mustLimitRange(minLimit, defaultLimit, maxLimit)}
 }
 
-// GetProductByID fetches a product by its ID
-func (r *ProductRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*Product, error) {
-	const query = `
-		SELECT id, name, description, image_url, category_id, price, quantity, created_at
+// productColumns are the columns of a product row, in Product's order
+const productColumns = `id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at`
+
+const getProductQuery = `
+		SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
 		FROM products
 		WHERE id = $1`
 
+// GetProductByID fetches a product by its ID
+func (r *ProductRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*Product, error) {
 	var product Product
-	err := r.db.GetContext(ctx, &product, query, id)
+	err := r.db.GetContext(ctx, &product, getProductQuery, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("getProductByID: %w: id `%s`", ErrNotFound, id)
+			return nil, repoError("getProductByID", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
 		}
-		return nil, fmt.Errorf("getProductByID: select query failed: %w", err)
+		return nil, repoError("getProductByID", EntityProduct, fmt.Errorf("select query failed: %w", err))
 	}
+	toUTC(&product.CreatedAt)
 
 	return &product, nil
 }
 
-// ListProducts fetches all products from the database
+// ProductExists reports whether a product with id exists without fetching
+// the row
+func (r *ProductRepo) ProductExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	const query = `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`
+
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, query, id); err != nil {
+		return false, repoError("productExists", EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+	return exists, nil
+}
+
+// GetProductAvailability fetches the quantity in stock of an active
+// product. Drafts and discontinued products are reported as not found.
+func (r *ProductRepo) GetProductAvailability(ctx context.Context, id uuid.UUID) (int, error) {
+	const query = `SELECT quantity FROM products WHERE id = $1 AND status = 'active'`
+
+	var quantity int
+	if err := r.db.GetContext(ctx, &quantity, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, repoError("getProductAvailability", EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+		}
+		return 0, repoError("getProductAvailability", EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+	return quantity, nil
+}
+
+// listProductsQuery builds the query listing up to limit products
+// matching filter created after createdAfter
+func listProductsQuery(filter ProductFilter, createdAfter time.Time, limit int) (string, []any) {
+	qb := whereAfter(NewQueryBuilder(`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products`),
+		createdAfter, filter.AfterID)
+	if filter.Status != "" {
+		qb.Where("status = ?", filter.Status)
+	}
+	if filter.HasImage != nil {
+		if *filter.HasImage {
+			qb.Where("image_url IS NOT NULL AND image_url <> ''")
+		} else {
+			qb.Where("(image_url IS NULL OR image_url = '')")
+		}
+	}
+	whereAttributes(qb, filter.Attributes)
+	categoryIDs := make([]any, len(filter.CategoryIDs))
+	for i, id := range filter.CategoryIDs {
+		categoryIDs[i] = id
+	}
+	qb.WhereIn("category_id", categoryIDs...)
+	whereNotIDs(qb, filter.ExcludeIDs)
+	return qb.OrderBy("created_at ASC, id ASC").Limit(limit).Build()
+}
+
+// ListProducts fetches products matching filter from the database
 func (r *ProductRepo) ListProducts(
 	ctx context.Context,
+	filter ProductFilter,
 	createdAfter time.Time, // pagination token
 	limit int,
 ) ([]*Product, error) {
-	limit = checkLimit(limit)
-	args := map[string]any{
-		"created_at": createdAfter,
-		"limit":      limit,
-	}
-
-	const query = `
-		SELECT id, name, description, image_url, category_id, price, quantity, created_at
-		FROM products 
-		WHERE created_at > :created_at 
-		ORDER BY created_at ASC
-		LIMIT :limit
-	`
-
-	stmt, err := r.db.NamedQueryContext(ctx, query, args)
+	query, args := listProductsQuery(filter, createdAfter, r.limits.clamp(limit))
+	rows, err := r.db.QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("listProducts: select query failed: %w", err)
+		return nil, repoError("listProducts", EntityProduct, fmt.Errorf("select query failed: %w", err))
 	}
-	defer stmt.Close()
+	defer rows.Close()
 
 	var products []*Product
-	for stmt.Next() {
+	for rows.Next() {
 		var product Product
-		if err := stmt.StructScan(&product); err != nil {
-			return nil, fmt.Errorf("listProducts: scan failed: %w", err)
+		if err := rows.StructScan(&product); err != nil {
+			return nil, repoError("listProducts", EntityProduct, fmt.Errorf("scan failed: %w", err))
 		}
+		toUTC(&product.CreatedAt)
 		products = append(products, &product)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, repoError("listProducts", EntityProduct, fmt.Errorf("row iteration failed: %w", err))
+	}
 
 	if len(products) == 0 {
 		return []*Product{}, nil
@@ -100,40 +275,317 @@ func (r *ProductRepo) ListProducts(
 	return products, nil
 }
 
-// CreateProduct inserts a new product into the database
+// CountAllProducts counts the active products matching filter
+func (r *ProductRepo) CountAllProducts(ctx context.Context, filter ProductCountFilter) (int64, error) {
+	qb := NewQueryBuilder(`SELECT COUNT(*) FROM products`).
+		Where("status = ?", ProductActive)
+	if filter.CategoryID != nil {
+		qb.Where("category_id = ?", *filter.CategoryID)
+	}
+	query, args := qb.Build()
+
+	var count int64
+	if err := r.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, repoError("countAllProducts", EntityProduct, fmt.Errorf("count query failed: %w", err))
+	}
+	return count, nil
+}
+
+// ListRelatedProducts fetches other active products in the same category
+// as productID in created_at order. The source product must exist.
+func (r *ProductRepo) ListRelatedProducts(ctx context.Context, productID uuid.UUID, limit int) ([]*Product, error) {
+	const op = "listRelatedProducts"
+	const categoryQuery = `SELECT category_id FROM products WHERE id = $1`
+
+	var categoryID uuid.UUID
+	if err := r.db.GetContext(ctx, &categoryID, categoryQuery, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, productID))
+		}
+		return nil, repoError(op, EntityProduct, fmt.Errorf("select category failed: %w", err))
+	}
+
+	limit = r.limits.clamp(limit)
+	query, args := NewQueryBuilder(`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products`).
+		Where("category_id = ?", categoryID).
+		Where("id != ?", productID).
+		Where("status = ?", ProductActive).
+		OrderBy("created_at ASC, id ASC").
+		Limit(limit).
+		Build()
+
+	products := []*Product{}
+	if err := r.db.SelectContext(ctx, &products, query, args...); err != nil {
+		return nil, repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+	for _, product := range products {
+		toUTC(&product.CreatedAt)
+	}
+	return products, nil
+}
+
+// listProductsGroupedQuery ranks the active products of each category
+// newest first and keeps the first $2 of each
+const listProductsGroupedQuery = `
+	SELECT ` + productColumns + `
+	FROM (
+		SELECT ` + productColumns + `,
+			ROW_NUMBER() OVER (PARTITION BY category_id ORDER BY created_at DESC, id DESC) AS position
+		FROM products
+		WHERE status = $1
+	) ranked
+	WHERE position <= $2
+	ORDER BY category_id, created_at DESC, id DESC
+`
+
+// ListProductsGroupedByCategory fetches the newest active products of every
+// category in one query, keyed by category ID and newest first. Each
+// category holds up to limitPerCategory products; categories without
+// active products are absent.
+func (r *ProductRepo) ListProductsGroupedByCategory(ctx context.Context, limitPerCategory int) (map[uuid.UUID][]*Product, error) {
+	limitPerCategory = r.limits.clamp(limitPerCategory)
+
+	var products []*Product
+	if err := r.db.SelectContext(ctx, &products, listProductsGroupedQuery, ProductActive, limitPerCategory); err != nil {
+		return nil, repoError("listProductsGroupedByCategory", EntityProduct, fmt.Errorf("select query failed: %w", err))
+	}
+
+	groups := map[uuid.UUID][]*Product{}
+	for _, product := range products {
+		toUTC(&product.CreatedAt)
+		groups[product.CategoryID] = append(groups[product.CategoryID], product)
+	}
+	return groups, nil
+}
+
+// CreateProduct inserts a new product into the database, generating an ID and
+// stamping CreatedAt with the current time when they are not set. A product
+// without a status is rejected. product is then filled from the stored row.
 func (r *ProductRepo) CreateProduct(ctx context.Context, product *Product) error {
-	const query = `
-		INSERT INTO products(id, name, description, image_url, category_id, price, quantity, created_at) 
-		VALUES(:id, :name, :description, :image_url, :category_id, :price, :quantity, :created_at)
-	`
-	result, err := r.db.NamedExecContext(ctx, query, product)
-	if err != nil {
-		return fmt.Errorf("createProduct: insert query failed: %w", err)
+	if err := checkProductStatus(product); err != nil {
+		return err
+	}
+	setProductDefaults(product, r.now)
+
+	write := rowWrite{query: insertProductQuery, verb: "insert", table: "products", columns: productColumns, entity: EntityProduct, id: product.ID}
+	if err := writeReturning(ctx, r.db, "createProduct", write, product); err != nil {
+		return err
 	}
-	return checkRowsAffected(result, "createProduct")
+	toUTC(&product.CreatedAt)
+	return nil
 }
 
-// UpdateProduct modifies an existing product
+// UpdateProduct modifies an existing product. Status and attributes are
+// left unchanged; use UpdateProductStatus so transitions are enforced and
+// UpdateProductAttributes for attributes. product is then filled from the
+// stored row, so it holds the current status and attributes.
 func (r *ProductRepo) UpdateProduct(ctx context.Context, product *Product) error {
 	const query = `
 		UPDATE products
 		SET name=:name, description=:description, image_url=:image_url,category_id=:category_id,
-		price=:price, quantity=:quantity, created_at=:created_at
+		price=:price, currency=:currency, quantity=:quantity, weight=:weight, metadata=:metadata, created_at=:created_at
 		WHERE id=:id
 	`
-	result, err := r.db.NamedExecContext(ctx, query, product)
+	toUTC(&product.CreatedAt)
+	write := rowWrite{query: query, verb: "update", table: "products", columns: productColumns, entity: EntityProduct, id: product.ID}
+	if err := writeReturning(ctx, r.db, "updateProduct", write, product); err != nil {
+		return err
+	}
+	toUTC(&product.CreatedAt)
+	return nil
+}
+
+// UpdateProductStatus moves a product to status if the transition is
+// allowed and returns the updated product. Setting the current status
+// again is a no-op.
+func (r *ProductRepo) UpdateProductStatus(ctx context.Context, id uuid.UUID, status ProductStatus) (*Product, error) {
+	const op = "updateProductStatus"
+	const selectQuery = `
+		SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
+		FROM products
+		WHERE id = $1
+		FOR UPDATE`
+	const updateQuery = `UPDATE products SET status = $1 WHERE id = $2`
+
+	var product Product
+	err := withTx(ctx, r.db, op, EntityProduct, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &product, selectQuery, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+		}
+		toUTC(&product.CreatedAt)
+		if product.Status == status {
+			return nil
+		}
+		if !product.Status.CanTransitionTo(status) {
+			return repoError(op, EntityProduct, &StatusTransitionError{From: product.Status, To: status})
+		}
+
+		if _, err := tx.ExecContext(ctx, updateQuery, status, id); err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("update query failed: %w", err))
+		}
+		product.Status = status
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("updateProduct: update query failed: %w", err)
+		return nil, err
 	}
-	return checkRowsAffected(result, "updateProduct")
+
+	return &product, nil
 }
 
-// DeleteProduct removes a product by its ID
+// PatchProduct changes the status and attributes set in patch in one
+// transaction, reading the row FOR UPDATE so the Precondition and the
+// status transition are checked against the row the update overwrites
+func (r *ProductRepo) PatchProduct(ctx context.Context, id uuid.UUID, patch ProductPatch) (*Product, error) {
+	const op = "patchProduct"
+	const selectQuery = getProductQuery + `
+		FOR UPDATE`
+	const updateQuery = `UPDATE products SET status = $1, attributes = $2 WHERE id = $3`
+
+	if patch.IsEmpty() {
+		return nil, repoError(op, EntityProduct, ErrEmptyPatch)
+	}
+
+	var product Product
+	err := withTx(ctx, r.db, op, EntityProduct, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &product, selectQuery, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+		}
+		toUTC(&product.CreatedAt)
+		if err := patch.applyTo(op, &product); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, updateQuery, product.Status, product.Attributes, id); err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("update query failed: %w", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// applyTo checks the Precondition and status transition of the patch
+// against product, as stored, and then applies the patch to it
+func (p ProductPatch) applyTo(op string, product *Product) error {
+	if p.Precondition != nil {
+		current := *product
+		if err := p.Precondition(&current); err != nil {
+			return repoError(op, EntityProduct, err)
+		}
+	}
+	if p.Status != nil && product.Status != *p.Status && !product.Status.CanTransitionTo(*p.Status) {
+		return repoError(op, EntityProduct, &StatusTransitionError{From: product.Status, To: *p.Status})
+	}
+
+	if p.Status != nil {
+		product.Status = *p.Status
+	}
+	if p.Attributes != nil {
+		product.Attributes = maps.Clone(*p.Attributes)
+		if product.Attributes == nil {
+			product.Attributes = ProductAttributes{}
+		}
+	}
+	return nil
+}
+
+// checkProductStatus rejects a product created without a status, which
+// the caller must set, see DefaultProductStatus
+func checkProductStatus(product *Product) error {
+	if product.Status == "" {
+		return repoError("createProduct", EntityProduct, fmt.Errorf("%w: id `%s`", ErrMissingStatus, product.ID))
+	}
+	return nil
+}
+
+// setProductDefaults generates an ID and stamps CreatedAt with now when
+// they are not set. Products without a currency are priced in
+// DefaultCurrency.
+func setProductDefaults(product *Product, now func() time.Time) {
+	if product.ID == uuid.Nil {
+		product.ID = uuid.New()
+	}
+	if product.Currency == "" {
+		product.Currency = DefaultCurrency
+	}
+	if product.CreatedAt.IsZero() {
+		product.CreatedAt = now()
+	}
+	toUTC(&product.CreatedAt)
+	if product.Attributes == nil {
+		product.Attributes = ProductAttributes{}
+	}
+	if product.Metadata == nil {
+		product.Metadata = ProductMetadata{}
+	}
+}
+
+// deleteRelationsQuery removes a product's curated relations in both
+// directions before the product itself is deleted
+const deleteRelationsQuery = `DELETE FROM product_relations WHERE product_id = $1 OR related_product_id = $1`
+
+// DeleteProduct removes a product by its ID along with its relations
 func (r *ProductRepo) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	const op = "deleteProduct"
 	const query = `DELETE FROM products WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+
+	return withTx(ctx, r.db, op, EntityProduct, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, deleteRelationsQuery, id); err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("delete relations query failed: %w", err))
+		}
+
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("delete query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityProduct)
+	})
+}
+
+// DeleteProductReturning removes a product by its ID along with its
+// relations and returns the row as it was before deletion. The read and
+// deletes share one transaction.
+func (r *ProductRepo) DeleteProductReturning(ctx context.Context, id uuid.UUID) (*Product, error) {
+	const op = "deleteProductReturning"
+	const selectQuery = `
+		SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
+		FROM products
+		WHERE id = $1
+		FOR UPDATE`
+	const deleteQuery = `DELETE FROM products WHERE id = $1`
+
+	var product Product
+	err := withTx(ctx, r.db, op, EntityProduct, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &product, selectQuery, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityProduct, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+			}
+			return repoError(op, EntityProduct, fmt.Errorf("select query failed: %w", err))
+		}
+		toUTC(&product.CreatedAt)
+
+		if _, err := tx.ExecContext(ctx, deleteRelationsQuery, id); err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("delete relations query failed: %w", err))
+		}
+		result, err := tx.ExecContext(ctx, deleteQuery, id)
+		if err != nil {
+			return repoError(op, EntityProduct, fmt.Errorf("delete query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityProduct)
+	})
 	if err != nil {
-		return fmt.Errorf("deleteProduct: delete query failed: %w", err)
+		return nil, err
 	}
-	return checkRowsAffected(result, "deleteProduct")
+
+	return &product, nil
 }
@@ -2,6 +2,8 @@ package datalayer
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
@@ -11,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testProductOne = Product{
@@ -20,7 +23,12 @@ var testProductOne = Product{
 	ImageURL:    "test/image/url",
 	CategoryID:  uuid.MustParse("0c34eab4-2d9d-4755-8c4d-dbfbac6728e8"),
 	Price:       234.85,
+	Currency:    "USD",
 	Quantity:    20,
+	Weight:      floatPtr(1.25),
+	Status:      ProductActive,
+	Attributes:  ProductAttributes{"color": "red"},
+	Metadata:    ProductMetadata{"brand": "Acme"},
 	CreatedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 }
 
@@ -30,10 +38,66 @@ var testProductTwo = Product{
 	Description: "Test product B description",
 	CategoryID:  uuid.MustParse("9fcceb36-8a46-404f-9ce6-047c3fb65617"),
 	Price:       234.85,
+	Currency:    "USD",
 	Quantity:    1543,
+	Status:      ProductActive,
+	Attributes:  ProductAttributes{},
+	Metadata:    ProductMetadata{},
 	CreatedAt:   time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC),
 }
 
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// productRow returns product as the one row of a query result
+func productRow(product Product) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+		AddRow(product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, productAttributesJSON(product.Attributes), productMetadataJSON(product.Metadata), product.CreatedAt)
+}
+
+// expectProductReadBack expects the read of the product with id after a
+// write without RETURNING and answers it with stored
+func expectProductReadBack(mock sqlmock.Sqlmock, id driver.Value, stored Product) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT ` + productColumns + ` FROM products WHERE id = ?`)).
+		WithArgs(id).
+		WillReturnRows(productRow(stored))
+}
+
+// sameArg matches any value the first time and only that value after, so
+// a generated id can be followed from one query to the next
+type sameArg struct {
+	value driver.Value
+}
+
+func (a *sameArg) Match(v driver.Value) bool {
+	if a.value == nil {
+		a.value = v
+	}
+	return a.value == v
+}
+
+// assertGeneratedID asserts that arg matched a random UUID
+func assertGeneratedID(t *testing.T, arg *sameArg) {
+	t.Helper()
+
+	raw, ok := arg.value.(string)
+	require.True(t, ok, "id was not bound as a string: %#v", arg.value)
+	id, err := uuid.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), id.Version())
+}
+
+func productAttributesJSON(attributes ProductAttributes) []byte {
+	raw, _ := attributes.Value()
+	return raw.([]byte)
+}
+
+func productMetadataJSON(metadata ProductMetadata) []byte {
+	raw, _ := metadata.Value()
+	return raw.([]byte)
+}
+
 func TestGetProductByID(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -43,13 +107,13 @@ func TestGetProductByID(t *testing.T) {
 	ctx := context.Background()
 
 	selectQuery := regexp.QuoteMeta(
-		`SELECT id, name, description, image_url, category_id, price, quantity, created_at
+		`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
 		FROM products
 		WHERE id = $1`,
 	)
 	t.Run("should return product", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "quantity", "created_at"}).
-			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
 		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(mockRows)
 		product, err := repo.GetProductByID(ctx, testProductOne.ID)
 		assert.NoError(t, err)
@@ -57,6 +121,17 @@ func TestGetProductByID(t *testing.T) {
 		assert.Equal(t, &testProductOne, product)
 	})
 
+	t.Run("should return created at in UTC", func(t *testing.T) {
+		createdAt := testProductOne.CreatedAt.In(time.FixedZone("UTC+5:30", 5*60*60+30*60))
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), createdAt)
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(mockRows)
+		product, err := repo.GetProductByID(ctx, testProductOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, time.UTC, product.CreatedAt.Location())
+		assert.True(t, testProductOne.CreatedAt.Equal(product.CreatedAt))
+	})
+
 	t.Run("should return error if select query error", func(t *testing.T) {
 		dbErr := errors.New("query error")
 		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnError(dbErr)
@@ -65,6 +140,12 @@ func TestGetProductByID(t *testing.T) {
 		assert.Nil(t, product)
 		expectedErrMsg := "getProductByID: select query failed: query error"
 		assert.Equal(t, expectedErrMsg, err.Error())
+
+		var repoErr *RepoError
+		assert.True(t, errors.As(err, &repoErr))
+		assert.Equal(t, "getProductByID", repoErr.Op)
+		assert.Equal(t, EntityProduct, repoErr.Entity)
+		assert.Equal(t, KindInternal, repoErr.Kind)
 	})
 
 	t.Run("should return error if no row", func(t *testing.T) {
@@ -79,6 +160,145 @@ func TestGetProductByID(t *testing.T) {
 	})
 }
 
+func TestGetProductByIDMetadata(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(getProductQuery)
+	metadataRow := func(metadata driver.Value) *sqlmock.Rows {
+		p := testProductOne
+		return sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(p.ID, p.Name, p.Description, p.ImageURL, p.CategoryID, p.Price, p.Currency, p.Quantity, p.Weight, p.Status, productAttributesJSON(p.Attributes), metadata, p.CreatedAt)
+	}
+
+	t.Run("should decode the JSONB metadata", func(t *testing.T) {
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(metadataRow([]byte(`{"brand":"Acme","color":"red"}`)))
+		product, err := repo.GetProductByID(ctx, testProductOne.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ProductMetadata{"brand": "Acme", "color": "red"}, product.Metadata)
+	})
+
+	t.Run("should read NULL metadata as empty", func(t *testing.T) {
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(metadataRow(nil))
+		product, err := repo.GetProductByID(ctx, testProductOne.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ProductMetadata{}, product.Metadata)
+	})
+
+	t.Run("should return error if metadata holds a non-string value", func(t *testing.T) {
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(metadataRow([]byte(`{"weight":5}`)))
+		product, err := repo.GetProductByID(ctx, testProductOne.ID)
+		assert.Nil(t, product)
+		assert.ErrorContains(t, err, "scan product metadata")
+	})
+
+	t.Run("should encode nil metadata as an empty object", func(t *testing.T) {
+		raw, err := ProductMetadata(nil).Value()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("{}"), raw)
+	})
+}
+
+func TestProductExists(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	existsQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`)
+	t.Run("should report an existing product", func(t *testing.T) {
+		mock.ExpectQuery(existsQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		exists, err := repo.ProductExists(ctx, testProductOne.ID)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("should report a missing product", func(t *testing.T) {
+		mock.ExpectQuery(existsQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		exists, err := repo.ProductExists(ctx, testProductOne.ID)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("should return error if select query error", func(t *testing.T) {
+		mock.ExpectQuery(existsQuery).WithArgs(testProductOne.ID).WillReturnError(errors.New("query error"))
+		exists, err := repo.ProductExists(ctx, testProductOne.ID)
+		assert.False(t, exists)
+		assert.EqualError(t, err, "productExists: select query failed: query error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetProductAvailability(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	availabilityQuery := regexp.QuoteMeta(`SELECT quantity FROM products WHERE id = $1 AND status = 'active'`)
+	t.Run("should return the quantity of an active product", func(t *testing.T) {
+		mock.ExpectQuery(availabilityQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(42))
+		quantity, err := repo.GetProductAvailability(ctx, testProductOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, quantity)
+	})
+
+	t.Run("should return not found if no active product matches", func(t *testing.T) {
+		mock.ExpectQuery(availabilityQuery).WithArgs(testProductOne.ID).WillReturnError(sql.ErrNoRows)
+		_, err := repo.GetProductAvailability(ctx, testProductOne.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.EqualError(t, err, "getProductAvailability: not found: id `"+testProductOne.ID.String()+"`")
+	})
+
+	t.Run("should return error if select query error", func(t *testing.T) {
+		mock.ExpectQuery(availabilityQuery).WithArgs(testProductOne.ID).WillReturnError(errors.New("query error"))
+		_, err := repo.GetProductAvailability(ctx, testProductOne.ID)
+		assert.EqualError(t, err, "getProductAvailability: select query failed: query error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountAllProducts(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	t.Run("should count active products of every category", func(t *testing.T) {
+		query := regexp.QuoteMeta(`SELECT COUNT(*) FROM products WHERE status = $1`)
+		mock.ExpectQuery(query + "$").WithArgs(ProductActive).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1234))
+		count, err := repo.CountAllProducts(ctx, ProductCountFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1234), count)
+	})
+
+	t.Run("should count active products of one category", func(t *testing.T) {
+		categoryID := testProductOne.CategoryID
+		query := regexp.QuoteMeta(`SELECT COUNT(*) FROM products WHERE status = $1 AND category_id = $2`)
+		mock.ExpectQuery(query).WithArgs(ProductActive, categoryID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+		count, err := repo.CountAllProducts(ctx, ProductCountFilter{CategoryID: &categoryID})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), count)
+	})
+
+	t.Run("should return error if count query fails", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM products`)).WillReturnError(errors.New("query error"))
+		count, err := repo.CountAllProducts(ctx, ProductCountFilter{})
+		assert.Zero(t, count)
+		assert.EqualError(t, err, "countAllProducts: count query failed: query error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestListProducts(t *testing.T) {
 	var createdAfter time.Time
 	limit := 10
@@ -90,34 +310,44 @@ func TestListProducts(t *testing.T) {
 	repo := NewProductRepo(db)
 	ctx := context.Background()
 
-	selectQuery := regexp.QuoteMeta(`
-			SELECT id, name, description, image_url, category_id, price, quantity, created_at
-			FROM products
-			WHERE created_at > ?
-			ORDER BY created_at ASC
-			LIMIT ?
-		`)
+	selectQuery := regexp.QuoteMeta(
+		`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products ` +
+			`WHERE created_at > $1 ORDER BY created_at ASC, id ASC LIMIT $2`,
+	)
 
 	t.Run("should return list of products", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "quantity", "created_at"}).
-			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
-			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Quantity, testProductTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
+			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Currency, testProductTwo.Quantity, testProductTwo.Weight, testProductTwo.Status, productAttributesJSON(testProductTwo.Attributes), productMetadataJSON(testProductTwo.Metadata), testProductTwo.CreatedAt)
 
 		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnRows(mockRows)
-		products, err := repo.ListProducts(ctx, createdAfter, limit)
+		products, err := repo.ListProducts(ctx, ProductFilter{}, createdAfter, limit)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, products)
 		assert.Equal(t, []*Product{&testProductOne, &testProductTwo}, products)
 	})
 
+	t.Run("should resume after the created_at and id of a cursor", func(t *testing.T) {
+		keysetQuery := regexp.QuoteMeta(
+			`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products ` +
+				`WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT $3`,
+		)
+
+		mock.ExpectQuery(keysetQuery).WithArgs(testProductOne.CreatedAt, testProductOne.ID, limit).WillReturnRows(productRow(testProductTwo))
+		products, err := repo.ListProducts(ctx, ProductFilter{AfterID: testProductOne.ID}, testProductOne.CreatedAt, limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*Product{&testProductTwo}, products)
+	})
+
 	t.Run("should use minimum limit if limit is less than minimum limit", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "quantity", "created_at"}).
-			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
-			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Quantity, testProductTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
+			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Currency, testProductTwo.Quantity, testProductTwo.Weight, testProductTwo.Status, productAttributesJSON(testProductTwo.Attributes), productMetadataJSON(testProductTwo.Metadata), testProductTwo.CreatedAt)
 
 		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, 1).WillReturnRows(mockRows)
-		products, err := repo.ListProducts(ctx, createdAfter, -1)
+		products, err := repo.ListProducts(ctx, ProductFilter{}, createdAfter, -1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, products)
@@ -125,12 +355,12 @@ func TestListProducts(t *testing.T) {
 	})
 
 	t.Run("should use maximum limit if limit is greater than maximum limit", func(t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "quantity", "created_at"}).
-			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
-			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Quantity, testProductTwo.CreatedAt)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
+			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Currency, testProductTwo.Quantity, testProductTwo.Weight, testProductTwo.Status, productAttributesJSON(testProductTwo.Attributes), productMetadataJSON(testProductTwo.Metadata), testProductTwo.CreatedAt)
 
 		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, 1000).WillReturnRows(mockRows)
-		products, err := repo.ListProducts(ctx, createdAfter, 100009)
+		products, err := repo.ListProducts(ctx, ProductFilter{}, createdAfter, 100009)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, products)
@@ -147,11 +377,13 @@ func TestListProducts(t *testing.T) {
 				"category_id",
 				"price",
 				"quantity",
+				"weight",
+				"status",
 				"created_at",
 			},
 		)
 		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnRows(mockRows)
-		products, err := repo.ListProducts(ctx, createdAfter, limit)
+		products, err := repo.ListProducts(ctx, ProductFilter{}, createdAfter, limit)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, products)
@@ -161,7 +393,7 @@ func TestListProducts(t *testing.T) {
 	t.Run("should return error if select query fails", func(t *testing.T) {
 		dbErr := errors.New("query error")
 		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnError(dbErr)
-		products, err := repo.ListProducts(ctx, createdAfter, limit)
+		products, err := repo.ListProducts(ctx, ProductFilter{}, createdAfter, limit)
 
 		assert.Nil(t, products)
 		assert.Error(t, err)
@@ -169,13 +401,78 @@ func TestListProducts(t *testing.T) {
 		assert.Equal(t, expectedErrMsg, err.Error())
 	})
 
+	t.Run("should add a containment condition per attribute filter", func(t *testing.T) {
+		filterQuery := regexp.QuoteMeta(
+			`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products ` +
+				`WHERE created_at > $1 AND (attributes @> $2::jsonb) ORDER BY created_at ASC, id ASC LIMIT $3`,
+		)
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+
+		mock.ExpectQuery(filterQuery).WithArgs(createdAfter, `{"color":"red"}`, limit).WillReturnRows(mockRows)
+		filter := ProductFilter{Attributes: map[string]string{"color": "red"}}
+		products, err := repo.ListProducts(ctx, filter, createdAfter, limit)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*Product{&testProductOne}, products)
+	})
+
+	t.Run("should bind the cursor and return created_at in UTC", func(t *testing.T) {
+		ist := time.FixedZone("IST", 5*60*60+30*60)
+		cursor := testProductOne.CreatedAt.Add(-time.Hour)
+		stored := testProductOne
+		stored.CreatedAt = testProductOne.CreatedAt.In(ist)
+		mock.ExpectQuery(selectQuery).WithArgs(cursor.UTC(), limit).WillReturnRows(productRow(stored))
+
+		products, err := repo.ListProducts(ctx, ProductFilter{}, cursor.In(ist), limit)
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, time.UTC, products[0].CreatedAt.Location())
+		assert.Equal(t, []*Product{&testProductOne}, products)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should keep products of any of the category ids", func(t *testing.T) {
+		other := uuid.New()
+		filterQuery := "^" + regexp.QuoteMeta(
+			`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products `+
+				`WHERE created_at > $1 AND category_id IN ($2, $3) ORDER BY created_at ASC, id ASC LIMIT $4`,
+		) + "$"
+		mock.ExpectQuery(filterQuery).WithArgs(createdAfter, testProductOne.CategoryID, other, limit).WillReturnRows(productRow(testProductOne))
+
+		filter := ProductFilter{CategoryIDs: []uuid.UUID{testProductOne.CategoryID, other}}
+		products, err := repo.ListProducts(ctx, filter, createdAfter, limit)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Product{&testProductOne}, products)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should keep only products with or without an image", func(t *testing.T) {
+		conditions := map[bool]string{
+			true:  `image_url IS NOT NULL AND image_url <> ''`,
+			false: `(image_url IS NULL OR image_url = '')`,
+		}
+		for hasImage, condition := range conditions {
+			filterQuery := "^" + regexp.QuoteMeta(
+				`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products `+
+					`WHERE created_at > $1 AND `+condition+` ORDER BY created_at ASC, id ASC LIMIT $2`,
+			) + "$"
+			mock.ExpectQuery(filterQuery).WithArgs(createdAfter, limit).WillReturnRows(productRow(testProductOne))
+
+			products, err := repo.ListProducts(ctx, ProductFilter{HasImage: &hasImage}, createdAfter, limit)
+			assert.NoError(t, err)
+			assert.Equal(t, []*Product{&testProductOne}, products)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("should return error if scan fails", func(t *testing.T) {
 		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "createdAt"}).
 			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.CreatedAt).
 			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.CreatedAt)
 
 		mock.ExpectQuery(selectQuery).WithArgs(createdAfter, limit).WillReturnRows(mockRows)
-		products, err := repo.ListProducts(ctx, createdAfter, limit)
+		products, err := repo.ListProducts(ctx, ProductFilter{}, createdAfter, limit)
 
 		assert.Nil(t, products)
 		assert.Error(t, err)
@@ -184,6 +481,101 @@ func TestListProducts(t *testing.T) {
 	})
 }
 
+func TestListRelatedProducts(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	categoryQuery := regexp.QuoteMeta(`SELECT category_id FROM products WHERE id = $1`)
+	relatedQuery := "^" + regexp.QuoteMeta(
+		`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products `+
+			`WHERE category_id = $1 AND id != $2 AND status = $3 ORDER BY created_at ASC, id ASC LIMIT $4`,
+	) + "$"
+
+	t.Run("should query the source category then its other products", func(t *testing.T) {
+		mock.ExpectQuery(categoryQuery).WithArgs(testProductTwo.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(testProductOne.CategoryID))
+		mockRows := sqlmock.NewRows([]string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+		mock.ExpectQuery(relatedQuery).WithArgs(testProductOne.CategoryID, testProductTwo.ID, ProductActive, 5).WillReturnRows(mockRows)
+
+		products, err := repo.ListRelatedProducts(ctx, testProductTwo.ID, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Product{&testProductOne}, products)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found without listing if product is missing", func(t *testing.T) {
+		mock.ExpectQuery(categoryQuery).WithArgs(testProductOne.ID).WillReturnError(sql.ErrNoRows)
+
+		products, err := repo.ListRelatedProducts(ctx, testProductOne.ID, 5)
+		assert.Nil(t, products)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.Equal(t, "listRelatedProducts: not found: id `f2aa335f-6f91-4d4d-8057-53b0009bc376`", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error if related query fails", func(t *testing.T) {
+		mock.ExpectQuery(categoryQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(testProductOne.CategoryID))
+		mock.ExpectQuery(relatedQuery).WillReturnError(errors.New("query error"))
+
+		products, err := repo.ListRelatedProducts(ctx, testProductOne.ID, 5)
+		assert.Nil(t, products)
+		assert.Equal(t, "listRelatedProducts: select query failed: query error", err.Error())
+	})
+}
+
+func TestListProductsGroupedByCategory(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	groupedQuery := regexp.QuoteMeta(`ROW_NUMBER() OVER (PARTITION BY category_id ORDER BY created_at DESC, id DESC) AS position`) +
+		`\s+FROM products\s+WHERE status = \$1\s+\) ranked\s+WHERE position <= \$2\s+` +
+		regexp.QuoteMeta(`ORDER BY category_id, created_at DESC, id DESC`)
+
+	t.Run("should rank each category's active products in one query", func(t *testing.T) {
+		older := testProductOne
+		older.ID = uuid.MustParse("7a4b1c2d-3e5f-4a6b-8c7d-9e0f1a2b3c4d")
+		older.CreatedAt = testProductOne.CreatedAt.Add(-time.Hour)
+		rows := productRow(testProductOne).
+			AddRow(older.ID, older.Name, older.Description, older.ImageURL, older.CategoryID, older.Price, older.Currency, older.Quantity, older.Weight, older.Status, productAttributesJSON(older.Attributes), productMetadataJSON(older.Metadata), older.CreatedAt).
+			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Currency, testProductTwo.Quantity, testProductTwo.Weight, testProductTwo.Status, productAttributesJSON(testProductTwo.Attributes), productMetadataJSON(testProductTwo.Metadata), testProductTwo.CreatedAt)
+		mock.ExpectQuery(groupedQuery).WithArgs(ProductActive, 5).WillReturnRows(rows)
+
+		groups, err := repo.ListProductsGroupedByCategory(ctx, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID][]*Product{
+			testProductOne.CategoryID: {&testProductOne, &older},
+			testProductTwo.CategoryID: {&testProductTwo},
+		}, groups)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should use the default limit per category when none is given", func(t *testing.T) {
+		mock.ExpectQuery(groupedQuery).WithArgs(ProductActive, defaultLimit).WillReturnRows(sqlmock.NewRows([]string{"id", "category_id"}))
+
+		groups, err := repo.ListProductsGroupedByCategory(ctx, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, groups)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error if select query fails", func(t *testing.T) {
+		mock.ExpectQuery(groupedQuery).WillReturnError(errors.New("query error"))
+
+		groups, err := repo.ListProductsGroupedByCategory(ctx, 5)
+		assert.Nil(t, groups)
+		assert.EqualError(t, err, "listProductsGroupedByCategory: select query failed: query error")
+	})
+}
+
 func TestCreateProduct(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -193,21 +585,113 @@ func TestCreateProduct(t *testing.T) {
 	ctx := context.Background()
 
 	insertQuery := regexp.QuoteMeta(
-		`INSERT INTO products(id, name, description, image_url, category_id, price, quantity, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO products(id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 	)
 	t.Run("should create valid product", func(t *testing.T) {
 		mock.ExpectExec(insertQuery).
-			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
+			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, sqlmock.AnyArg(), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectProductReadBack(mock, testProductOne.ID, testProductOne)
 
 		err := repo.CreateProduct(ctx, &testProductOne)
 		assert.NoError(t, err)
 	})
 
+	t.Run("should default created at to now if not set", func(t *testing.T) {
+		now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+		clockRepo := &ProductRepo{db: db, now: func() time.Time { return now }}
+		product := testProductOne
+		product.CreatedAt = time.Time{}
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		row := product
+		row.CreatedAt = now
+		expectProductReadBack(mock, product.ID, row)
+
+		err := clockRepo.CreateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assert.Equal(t, now, product.CreatedAt)
+	})
+
+	t.Run("should store created at in UTC", func(t *testing.T) {
+		product := testProductOne
+		product.CreatedAt = time.Date(2024, 5, 6, 9, 8, 9, 0, time.FixedZone("UTC+2", 2*60*60))
+		stored := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), stored).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		row := product
+		row.CreatedAt = stored
+		expectProductReadBack(mock, product.ID, row)
+
+		err := repo.CreateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, product.CreatedAt)
+	})
+
+	t.Run("should generate id if nil", func(t *testing.T) {
+		product := testProductOne
+		product.ID = uuid.Nil
+
+		generated := &sameArg{}
+		row := product
+		row.ID = testProductTwo.ID
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(generated, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), product.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectProductReadBack(mock, generated, row)
+
+		err := repo.CreateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assertGeneratedID(t, generated)
+		assert.Equal(t, row.ID, product.ID, "the stored row is read back")
+	})
+
+	t.Run("should keep explicit id", func(t *testing.T) {
+		product := testProductOne
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(testProductOne.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), product.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectProductReadBack(mock, testProductOne.ID, product)
+
+		err := repo.CreateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assert.Equal(t, testProductOne.ID, product.ID)
+	})
+
+	t.Run("should reject a product without a status", func(t *testing.T) {
+		product := testProductOne
+		product.Status = ""
+
+		err := repo.CreateProduct(ctx, &product)
+		assert.ErrorIs(t, err, ErrMissingStatus)
+		assert.Equal(t, KindInvalid, KindOf(err))
+		assert.NoError(t, mock.ExpectationsWereMet(), "nothing is inserted")
+	})
+
+	t.Run("should keep explicit created at", func(t *testing.T) {
+		clockRepo := &ProductRepo{db: db, now: time.Now}
+		product := testProductOne
+
+		mock.ExpectExec(insertQuery).
+			WithArgs(product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), testProductOne.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectProductReadBack(mock, product.ID, product)
+
+		err := clockRepo.CreateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assert.Equal(t, testProductOne.CreatedAt, product.CreatedAt)
+	})
+
 	t.Run("should return error if insert query fails", func(t *testing.T) {
 		dbErr := errors.New("database error")
 		mock.ExpectExec(insertQuery).
-			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
+			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, sqlmock.AnyArg(), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
 			WillReturnError(dbErr)
 
 		err := repo.CreateProduct(ctx, &testProductOne)
@@ -218,7 +702,7 @@ func TestCreateProduct(t *testing.T) {
 
 	t.Run("should return not found if no rows affected", func(t *testing.T) {
 		mock.ExpectExec(insertQuery).
-			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
+			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, sqlmock.AnyArg(), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		err := repo.CreateProduct(ctx, &testProductOne)
@@ -231,7 +715,7 @@ func TestCreateProduct(t *testing.T) {
 	t.Run("should return error if rows affected fails", func(t *testing.T) {
 		dbErr := errors.New("rows affected error")
 		mock.ExpectExec(insertQuery).
-			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt).
+			WithArgs(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, sqlmock.AnyArg(), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt).
 			WillReturnResult(sqlmock.NewErrorResult(dbErr))
 
 		err := repo.CreateProduct(ctx, &testProductOne)
@@ -250,13 +734,14 @@ func TestUpdateProduct(t *testing.T) {
 	ctx := context.Background()
 
 	updateQuery := regexp.QuoteMeta(
-		`UPDATE products SET name=?, description=?, image_url=?,category_id=?, price=?, quantity=?, created_at=? WHERE id=?`,
+		`UPDATE products SET name=?, description=?, image_url=?,category_id=?, price=?, currency=?, quantity=?, weight=?, metadata=?, created_at=? WHERE id=?`,
 	)
 
 	t.Run("should update valid product", func(t *testing.T) {
 		mock.ExpectExec(updateQuery).
-			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt, testProductOne.ID).
+			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt, testProductOne.ID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectProductReadBack(mock, testProductOne.ID, testProductOne)
 
 		err := repo.UpdateProduct(ctx, &testProductOne)
 		assert.NoError(t, err)
@@ -265,7 +750,7 @@ func TestUpdateProduct(t *testing.T) {
 	t.Run("should return error if update query fails", func(t *testing.T) {
 		dbErr := errors.New("database error")
 		mock.ExpectExec(updateQuery).
-			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt, testProductOne.ID).
+			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt, testProductOne.ID).
 			WillReturnError(dbErr)
 
 		err := repo.UpdateProduct(ctx, &testProductOne)
@@ -276,7 +761,7 @@ func TestUpdateProduct(t *testing.T) {
 
 	t.Run("should return not found if no rows affected", func(t *testing.T) {
 		mock.ExpectExec(updateQuery).
-			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt, testProductOne.ID).
+			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt, testProductOne.ID).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
 		err := repo.UpdateProduct(ctx, &testProductOne)
@@ -289,7 +774,7 @@ func TestUpdateProduct(t *testing.T) {
 	t.Run("should return error if rows affected fails", func(t *testing.T) {
 		dbErr := errors.New("rows affected error")
 		mock.ExpectExec(updateQuery).
-			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Quantity, testProductOne.CreatedAt, testProductOne.ID).
+			WithArgs(testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt, testProductOne.ID).
 			WillReturnResult(sqlmock.NewErrorResult(dbErr))
 
 		err := repo.UpdateProduct(ctx, &testProductOne)
@@ -299,6 +784,71 @@ func TestUpdateProduct(t *testing.T) {
 	})
 }
 
+func TestProductWritesReturning(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "postgres")
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	returning := regexp.QuoteMeta(` RETURNING ` + productColumns)
+	insertQuery := `^\s*INSERT INTO products\(.+\)\s+VALUES\(\$1, .+\)` + returning + `$`
+	updateQuery := `^\s*UPDATE products\s+SET .+\s+WHERE id=\$\d+\s*` + returning + `$`
+
+	t.Run("should create product from the returned row", func(t *testing.T) {
+		product := testProductOne
+		product.CreatedAt = product.CreatedAt.In(time.FixedZone("UTC+2", 2*60*60))
+		stored := testProductOne
+		stored.CreatedAt = product.CreatedAt
+
+		mock.ExpectQuery(insertQuery).
+			WithArgs(product.ID, product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, product.Status, sqlmock.AnyArg(), productMetadataJSON(product.Metadata), testProductOne.CreatedAt).
+			WillReturnRows(productRow(stored))
+
+		err := repo.CreateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assert.Equal(t, testProductOne, product)
+	})
+
+	t.Run("should update product and return its current status and attributes", func(t *testing.T) {
+		product := testProductOne
+		product.Name = "Renamed"
+		product.Status = ""
+		product.Attributes = nil
+		stored := testProductOne
+		stored.Name = "Renamed"
+		stored.Status = ProductDraft
+
+		mock.ExpectQuery(updateQuery).
+			WithArgs(product.Name, product.Description, product.ImageURL, product.CategoryID, product.Price, product.Currency, product.Quantity, product.Weight, productMetadataJSON(product.Metadata), product.CreatedAt, product.ID).
+			WillReturnRows(productRow(stored))
+
+		err := repo.UpdateProduct(ctx, &product)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, product)
+	})
+
+	t.Run("should return not found if no row is returned", func(t *testing.T) {
+		product := testProductOne
+		mock.ExpectQuery(updateQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		err := repo.UpdateProduct(ctx, &product)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.EqualError(t, err, "updateProduct: no rows affected: not found")
+	})
+
+	t.Run("should return error if update query fails", func(t *testing.T) {
+		product := testProductOne
+		mock.ExpectQuery(updateQuery).WillReturnError(errors.New("database error"))
+
+		err := repo.UpdateProduct(ctx, &product)
+		assert.EqualError(t, err, "updateProduct: update query failed: database error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteProduct(t *testing.T) {
 	mockDB, mock, _ := sqlmock.New()
 	defer mockDB.Close()
@@ -307,20 +857,37 @@ func TestDeleteProduct(t *testing.T) {
 	repo := NewProductRepo(db)
 	ctx := context.Background()
 
+	relationsQuery := regexp.QuoteMeta(`DELETE FROM product_relations WHERE product_id = $1 OR related_product_id = $1`)
 	deleteQuery := regexp.QuoteMeta(`DELETE FROM products WHERE id = $1`)
 
-	t.Run("should delete valid product", func(t *testing.T) {
+	t.Run("should delete valid product and its relations in one transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 2))
 		mock.ExpectExec(deleteQuery).
 			WithArgs(testProductOne.ID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		err := repo.DeleteProduct(ctx, testProductOne.ID)
 		assert.NoError(t, err)
 	})
 
+	t.Run("should roll back if deleting relations fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		err := repo.DeleteProduct(ctx, testProductOne.ID)
+		assert.Error(t, err)
+		assert.Equal(t, "deleteProduct: delete relations query failed: database error", err.Error())
+	})
+
 	t.Run("should return error if delete query fails", func(t *testing.T) {
 		dbErr := errors.New("database error")
+		mock.ExpectBegin()
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(deleteQuery).WithArgs(testProductOne.ID).WillReturnError(dbErr)
+		mock.ExpectRollback()
 
 		err := repo.DeleteProduct(ctx, testProductOne.ID)
 		assert.Error(t, err)
@@ -329,9 +896,12 @@ func TestDeleteProduct(t *testing.T) {
 	})
 
 	t.Run("should return not found if no rows affected", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(deleteQuery).
 			WithArgs(testProductOne.ID).
 			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
 
 		err := repo.DeleteProduct(ctx, testProductOne.ID)
 		assert.Error(t, err)
@@ -342,12 +912,324 @@ func TestDeleteProduct(t *testing.T) {
 
 	t.Run("should return error if rows affected fails", func(t *testing.T) {
 		dbErr := errors.New("rows affected error")
+		mock.ExpectBegin()
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec(deleteQuery).
 			WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewErrorResult(dbErr))
+		mock.ExpectRollback()
 
 		err := repo.DeleteProduct(ctx, testProductOne.ID)
 		assert.Error(t, err)
 		expectedErrMsg := "deleteProduct: failed to get rows affected: rows affected error"
 		assert.Equal(t, expectedErrMsg, err.Error())
 	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteProductReturning(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	selectQuery := regexp.QuoteMeta(`
+		SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
+		FROM products
+		WHERE id = $1
+		FOR UPDATE`)
+	relationsQuery := regexp.QuoteMeta(`DELETE FROM product_relations WHERE product_id = $1 OR related_product_id = $1`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM products WHERE id = $1`)
+	columns := []string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+
+	t.Run("should delete and return product in one transaction", func(t *testing.T) {
+		mockRows := sqlmock.NewRows(columns).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(mockRows)
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		product, err := repo.DeleteProductReturning(ctx, testProductOne.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &testProductOne, product)
+	})
+
+	t.Run("should return not found and roll back if no row", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		product, err := repo.DeleteProductReturning(ctx, testProductOne.ID)
+		assert.Nil(t, product)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		expectedErrMsg := "deleteProductReturning: not found: id `f2aa335f-6f91-4d4d-8057-53b0009bc376`"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	t.Run("should roll back if commit fails", func(t *testing.T) {
+		mockRows := sqlmock.NewRows(columns).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(mockRows)
+		mock.ExpectExec(relationsQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(deleteQuery).WithArgs(testProductOne.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit().WillReturnError(errors.New("commit error"))
+
+		product, err := repo.DeleteProductReturning(ctx, testProductOne.ID)
+		assert.Nil(t, product)
+		expectedErrMsg := "deleteProductReturning: commit failed: commit error"
+		assert.Equal(t, expectedErrMsg, err.Error())
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListProductsStatusFilter(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	var createdAfter time.Time
+
+	selectQuery := "^" + regexp.QuoteMeta(
+		`SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at FROM products `+
+			`WHERE created_at > $1 AND status = $2 ORDER BY created_at ASC, id ASC LIMIT $3`,
+	) + "$"
+	mock.ExpectQuery(selectQuery).WithArgs(createdAfter, ProductDraft, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	products, err := repo.ListProducts(context.Background(), ProductFilter{Status: ProductDraft}, createdAfter, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Product{}, products)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateProductStatus(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	selectQuery := regexp.QuoteMeta(`
+		SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
+		FROM products
+		WHERE id = $1
+		FOR UPDATE`)
+	updateQuery := regexp.QuoteMeta(`UPDATE products SET status = $1 WHERE id = $2`)
+	columns := []string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+	rowWithStatus := func(status ProductStatus) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+	}
+
+	t.Run("should update status in one transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductActive))
+		mock.ExpectExec(updateQuery).WithArgs(ProductDiscontinued, testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		product, err := repo.UpdateProductStatus(ctx, testProductOne.ID, ProductDiscontinued)
+		assert.NoError(t, err)
+		assert.Equal(t, ProductDiscontinued, product.Status)
+	})
+
+	t.Run("should skip the update if status is unchanged", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductActive))
+		mock.ExpectCommit()
+
+		product, err := repo.UpdateProductStatus(ctx, testProductOne.ID, ProductActive)
+		assert.NoError(t, err)
+		assert.Equal(t, ProductActive, product.Status)
+	})
+
+	t.Run("should roll back an invalid transition", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductDraft))
+		mock.ExpectRollback()
+
+		product, err := repo.UpdateProductStatus(ctx, testProductOne.ID, ProductDiscontinued)
+		assert.Nil(t, product)
+		assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+		assert.Equal(t, "updateProductStatus: cannot change status from draft to discontinued", err.Error())
+	})
+
+	t.Run("should return not found and roll back if no row", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		product, err := repo.UpdateProductStatus(ctx, testProductOne.ID, ProductActive)
+		assert.Nil(t, product)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateProductAttributes(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	repo := NewProductRepo(db)
+	ctx := context.Background()
+
+	updateQuery := regexp.QuoteMeta(`
+		UPDATE products SET attributes = $1 WHERE id = $2
+		RETURNING id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at`)
+	columns := []string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+
+	t.Run("should replace attributes and return the product", func(t *testing.T) {
+		mockRows := sqlmock.NewRows(columns).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, testProductOne.Status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+		mock.ExpectQuery(updateQuery).WithArgs([]byte(`{"color":"red"}`), testProductOne.ID).WillReturnRows(mockRows)
+
+		product, err := repo.UpdateProductAttributes(ctx, testProductOne.ID, testProductOne.Attributes)
+		assert.NoError(t, err)
+		assert.Equal(t, &testProductOne, product)
+	})
+
+	t.Run("should write nil attributes as an empty object", func(t *testing.T) {
+		mockRows := sqlmock.NewRows(columns).
+			AddRow(testProductTwo.ID, testProductTwo.Name, testProductTwo.Description, testProductTwo.ImageURL, testProductTwo.CategoryID, testProductTwo.Price, testProductTwo.Currency, testProductTwo.Quantity, testProductTwo.Weight, testProductTwo.Status, productAttributesJSON(testProductTwo.Attributes), productMetadataJSON(testProductTwo.Metadata), testProductTwo.CreatedAt)
+		mock.ExpectQuery(updateQuery).WithArgs([]byte(`{}`), testProductTwo.ID).WillReturnRows(mockRows)
+
+		product, err := repo.UpdateProductAttributes(ctx, testProductTwo.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, ProductAttributes{}, product.Attributes)
+	})
+
+	t.Run("should return not found if no row", func(t *testing.T) {
+		mock.ExpectQuery(updateQuery).WithArgs([]byte(`{}`), testProductOne.ID).WillReturnRows(sqlmock.NewRows(columns))
+
+		product, err := repo.UpdateProductAttributes(ctx, testProductOne.ID, ProductAttributes{})
+		assert.Nil(t, product)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("should return error if update query fails", func(t *testing.T) {
+		mock.ExpectQuery(updateQuery).WillReturnError(errors.New("query error"))
+
+		_, err := repo.UpdateProductAttributes(ctx, testProductOne.ID, ProductAttributes{})
+		assert.EqualError(t, err, "updateProductAttributes: update query failed: query error")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchProduct(t *testing.T) {
+	mockDB, mock, _ := sqlmock.New()
+	defer mockDB.Close()
+
+	repo := NewProductRepo(sqlx.NewDb(mockDB, "sqlmock"))
+	ctx := context.Background()
+
+	selectQuery := regexp.QuoteMeta(`
+		SELECT id, name, description, image_url, category_id, price, currency, quantity, weight, status, attributes, metadata, created_at
+		FROM products
+		WHERE id = $1
+		FOR UPDATE`)
+	updateQuery := regexp.QuoteMeta(`UPDATE products SET status = $1, attributes = $2 WHERE id = $3`)
+	columns := []string{"id", "name", "description", "image_url", "category_id", "price", "currency", "quantity", "weight", "status", "attributes", "metadata", "created_at"}
+	rowWithStatus := func(status ProductStatus) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(testProductOne.ID, testProductOne.Name, testProductOne.Description, testProductOne.ImageURL, testProductOne.CategoryID, testProductOne.Price, testProductOne.Currency, testProductOne.Quantity, testProductOne.Weight, status, productAttributesJSON(testProductOne.Attributes), productMetadataJSON(testProductOne.Metadata), testProductOne.CreatedAt)
+	}
+	discontinued := ProductDiscontinued
+	attributes := ProductAttributes{"color": "blue"}
+
+	t.Run("should update status and attributes in one transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductActive))
+		mock.ExpectExec(updateQuery).WithArgs(ProductDiscontinued, []byte(`{"color":"blue"}`), testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		product, err := repo.PatchProduct(ctx, testProductOne.ID, ProductPatch{Status: &discontinued, Attributes: &attributes})
+		require.NoError(t, err)
+		assert.Equal(t, ProductDiscontinued, product.Status)
+		assert.Equal(t, attributes, product.Attributes)
+	})
+
+	t.Run("should check the precondition against the locked row", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductActive))
+		mock.ExpectExec(updateQuery).WithArgs(ProductDiscontinued, []byte(`{"color":"red"}`), testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		var checked *Product
+		patch := ProductPatch{Status: &discontinued, Precondition: func(current *Product) error {
+			checked = current
+			return nil
+		}}
+		_, err := repo.PatchProduct(ctx, testProductOne.ID, patch)
+		require.NoError(t, err)
+		assert.Equal(t, &testProductOne, checked)
+	})
+
+	t.Run("should roll back if the precondition fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductActive))
+		mock.ExpectRollback()
+
+		patch := ProductPatch{Status: &discontinued, Precondition: func(*Product) error { return ErrPreconditionFailed }}
+		product, err := repo.PatchProduct(ctx, testProductOne.ID, patch)
+		assert.Nil(t, product)
+		assert.True(t, errors.Is(err, ErrPreconditionFailed))
+		assert.Equal(t, "patchProduct: precondition failed", err.Error())
+	})
+
+	t.Run("should roll back an invalid transition", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(rowWithStatus(ProductDraft))
+		mock.ExpectRollback()
+
+		_, err := repo.PatchProduct(ctx, testProductOne.ID, ProductPatch{Status: &discontinued, Attributes: &attributes})
+		assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+	})
+
+	t.Run("should return not found and roll back if no row", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+
+		_, err := repo.PatchProduct(ctx, testProductOne.ID, ProductPatch{Attributes: &attributes})
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("should return error without querying if patch is empty", func(t *testing.T) {
+		_, err := repo.PatchProduct(ctx, testProductOne.ID, ProductPatch{})
+		assert.True(t, errors.Is(err, ErrEmptyPatch))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from ProductStatus
+		to   ProductStatus
+		want bool
+	}{
+		{ProductDraft, ProductActive, true},
+		{ProductDraft, ProductDiscontinued, false},
+		{ProductActive, ProductDiscontinued, true},
+		{ProductActive, ProductDraft, false},
+		{ProductDiscontinued, ProductActive, true},
+		{ProductDiscontinued, ProductDraft, false},
+		{ProductActive, ProductActive, false},
+		{ProductActive, "archived", false},
+		{"archived", ProductActive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
 }
@@ -0,0 +1,94 @@
+package datalayer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder assembles a SELECT from a base query and optional WHERE,
+// ORDER BY and LIMIT clauses. Conditions use ? for their arguments, which
+// Build numbers as Postgres $n placeholders in the order they appear.
+type QueryBuilder struct {
+	base       string
+	conditions []string
+	args       []any
+	orderBy    string
+	limit      int
+	hasLimit   bool
+}
+
+// NewQueryBuilder starts a query from base, e.g. "SELECT id FROM products"
+func NewQueryBuilder(base string) *QueryBuilder {
+	return &QueryBuilder{base: base}
+}
+
+// Where adds a condition ANDed with any others. It panics if the number of
+// ? placeholders in condition does not match len(args).
+func (b *QueryBuilder) Where(condition string, args ...any) *QueryBuilder {
+	if n := strings.Count(condition, "?"); n != len(args) {
+		panic(fmt.Sprintf("QueryBuilder.Where: %q has %d placeholders but %d args", condition, n, len(args)))
+	}
+	b.conditions = append(b.conditions, condition)
+	b.args = append(b.args, args...)
+	return b
+}
+
+// WhereIn adds "column IN (...)" with one placeholder per value. With no
+// values it adds nothing, so the column is not filtered.
+func (b *QueryBuilder) WhereIn(column string, values ...any) *QueryBuilder {
+	if len(values) == 0 {
+		return b
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return b.Where(column+" IN ("+placeholders+")", values...)
+}
+
+// OrderBy sets the ORDER BY clause, replacing any previous one
+func (b *QueryBuilder) OrderBy(clause string) *QueryBuilder {
+	b.orderBy = clause
+	return b
+}
+
+// Limit sets the LIMIT, passed as an argument rather than inlined
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.limit = n
+	b.hasLimit = true
+	return b
+}
+
+// Build returns the query with numbered placeholders and its arguments
+func (b *QueryBuilder) Build() (string, []any) {
+	var sb strings.Builder
+	sb.WriteString(b.base)
+
+	args := make([]any, 0, len(b.args)+1)
+	args = append(args, b.args...)
+	next := 1
+
+	if len(b.conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		for i, condition := range b.conditions {
+			if i > 0 {
+				sb.WriteString(" AND ")
+			}
+			for _, r := range condition {
+				if r == '?' {
+					sb.WriteString("$" + strconv.Itoa(next))
+					next++
+					continue
+				}
+				sb.WriteRune(r)
+			}
+		}
+	}
+	if b.orderBy != "" {
+		sb.WriteString(" ORDER BY " + b.orderBy)
+	}
+	if b.hasLimit {
+		sb.WriteString(" LIMIT $" + strconv.Itoa(next))
+		args = append(args, b.limit)
+	}
+
+	return sb.String(), args
+}
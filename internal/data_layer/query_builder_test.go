@@ -0,0 +1,61 @@
+package datalayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	const base = "SELECT id FROM products"
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should return base query without conditions", func(t *testing.T) {
+		query, args := NewQueryBuilder(base).Build()
+		assert.Equal(t, base, query)
+		assert.Empty(t, args)
+	})
+
+	t.Run("should number a single condition and the limit", func(t *testing.T) {
+		query, args := NewQueryBuilder(base).
+			Where("created_at > ?", createdAt).
+			OrderBy("created_at ASC").
+			Limit(10).
+			Build()
+		assert.Equal(t, "SELECT id FROM products WHERE created_at > $1 ORDER BY created_at ASC LIMIT $2", query)
+		assert.Equal(t, []any{createdAt, 10}, args)
+	})
+
+	t.Run("should join multiple conditions with AND in order", func(t *testing.T) {
+		query, args := NewQueryBuilder(base).
+			Limit(5).
+			Where("created_at > ?", createdAt).
+			Where("price BETWEEN ? AND ?", 1.5, 9.5).
+			Where("deleted_at IS NULL").
+			Build()
+		assert.Equal(t, "SELECT id FROM products WHERE created_at > $1 AND price BETWEEN $2 AND $3 AND deleted_at IS NULL LIMIT $4", query)
+		assert.Equal(t, []any{createdAt, 1.5, 9.5, 5}, args)
+	})
+
+	t.Run("should add one placeholder per IN value and skip an empty list", func(t *testing.T) {
+		query, args := NewQueryBuilder(base).
+			Where("created_at > ?", createdAt).
+			WhereIn("category_id", "a", "b").
+			WhereIn("status").
+			Build()
+		assert.Equal(t, "SELECT id FROM products WHERE created_at > $1 AND category_id IN ($2, $3)", query)
+		assert.Equal(t, []any{createdAt, "a", "b"}, args)
+	})
+
+	t.Run("should replace order by clause", func(t *testing.T) {
+		query, _ := NewQueryBuilder(base).OrderBy("name").OrderBy("created_at DESC").Build()
+		assert.Equal(t, "SELECT id FROM products ORDER BY created_at DESC", query)
+	})
+
+	t.Run("should panic if placeholders and args differ", func(t *testing.T) {
+		assert.PanicsWithValue(t, `QueryBuilder.Where: "price > ?" has 1 placeholders but 0 args`, func() {
+			NewQueryBuilder(base).Where("price > ?")
+		})
+	})
+}
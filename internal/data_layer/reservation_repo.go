@@ -0,0 +1,212 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReservationStatus is the lifecycle state of a stock hold
+type ReservationStatus string
+
+const (
+	ReservationHeld      ReservationStatus = "held"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationCommitted ReservationStatus = "committed"
+)
+
+var (
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrReservationClosed  = errors.New("reservation is no longer held")
+	ErrReservationExpired = errors.New("reservation has expired")
+)
+
+// Reservation holds Quantity units of a product until ExpiresAt. Held stock
+// is already subtracted from the product's quantity.
+type Reservation struct {
+	ID        uuid.UUID         `db:"id" json:"id"`
+	ProductID uuid.UUID         `db:"product_id" json:"productId"`
+	Quantity  int               `db:"quantity" json:"quantity"`
+	ExpiresAt time.Time         `db:"expires_at" json:"expiresAt"`
+	Status    ReservationStatus `db:"status" json:"status"`
+	CreatedAt time.Time         `db:"created_at" json:"createdAt"`
+}
+
+type ReservationRepo struct {
+	db  *sqlx.DB
+	now func() time.Time
+}
+
+type ReservationRepoInterface interface {
+	CreateReservation(ctx context.Context, reservation *Reservation) error
+	ReleaseReservation(ctx context.Context, id uuid.UUID) (*Reservation, error)
+	CommitReservation(ctx context.Context, id uuid.UUID) (*Reservation, error)
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+}
+
+// NewReservationRepo creates a new repository instance
+func NewReservationRepo(db *sqlx.DB) ReservationRepoInterface {
+	return &ReservationRepo{db: db, now: time.Now}
+}
+
+const selectReservationForUpdate = `
+		SELECT id, product_id, quantity, expires_at, status, created_at
+		FROM reservations
+		WHERE id = $1
+		FOR UPDATE`
+
+// CreateReservation takes Quantity units of the product out of stock and
+// records the hold, failing with ErrInsufficientStock if too few remain.
+// ID, Status and CreatedAt are set on reservation.
+func (r *ReservationRepo) CreateReservation(ctx context.Context, reservation *Reservation) error {
+	const op = "createReservation"
+	const selectQuery = `SELECT quantity FROM products WHERE id = $1 FOR UPDATE`
+	const updateQuery = `UPDATE products SET quantity = quantity - $1 WHERE id = $2`
+	const insertQuery = `
+		INSERT INTO reservations(id, product_id, quantity, expires_at, status, created_at)
+		VALUES(:id, :product_id, :quantity, :expires_at, :status, :created_at)`
+
+	if reservation.ID == uuid.Nil {
+		reservation.ID = uuid.New()
+	}
+	reservation.Status = ReservationHeld
+	reservation.CreatedAt = r.now()
+	toUTC(&reservation.ExpiresAt, &reservation.CreatedAt)
+
+	return withTx(ctx, r.db, op, EntityReservation, func(tx *sqlx.Tx) error {
+		var available int
+		if err := tx.GetContext(ctx, &available, selectQuery, reservation.ProductID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repoError(op, EntityReservation, fmt.Errorf("%w: product id `%s`", ErrNotFound, reservation.ProductID))
+			}
+			return repoError(op, EntityReservation, fmt.Errorf("select query failed: %w", err))
+		}
+		if available < reservation.Quantity {
+			return repoError(op, EntityReservation, fmt.Errorf("%w: %d requested, %d available", ErrInsufficientStock, reservation.Quantity, available))
+		}
+
+		if _, err := tx.ExecContext(ctx, updateQuery, reservation.Quantity, reservation.ProductID); err != nil {
+			return repoError(op, EntityReservation, fmt.Errorf("update query failed: %w", err))
+		}
+		result, err := tx.NamedExecContext(ctx, insertQuery, reservation)
+		if err != nil {
+			return repoError(op, EntityReservation, fmt.Errorf("insert query failed: %w", err))
+		}
+		return checkRowsAffected(result, op, EntityReservation)
+	})
+}
+
+// ReleaseReservation returns a held reservation's stock to the product
+func (r *ReservationRepo) ReleaseReservation(ctx context.Context, id uuid.UUID) (*Reservation, error) {
+	const op = "releaseReservation"
+
+	var reservation Reservation
+	err := withTx(ctx, r.db, op, EntityReservation, func(tx *sqlx.Tx) error {
+		if err := lockHeld(ctx, tx, op, id, &reservation); err != nil {
+			return err
+		}
+		return releaseHeld(ctx, tx, op, &reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// CommitReservation finalizes a held reservation so its stock stays
+// removed. Expired holds can no longer be committed.
+func (r *ReservationRepo) CommitReservation(ctx context.Context, id uuid.UUID) (*Reservation, error) {
+	const op = "commitReservation"
+	const updateQuery = `UPDATE reservations SET status = $1 WHERE id = $2`
+
+	var reservation Reservation
+	err := withTx(ctx, r.db, op, EntityReservation, func(tx *sqlx.Tx) error {
+		if err := lockHeld(ctx, tx, op, id, &reservation); err != nil {
+			return err
+		}
+		if !reservation.ExpiresAt.After(r.now()) {
+			return repoError(op, EntityReservation, fmt.Errorf("%w: id `%s`", ErrReservationExpired, id))
+		}
+
+		result, err := tx.ExecContext(ctx, updateQuery, ReservationCommitted, id)
+		if err != nil {
+			return repoError(op, EntityReservation, fmt.Errorf("update query failed: %w", err))
+		}
+		reservation.Status = ReservationCommitted
+		return checkRowsAffected(result, op, EntityReservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// ReleaseExpiredReservations releases every held reservation past its
+// expiry and returns how many were released. Rows locked by a concurrent
+// release or commit are skipped and picked up on the next run.
+func (r *ReservationRepo) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	const op = "releaseExpiredReservations"
+	const selectQuery = `
+		SELECT id, product_id, quantity, expires_at, status, created_at
+		FROM reservations
+		WHERE status = $1 AND expires_at <= $2
+		FOR UPDATE SKIP LOCKED`
+
+	var released int
+	err := withTx(ctx, r.db, op, EntityReservation, func(tx *sqlx.Tx) error {
+		var expired []Reservation
+		if err := tx.SelectContext(ctx, &expired, selectQuery, ReservationHeld, r.now().UTC()); err != nil {
+			return repoError(op, EntityReservation, fmt.Errorf("select query failed: %w", err))
+		}
+		for i := range expired {
+			if err := releaseHeld(ctx, tx, op, &expired[i]); err != nil {
+				return err
+			}
+		}
+		released = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return released, nil
+}
+
+// lockHeld loads and locks a reservation, failing unless it is still held
+func lockHeld(ctx context.Context, tx *sqlx.Tx, op string, id uuid.UUID, reservation *Reservation) error {
+	if err := tx.GetContext(ctx, reservation, selectReservationForUpdate, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repoError(op, EntityReservation, fmt.Errorf("%w: id `%s`", ErrNotFound, id))
+		}
+		return repoError(op, EntityReservation, fmt.Errorf("select query failed: %w", err))
+	}
+	toUTC(&reservation.ExpiresAt, &reservation.CreatedAt)
+	if reservation.Status != ReservationHeld {
+		return repoError(op, EntityReservation, fmt.Errorf("%w: id `%s` is %s", ErrReservationClosed, id, reservation.Status))
+	}
+	return nil
+}
+
+// releaseHeld puts a locked reservation's stock back and marks it released
+func releaseHeld(ctx context.Context, tx *sqlx.Tx, op string, reservation *Reservation) error {
+	const stockQuery = `UPDATE products SET quantity = quantity + $1 WHERE id = $2`
+	const statusQuery = `UPDATE reservations SET status = $1 WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, stockQuery, reservation.Quantity, reservation.ProductID); err != nil {
+		return repoError(op, EntityReservation, fmt.Errorf("update query failed: %w", err))
+	}
+	result, err := tx.ExecContext(ctx, statusQuery, ReservationReleased, reservation.ID)
+	if err != nil {
+		return repoError(op, EntityReservation, fmt.Errorf("update query failed: %w", err))
+	}
+	reservation.Status = ReservationReleased
+	return checkRowsAffected(result, op, EntityReservation)
+}
@@ -0,0 +1,190 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testReservation = Reservation{
+	ID:        uuid.MustParse("0d9d7b4a-3a7e-4f4c-9d65-1b1f8a4c2e11"),
+	ProductID: testProductOne.ID,
+	Quantity:  2,
+	ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC),
+	Status:    ReservationHeld,
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var reservationColumns = []string{"id", "product_id", "quantity", "expires_at", "status", "created_at"}
+
+func reservationRow(reservation Reservation) *sqlmock.Rows {
+	return sqlmock.NewRows(reservationColumns).AddRow(
+		reservation.ID, reservation.ProductID, reservation.Quantity,
+		reservation.ExpiresAt, reservation.Status, reservation.CreatedAt,
+	)
+}
+
+func newTestReservationRepo(t *testing.T, now time.Time) (*ReservationRepo, sqlmock.Sqlmock) {
+	mockDB, mock, _ := sqlmock.New()
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	return &ReservationRepo{db: db, now: func() time.Time { return now }}, mock
+}
+
+func TestCreateReservation(t *testing.T) {
+	ctx := context.Background()
+	now := testReservation.CreatedAt
+	selectQuery := regexp.QuoteMeta(`SELECT quantity FROM products WHERE id = $1 FOR UPDATE`)
+	updateQuery := regexp.QuoteMeta(`UPDATE products SET quantity = quantity - $1 WHERE id = $2`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO reservations(id, product_id, quantity, expires_at, status, created_at)`)
+
+	t.Run("should lock product, decrement stock and insert hold in one transaction", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, now)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(5))
+		mock.ExpectExec(updateQuery).WithArgs(2, testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).
+			WithArgs(testReservation.ID, testProductOne.ID, 2, testReservation.ExpiresAt, ReservationHeld, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		reservation := &Reservation{ID: testReservation.ID, ProductID: testProductOne.ID, Quantity: 2, ExpiresAt: testReservation.ExpiresAt}
+		err := repo.CreateReservation(ctx, reservation)
+		assert.NoError(t, err)
+		assert.Equal(t, &testReservation, reservation)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if stock is insufficient", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, now)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+		mock.ExpectRollback()
+
+		err := repo.CreateReservation(ctx, &Reservation{ProductID: testProductOne.ID, Quantity: 2})
+		assert.True(t, errors.Is(err, ErrInsufficientStock))
+		assert.Equal(t, "createReservation: insufficient stock: 2 requested, 1 available", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found if product is missing", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, now)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testProductOne.ID).WillReturnRows(sqlmock.NewRows([]string{"quantity"}))
+		mock.ExpectRollback()
+
+		err := repo.CreateReservation(ctx, &Reservation{ProductID: testProductOne.ID, Quantity: 1})
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.Equal(t, "createReservation: not found: product id `f2aa335f-6f91-4d4d-8057-53b0009bc376`", err.Error())
+	})
+}
+
+func TestReleaseReservation(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(selectReservationForUpdate)
+	stockQuery := regexp.QuoteMeta(`UPDATE products SET quantity = quantity + $1 WHERE id = $2`)
+	statusQuery := regexp.QuoteMeta(`UPDATE reservations SET status = $1 WHERE id = $2`)
+
+	t.Run("should return stock and mark reservation released", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, testReservation.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testReservation.ID).WillReturnRows(reservationRow(testReservation))
+		mock.ExpectExec(stockQuery).WithArgs(2, testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(statusQuery).WithArgs(ReservationReleased, testReservation.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		reservation, err := repo.ReleaseReservation(ctx, testReservation.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, ReservationReleased, reservation.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to release a committed reservation", func(t *testing.T) {
+		committed := testReservation
+		committed.Status = ReservationCommitted
+		repo, mock := newTestReservationRepo(t, testReservation.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testReservation.ID).WillReturnRows(reservationRow(committed))
+		mock.ExpectRollback()
+
+		reservation, err := repo.ReleaseReservation(ctx, testReservation.ID)
+		assert.Nil(t, reservation)
+		assert.True(t, errors.Is(err, ErrReservationClosed))
+		assert.Equal(t, "releaseReservation: reservation is no longer held: id `0d9d7b4a-3a7e-4f4c-9d65-1b1f8a4c2e11` is committed", err.Error())
+	})
+}
+
+func TestCommitReservation(t *testing.T) {
+	ctx := context.Background()
+	selectQuery := regexp.QuoteMeta(selectReservationForUpdate)
+	statusQuery := regexp.QuoteMeta(`UPDATE reservations SET status = $1 WHERE id = $2`)
+
+	t.Run("should mark held reservation committed", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, testReservation.CreatedAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testReservation.ID).WillReturnRows(reservationRow(testReservation))
+		mock.ExpectExec(statusQuery).WithArgs(ReservationCommitted, testReservation.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		reservation, err := repo.CommitReservation(ctx, testReservation.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, ReservationCommitted, reservation.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse to commit an expired reservation", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, testReservation.ExpiresAt)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(testReservation.ID).WillReturnRows(reservationRow(testReservation))
+		mock.ExpectRollback()
+
+		reservation, err := repo.CommitReservation(ctx, testReservation.ID)
+		assert.Nil(t, reservation)
+		assert.True(t, errors.Is(err, ErrReservationExpired))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReleaseExpiredReservations(t *testing.T) {
+	ctx := context.Background()
+	now := testReservation.ExpiresAt.Add(time.Minute)
+	selectQuery := regexp.QuoteMeta(`WHERE status = $1 AND expires_at <= $2
+		FOR UPDATE SKIP LOCKED`)
+
+	t.Run("should release every expired hold", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, now)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WithArgs(ReservationHeld, now).WillReturnRows(reservationRow(testReservation))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE products SET quantity = quantity + $1 WHERE id = $2`)).
+			WithArgs(2, testProductOne.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE reservations SET status = $1 WHERE id = $2`)).
+			WithArgs(ReservationReleased, testReservation.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		released, err := repo.ReleaseExpiredReservations(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, released)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back if select fails", func(t *testing.T) {
+		repo, mock := newTestReservationRepo(t, now)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).WillReturnError(errors.New("query error"))
+		mock.ExpectRollback()
+
+		released, err := repo.ReleaseExpiredReservations(ctx)
+		assert.Zero(t, released)
+		assert.Equal(t, "releaseExpiredReservations: select query failed: query error", err.Error())
+	})
+}
@@ -0,0 +1,108 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrWalkLimitExceeded is returned when a page walk reads more pages or rows
+// than its WalkLimits allow, which usually means the cursor stopped advancing
+var ErrWalkLimitExceeded = errors.New("page walk limit exceeded")
+
+// WalkLimits caps how far WalkCategories and WalkProducts iterate. A zero
+// field means no cap.
+type WalkLimits struct {
+	MaxPages int
+	MaxRows  int
+}
+
+// DefaultWalkLimits allow walking a catalog of a million rows in maximum
+// sized pages
+var DefaultWalkLimits = WalkLimits{MaxPages: 10000, MaxRows: 1000000}
+
+// walkGuard counts pages and rows against the limits of a single walk
+type walkGuard struct {
+	op     string
+	limits WalkLimits
+	pages  int
+	rows   int
+}
+
+func (g *walkGuard) addPage(rows int) error {
+	g.pages++
+	g.rows += rows
+	if g.limits.MaxPages > 0 && g.pages > g.limits.MaxPages {
+		return fmt.Errorf("%s: %w: more than %d pages", g.op, ErrWalkLimitExceeded, g.limits.MaxPages)
+	}
+	if g.limits.MaxRows > 0 && g.rows > g.limits.MaxRows {
+		return fmt.Errorf("%s: %w: more than %d rows", g.op, ErrWalkLimitExceeded, g.limits.MaxRows)
+	}
+	return nil
+}
+
+// WalkCategories calls fn for every category in (created_at, id) order,
+// fetching pageSize rows at a time. It stops with ErrWalkLimitExceeded before
+// calling fn for a page that would break limits.
+func WalkCategories(
+	ctx context.Context,
+	repo CategoryRepoInterface,
+	pageSize int,
+	limits WalkLimits,
+	fn func(*Category) error,
+) error {
+	guard := &walkGuard{op: "walkCategories", limits: limits}
+	var cursor Cursor
+	for {
+		filter := CategoryFilter{AfterID: cursor.AfterID}
+		page, err := repo.ListCategories(ctx, filter, cursor.CreatedAfter, pageSize)
+		if err != nil {
+			return fmt.Errorf("walkCategories: %w", err)
+		}
+		if err := guard.addPage(len(page.Categories)); err != nil {
+			return err
+		}
+		for _, category := range page.Categories {
+			if err := fn(category); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore || len(page.Categories) == 0 {
+			return nil
+		}
+		cursor = NextCursor(cursor, page.Categories, CategoryKey, 0)
+	}
+}
+
+// WalkProducts calls fn for every product in (created_at, id) order,
+// fetching pageSize rows at a time. It stops with ErrWalkLimitExceeded before
+// calling fn for a page that would break limits.
+func WalkProducts(
+	ctx context.Context,
+	repo ProductRepoInterface,
+	pageSize int,
+	limits WalkLimits,
+	fn func(*Product) error,
+) error {
+	guard := &walkGuard{op: "walkProducts", limits: limits}
+	var cursor Cursor
+	for {
+		filter := ProductFilter{AfterID: cursor.AfterID}
+		products, err := repo.ListProducts(ctx, filter, cursor.CreatedAfter, pageSize)
+		if err != nil {
+			return fmt.Errorf("walkProducts: %w", err)
+		}
+		if len(products) == 0 {
+			return nil
+		}
+		if err := guard.addPage(len(products)); err != nil {
+			return err
+		}
+		for _, product := range products {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+		cursor = NextCursor(cursor, products, ProductKey, 0)
+	}
+}
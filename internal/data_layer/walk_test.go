@@ -0,0 +1,199 @@
+package datalayer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkCategories(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should visit every category once", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 25; i++ {
+			category := &datalayer.Category{Name: fmt.Sprintf("Category %02d", i), CreatedAt: base.Add(time.Duration(i) * time.Second)}
+			require.NoError(t, repo.CreateCategory(ctx, category))
+		}
+
+		seen := map[uuid.UUID]bool{}
+		err := datalayer.WalkCategories(ctx, repo, 10, datalayer.DefaultWalkLimits, func(category *datalayer.Category) error {
+			assert.False(t, seen[category.ID], "category visited twice")
+			seen[category.ID] = true
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, seen, 25)
+	})
+
+	t.Run("should visit categories sharing a created_at across pages once", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 25; i++ {
+			category := &datalayer.Category{Name: fmt.Sprintf("Category %02d", i), CreatedAt: createdAt}
+			require.NoError(t, repo.CreateCategory(ctx, category))
+		}
+
+		seen := map[uuid.UUID]bool{}
+		err := datalayer.WalkCategories(ctx, repo, 10, datalayer.DefaultWalkLimits, func(category *datalayer.Category) error {
+			assert.False(t, seen[category.ID], "category visited twice")
+			seen[category.ID] = true
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, seen, 25)
+	})
+
+	t.Run("should stop when the cursor does not advance", func(t *testing.T) {
+		category := &datalayer.Category{ID: uuid.New(), CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		calls := 0
+		repo := &mocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+				calls++
+				return &datalayer.CategoryPage{
+					Categories: []*datalayer.Category{category},
+					NextCursor: category.CreatedAt,
+					HasMore:    true,
+				}, nil
+			},
+		}
+
+		err := walkWithTimeout(t, func() error {
+			return datalayer.WalkCategories(ctx, repo, 1, datalayer.WalkLimits{MaxPages: 5}, func(*datalayer.Category) error {
+				return nil
+			})
+		})
+		assert.True(t, errors.Is(err, datalayer.ErrWalkLimitExceeded))
+		assert.EqualError(t, err, "walkCategories: page walk limit exceeded: more than 5 pages")
+		assert.Equal(t, 6, calls)
+	})
+
+	t.Run("should stop when rows exceed the cap", func(t *testing.T) {
+		repo := &mocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(_ context.Context, _ datalayer.CategoryFilter, createdAfter time.Time, _ int) (*datalayer.CategoryPage, error) {
+				next := createdAfter.Add(time.Second)
+				return &datalayer.CategoryPage{
+					Categories: []*datalayer.Category{{ID: uuid.New(), CreatedAt: next}, {ID: uuid.New(), CreatedAt: next}},
+					NextCursor: next,
+					HasMore:    true,
+				}, nil
+			},
+		}
+
+		visited := 0
+		err := walkWithTimeout(t, func() error {
+			return datalayer.WalkCategories(ctx, repo, 2, datalayer.WalkLimits{MaxRows: 5}, func(*datalayer.Category) error {
+				visited++
+				return nil
+			})
+		})
+		assert.EqualError(t, err, "walkCategories: page walk limit exceeded: more than 5 rows")
+		assert.Equal(t, 4, visited)
+	})
+}
+
+func TestWalkProducts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should visit products sharing a created_at across pages once", func(t *testing.T) {
+		repo := datalayer.NewMemoryProductRepo()
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 25; i++ {
+			product := &datalayer.Product{
+				ID:        uuid.New(),
+				Name:      fmt.Sprintf("Product %02d", i),
+				Status:    datalayer.ProductDraft,
+				CreatedAt: base.Add(time.Duration(i/10) * time.Second),
+			}
+			require.NoError(t, repo.CreateProduct(ctx, product))
+		}
+
+		seen := map[uuid.UUID]bool{}
+		err := datalayer.WalkProducts(ctx, repo, 7, datalayer.DefaultWalkLimits, func(product *datalayer.Product) error {
+			assert.False(t, seen[product.ID], "product visited twice")
+			seen[product.ID] = true
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, seen, 25)
+	})
+
+	t.Run("should resume after the created_at and id of the last row visited", func(t *testing.T) {
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		first := &datalayer.Product{ID: uuid.New(), CreatedAt: createdAt}
+		var filters []datalayer.ProductFilter
+		var bounds []time.Time
+		repo := &mocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, createdAfter time.Time, _ int) ([]*datalayer.Product, error) {
+				filters = append(filters, filter)
+				bounds = append(bounds, createdAfter)
+				if len(filters) == 1 {
+					return []*datalayer.Product{first}, nil
+				}
+				return []*datalayer.Product{}, nil
+			},
+		}
+
+		err := datalayer.WalkProducts(ctx, repo, 1, datalayer.DefaultWalkLimits, func(*datalayer.Product) error {
+			return nil
+		})
+		assert.NoError(t, err)
+		require.Len(t, filters, 2)
+		assert.Equal(t, uuid.Nil, filters[0].AfterID)
+		assert.Equal(t, first.ID, filters[1].AfterID)
+		assert.Equal(t, createdAt, bounds[1])
+	})
+
+	t.Run("should stop when the cursor does not advance", func(t *testing.T) {
+		product := &datalayer.Product{ID: uuid.New(), CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		repo := &mocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(context.Context, datalayer.ProductFilter, time.Time, int) ([]*datalayer.Product, error) {
+				return []*datalayer.Product{product}, nil
+			},
+		}
+
+		err := walkWithTimeout(t, func() error {
+			return datalayer.WalkProducts(ctx, repo, 1, datalayer.WalkLimits{MaxPages: 3}, func(*datalayer.Product) error {
+				return nil
+			})
+		})
+		assert.EqualError(t, err, "walkProducts: page walk limit exceeded: more than 3 pages")
+	})
+
+	t.Run("should return repo errors", func(t *testing.T) {
+		repo := &mocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(context.Context, datalayer.ProductFilter, time.Time, int) ([]*datalayer.Product, error) {
+				return nil, errors.New("listProducts: select query failed: query error")
+			},
+		}
+
+		err := datalayer.WalkProducts(ctx, repo, 10, datalayer.DefaultWalkLimits, func(*datalayer.Product) error {
+			return nil
+		})
+		assert.EqualError(t, err, "walkProducts: listProducts: select query failed: query error")
+	})
+}
+
+// walkWithTimeout fails the test instead of hanging if walk never returns
+func walkWithTimeout(t *testing.T, walk func() error) error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- walk() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("page walk did not stop")
+		return nil
+	}
+}
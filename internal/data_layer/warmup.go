@@ -0,0 +1,53 @@
+package datalayer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// warmupQueries returns the statements of the hottest reads: getting a
+// product or category by id and listing their first page
+func warmupQueries() []string {
+	listProducts, _ := listProductsQuery(ProductFilter{}, time.Time{}, defaultLimit)
+	listCategories, _ := listCategoriesQuery(CategoryFilter{}, time.Time{}, defaultLimit+1)
+	return []string{getProductQuery, getCategoryQuery, listProducts, listCategories}
+}
+
+// Warmup opens conns connections to db and prepares the hottest queries on
+// each, so the first requests after a cold start neither dial the database
+// nor wait for it to load the tables' metadata. The connections go back to
+// the pool idle, where they stay as long as db keeps at least conns idle
+// connections. Zero conns disables the warmup.
+func Warmup(ctx context.Context, db *sqlx.DB, conns int) error {
+	const op = "warmup"
+
+	// holding every connection until the end makes each one a new one
+	held := make([]*sql.Conn, 0, conns)
+	defer func() {
+		for _, conn := range held {
+			_ = conn.Close()
+		}
+	}()
+
+	queries := warmupQueries()
+	for range conns {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: open connection failed: %w", op, err)
+		}
+		held = append(held, conn)
+
+		for _, query := range queries {
+			stmt, err := conn.PrepareContext(ctx, query)
+			if err != nil {
+				return fmt.Errorf("%s: prepare failed: %w", op, err)
+			}
+			_ = stmt.Close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package datalayer
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	ctx := context.Background()
+
+	newDB := func(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { mockDB.Close() })
+		return sqlx.NewDb(mockDB, "sqlmock"), mock
+	}
+
+	t.Run("should leave warmed up connections idle in the pool", func(t *testing.T) {
+		db, mock := newDB(t)
+		db.SetMaxIdleConns(3)
+		for range 3 {
+			for _, query := range warmupQueries() {
+				mock.ExpectPrepare(regexp.QuoteMeta(query)).WillBeClosed()
+			}
+		}
+
+		require.NoError(t, Warmup(ctx, db, 3))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, 3, db.Stats().Idle)
+	})
+
+	t.Run("should do nothing when disabled", func(t *testing.T) {
+		db, mock := newDB(t)
+		before := db.Stats().OpenConnections
+
+		require.NoError(t, Warmup(ctx, db, 0))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, before, db.Stats().OpenConnections)
+	})
+
+	t.Run("should return error if a statement fails to prepare", func(t *testing.T) {
+		db, mock := newDB(t)
+		mock.ExpectPrepare(regexp.QuoteMeta(getProductQuery)).WillReturnError(errors.New("relation \"products\" does not exist"))
+
+		err := Warmup(ctx, db, 2)
+		assert.EqualError(t, err, `warmup: prepare failed: relation "products" does not exist`)
+	})
+}
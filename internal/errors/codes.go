@@ -0,0 +1,170 @@
+// Package apierrors is the registry of error codes returned in the error
+// envelope. Codes are grouped by their first two digits: 10xx request
+// validation, 12xx authorization, 13xx missing resources, 14xx conflicts
+// with the current state and 16xx server failures.
+package apierrors
+
+import "net/http"
+
+const (
+	ErrCodeValidationFailed    = 1001
+	ErrCodeInvalidFieldFormat  = 1002
+	ErrCodeUnsupportedMedia    = 1004
+	ErrCodeMethodNotAllowed    = 1005
+	ErrCodeUnsupportedCurrency = 1006
+	ErrCodeForbidden           = 1200
+	ErrCodeResourceNotFound    = 1300
+	ErrCodeRouteNotFound       = 1301
+	ErrCodeInsufficientStock   = 1401
+	ErrCodeReservationClosed   = 1402
+	ErrCodeInvalidTransition   = 1403
+	ErrCodeScheduleConflict    = 1404
+	ErrCodeScheduleApplied     = 1405
+	ErrCodeAttributeExists     = 1406
+	ErrCodePreconditionFailed  = 1407
+	ErrCodeInternalServerError = 1600
+	ErrCodeServerBusy          = 1601
+	ErrCodeDatabaseUnavailable = 1602
+	ErrCodeMaintenance         = 1603
+	ErrCodeTooManyStreams      = 1604
+)
+
+// ErrorCodeInfo documents an error code. UserMessage is the default message
+// sent to clients; handlers may send a more specific one. DevNote explains
+// when the code is used.
+type ErrorCodeInfo struct {
+	Code        int
+	HTTPStatus  int
+	UserMessage string
+	DevNote     string
+}
+
+// ErrorCodeRegistry holds every error code the API returns
+var ErrorCodeRegistry = map[int]ErrorCodeInfo{
+	ErrCodeValidationFailed: {
+		Code:        ErrCodeValidationFailed,
+		HTTPStatus:  http.StatusUnprocessableEntity,
+		UserMessage: "validation failed",
+		DevNote:     "One or more body fields or batch items were rejected; the errors array lists each one.",
+	},
+	ErrCodeInvalidFieldFormat: {
+		Code:        ErrCodeInvalidFieldFormat,
+		HTTPStatus:  http.StatusBadRequest,
+		UserMessage: "invalid request",
+		DevNote:     "A path parameter, query parameter or body field is malformed or out of range.",
+	},
+	ErrCodeUnsupportedMedia: {
+		Code:        ErrCodeUnsupportedMedia,
+		HTTPStatus:  http.StatusUnsupportedMediaType,
+		UserMessage: "Content-Type must be application/json",
+		DevNote:     "A request with a body did not declare it as application/json.",
+	},
+	ErrCodeMethodNotAllowed: {
+		Code:        ErrCodeMethodNotAllowed,
+		HTTPStatus:  http.StatusMethodNotAllowed,
+		UserMessage: "method not allowed",
+		DevNote:     "The path exists but has no route for the request method; the Allow header and the message list the methods it has.",
+	},
+	ErrCodeUnsupportedCurrency: {
+		Code:        ErrCodeUnsupportedCurrency,
+		HTTPStatus:  http.StatusUnprocessableEntity,
+		UserMessage: "unsupported currency",
+		DevNote:     "A product currency is not an active ISO 4217 code; codes are three upper case letters such as USD or EUR.",
+	},
+	ErrCodeForbidden: {
+		Code:        ErrCodeForbidden,
+		HTTPStatus:  http.StatusForbidden,
+		UserMessage: "admin role required",
+		DevNote:     "The request needs the admin role, granted by the ADMIN_TOKEN bearer token.",
+	},
+	ErrCodeResourceNotFound: {
+		Code:        ErrCodeResourceNotFound,
+		HTTPStatus:  http.StatusNotFound,
+		UserMessage: "resource not found",
+		DevNote:     "The repository returned ErrNotFound for the requested ID.",
+	},
+	ErrCodeRouteNotFound: {
+		Code:        ErrCodeRouteNotFound,
+		HTTPStatus:  http.StatusNotFound,
+		UserMessage: "route not found",
+		DevNote:     "No route matches the request path for any method; a path with routes for other methods gets ErrCodeMethodNotAllowed instead.",
+	},
+	ErrCodeInsufficientStock: {
+		Code:        ErrCodeInsufficientStock,
+		HTTPStatus:  http.StatusConflict,
+		UserMessage: "insufficient stock",
+		DevNote:     "A reservation or stock adjustment would take a product's quantity below zero.",
+	},
+	ErrCodeReservationClosed: {
+		Code:        ErrCodeReservationClosed,
+		HTTPStatus:  http.StatusConflict,
+		UserMessage: "reservation is no longer held",
+		DevNote:     "The reservation was already released, committed or has expired.",
+	},
+	ErrCodeInvalidTransition: {
+		Code:        ErrCodeInvalidTransition,
+		HTTPStatus:  http.StatusConflict,
+		UserMessage: "invalid status transition",
+		DevNote:     "A product status change is not one of the allowed transitions; the message names both statuses.",
+	},
+	ErrCodeScheduleConflict: {
+		Code:        ErrCodeScheduleConflict,
+		HTTPStatus:  http.StatusConflict,
+		UserMessage: "a price change is already scheduled for that time",
+		DevNote:     "The product already has a price schedule with the same effectiveAt.",
+	},
+	ErrCodeScheduleApplied: {
+		Code:        ErrCodeScheduleApplied,
+		HTTPStatus:  http.StatusConflict,
+		UserMessage: "price schedule has already been applied",
+		DevNote:     "Applied schedules are kept as history and cannot be cancelled.",
+	},
+	ErrCodeAttributeExists: {
+		Code:        ErrCodeAttributeExists,
+		HTTPStatus:  http.StatusConflict,
+		UserMessage: "attribute is already defined for the category",
+		DevNote:     "The category already has an attribute definition with the requested name.",
+	},
+	ErrCodePreconditionFailed: {
+		Code:        ErrCodePreconditionFailed,
+		HTTPStatus:  http.StatusPreconditionFailed,
+		UserMessage: "resource has changed since it was read",
+		DevNote:     "The If-Match header of an update does not list the resource's current ETag, as returned by its GET.",
+	},
+	ErrCodeInternalServerError: {
+		Code:        ErrCodeInternalServerError,
+		HTTPStatus:  http.StatusInternalServerError,
+		UserMessage: "internal server error",
+		DevNote:     "An unexpected failure; the cause is logged under the request's op and never sent to clients.",
+	},
+	ErrCodeServerBusy: {
+		Code:        ErrCodeServerBusy,
+		HTTPStatus:  http.StatusServiceUnavailable,
+		UserMessage: "server is busy, retry later",
+		DevNote:     "The MAX_IN_FLIGHT limit on concurrent requests was reached; Retry-After says when to try again.",
+	},
+	ErrCodeDatabaseUnavailable: {
+		Code:        ErrCodeDatabaseUnavailable,
+		HTTPStatus:  http.StatusServiceUnavailable,
+		UserMessage: "database unavailable",
+		DevNote:     "The readiness check could not query the database, whose cause is logged, or the database circuit breaker is open after repeated failures.",
+	},
+	ErrCodeMaintenance: {
+		Code:        ErrCodeMaintenance,
+		HTTPStatus:  http.StatusServiceUnavailable,
+		UserMessage: "the API is in maintenance mode and only serves reads, retry later",
+		DevNote:     "An admin turned on maintenance mode with PUT /maintenance, so POST, PUT, PATCH and DELETE are refused; Retry-After says when to try again.",
+	},
+	ErrCodeTooManyStreams: {
+		Code:        ErrCodeTooManyStreams,
+		HTTPStatus:  http.StatusServiceUnavailable,
+		UserMessage: "too many open event streams, retry later",
+		DevNote:     "The MAX_EVENT_STREAMS limit on open GET /products/stream connections was reached.",
+	},
+}
+
+// Lookup returns the registered info for code
+func Lookup(code int) (ErrorCodeInfo, bool) {
+	info, ok := ErrorCodeRegistry[code]
+	return info, ok
+}
@@ -0,0 +1,27 @@
+package apierrors
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeRegistry(t *testing.T) {
+	for code, info := range ErrorCodeRegistry {
+		assert.Equal(t, code, info.Code, "code %d is registered under another key", info.Code)
+		assert.NotEmpty(t, info.UserMessage, "code %d has no user message", code)
+		assert.NotEmpty(t, info.DevNote, "code %d has no dev note", code)
+		assert.GreaterOrEqual(t, info.HTTPStatus, 400, "code %d must map to an error status", code)
+		assert.NotEmpty(t, http.StatusText(info.HTTPStatus), "code %d has unknown status %d", code, info.HTTPStatus)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup(ErrCodeResourceNotFound)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, info.HTTPStatus)
+
+	_, ok = Lookup(9999)
+	assert.False(t, ok)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+)
+
+// AttributeDefinitionHandler serves the typed attribute definitions a
+// category declares for its products
+type AttributeDefinitionHandler struct {
+	service *service.AttributeService
+	logger  LoggerInterface
+}
+
+// NewAttributeDefinitionHandler creates a new attribute definition handler
+// instance
+func NewAttributeDefinitionHandler(
+	repo datalayer.AttributeDefinitionRepoInterface,
+	logger LoggerInterface,
+) *AttributeDefinitionHandler {
+	return &AttributeDefinitionHandler{service: service.NewAttributeService(repo), logger: logger}
+}
+
+// RegisterRoutes registers the attribute definition endpoints on the router
+func (h *AttributeDefinitionHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("GET /categories/{id}/attribute-definitions", h.ListAttributeDefinitions)
+	router.HandleFunc("POST /categories/{id}/attribute-definitions", h.CreateAttributeDefinition)
+	router.HandleFunc("PUT /attribute-definitions/{id}", h.UpdateAttributeDefinition)
+	router.HandleFunc("DELETE /attribute-definitions/{id}", h.DeleteAttributeDefinition)
+}
+
+// ListAttributeDefinitions returns a category's definitions in name order
+func (h *AttributeDefinitionHandler) ListAttributeDefinitions(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	definitions, err := h.service.ListDefinitions(r.Context(), categoryID)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "attribute definitions retrieved", definitions, h.logger)
+}
+
+// CreateAttributeDefinition adds a definition to a category. A name the
+// category already defines is rejected with 409.
+func (h *AttributeDefinitionHandler) CreateAttributeDefinition(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req service.AttributeDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+
+	definition, err := h.service.CreateDefinition(r.Context(), categoryID, req)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "attribute definition created", definition, h.logger)
+}
+
+// UpdateAttributeDefinition replaces a definition. Existing product values
+// are kept and checked against it the next time the product's attributes
+// are written.
+func (h *AttributeDefinitionHandler) UpdateAttributeDefinition(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req service.AttributeDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+
+	definition, err := h.service.UpdateDefinition(r.Context(), id, req)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "attribute definition updated", definition, h.logger)
+}
+
+// DeleteAttributeDefinition removes a definition and responds with an
+// empty 204
+func (h *AttributeDefinitionHandler) DeleteAttributeDefinition(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	if err := h.service.DeleteDefinition(r.Context(), id); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAttributeDefinitionHandler(repo datalayer.AttributeDefinitionRepoInterface) *handlers.AttributeDefinitionHandler {
+	return handlers.NewAttributeDefinitionHandler(repo, handlermocks.NewLogger())
+}
+
+func TestAttributeDefinitionHandlerListAttributeDefinitions(t *testing.T) {
+	target := "/categories/" + testCategory.ID.String() + "/attribute-definitions"
+
+	t.Run("should list the category's definitions", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			ListAttributeDefinitionsFunc: func(_ context.Context, categoryID uuid.UUID) ([]*datalayer.AttributeDefinition, error) {
+				assert.Equal(t, testCategory.ID, categoryID)
+				definition := testDefinition
+				return []*datalayer.AttributeDefinition{&definition}, nil
+			},
+		}
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_attribute_definitions", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if category not found", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			ListAttributeDefinitionsFunc: func(_ context.Context, categoryID uuid.UUID) ([]*datalayer.AttributeDefinition, error) {
+				return nil, fmt.Errorf("listAttributeDefinitions: %w: category id `%s`", datalayer.ErrNotFound, categoryID)
+			},
+		}
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestAttributeDefinitionHandlerCreateAttributeDefinition(t *testing.T) {
+	target := "/categories/" + testCategory.ID.String() + "/attribute-definitions"
+
+	t.Run("should create the definition", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			CreateAttributeDefinitionFunc: func(_ context.Context, definition *datalayer.AttributeDefinition) error {
+				assert.NotEqual(t, uuid.Nil, definition.ID)
+				assert.Equal(t, testCategory.ID, definition.CategoryID)
+				assert.Equal(t, datalayer.EnumValues{"matte", "gloss"}, definition.AllowedValues)
+				*definition = testDefinition
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"finish","type":"enum","required":true,"allowedValues":["matte","gloss"]}`)
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodPost, target, body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		testutil.AssertGolden(t, "create_attribute_definition", rec.Body.Bytes())
+	})
+
+	t.Run("should return 409 if the name is taken", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			CreateAttributeDefinitionFunc: func(context.Context, *datalayer.AttributeDefinition) error {
+				return fmt.Errorf("createAttributeDefinition: %w: name `finish`", datalayer.ErrAttributeExists)
+			},
+		}
+		body := strings.NewReader(`{"name":"finish","type":"string"}`)
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodPost, target, body)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		testutil.AssertGolden(t, "create_attribute_definition_conflict", rec.Body.Bytes())
+	})
+
+	tests := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{name: "body is not JSON", body: `[`, wantMsg: "request body must be a JSON object"},
+		{name: "type is unknown", body: `{"name":"finish","type":"date"}`, wantMsg: "type must be one of: string, number, bool, enum"},
+		{name: "enum has no values", body: `{"name":"finish","type":"enum"}`, wantMsg: "allowedValues is required for enum attributes"},
+	}
+	for _, tt := range tests {
+		t.Run("should return 400 if "+tt.name, func(t *testing.T) {
+			rec := serve(newTestAttributeDefinitionHandler(&mocks.MockAttributeDefinitionRepo{}), http.MethodPost, target, strings.NewReader(tt.body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+			assert.Contains(t, rec.Body.String(), tt.wantMsg)
+		})
+	}
+}
+
+func TestAttributeDefinitionHandlerUpdateAttributeDefinition(t *testing.T) {
+	target := "/attribute-definitions/" + testDefinition.ID.String()
+
+	t.Run("should replace the definition", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			UpdateAttributeDefinitionFunc: func(_ context.Context, definition *datalayer.AttributeDefinition) error {
+				assert.Equal(t, testDefinition.ID, definition.ID)
+				assert.Equal(t, datalayer.AttributeString, definition.Type)
+				assert.Equal(t, datalayer.EnumValues{}, definition.AllowedValues)
+				definition.CategoryID = testDefinition.CategoryID
+				definition.CreatedAt = testDefinition.CreatedAt
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"finish","type":"string"}`)
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodPut, target, body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "update_attribute_definition", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if definition not found", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			UpdateAttributeDefinitionFunc: func(_ context.Context, definition *datalayer.AttributeDefinition) error {
+				return fmt.Errorf("updateAttributeDefinition: %w: id `%s`", datalayer.ErrNotFound, definition.ID)
+			},
+		}
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodPut, target, strings.NewReader(`{"name":"finish","type":"bool"}`))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should return 400 if a non-enum lists allowed values", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"finish","type":"string","allowedValues":["matte"]}`)
+		rec := serve(newTestAttributeDefinitionHandler(&mocks.MockAttributeDefinitionRepo{}), http.MethodPut, target, body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "allowedValues is only accepted for enum attributes")
+	})
+}
+
+func TestAttributeDefinitionHandlerDeleteAttributeDefinition(t *testing.T) {
+	target := "/attribute-definitions/" + testDefinition.ID.String()
+
+	t.Run("should delete with 204", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			DeleteAttributeDefinitionFunc: func(_ context.Context, id uuid.UUID) error {
+				assert.Equal(t, testDefinition.ID, id)
+				return nil
+			},
+		}
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should return 404 if definition not found", func(t *testing.T) {
+		repo := &mocks.MockAttributeDefinitionRepo{
+			DeleteAttributeDefinitionFunc: func(context.Context, uuid.UUID) error {
+				return fmt.Errorf("deleteAttributeDefinition: no rows affected: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serve(newTestAttributeDefinitionHandler(repo), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		rec := serve(newTestAttributeDefinitionHandler(&mocks.MockAttributeDefinitionRepo{}), http.MethodDelete, "/attribute-definitions/abc", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+func BenchmarkWriteSuccessResponse(b *testing.B) {
+	for _, size := range []int{1, 100, 1000} {
+		products := make([]*datalayer.Product, size)
+		for i := range products {
+			weight := 1.5
+			products[i] = &datalayer.Product{
+				ID:          uuid.New(),
+				Name:        fmt.Sprintf("Product %04d", i),
+				Description: "A product used to measure response encoding",
+				ImageURL:    "https://example.com/product.png",
+				CategoryID:  uuid.New(),
+				Price:       9.99,
+				Currency:    "USD",
+				Quantity:    i,
+				Weight:      &weight,
+				CreatedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			}
+		}
+
+		b.Run(fmt.Sprintf("products=%d", size), func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				WriteSuccessResponse(httptest.NewRecorder(), req, http.StatusOK, "products retrieved", products, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkEncodeTimeToCursor(b *testing.B) {
+	createdAt := time.Date(2024, 1, 1, 10, 30, 0, 123456789, time.UTC)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		EncodeTimeToCursor(createdAt)
+	}
+}
+
+func BenchmarkDecodeCursorToTime(b *testing.B) {
+	cursor := EncodeTimeToCursor(time.Date(2024, 1, 1, 10, 30, 0, 123456789, time.UTC))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeCursorToTime(cursor); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+	"github.com/google/uuid"
+)
+
+// CategoryHandler serves the category endpoints. Reads go to the repo;
+// writes go through the service, which owns validation and defaults.
+type CategoryHandler struct {
+	repo    datalayer.CategoryRepoInterface
+	service *service.CategoryService
+	skew    time.Duration
+	cache   *ResponseCache
+	logger  LoggerInterface
+}
+
+// NewCategoryHandler creates a new category handler instance. Listing
+// rewinds cursors by skew to tolerate clock drift between writers; see
+// datalayer.Cursor. List responses are cached for cacheTTL, zero disabling
+// the cache, and dropped whenever a category changes.
+func NewCategoryHandler(
+	repo datalayer.CategoryRepoInterface,
+	skew time.Duration,
+	cacheTTL time.Duration,
+	logger LoggerInterface,
+) *CategoryHandler {
+	h := &CategoryHandler{
+		repo:    repo,
+		service: service.NewCategoryService(repo),
+		skew:    skew,
+		cache:   NewResponseCache(cacheTTL, time.Now),
+		logger:  logger,
+	}
+	h.service.OnChange(h.cache.Invalidate)
+	return h
+}
+
+// RegisterRoutes registers the category endpoints on the router
+func (h *CategoryHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("GET /categories", h.cache.Wrap(h.ListCategories))
+	router.HandleFunc("POST /categories", h.CreateCategory)
+	router.HandleFunc("POST /categories/bulk", h.BulkCreateCategories)
+	router.HandleFunc("POST /categories/batch-get", h.BatchGetCategories)
+	router.HandleFunc("GET /categories/{id}", h.GetCategory)
+	router.HandleFunc("PATCH /categories/{id}", h.PatchCategory)
+	router.HandleFunc("DELETE /categories/{id}", h.DeleteCategory)
+}
+
+// ListCategories returns a page of categories. The cursor for the next page
+// is only included when more categories follow, and ?include_total=true adds
+// the number of categories after the requested cursor. Each ?attr.<key>=value
+// keeps only categories whose attribute equals value. The page carries an
+// ETag and a Last-Modified of its newest created_at, and conditional
+// requests it still satisfies get 304.
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	cursor, err := DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "cursor is invalid", h.logger)
+		return
+	}
+
+	limit, err := ParseLimit(r, datalayer.MaxLimit)
+	if err != nil {
+		writeParamError(w, r, err, h.logger)
+		return
+	}
+
+	includeTotal, err := parseBoolQuery(r, "include_total")
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+
+	attributes, err := parseAttributeFilter(r)
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+	createdAfter, afterID := cursor.Rewind(h.skew)
+	filter := datalayer.CategoryFilter{Attributes: attributes, ExcludeIDs: cursor.Seen, AfterID: afterID}
+
+	page, err := h.repo.ListCategories(r.Context(), filter, createdAfter, limit)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	var next string
+	if page.HasMore && len(page.Categories) > 0 {
+		next = EncodeCursor(datalayer.NextCursor(cursor, page.Categories, datalayer.CategoryKey, h.skew))
+	}
+	pagination := newPagination(datalayer.ClampLimit(limit), next)
+
+	if includeTotal {
+		total, err := h.repo.CountCategories(r.Context(), filter, cursor.CreatedAfter)
+		if err != nil {
+			writeRepoError(w, r, err, h.logger)
+			return
+		}
+		pagination.Total = &total
+	}
+
+	var lastModified time.Time
+	for _, category := range page.Categories {
+		if category.CreatedAt.After(lastModified) {
+			lastModified = category.CreatedAt
+		}
+	}
+	WriteConditionalListResponse(w, r, "categories retrieved", page.Categories, pagination, lastModified, h.logger)
+}
+
+// GetCategory returns one category with its ETag. A missing category is a
+// 404.
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	category, err := h.repo.GetCategoryByID(r.Context(), id)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	setResourceETag(w, category)
+	WriteSuccessResponse(w, r, http.StatusOK, "category retrieved", category, h.logger)
+}
+
+// BatchGetCategories returns the categories whose IDs are in the JSON
+// array body, in the order of their first occurrence. IDs without a
+// category are left out rather than failing the request. More distinct IDs
+// than Options.MaxBatchSize allows are a 400.
+func (h *CategoryHandler) BatchGetCategories(w http.ResponseWriter, r *http.Request) {
+	var ids []uuid.UUID
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON array of category IDs", h.logger)
+		return
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if !checkBatchSize(w, r, len(unique), "category IDs", h.logger) {
+		return
+	}
+
+	found, err := h.repo.GetCategoriesByIDs(r.Context(), unique)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	categories := make([]*datalayer.Category, 0, len(found))
+	for _, id := range unique {
+		if category, ok := found[id]; ok {
+			categories = append(categories, category)
+		}
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "categories retrieved", categories, h.logger)
+}
+
+// CreateCategory creates a category from the name, description and
+// attributes in the JSON body
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+
+	category, err := h.service.CreateCategory(r.Context(), req)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "category created", category, h.logger)
+}
+
+// BulkCreateCategories creates the categories of a JSON array of create
+// requests in one transaction. A category names its parent by parentId if
+// it exists, or by parentRef if it is created earlier in the same batch. A
+// batch over Options.MaxBatchSize is a 400. If any is rejected, including
+// for a dangling parent, nothing is created and the 422 response lists the
+// rejected items.
+func (h *CategoryHandler) BulkCreateCategories(w http.ResponseWriter, r *http.Request) {
+	var reqs []service.BulkCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON array", h.logger)
+		return
+	}
+	if !checkBatchSize(w, r, len(reqs), "categories", h.logger) {
+		return
+	}
+
+	categories, itemErrs, err := h.service.BulkCreateCategories(r.Context(), reqs)
+	if errors.Is(err, service.ErrBatchRejected) {
+		var errs []BatchItemError
+		for i, itemErr := range itemErrs {
+			if itemErr != nil {
+				errs = append(errs, BatchItemError{Index: i, Field: "category", Message: itemErr.Error()})
+			}
+		}
+		WriteBatchErrorResponse(w, r, errs, h.logger)
+		return
+	}
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "categories created", categories, h.logger)
+}
+
+// PatchCategory updates only the fields present in the JSON body and
+// returns the updated category. With If-Match the update only proceeds
+// while the category's ETag is listed, otherwise it answers 412.
+func (h *CategoryHandler) PatchCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var patch datalayer.CategoryPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+
+	patch.Precondition = ifMatchPrecondition[datalayer.Category](r)
+	category, err := h.service.UpdateCategory(r.Context(), id, patch)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	setResourceETag(w, category)
+	WriteSuccessResponse(w, r, http.StatusOK, "category updated", category, h.logger)
+}
+
+// DeleteCategory removes a category. With ?return=true the deleted category
+// is returned with 200, otherwise the response follows the delete style.
+// A missing category answers 404 unless Options.AlreadyDeletedOK is on, in which
+// case it answers as deleted. ?return=true still answers 404 then, as
+// there is no category to return.
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	returnDeleted, err := parseBoolQuery(r, "return")
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+
+	if returnDeleted {
+		category, err := h.service.DeleteCategoryReturning(r.Context(), id)
+		if err != nil {
+			writeRepoError(w, r, err, h.logger)
+			return
+		}
+		WriteSuccessResponse(w, r, http.StatusOK, "category deleted", category, h.logger)
+		return
+	}
+
+	if err := h.service.DeleteCategory(r.Context(), id); err != nil && !alreadyDeleted(r, err) {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	writeDeleted(w, r, "category deleted", h.logger)
+}
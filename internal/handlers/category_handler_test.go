@@ -0,0 +1,918 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	repomocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer/mocks"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryHandlerListCategories(t *testing.T) {
+	t.Run("should omit cursor for empty catalog", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{}}, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "nextCursor")
+		testutil.AssertGolden(t, "list_categories_empty", rec.Body.Bytes())
+	})
+
+	t.Run("should report no more pages for an empty page claiming more", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{}, HasMore: true}, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_categories_empty", rec.Body.Bytes())
+	})
+
+	t.Run("should return cursor if more categories follow", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(_ context.Context, _ datalayer.CategoryFilter, createdAfter time.Time, limit int) (*datalayer.CategoryPage, error) {
+				assert.True(t, createdAfter.IsZero())
+				assert.Equal(t, 1, limit)
+				category := testCategory
+				return &datalayer.CategoryPage{
+					Categories: []*datalayer.Category{&category},
+					NextCursor: category.CreatedAt,
+					HasMore:    true,
+				}, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?limit=1", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_categories_has_more", rec.Body.Bytes())
+	})
+
+	t.Run("should pass decoded cursor and include total", func(t *testing.T) {
+		cursor := handlers.EncodeTimeToCursor(testCategory.CreatedAt)
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(_ context.Context, _ datalayer.CategoryFilter, createdAfter time.Time, _ int) (*datalayer.CategoryPage, error) {
+				assert.Equal(t, testCategory.CreatedAt, createdAfter)
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{}}, nil
+			},
+			CountCategoriesFunc: func(_ context.Context, _ datalayer.CategoryFilter, createdAfter time.Time) (int64, error) {
+				assert.Equal(t, testCategory.CreatedAt, createdAfter)
+				return 0, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?include_total=true&cursor="+cursor, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_categories_include_total", rec.Body.Bytes())
+	})
+
+	t.Run("should rewind the cursor by the skew and exclude the ids it served", func(t *testing.T) {
+		const skew = 2 * time.Second
+		served := uuid.New()
+		cursor := handlers.EncodeCursor(datalayer.Cursor{CreatedAfter: testCategory.CreatedAt, Seen: []uuid.UUID{served}})
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(_ context.Context, filter datalayer.CategoryFilter, createdAfter time.Time, _ int) (*datalayer.CategoryPage, error) {
+				assert.Equal(t, testCategory.CreatedAt.Add(-skew), createdAfter)
+				assert.Equal(t, []uuid.UUID{served}, filter.ExcludeIDs)
+				category := testCategory
+				category.CreatedAt = testCategory.CreatedAt.Add(time.Second)
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{&category}, HasMore: true}, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, skew, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?cursor="+cursor, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		next := handlers.EncodeCursor(datalayer.Cursor{
+			CreatedAfter: testCategory.CreatedAt.Add(time.Second),
+			AfterID:      testCategory.ID,
+			Seen:         []uuid.UUID{served, testCategory.ID},
+		})
+		assert.Contains(t, rec.Body.String(), `"nextCursor":"`+next+`"`)
+	})
+
+	t.Run("should resume after the created_at and id of the cursor", func(t *testing.T) {
+		last := uuid.New()
+		cursor := handlers.EncodeCursor(datalayer.Cursor{CreatedAfter: testCategory.CreatedAt, AfterID: last})
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(_ context.Context, filter datalayer.CategoryFilter, createdAfter time.Time, _ int) (*datalayer.CategoryPage, error) {
+				assert.Equal(t, testCategory.CreatedAt, createdAfter)
+				assert.Equal(t, last, filter.AfterID)
+				category := testCategory
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{&category}, HasMore: true}, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?cursor="+cursor, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		next := handlers.EncodeCursor(datalayer.Cursor{CreatedAfter: testCategory.CreatedAt, AfterID: testCategory.ID})
+		assert.Contains(t, rec.Body.String(), `"nextCursor":"`+next+`"`)
+	})
+
+	t.Run("should pass attribute filters to list and count", func(t *testing.T) {
+		want := datalayer.CategoryFilter{Attributes: map[string]string{"icon": "book", "featured": "true"}}
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(_ context.Context, filter datalayer.CategoryFilter, _ time.Time, _ int) (*datalayer.CategoryPage, error) {
+				assert.Equal(t, want, filter)
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{}}, nil
+			},
+			CountCategoriesFunc: func(_ context.Context, filter datalayer.CategoryFilter, _ time.Time) (int64, error) {
+				assert.Equal(t, want, filter)
+				return 0, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?include_total=true&attr.icon=book&attr.featured=true", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should return 400 if an attribute filter repeats", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?attr.icon=a&attr.icon=b", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "list_categories_repeated_attribute", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if an attribute filter has no key", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?attr.=a", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	limits := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "default", query: "", want: "20"},
+		{name: "within range", query: "?limit=50", want: "50"},
+		{name: "the ceiling", query: "?limit=1000", want: "1000"},
+	}
+	for _, tt := range limits {
+		t.Run("should report the effective limit for "+tt.name, func(t *testing.T) {
+			repo := &repomocks.CategoryRepoInterfaceMock{
+				ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+					return &datalayer.CategoryPage{Categories: []*datalayer.Category{}}, nil
+				},
+			}
+			rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories"+tt.query, nil)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, rec.Header().Get("X-Page-Limit"))
+			assert.Contains(t, rec.Body.String(), `"limit":`+tt.want)
+		})
+	}
+
+	t.Run("should return 400 if cursor is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?cursor=abc%23", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "list_categories_invalid_cursor", rec.Body.Bytes())
+	})
+
+	for _, limit := range []string{"-1", "0", "1001", "99999999999999999999"} {
+		t.Run("should return 400 if limit is "+limit, func(t *testing.T) {
+			rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?limit="+limit, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			testutil.AssertGolden(t, "list_categories_limit_out_of_range", rec.Body.Bytes())
+		})
+	}
+
+	t.Run("should return 400 if limit is not an integer", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories?limit=ten", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "list_categories_invalid_limit", rec.Body.Bytes())
+	})
+
+	t.Run("should return 503 while the database breaker is open", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+				return nil, datalayer.ErrCircuitOpen
+			},
+		}
+		logger := handlermocks.NewLogger()
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, logger), http.MethodGet, "/categories", nil)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeDatabaseUnavailable, errorCode(t, rec))
+		assert.Empty(t, logger.LogErrorCalls())
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+				return nil, errors.New("listCategories: select query failed: query error")
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		testutil.AssertGolden(t, "list_categories_internal_error", rec.Body.Bytes())
+	})
+}
+
+func TestCategoryHandlerListCategoriesConditional(t *testing.T) {
+	older, newer := testCategory, testCategory
+	older.CreatedAt = newer.CreatedAt.Add(-time.Hour)
+	repo := &repomocks.CategoryRepoInterfaceMock{
+		ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+			first, second := newer, older
+			return &datalayer.CategoryPage{Categories: []*datalayer.Category{&first, &second}}, nil
+		},
+	}
+	handler := handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger())
+	lastModified := newer.CreatedAt.UTC().Truncate(time.Second)
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+		req.Header = header
+		router := handlers.NewRouter(handlers.Options{})
+		handler.RegisterRoutes(router)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get(http.Header{})
+	etag := first.Header().Get("ETag")
+
+	t.Run("should set validators on a full response", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, first.Code)
+		unstamped := regexp.MustCompile(`,"generatedAt":"[^"]*"`).ReplaceAll(first.Body.Bytes(), nil)
+		sum := sha256.Sum256(unstamped)
+		assert.Equal(t, `"`+hex.EncodeToString(sum[:])+`"`, etag)
+		assert.Equal(t, lastModified.Format(http.TimeFormat), first.Header().Get("Last-Modified"))
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"matching etag", "If-None-Match", etag, http.StatusNotModified},
+		{"matching weak etag in a list", "If-None-Match", `"other", W/` + etag, http.StatusNotModified},
+		{"any etag", "If-None-Match", "*", http.StatusNotModified},
+		{"stale etag", "If-None-Match", `"other"`, http.StatusOK},
+		{"unchanged since last modified", "If-Modified-Since", lastModified.Format(http.TimeFormat), http.StatusNotModified},
+		{"modified since", "If-Modified-Since", lastModified.Add(-time.Second).Format(http.TimeFormat), http.StatusOK},
+		{"unparsable date", "If-Modified-Since", "yesterday", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run("should return "+http.StatusText(tt.wantStatus)+" for "+tt.name, func(t *testing.T) {
+			rec := get(http.Header{tt.header: {tt.value}})
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tt.wantStatus == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				testutil.AssertGolden(t, "list_categories_conditional", rec.Body.Bytes())
+			}
+		})
+	}
+
+	t.Run("should prefer If-None-Match over If-Modified-Since", func(t *testing.T) {
+		rec := get(http.Header{
+			"If-None-Match":     {`"other"`},
+			"If-Modified-Since": {lastModified.Format(http.TimeFormat)},
+		})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should omit Last-Modified for an empty page", func(t *testing.T) {
+		empty := &repomocks.CategoryRepoInterfaceMock{
+			ListCategoriesFunc: func(context.Context, datalayer.CategoryFilter, time.Time, int) (*datalayer.CategoryPage, error) {
+				return &datalayer.CategoryPage{Categories: []*datalayer.Category{}}, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(empty, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		assert.Empty(t, rec.Header().Get("Last-Modified"))
+	})
+}
+
+func TestCategoryHandlerDeleteCategory(t *testing.T) {
+	target := "/categories/" + testCategory.ID.String()
+
+	t.Run("should return 204 with no body by default", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryFunc: func(_ context.Context, id uuid.UUID) error {
+				assert.Equal(t, testCategory.ID, id)
+				return nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should return 200 with the success envelope in envelope style", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryFunc: func(context.Context, uuid.UUID) error { return nil },
+		}
+		rec := serveWithOptions(handlers.Options{DeleteStyle: handlers.DeleteEnvelope}, handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "delete_category_envelope", rec.Body.Bytes())
+	})
+
+	t.Run("should return deleted category if return is true", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryReturningFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Category, error) {
+				assert.Equal(t, testCategory.ID, id)
+				category := testCategory
+				return &category, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target+"?return=true", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "delete_category_return_true", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, "/categories/abc", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "delete_category_invalid_id", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if return is not a boolean", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target+"?return=maybe", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "delete_category_invalid_return", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if category not found", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryFunc: func(context.Context, uuid.UUID) error {
+				return fmt.Errorf("deleteCategory: no rows affected: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		testutil.AssertGolden(t, "delete_category_not_found", rec.Body.Bytes())
+	})
+
+	t.Run("should treat a missing category as deleted if already deleted is ok", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryFunc: func(context.Context, uuid.UUID) error {
+				return fmt.Errorf("deleteCategory: no rows affected: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serveWithOptions(handlers.Options{AlreadyDeletedOK: true}, handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should answer in envelope style for a category already deleted", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryFunc: func(context.Context, uuid.UUID) error {
+				return fmt.Errorf("deleteCategory: no rows affected: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serveWithOptions(handlers.Options{AlreadyDeletedOK: true, DeleteStyle: handlers.DeleteEnvelope}, handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "delete_category_envelope", rec.Body.Bytes())
+	})
+
+	t.Run("should still return 404 with return true if already deleted is ok", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Category, error) {
+				return nil, fmt.Errorf("deleteCategoryReturning: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serveWithOptions(handlers.Options{AlreadyDeletedOK: true}, handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target+"?return=true", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should still return 500 if already deleted is ok and repo fails", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryFunc: func(context.Context, uuid.UUID) error {
+				return errors.New("deleteCategory: delete query failed: database error")
+			},
+		}
+		rec := serveWithOptions(handlers.Options{AlreadyDeletedOK: true}, handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("should return 500 and log if repo fails", func(t *testing.T) {
+		logger := handlermocks.NewLogger()
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			DeleteCategoryReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Category, error) {
+				return nil, errors.New("deleteCategoryReturning: delete query failed: database error")
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, logger), http.MethodDelete, target+"?return=true", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		testutil.AssertGolden(t, "delete_category_internal_error", rec.Body.Bytes())
+		assert.Len(t, logger.LogErrorCalls(), 1)
+		assert.Equal(t, "DELETE /categories/{id}", logger.LogErrorCalls()[0].Op)
+	})
+}
+
+func TestCategoryHandlerGetCategory(t *testing.T) {
+	target := "/categories/" + testCategory.ID.String()
+
+	t.Run("should return the category", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			GetCategoryByIDFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Category, error) {
+				assert.Equal(t, testCategory.ID, id)
+				category := testCategory
+				return &category, nil
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "get_category", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if category is missing", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			GetCategoryByIDFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Category, error) {
+				return nil, fmt.Errorf("getCategoryByID: %w: id `%s`", datalayer.ErrNotFound, id)
+			},
+		}
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeResourceNotFound, errorCode(t, rec))
+		testutil.AssertGolden(t, "get_category_not_found", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/categories/abc", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestCategoryHandlerBatchGetCategories(t *testing.T) {
+	other := testCategory
+	other.ID = uuid.MustParse("8c1f4e2a-3b5d-4a6c-9e7f-0a1b2c3d4e5f")
+	other.Name = "Test Category B"
+	missing := uuid.MustParse("5d2e9f1a-6b7c-4d8e-af90-1b2c3d4e5f60")
+
+	// newRepo returns a repo holding testCategory and other that records
+	// the ids it was asked for
+	newRepo := func(asked *[]uuid.UUID) *repomocks.CategoryRepoInterfaceMock {
+		stored := map[uuid.UUID]datalayer.Category{testCategory.ID: testCategory, other.ID: other}
+		return &repomocks.CategoryRepoInterfaceMock{
+			GetCategoriesByIDsFunc: func(_ context.Context, ids []uuid.UUID) (map[uuid.UUID]*datalayer.Category, error) {
+				*asked = ids
+				categories := map[uuid.UUID]*datalayer.Category{}
+				for _, id := range ids {
+					if category, ok := stored[id]; ok {
+						categories[id] = &category
+					}
+				}
+				return categories, nil
+			},
+		}
+	}
+
+	t.Run("should return found categories in request order and omit missing ones", func(t *testing.T) {
+		var asked []uuid.UUID
+		body := strings.NewReader(`["` + other.ID.String() + `","` + missing.String() + `","` + testCategory.ID.String() + `"]`)
+		rec := serve(handlers.NewCategoryHandler(newRepo(&asked), 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/batch-get", body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []uuid.UUID{other.ID, missing, testCategory.ID}, asked)
+		testutil.AssertGolden(t, "batch_get_categories", rec.Body.Bytes())
+	})
+
+	t.Run("should return each category once for duplicate ids", func(t *testing.T) {
+		var asked []uuid.UUID
+		body := strings.NewReader(`["` + testCategory.ID.String() + `","` + other.ID.String() + `","` + testCategory.ID.String() + `"]`)
+		rec := serve(handlers.NewCategoryHandler(newRepo(&asked), 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/batch-get", body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []uuid.UUID{testCategory.ID, other.ID}, asked)
+		var resp struct {
+			Data []datalayer.Category `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, testCategory.ID, resp.Data[0].ID)
+		assert.Equal(t, other.ID, resp.Data[1].ID)
+	})
+
+	t.Run("should return an empty list if no category is found", func(t *testing.T) {
+		var asked []uuid.UUID
+		body := strings.NewReader(`["` + missing.String() + `"]`)
+		rec := serve(handlers.NewCategoryHandler(newRepo(&asked), 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/batch-get", body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"data":[]`)
+	})
+
+	t.Run("should return 400 if the body is not an array of ids", func(t *testing.T) {
+		for _, body := range []string{`{"ids":[]}`, `["abc"]`, `[`} {
+			rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/batch-get", strings.NewReader(body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+			assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec), body)
+		}
+	})
+
+	t.Run("should return 400 for more than 100 ids", func(t *testing.T) {
+		ids := make([]uuid.UUID, 101)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+		body, err := json.Marshal(ids)
+		require.NoError(t, err)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/batch-get", bytes.NewReader(body))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "batch has 101 category IDs, at most 100 are allowed")
+	})
+
+	t.Run("should return 503 if the database is unavailable", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			GetCategoriesByIDsFunc: func(context.Context, []uuid.UUID) (map[uuid.UUID]*datalayer.Category, error) {
+				return nil, datalayer.ErrCircuitOpen
+			},
+		}
+		body := strings.NewReader(`["` + testCategory.ID.String() + `"]`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/batch-get", body)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeDatabaseUnavailable, errorCode(t, rec))
+	})
+}
+
+func TestCategoryHandlerBulkCreateCategories(t *testing.T) {
+	t.Run("should create every category in one call", func(t *testing.T) {
+		var created []*datalayer.Category
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			BulkCreateCategoriesFunc: func(_ context.Context, categories []*datalayer.Category) error {
+				created = categories
+				return nil
+			},
+		}
+		body := strings.NewReader(`[{"name":"Fiction"},{"name":"Poetry","attributes":{"icon":"quill"}}]`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		require.Len(t, created, 2)
+		assert.Equal(t, "Fiction", created[0].Name)
+		assert.Equal(t, datalayer.CategoryAttributes{"icon": "quill"}, created[1].Attributes)
+		for _, category := range created {
+			assert.Contains(t, rec.Body.String(), category.ID.String())
+		}
+	})
+
+	t.Run("should return 422 listing the rejected categories", func(t *testing.T) {
+		body := strings.NewReader(`[{"name":"Fiction"},{"name":" "},{"name":"Poetry","attributes":{"":"x"}}]`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		testutil.AssertGolden(t, "bulk_create_categories_rejected", rec.Body.Bytes())
+	})
+
+	t.Run("should create a child under a parent from the same batch", func(t *testing.T) {
+		var created []*datalayer.Category
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			BulkCreateCategoriesFunc: func(_ context.Context, categories []*datalayer.Category) error {
+				created = categories
+				return nil
+			},
+		}
+		body := strings.NewReader(`[{"name":"Books","ref":"books"},{"name":"Fiction","parentRef":"books"}]`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		require.Len(t, created, 2)
+		assert.Nil(t, created[0].ParentID)
+		require.NotNil(t, created[1].ParentID)
+		assert.Equal(t, created[0].ID, *created[1].ParentID)
+		assert.Contains(t, rec.Body.String(), `"parentId":"`+created[0].ID.String()+`"`)
+	})
+
+	t.Run("should return 422 for a dangling parent reference", func(t *testing.T) {
+		body := strings.NewReader(`[{"name":"Fiction","parentRef":"books"},{"name":"Books","ref":"books"}]`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "parentRef `books` does not match the ref of an earlier category")
+	})
+
+	t.Run("should return 400 if body is not an array", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Fiction"}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			BulkCreateCategoriesFunc: func(context.Context, []*datalayer.Category) error {
+				return errors.New("bulkCreateCategories: insert query failed: database error")
+			},
+		}
+		body := strings.NewReader(`[{"name":"Fiction"}]`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("should return 400 for a batch over the configured size", func(t *testing.T) {
+		body := strings.NewReader(`[{"name":"Fiction"},{"name":"Poetry"}]`)
+		rec := serveWithOptions(handlers.Options{MaxBatchSize: 1}, handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories/bulk", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), "batch has 2 categories, at most 1 are allowed")
+	})
+}
+
+func TestCategoryHandlerCreateCategory(t *testing.T) {
+	t.Run("should create the category with a generated ID", func(t *testing.T) {
+		var created *datalayer.Category
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			CreateCategoryFunc: func(_ context.Context, category *datalayer.Category) error {
+				created = category
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Books","description":"Paper","attributes":{"icon":"book","order":2}}`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.NotEqual(t, uuid.Nil, created.ID)
+			assert.False(t, created.CreatedAt.IsZero())
+			assert.Equal(t, "Books", created.Name)
+			assert.Equal(t, datalayer.CategoryAttributes{"icon": "book", "order": 2.0}, created.Attributes)
+			assert.Contains(t, rec.Body.String(), created.ID.String())
+		}
+	})
+
+	t.Run("should stamp created at on the server", func(t *testing.T) {
+		var created *datalayer.Category
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			CreateCategoryFunc: func(_ context.Context, category *datalayer.Category) error {
+				created = category
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Books","createdAt":"2999-01-01T00:00:00Z","created_at":"2999-01-01T00:00:00Z"}`)
+		before := time.Now().UTC()
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.WithinRange(t, created.CreatedAt, before, time.Now().UTC())
+			assert.Equal(t, time.UTC, created.CreatedAt.Location())
+		}
+	})
+
+	t.Run("should return 400 if name is blank", func(t *testing.T) {
+		body := strings.NewReader(`{"name":" "}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "create_category_blank_name", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if an attribute is not a scalar", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Books","attributes":{"tags":["a"]}}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "create_category_invalid_attribute", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if name is missing", func(t *testing.T) {
+		body := strings.NewReader(`{"description":"Paper"}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "name is required")
+	})
+
+	t.Run("should return 400 if name is too long", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"` + strings.Repeat("n", 101) + `"}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "name must be at most 100 characters")
+	})
+
+	t.Run("should return 400 if description is too long", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Books","description":"` + strings.Repeat("d", 1001) + `"}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "create_category_long_description", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if a field has the wrong type", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Books","description":7}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "request body must be a JSON object")
+	})
+
+	t.Run("should return 400 if body is not JSON", func(t *testing.T) {
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", strings.NewReader(`name=Books`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			CreateCategoryFunc: func(context.Context, *datalayer.Category) error {
+				return errors.New("insert failed")
+			},
+		}
+		body := strings.NewReader(`{"name":"Books"}`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/categories", body)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestCategoryHandlerPatchCategory(t *testing.T) {
+	target := "/categories/" + testCategory.ID.String()
+
+	t.Run("should patch only the provided fields", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			PatchCategoryFunc: func(_ context.Context, id uuid.UUID, patch datalayer.CategoryPatch) (*datalayer.Category, error) {
+				assert.Equal(t, testCategory.ID, id)
+				assert.Nil(t, patch.Name)
+				assert.Equal(t, "Patched description", *patch.Description)
+				category := testCategory
+				category.Description = *patch.Description
+				return &category, nil
+			},
+		}
+		body := strings.NewReader(`{"description":"Patched description"}`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "patch_category_description", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if no field is provided", func(t *testing.T) {
+		body := strings.NewReader(`{}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "patch_category_empty", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if name is blank", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"  "}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "name must not be empty")
+	})
+
+	t.Run("should return 400 if body is not json", func(t *testing.T) {
+		body := strings.NewReader(`name=x`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "patch_category_invalid_body", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Books"}`)
+		rec := serve(handlers.NewCategoryHandler(&repomocks.CategoryRepoInterfaceMock{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, "/categories/abc", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("should return 404 if category not found", func(t *testing.T) {
+		repo := &repomocks.CategoryRepoInterfaceMock{
+			PatchCategoryFunc: func(context.Context, uuid.UUID, datalayer.CategoryPatch) (*datalayer.Category, error) {
+				return nil, fmt.Errorf("patchCategory: %w: id `%s`", datalayer.ErrNotFound, testCategory.ID)
+			},
+		}
+		body := strings.NewReader(`{"name":"Books"}`)
+		rec := serve(handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestCategoryHandlerPatchCategoryIfMatch(t *testing.T) {
+	target := "/categories/" + testCategory.ID.String()
+	body := `{"description":"Patched description"}`
+	// newHandler serves a memory repo holding testCategory
+	newHandler := func(t *testing.T) (*handlers.CategoryHandler, *datalayer.MemoryCategoryRepo) {
+		t.Helper()
+		repo := datalayer.NewMemoryCategoryRepo()
+		category := testCategory
+		require.NoError(t, repo.CreateCategory(context.Background(), &category))
+		return handlers.NewCategoryHandler(repo, 0, 0, handlermocks.NewLogger()), repo
+	}
+	storedDescription := func(t *testing.T, repo *datalayer.MemoryCategoryRepo) string {
+		t.Helper()
+		stored, err := repo.GetCategoryByID(context.Background(), testCategory.ID)
+		require.NoError(t, err)
+		return stored.Description
+	}
+
+	t.Run("should update when If-Match lists the current ETag", func(t *testing.T) {
+		h, repo := newHandler(t)
+		etag := serve(h, http.MethodGet, target, nil).Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {etag}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Patched description", storedDescription(t, repo))
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should return 412 when If-Match does not match", func(t *testing.T) {
+		h, repo := newHandler(t)
+		rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {`"stale"`}})
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		assert.Equal(t, apierrors.ErrCodePreconditionFailed, errorCode(t, rec))
+		assert.Equal(t, testCategory.Description, storedDescription(t, repo))
+		testutil.AssertGolden(t, "patch_category_precondition_failed", rec.Body.Bytes())
+	})
+
+	t.Run("should update when If-Match is a wildcard", func(t *testing.T) {
+		h, repo := newHandler(t)
+		rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {"*"}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Patched description", storedDescription(t, repo))
+	})
+
+	t.Run("should let one of concurrent updates with the same ETag through", func(t *testing.T) {
+		h, repo := newHandler(t)
+		etag := serve(h, http.MethodGet, target, nil).Header().Get("ETag")
+
+		const writers = 8
+		codes := make([]int, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				patch := fmt.Sprintf(`{"description":"written by %d"}`, i)
+				codes[i] = serveWithHeader(h, http.MethodPatch, target, strings.NewReader(patch), http.Header{"If-Match": {etag}}).Code
+			}()
+		}
+		wg.Wait()
+
+		winner := slices.Index(codes, http.StatusOK)
+		require.NotEqual(t, -1, winner, codes)
+		for i, code := range codes {
+			if i != winner {
+				assert.Equal(t, http.StatusPreconditionFailed, code, codes)
+			}
+		}
+		assert.Equal(t, fmt.Sprintf("written by %d", winner), storedDescription(t, repo))
+	})
+}
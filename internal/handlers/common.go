@@ -0,0 +1,749 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+	"github.com/google/uuid"
+)
+
+const (
+	statusSuccess = "success"
+	statusError   = "error"
+)
+
+const uuidLength = 36
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidUUID   = errors.New("invalid uuid")
+)
+
+// EncodeTimeToCursor encodes a timestamp into an opaque pagination cursor.
+// The zero time encodes to the empty cursor: it would only lead a client
+// back to the first page.
+func EncodeTimeToCursor(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(t.UTC().Format(time.RFC3339Nano)))
+}
+
+// DecodeCursorToTime decodes a pagination cursor back into a timestamp.
+// An empty cursor decodes to the zero time so listing starts from the beginning.
+func DecodeCursorToTime(cursor string) (time.Time, error) {
+	if cursor == "" {
+		return time.Time{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return t.UTC(), nil
+}
+
+// EncodeCursor encodes a listing cursor as its CreatedAfter time, followed
+// by a comma and the AfterID when set, then the Seen ids. A cursor without
+// ids encodes exactly like EncodeTimeToCursor, and a zero CreatedAfter
+// encodes to the empty cursor.
+func EncodeCursor(cursor datalayer.Cursor) string {
+	if cursor.CreatedAfter.IsZero() {
+		return ""
+	}
+	raw := cursor.CreatedAfter.UTC().Format(time.RFC3339Nano)
+	if cursor.AfterID != uuid.Nil {
+		raw += "," + cursor.AfterID.String()
+	}
+	for _, id := range cursor.Seen {
+		raw += " " + id.String()
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor decodes a cursor made by EncodeCursor or EncodeTimeToCursor.
+// An empty cursor decodes to the zero cursor so listing starts from the
+// beginning, and one without an AfterID resumes after its time alone.
+func DecodeCursor(cursor string) (datalayer.Cursor, error) {
+	if cursor == "" {
+		return datalayer.Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return datalayer.Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	parts := strings.Split(string(raw), " ")
+	createdAfter, afterID, hasAfterID := strings.Cut(parts[0], ",")
+	t, err := time.Parse(time.RFC3339Nano, createdAfter)
+	if err != nil {
+		return datalayer.Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if len(parts)-1 > datalayer.MaxCursorSeen {
+		return datalayer.Cursor{}, fmt.Errorf("%w: more than %d ids", ErrInvalidCursor, datalayer.MaxCursorSeen)
+	}
+
+	decoded := datalayer.Cursor{CreatedAfter: t.UTC()}
+	if hasAfterID {
+		if decoded.AfterID, err = parseCursorID(afterID); err != nil {
+			return datalayer.Cursor{}, err
+		}
+	}
+	for _, part := range parts[1:] {
+		id, err := parseCursorID(part)
+		if err != nil {
+			return datalayer.Cursor{}, err
+		}
+		decoded.Seen = append(decoded.Seen, id)
+	}
+	return decoded, nil
+}
+
+// parseCursorID parses an id of a cursor in its canonical hyphenated form
+func parseCursorID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil || len(raw) != uuidLength {
+		return uuid.Nil, fmt.Errorf("%w: malformed id `%s`", ErrInvalidCursor, raw)
+	}
+	return id, nil
+}
+
+// EncodeGroupCursor encodes the key of the last duplicate group served into
+// an opaque pagination cursor
+func EncodeGroupCursor(key datalayer.DuplicateGroupKey) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key.CategoryID.String() + " " + key.NormalizedName))
+}
+
+// DecodeGroupCursor decodes a cursor made by EncodeGroupCursor. An empty
+// cursor decodes to the zero key so listing starts from the first group.
+func DecodeGroupCursor(cursor string) (datalayer.DuplicateGroupKey, error) {
+	if cursor == "" {
+		return datalayer.DuplicateGroupKey{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return datalayer.DuplicateGroupKey{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	rawID, name, ok := strings.Cut(string(raw), " ")
+	if !ok || len(rawID) != uuidLength {
+		return datalayer.DuplicateGroupKey{}, fmt.Errorf("%w: malformed group key", ErrInvalidCursor)
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return datalayer.DuplicateGroupKey{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	return datalayer.DuplicateGroupKey{CategoryID: id, NormalizedName: name}, nil
+}
+
+// UUIDErrorReason says why a UUID parameter was rejected
+type UUIDErrorReason string
+
+const (
+	// UUIDMissing means the parameter is absent or empty
+	UUIDMissing UUIDErrorReason = "missing"
+	// UUIDBlank means the parameter holds only white space
+	UUIDBlank UUIDErrorReason = "blank"
+	// UUIDInvalidFormat means the parameter is not a canonical UUID
+	UUIDInvalidFormat UUIDErrorReason = "invalid_format"
+	// UUIDNil means the parameter is the all-zero UUID
+	UUIDNil UUIDErrorReason = "nil"
+	// UUIDWrongVersion means the parameter is a UUID of another version
+	// than the one Options.UUIDVersion requires
+	UUIDWrongVersion UUIDErrorReason = "wrong_version"
+)
+
+// UUIDError reports a rejected UUID parameter. It matches ErrInvalidUUID
+// with errors.Is.
+type UUIDError struct {
+	Param  string
+	Reason UUIDErrorReason
+	// Version is the required version of a UUIDWrongVersion error
+	Version int
+}
+
+func (e *UUIDError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidUUID, e.Param, e.Detail())
+}
+
+func (e *UUIDError) Is(target error) bool {
+	return target == ErrInvalidUUID
+}
+
+// Detail describes the problem without naming the parameter, as the
+// message of a field error
+func (e *UUIDError) Detail() string {
+	switch e.Reason {
+	case UUIDMissing:
+		return "is required"
+	case UUIDBlank:
+		return "must not be blank"
+	case UUIDNil:
+		return "must not be nil"
+	case UUIDWrongVersion:
+		return fmt.Sprintf("must be a version %d UUID", e.Version)
+	default:
+		return "must be a UUID in canonical form"
+	}
+}
+
+// ParseUUIDParam parses a path parameter as a canonical, non-nil UUID of
+// the version the request's Options require. Errors are *UUIDError.
+func ParseUUIDParam(r *http.Request, name string) (uuid.UUID, error) {
+	raw := r.PathValue(name)
+	if raw == "" {
+		return uuid.Nil, &UUIDError{Param: name, Reason: UUIDMissing}
+	}
+	return parseUUID(raw, name, OptionsFromContext(r.Context()).UUIDVersion)
+}
+
+// ParseOptionalUUIDQuery parses a query parameter like ParseUUIDParam,
+// returning nil when it is absent or empty
+func ParseOptionalUUIDQuery(r *http.Request, name string) (*uuid.UUID, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	id, err := parseUUID(raw, name, OptionsFromContext(r.Context()).UUIDVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// parseUUID parses raw, the value of name, as a canonical, non-nil UUID of
+// version, or of any version when it is zero
+func parseUUID(raw, name string, version int) (uuid.UUID, error) {
+	if strings.TrimSpace(raw) == "" {
+		return uuid.Nil, &UUIDError{Param: name, Reason: UUIDBlank}
+	}
+	// uuid.Parse also accepts urn, braced and unhyphenated forms; only the
+	// canonical form is valid in our URLs. Its errors are not passed on as
+	// they describe the formats it accepts rather than ours.
+	if len(raw) != uuidLength {
+		return uuid.Nil, &UUIDError{Param: name, Reason: UUIDInvalidFormat}
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, &UUIDError{Param: name, Reason: UUIDInvalidFormat}
+	}
+	if id == uuid.Nil {
+		return uuid.Nil, &UUIDError{Param: name, Reason: UUIDNil}
+	}
+	if version != 0 && int(id.Version()) != version {
+		return uuid.Nil, &UUIDError{Param: name, Reason: UUIDWrongVersion, Version: version}
+	}
+
+	return id, nil
+}
+
+// writeUUIDError writes the 400 for a UUID parameter rejected with err,
+// naming the parameter as the rejected field
+func writeUUIDError(w http.ResponseWriter, r *http.Request, err error, logger LoggerInterface) {
+	var uuidErr *UUIDError
+	if !errors.As(err, &uuidErr) {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), logger)
+		return
+	}
+	fields := []service.FieldError{{Field: uuidErr.Param, Message: uuidErr.Detail()}}
+	WriteFieldErrorResponse(w, r, uuidErr.Error(), fields, logger)
+}
+
+// SuccessResponse is the envelope of every successful response. An empty
+// Message is left out. GeneratedAt is when the server built it, in RFC 3339
+// UTC, so clients can tell a stale cached copy from a fresh one.
+type SuccessResponse struct {
+	Status      string      `json:"status"`
+	Message     string      `json:"message,omitempty"`
+	Data        any         `json:"data,omitempty"`
+	Pagination  *Pagination `json:"pagination,omitempty"`
+	GeneratedAt string      `json:"generatedAt,omitempty"`
+}
+
+// successMessage returns the message a success response to r carries
+func successMessage(r *http.Request, message string) string {
+	if OptionsFromContext(r.Context()).OmitSuccessMessages {
+		return ""
+	}
+	return message
+}
+
+// DeleteStyle selects how a successful delete without ?return=true answers
+type DeleteStyle string
+
+const (
+	// DeleteNoContent answers 204 with no body
+	DeleteNoContent DeleteStyle = "no_content"
+	// DeleteEnvelope answers 200 with the success envelope and no data
+	DeleteEnvelope DeleteStyle = "envelope"
+)
+
+// ParseDeleteStyle validates a configured delete response style
+func ParseDeleteStyle(name string) (DeleteStyle, error) {
+	switch style := DeleteStyle(name); style {
+	case DeleteNoContent, DeleteEnvelope:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unsupported delete response style `%s`", name)
+	}
+}
+
+// alreadyDeleted reports whether a delete of r that failed with err is to
+// be answered as deleted because the row is already gone
+func alreadyDeleted(r *http.Request, err error) bool {
+	return OptionsFromContext(r.Context()).AlreadyDeletedOK && datalayer.KindOf(err) == datalayer.KindNotFound
+}
+
+// writeDeleted answers a successful delete in the configured style
+func writeDeleted(w http.ResponseWriter, r *http.Request, message string, logger LoggerInterface) {
+	if OptionsFromContext(r.Context()).DeleteStyle == DeleteEnvelope {
+		WriteSuccessResponse(w, r, http.StatusOK, message, nil, logger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// generatedAt returns the GeneratedAt stamp for a response built now
+func generatedAt() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// Pagination describes how to fetch the page after the current one.
+// NextCursor is omitted when there are no more results, and HasMore is
+// never true without it. Limit is the effective page size, also sent in
+// the X-Page-Limit header for clients that only read headers.
+type Pagination struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+	Total      *int64 `json:"total,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// newPagination returns the pagination of a page served with limit.
+// HasMore follows nextCursor so clients are never told to follow a cursor
+// that is not there.
+func newPagination(limit int, nextCursor string) *Pagination {
+	return &Pagination{NextCursor: nextCursor, HasMore: nextCursor != "", Limit: limit}
+}
+
+type ErrorResponse struct {
+	Status string `json:"status"`
+	Error  Error  `json:"error"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchErrorResponse is the error envelope carrying per-item failures of a
+// batch request
+type BatchErrorResponse struct {
+	Status string           `json:"status"`
+	Error  Error            `json:"error"`
+	Errors []BatchItemError `json:"errors"`
+}
+
+// FieldErrorResponse is the error envelope listing every rejected field of
+// a request
+type FieldErrorResponse struct {
+	Status string       `json:"status"`
+	Error  Error        `json:"error"`
+	Errors []FieldError `json:"errors"`
+}
+
+// BatchItemError describes why one item of a batch request was rejected
+type BatchItemError struct {
+	Index   int    `json:"index"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// WriteSuccessResponse writes data wrapped in the success envelope
+func WriteSuccessResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	statusCode int,
+	message string,
+	data any,
+	logger LoggerInterface,
+) {
+	writeJSON(w, r, statusCode, SuccessResponse{
+		Status:      statusSuccess,
+		Message:     successMessage(r, message),
+		Data:        data,
+		GeneratedAt: generatedAt(),
+	}, logger)
+}
+
+// WriteListResponse writes a page of data and its pagination wrapped in the
+// success envelope
+func WriteListResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	data any,
+	pagination *Pagination,
+	logger LoggerInterface,
+) {
+	setPageLimit(w, pagination)
+	writeJSON(w, r, http.StatusOK, SuccessResponse{
+		Status:      statusSuccess,
+		Message:     successMessage(r, message),
+		Data:        data,
+		Pagination:  pagination,
+		GeneratedAt: generatedAt(),
+	}, logger)
+}
+
+// setPageLimit sets the X-Page-Limit header to the effective page size
+func setPageLimit(w http.ResponseWriter, pagination *Pagination) {
+	if pagination != nil && pagination.Limit > 0 {
+		w.Header().Set("X-Page-Limit", strconv.Itoa(pagination.Limit))
+	}
+}
+
+// WriteErrorResponse writes an error code and message wrapped in the error envelope
+func WriteErrorResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	statusCode int,
+	errCode int,
+	message string,
+	logger LoggerInterface,
+) {
+	writeJSON(w, r, statusCode, ErrorResponse{
+		Status: statusError,
+		Error: Error{
+			Code:    errCode,
+			Message: message,
+		},
+	}, logger)
+}
+
+// WriteCodeResponse writes a registered error code with its HTTP status and
+// default message
+func WriteCodeResponse(w http.ResponseWriter, r *http.Request, code int, logger LoggerInterface) {
+	info, ok := apierrors.Lookup(code)
+	if !ok {
+		info = apierrors.ErrorCodeRegistry[apierrors.ErrCodeInternalServerError]
+	}
+	WriteErrorResponse(w, r, info.HTTPStatus, info.Code, info.UserMessage, logger)
+}
+
+// WriteBatchErrorResponse writes a 422 listing every rejected item of a batch
+// request in the error envelope
+func WriteBatchErrorResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	errors []BatchItemError,
+	logger LoggerInterface,
+) {
+	if errors == nil {
+		errors = []BatchItemError{}
+	}
+	writeJSON(w, r, http.StatusUnprocessableEntity, BatchErrorResponse{
+		Status: statusError,
+		Error: Error{
+			Code:    apierrors.ErrCodeValidationFailed,
+			Message: "validation failed",
+		},
+		Errors: errors,
+	}, logger)
+}
+
+// WriteFieldErrorResponse writes a 400 listing every rejected field in the
+// error envelope
+func WriteFieldErrorResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	fields []service.FieldError,
+	logger LoggerInterface,
+) {
+	writeJSON(w, r, http.StatusBadRequest, FieldErrorResponse{
+		Status: statusError,
+		Error: Error{
+			Code:    apierrors.ErrCodeInvalidFieldFormat,
+			Message: message,
+		},
+		Errors: fieldErrors(fields),
+	}, logger)
+}
+
+// writeValidationErrors writes the 422 for a body the service rejected,
+// listing every rejected field in the error envelope
+func writeValidationErrors(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	fields []service.FieldError,
+	logger LoggerInterface,
+) {
+	info := apierrors.ErrorCodeRegistry[apierrors.ErrCodeValidationFailed]
+	writeJSON(w, r, info.HTTPStatus, FieldErrorResponse{
+		Status: statusError,
+		Error: Error{
+			Code:    info.Code,
+			Message: message,
+		},
+		Errors: fieldErrors(fields),
+	}, logger)
+}
+
+func fieldErrors(fields []service.FieldError) []FieldError {
+	errs := make([]FieldError, len(fields))
+	for i, field := range fields {
+		errs[i] = FieldError{Field: field.Field, Message: field.Message}
+	}
+	return errs
+}
+
+// writeNotFound writes the 404 response for a missing resource. Handlers
+// that hide a resource from the caller use it too, so hidden and missing
+// look the same.
+func writeNotFound(w http.ResponseWriter, r *http.Request, logger LoggerInterface) {
+	WriteCodeResponse(w, r, apierrors.ErrCodeResourceNotFound, logger)
+}
+
+// writeParamError writes the 400 for a query parameter that ParseLimit or
+// ParseMultiValueParam rejected, listing the parameter in the error envelope
+func writeParamError(w http.ResponseWriter, r *http.Request, err error, logger LoggerInterface) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		WriteFieldErrorResponse(w, r, validationErr.Error(), validationErr.Fields, logger)
+		return
+	}
+	writeRepoError(w, r, err, logger)
+}
+
+// writeRepoError maps a repository or service error to the matching HTTP
+// error response
+func writeRepoError(w http.ResponseWriter, r *http.Request, err error, logger LoggerInterface) {
+	var transitionErr *datalayer.StatusTransitionError
+	var validationErr *service.ValidationError
+	var currencyErr *service.CurrencyError
+	switch {
+	case errors.As(err, &currencyErr):
+		info := apierrors.ErrorCodeRegistry[apierrors.ErrCodeUnsupportedCurrency]
+		WriteErrorResponse(w, r, info.HTTPStatus, info.Code, currencyErr.Error(), logger)
+		return
+	case errors.As(err, &validationErr) && len(validationErr.Fields) > 0:
+		writeValidationErrors(w, r, validationErr.Error(), validationErr.Fields, logger)
+		return
+	case errors.As(err, &validationErr):
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, validationErr.Error(), logger)
+		return
+	case errors.As(err, &transitionErr):
+		info := apierrors.ErrorCodeRegistry[apierrors.ErrCodeInvalidTransition]
+		WriteErrorResponse(w, r, info.HTTPStatus, info.Code, transitionErr.Error(), logger)
+		return
+	case datalayer.KindOf(err) == datalayer.KindNotFound:
+		writeNotFound(w, r, logger)
+		return
+	case errors.Is(err, datalayer.ErrInsufficientStock):
+		WriteCodeResponse(w, r, apierrors.ErrCodeInsufficientStock, logger)
+		return
+	case errors.Is(err, datalayer.ErrReservationClosed), errors.Is(err, datalayer.ErrReservationExpired):
+		WriteCodeResponse(w, r, apierrors.ErrCodeReservationClosed, logger)
+		return
+	case errors.Is(err, datalayer.ErrScheduleConflict):
+		WriteCodeResponse(w, r, apierrors.ErrCodeScheduleConflict, logger)
+		return
+	case errors.Is(err, datalayer.ErrScheduleApplied):
+		WriteCodeResponse(w, r, apierrors.ErrCodeScheduleApplied, logger)
+		return
+	case errors.Is(err, datalayer.ErrAttributeExists):
+		WriteCodeResponse(w, r, apierrors.ErrCodeAttributeExists, logger)
+		return
+	case errors.Is(err, datalayer.ErrCircuitOpen):
+		WriteCodeResponse(w, r, apierrors.ErrCodeDatabaseUnavailable, logger)
+		return
+	case errors.Is(err, datalayer.ErrPreconditionFailed):
+		WriteCodeResponse(w, r, apierrors.ErrCodePreconditionFailed, logger)
+		return
+	}
+
+	logger.LogError(OpFromContext(r.Context()), err)
+	WriteCodeResponse(w, r, apierrors.ErrCodeInternalServerError, logger)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, statusCode int, v any, logger LoggerInterface) {
+	body, err := marshalJSON(v, OptionsFromContext(r.Context()).Naming)
+	if err != nil {
+		logResponseError(logger, r, fmt.Errorf("failed to encode response: %w", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		logResponseError(logger, r, fmt.Errorf("failed to write response: %w", err))
+	}
+}
+
+// logResponseError logs a failure to encode or write a response. Responses
+// written outside a handler, such as the router's own 404, may have no
+// logger, in which case the failure is dropped.
+func logResponseError(logger LoggerInterface, r *http.Request, err error) {
+	if logger == nil {
+		return
+	}
+	logger.LogError(OpFromContext(r.Context()), err)
+}
+
+// ParseLimit parses the optional limit query parameter, returning 0 when it
+// is absent so the default page size is served. A limit that is not an
+// integer from datalayer.MinLimit to maxLimit is a validation error
+// naming the valid range rather than being clamped into it.
+func ParseLimit(r *http.Request, maxLimit int) (int, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < datalayer.MinLimit || limit > maxLimit {
+		return 0, &service.ValidationError{
+			Msg: "limit is invalid",
+			Fields: []service.FieldError{{
+				Field:   "limit",
+				Message: fmt.Sprintf("limit must be an integer from %d to %d", datalayer.MinLimit, maxLimit),
+			}},
+		}
+	}
+	return limit, nil
+}
+
+// parseBoolQuery parses an optional boolean query parameter, defaulting to false
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", name)
+	}
+	return value, nil
+}
+
+// DefaultMaxBatchSize is the most items a batch request may hold unless
+// Options.MaxBatchSize says otherwise
+const DefaultMaxBatchSize = 100
+
+// checkBatchSize writes a 400 naming the limit and returns false when a
+// batch of n items is over it. noun names the items, e.g. "categories".
+func checkBatchSize(w http.ResponseWriter, r *http.Request, n int, noun string, logger LoggerInterface) bool {
+	limit := OptionsFromContext(r.Context()).batchSizeLimit()
+	if n <= limit {
+		return true
+	}
+	msg := fmt.Sprintf("batch has %d %s, at most %d are allowed", n, noun, limit)
+	WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, msg, logger)
+	return false
+}
+
+// MaxMultiValues is the most values ParseMultiValueParam accepts for one
+// query parameter
+const MaxMultiValues = 50
+
+// ParseMultiValueParam collects the values of a multi-value query
+// parameter, given repeated (?name=a&name=b), comma separated (?name=a,b)
+// or both. Blank elements are skipped and duplicates are kept once, in the
+// order of their first occurrence. Each value must pass validate. More than
+// MaxMultiValues distinct values, or a value validate rejects, is a
+// ValidationError naming the parameter; the message of the latter names
+// the value. It returns nil when the parameter is absent.
+func ParseMultiValueParam(r *http.Request, name string, validate func(string) error) ([]string, error) {
+	var values []string
+	seen := map[string]bool{}
+	for _, raw := range r.URL.Query()[name] {
+		for _, value := range strings.Split(raw, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" || seen[value] {
+				continue
+			}
+			if err := validate(value); err != nil {
+				return nil, multiValueError(name, fmt.Sprintf("%s value `%s` is invalid: %v", name, value, err))
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if len(values) > MaxMultiValues {
+		return nil, multiValueError(name, fmt.Sprintf("%s accepts at most %d values", name, MaxMultiValues))
+	}
+	return values, nil
+}
+
+// multiValueError is the ValidationError of ParseMultiValueParam
+func multiValueError(name, msg string) error {
+	return &service.ValidationError{
+		Msg:    name + " is invalid",
+		Fields: []service.FieldError{{Field: name, Message: msg}},
+	}
+}
+
+// validUUIDs returns a ParseMultiValueParam validator holding each value
+// of name to the rules of parseUUID for version. Its errors are the Detail
+// of the *UUIDError, as the message already names the parameter and value.
+func validUUIDs(name string, version int) func(string) error {
+	return func(value string) error {
+		_, err := parseUUID(value, name, version)
+		var uuidErr *UUIDError
+		if errors.As(err, &uuidErr) {
+			return errors.New(uuidErr.Detail())
+		}
+		return err
+	}
+}
+
+// parseAttributeFilter collects the ?attr.<key>=value query parameters of
+// a list request. Each key may be given once. It returns nil when there
+// are none.
+func parseAttributeFilter(r *http.Request) (map[string]string, error) {
+	const prefix = "attr."
+
+	var attributes map[string]string
+	for name, values := range r.URL.Query() {
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if key == "" || len(key) > service.MaxAttributeKeyLength {
+			return nil, fmt.Errorf("attribute filter keys must be 1 to %d characters", service.MaxAttributeKeyLength)
+		}
+		if len(values) > 1 {
+			return nil, fmt.Errorf("%s%s may be given once", prefix, key)
+		}
+		if attributes == nil {
+			attributes = map[string]string{}
+		}
+		attributes[key] = values[0]
+	}
+	if len(attributes) > service.MaxCategoryAttributes {
+		return nil, fmt.Errorf("at most %d attribute filters are allowed", service.MaxCategoryAttributes)
+	}
+	return attributes, nil
+}
@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// malformedCursors are cursors that previously exposed encoding edge cases
+var malformedCursors = []string{
+	"not-a-cursor",
+	"MjAyMy0wMS0wMVQwMDowMDowMFo#",
+	"MjAyMy0wMS0wMVQwMDowMDowMFo_",
+	"MjAyMy0wMS0wMVQwMDowMDowMFo=",
+	"____",
+	"%%%%",
+	"bm90IGEgdGltZQ",
+}
+
+func TestCursorEncoding(t *testing.T) {
+	t.Run("should round trip time through cursor", func(t *testing.T) {
+		createdAt := time.Date(2023, 1, 1, 10, 30, 0, 123, time.UTC)
+		decoded, err := DecodeCursorToTime(EncodeTimeToCursor(createdAt))
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt, decoded)
+	})
+
+	t.Run("should decode empty cursor to zero time", func(t *testing.T) {
+		decoded, err := DecodeCursorToTime("")
+		assert.NoError(t, err)
+		assert.True(t, decoded.IsZero())
+	})
+
+	t.Run("should encode zero time to empty cursor", func(t *testing.T) {
+		assert.Empty(t, EncodeTimeToCursor(time.Time{}))
+		assert.Empty(t, EncodeCursor(datalayer.Cursor{Seen: []uuid.UUID{uuid.New()}}))
+	})
+
+	t.Run("should return error for malformed cursors", func(t *testing.T) {
+		for _, cursor := range malformedCursors {
+			decoded, err := DecodeCursorToTime(cursor)
+			assert.True(t, errors.Is(err, ErrInvalidCursor), cursor)
+			assert.True(t, decoded.IsZero(), cursor)
+		}
+	})
+}
+
+func TestCursorSeenEncoding(t *testing.T) {
+	createdAt := time.Date(2023, 1, 1, 10, 30, 0, 123, time.UTC)
+
+	t.Run("should round trip seen ids through cursor", func(t *testing.T) {
+		cursor := datalayer.Cursor{CreatedAfter: createdAt, AfterID: uuid.New(), Seen: []uuid.UUID{uuid.New(), uuid.New()}}
+		decoded, err := DecodeCursor(EncodeCursor(cursor))
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("should round trip the id of the last row through cursor", func(t *testing.T) {
+		cursor := datalayer.Cursor{CreatedAfter: createdAt, AfterID: uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")}
+		encoded := EncodeCursor(cursor)
+		raw, err := base64.RawURLEncoding.DecodeString(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, "2023-01-01T10:30:00.000000123Z,f2aa335f-6f91-4d4d-8057-53b0009bc376", string(raw))
+
+		decoded, err := DecodeCursor(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("should decode a cursor of seen ids without a last row id", func(t *testing.T) {
+		seen := uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")
+		raw := "2023-01-01T10:30:00.000000123Z " + seen.String()
+		decoded, err := DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte(raw)))
+		assert.NoError(t, err)
+		assert.Equal(t, datalayer.Cursor{CreatedAfter: createdAt, Seen: []uuid.UUID{seen}}, decoded)
+	})
+
+	t.Run("should encode a cursor without ids like a time cursor", func(t *testing.T) {
+		assert.Equal(t, EncodeTimeToCursor(createdAt), EncodeCursor(datalayer.Cursor{CreatedAfter: createdAt}))
+
+		decoded, err := DecodeCursor(EncodeTimeToCursor(createdAt))
+		assert.NoError(t, err)
+		assert.Equal(t, datalayer.Cursor{CreatedAfter: createdAt}, decoded)
+	})
+
+	t.Run("should return error for malformed seen ids", func(t *testing.T) {
+		for _, raw := range []string{
+			"2023-01-01T10:30:00Z not-a-uuid",
+			"2023-01-01T10:30:00Z f2aa335f6f914d4d805753b0009bc376",
+			"2023-01-01T10:30:00Z ",
+			"2023-01-01T10:30:00Z,not-a-uuid",
+			"2023-01-01T10:30:00Z,",
+		} {
+			_, err := DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte(raw)))
+			assert.True(t, errors.Is(err, ErrInvalidCursor), raw)
+		}
+	})
+}
+
+func TestGroupCursorEncoding(t *testing.T) {
+	t.Run("should round trip a group key with inner spaces", func(t *testing.T) {
+		key := datalayer.DuplicateGroupKey{CategoryID: uuid.New(), NormalizedName: "ünïcode  desk lamp"}
+		decoded, err := DecodeGroupCursor(EncodeGroupCursor(key))
+		assert.NoError(t, err)
+		assert.Equal(t, key, decoded)
+	})
+
+	t.Run("should decode empty cursor to the zero key", func(t *testing.T) {
+		decoded, err := DecodeGroupCursor("")
+		assert.NoError(t, err)
+		assert.Equal(t, datalayer.DuplicateGroupKey{}, decoded)
+	})
+
+	t.Run("should return error for malformed cursors", func(t *testing.T) {
+		for _, cursor := range append(malformedCursors, EncodeTimeToCursor(time.Now())) {
+			_, err := DecodeGroupCursor(cursor)
+			assert.True(t, errors.Is(err, ErrInvalidCursor), cursor)
+		}
+	})
+}
+
+func TestParseUUIDParam(t *testing.T) {
+	id := uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")
+
+	tests := []struct {
+		name       string
+		value      string
+		wantID     uuid.UUID
+		wantReason UUIDErrorReason
+		wantErr    string
+	}{
+		{"valid uuid", id.String(), id, "", ""},
+		{"missing", "", uuid.Nil, UUIDMissing, "invalid uuid: id is required"},
+		{"blank", "   ", uuid.Nil, UUIDBlank, "invalid uuid: id must not be blank"},
+		{"nil uuid", uuid.Nil.String(), uuid.Nil, UUIDNil, "invalid uuid: id must not be nil"},
+		{"unhyphenated", strings.ReplaceAll(id.String(), "-", ""), uuid.Nil, UUIDInvalidFormat, "invalid uuid: id must be a UUID in canonical form"},
+		{"braced", "{" + id.String() + "}", uuid.Nil, UUIDInvalidFormat, "invalid uuid: id must be a UUID in canonical form"},
+		{"invalid characters", "z2aa335f-6f91-4d4d-8057-53b0009bc376", uuid.Nil, UUIDInvalidFormat, "invalid uuid: id must be a UUID in canonical form"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/categories/x", nil)
+			req.SetPathValue("id", tt.value)
+			parsed, err := ParseUUIDParam(req, "id")
+			assert.Equal(t, tt.wantID, parsed)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrInvalidUUID))
+			assert.Equal(t, tt.wantErr, err.Error())
+
+			var uuidErr *UUIDError
+			require.True(t, errors.As(err, &uuidErr))
+			assert.Equal(t, "id", uuidErr.Param)
+			assert.Equal(t, tt.wantReason, uuidErr.Reason)
+		})
+	}
+}
+
+func TestParseUUIDParamVersion(t *testing.T) {
+	v4 := uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")
+	v1 := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	parse := func(id uuid.UUID, version int) (uuid.UUID, error) {
+		req := httptest.NewRequest("GET", "/categories/x", nil)
+		req = req.WithContext(WithOptions(req.Context(), Options{UUIDVersion: version}))
+		req.SetPathValue("id", id.String())
+		return ParseUUIDParam(req, "id")
+	}
+
+	t.Run("should accept any version by default", func(t *testing.T) {
+		parsed, err := parse(v1, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, v1, parsed)
+	})
+
+	t.Run("should reject another version than the configured one", func(t *testing.T) {
+		parsed, err := parse(v4, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, v4, parsed)
+
+		_, err = parse(v1, 4)
+		var uuidErr *UUIDError
+		require.True(t, errors.As(err, &uuidErr))
+		assert.Equal(t, UUIDWrongVersion, uuidErr.Reason)
+		assert.Equal(t, "invalid uuid: id must be a version 4 UUID", err.Error())
+	})
+}
+
+func TestParseMultiValueParam(t *testing.T) {
+	a := "f2aa335f-6f91-4d4d-8057-53b0009bc376"
+	b := "0b8a7c1e-3a8f-4e6b-9a51-2f6e2b5f7d10"
+	c := "6d1f0c2a-98b4-4f0e-8a3c-5c9e7a1b2d34"
+	parseVersion := func(query string, version int) ([]string, error) {
+		return ParseMultiValueParam(httptest.NewRequest("GET", "/products?"+query, nil), "category_id", validUUIDs("category_id", version))
+	}
+	parse := func(query string) ([]string, error) {
+		return parseVersion(query, 0)
+	}
+
+	t.Run("should accept repeated and comma separated values together", func(t *testing.T) {
+		values, err := parse("category_id=" + a + "," + b + "&category_id=" + c)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{a, b, c}, values)
+	})
+
+	t.Run("should keep duplicates once and skip blank elements", func(t *testing.T) {
+		values, err := parse("category_id=" + a + ",," + b + "&category_id=%20" + a + "%20,&category_id=" + b)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{a, b}, values)
+	})
+
+	t.Run("should return nil if absent", func(t *testing.T) {
+		values, err := parse("")
+		assert.NoError(t, err)
+		assert.Nil(t, values)
+	})
+
+	t.Run("should reject more than the maximum number of values", func(t *testing.T) {
+		ids := make([]string, MaxMultiValues+1)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+		}
+		values, err := parse("category_id=" + strings.Join(ids[:MaxMultiValues], ","))
+		assert.NoError(t, err)
+		assert.Len(t, values, MaxMultiValues)
+
+		values, err = parse("category_id=" + strings.Join(ids, ","))
+		assert.Nil(t, values)
+		var validationErr *service.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []service.FieldError{{Field: "category_id", Message: "category_id accepts at most 50 values"}}, validationErr.Fields)
+	})
+
+	t.Run("should name the one invalid value among valid ones", func(t *testing.T) {
+		values, err := parse("category_id=" + a + ",nope&category_id=" + b)
+		assert.Nil(t, values)
+		var validationErr *service.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Fields, 1)
+		assert.Equal(t, "category_id", validationErr.Fields[0].Field)
+		assert.Contains(t, validationErr.Fields[0].Message, "category_id value `nope` is invalid")
+	})
+
+	t.Run("should hold values to the rules of path parameters", func(t *testing.T) {
+		tests := []struct {
+			value  string
+			detail string
+		}{
+			{"urn:uuid:" + a, "must be a UUID in canonical form"},
+			{"{" + a + "}", "must be a UUID in canonical form"},
+			{strings.ReplaceAll(a, "-", ""), "must be a UUID in canonical form"},
+			{uuid.Nil.String(), "must not be nil"},
+			{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "must be a version 4 UUID"},
+		}
+		for _, tt := range tests {
+			values, err := parseVersion("category_id="+url.QueryEscape(tt.value), 4)
+			assert.Nil(t, values, tt.value)
+			var validationErr *service.ValidationError
+			require.ErrorAs(t, err, &validationErr, tt.value)
+			require.Len(t, validationErr.Fields, 1)
+			assert.Equal(t, "category_id value `"+tt.value+"` is invalid: "+tt.detail, validationErr.Fields[0].Message)
+		}
+	})
+}
+
+func TestParseOptionalUUIDQuery(t *testing.T) {
+	id := uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")
+	parse := func(query string) (*uuid.UUID, error) {
+		return ParseOptionalUUIDQuery(httptest.NewRequest("GET", "/products?"+query, nil), "category_id")
+	}
+
+	t.Run("should return the uuid", func(t *testing.T) {
+		parsed, err := parse("category_id=" + id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, &id, parsed)
+	})
+
+	t.Run("should return nil if absent or empty", func(t *testing.T) {
+		for _, query := range []string{"", "category_id="} {
+			parsed, err := parse(query)
+			assert.NoError(t, err)
+			assert.Nil(t, parsed)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantReason UUIDErrorReason
+	}{
+		{"blank", "category_id=%20%20", UUIDBlank},
+		{"invalid format", "category_id=abc", UUIDInvalidFormat},
+		{"nil uuid", "category_id=" + uuid.Nil.String(), UUIDNil},
+	}
+	for _, tt := range tests {
+		t.Run("should reject a "+tt.name+" value", func(t *testing.T) {
+			parsed, err := parse(tt.query)
+			assert.Nil(t, parsed)
+			var uuidErr *UUIDError
+			require.True(t, errors.As(err, &uuidErr))
+			assert.Equal(t, "category_id", uuidErr.Param)
+			assert.Equal(t, tt.wantReason, uuidErr.Reason)
+		})
+	}
+}
+
+func TestWriteUUIDError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/products/abc", nil)
+	writeUUIDError(rec, req, &UUIDError{Param: "id", Reason: UUIDInvalidFormat}, nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var body FieldErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, body.Error.Code)
+	assert.Equal(t, "invalid uuid: id must be a UUID in canonical form", body.Error.Message)
+	assert.Equal(t, []FieldError{{Field: "id", Message: "must be a UUID in canonical form"}}, body.Errors)
+}
+
+func TestWriteBatchErrorResponse(t *testing.T) {
+	t.Run("should serialise every item error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		WriteBatchErrorResponse(rec, req, []BatchItemError{
+			{Index: 0, Field: "name", Message: "is required"},
+			{Index: 2, Field: "price", Message: "must be positive"},
+		}, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var resp BatchErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, statusError, resp.Status)
+		assert.Equal(t, apierrors.ErrCodeValidationFailed, resp.Error.Code)
+		assert.Equal(t, []BatchItemError{
+			{Index: 0, Field: "name", Message: "is required"},
+			{Index: 2, Field: "price", Message: "must be positive"},
+		}, resp.Errors)
+		testutil.AssertGolden(t, "batch_error_response", rec.Body.Bytes())
+	})
+
+	t.Run("should serialise nil errors as an empty array", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		WriteBatchErrorResponse(rec, req, nil, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"errors":[]`)
+	})
+}
+
+func TestWriteCodeResponse(t *testing.T) {
+	t.Run("should write the registered status and message", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteCodeResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), apierrors.ErrCodeResourceNotFound, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"status":"error","error":{"code":1300,"message":"resource not found"}}`, rec.Body.String())
+	})
+
+	t.Run("should fall back to internal server error for unregistered codes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteCodeResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), 9999, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":1600`)
+	})
+}
+
+func TestSuccessResponseGeneratedAt(t *testing.T) {
+	writers := map[string]func(http.ResponseWriter, *http.Request){
+		"WriteSuccessResponse": func(w http.ResponseWriter, r *http.Request) {
+			WriteSuccessResponse(w, r, http.StatusOK, "ok", nil, nil)
+		},
+		"WriteListResponse": func(w http.ResponseWriter, r *http.Request) {
+			WriteListResponse(w, r, "ok", []string{}, &Pagination{}, nil)
+		},
+		"WriteConditionalListResponse": func(w http.ResponseWriter, r *http.Request) {
+			WriteConditionalListResponse(w, r, "ok", []string{}, &Pagination{}, time.Time{}, nil)
+		},
+	}
+	for name, write := range writers {
+		t.Run(name+" should stamp the response with the time it was generated", func(t *testing.T) {
+			start := time.Now().UTC().Truncate(time.Second)
+			rec := httptest.NewRecorder()
+			write(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var resp SuccessResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			generated, err := time.Parse(time.RFC3339, resp.GeneratedAt)
+			require.NoError(t, err)
+			assert.Equal(t, time.UTC, generated.Location())
+			assert.WithinDuration(t, start, generated, 2*time.Second)
+			assert.False(t, generated.Before(start))
+		})
+	}
+}
+
+func TestSuccessResponseMessage(t *testing.T) {
+	writeWith := func(opts Options, message string) map[string]any {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		WriteSuccessResponse(rec, req.WithContext(WithOptions(req.Context(), opts)), http.StatusOK, message, []string{}, nil)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+	write := func(message string) map[string]any {
+		return writeWith(Options{}, message)
+	}
+
+	t.Run("should send the message by default", func(t *testing.T) {
+		assert.Equal(t, "categories retrieved", write("categories retrieved")[JSONKeyMessage])
+	})
+
+	t.Run("should omit an empty message", func(t *testing.T) {
+		body := write("")
+		assert.NotContains(t, body, JSONKeyMessage)
+		assert.Equal(t, statusSuccess, body[JSONKeyStatus])
+	})
+
+	t.Run("should omit every message once disabled", func(t *testing.T) {
+		assert.NotContains(t, writeWith(Options{OmitSuccessMessages: true}, "categories retrieved"), JSONKeyMessage)
+	})
+}
+
+func TestParseDeleteStyle(t *testing.T) {
+	style, err := ParseDeleteStyle("envelope")
+	assert.NoError(t, err)
+	assert.Equal(t, DeleteEnvelope, style)
+
+	_, err = ParseDeleteStyle("empty")
+	assert.EqualError(t, err, "unsupported delete response style `empty`")
+}
+
+func TestLoggerLogError(t *testing.T) {
+	decode := func(t *testing.T, out *strings.Builder) map[string]any {
+		t.Helper()
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out.String()), &entry))
+		return entry
+	}
+
+	t.Run("should add the repo op, entity and kind of a repo error", func(t *testing.T) {
+		var out strings.Builder
+		repoErr := &datalayer.RepoError{Op: "getProductByID", Entity: datalayer.EntityProduct, Kind: datalayer.KindNotFound, Err: datalayer.ErrNotFound}
+		NewLogger(&out).LogError("GetProduct", repoErr)
+
+		entry := decode(t, &out)
+		assert.Equal(t, "getProductByID: not found", entry["msg"])
+		assert.Equal(t, "GetProduct", entry["op"])
+		assert.Equal(t, "getProductByID", entry["repoOp"])
+		assert.Equal(t, "product", entry["entity"])
+		assert.Equal(t, "not_found", entry["kind"])
+	})
+
+	t.Run("should log other errors with the op only", func(t *testing.T) {
+		var out strings.Builder
+		NewLogger(&out).LogError("GetProduct", errors.New("boom"))
+
+		entry := decode(t, &out)
+		assert.Equal(t, "boom", entry["msg"])
+		assert.NotContains(t, entry, "kind")
+	})
+}
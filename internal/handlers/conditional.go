@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+)
+
+// WriteConditionalListResponse is WriteListResponse with cache validators.
+// The ETag is the SHA-256 of the response body, less its GeneratedAt
+// stamp, and Last-Modified is lastModified, omitted when zero. A request
+// whose If-None-Match or If-Modified-Since still holds gets an empty 304
+// instead of the body.
+func WriteConditionalListResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	data any,
+	pagination *Pagination,
+	lastModified time.Time,
+	logger LoggerInterface,
+) {
+	response := SuccessResponse{
+		Status:     statusSuccess,
+		Message:    successMessage(r, message),
+		Data:       data,
+		Pagination: pagination,
+	}
+	// the ETag covers the body without GeneratedAt, which changes every
+	// second while the content does not
+	naming := OptionsFromContext(r.Context()).Naming
+	unstamped, err := marshalJSON(response, naming)
+	if err != nil {
+		logResponseError(logger, r, fmt.Errorf("failed to encode response: %w", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	response.GeneratedAt = generatedAt()
+	body, err := marshalJSON(response, naming)
+	if err != nil {
+		logResponseError(logger, r, fmt.Errorf("failed to encode response: %w", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(unstamped)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	// HTTP dates have second precision
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	setPageLimit(w, pagination)
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logResponseError(logger, r, fmt.Errorf("failed to write response: %w", err))
+	}
+}
+
+// notModified reports whether the request's validators still match. As in
+// RFC 9110, If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.After(since)
+}
+
+// resourceETag returns the strong ETag of a stored resource, the SHA-256
+// of its camelCase JSON encoding, so it does not change with the naming
+// strategy. GET handlers send it and update handlers compare If-Match
+// against it.
+func resourceETag(resource any) (string, error) {
+	body, err := marshalJSON(resource, NamingCamelCase)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resource: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`, nil
+}
+
+// ifMatch reports whether the request's If-Match header lists etag or is
+// "*". As in RFC 9110, If-Match uses the strong comparison, so weak tags
+// never match.
+func ifMatch(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// setResourceETag sets the ETag header for resource. A resource that cannot
+// be encoded is sent without one; the encoding failure surfaces when the
+// body is written.
+func setResourceETag(w http.ResponseWriter, resource any) {
+	if etag, err := resourceETag(resource); err == nil {
+		w.Header().Set("ETag", etag)
+	}
+}
+
+// ifMatchPrecondition returns the Precondition of an update that honors
+// r's If-Match header, or nil when there is none. It runs against the
+// resource as stored, in the repo's transaction, so no write can slip in
+// between the check and the update. A mismatch is
+// datalayer.ErrPreconditionFailed.
+func ifMatchPrecondition[T any](r *http.Request) func(current *T) error {
+	if r.Header.Get("If-Match") == "" {
+		return nil
+	}
+	return func(current *T) error {
+		etag, err := resourceETag(current)
+		if err != nil {
+			return err
+		}
+		if !ifMatch(r, etag) {
+			return datalayer.ErrPreconditionFailed
+		}
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+func FuzzDecodeCursorToTime(f *testing.F) {
+	for _, cursor := range malformedCursors {
+		f.Add(cursor)
+	}
+	f.Add(EncodeTimeToCursor(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	f.Fuzz(func(t *testing.T, cursor string) {
+		decoded, err := DecodeCursorToTime(cursor)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("unexpected error type for %q: %v", cursor, err)
+			}
+			if !decoded.IsZero() {
+				t.Fatalf("non-zero time %v returned with error for %q", decoded, cursor)
+			}
+			return
+		}
+
+		again, err := DecodeCursorToTime(EncodeTimeToCursor(decoded))
+		if err != nil || !again.Equal(decoded) {
+			t.Fatalf("cursor %q decoded to %v which does not round trip: %v, %v", cursor, decoded, again, err)
+		}
+	})
+}
+
+func FuzzEncodeDecodeCursorToTime(f *testing.F) {
+	f.Add(int64(0), int64(0))
+	f.Add(int64(1672531200), int64(123456789))
+
+	f.Fuzz(func(t *testing.T, sec int64, nsec int64) {
+		createdAt := time.Unix(sec, nsec).UTC()
+		if createdAt.Year() < 0 || createdAt.Year() > 9999 {
+			t.Skip("RFC3339 only supports four digit years")
+		}
+
+		decoded, err := DecodeCursorToTime(EncodeTimeToCursor(createdAt))
+		if err != nil {
+			t.Fatalf("encode/decode failed for %v: %v", createdAt, err)
+		}
+		if !decoded.Equal(createdAt) {
+			t.Fatalf("round trip mismatch: got %v, want %v", decoded, createdAt)
+		}
+	})
+}
+
+func FuzzDecodeCursor(f *testing.F) {
+	for _, cursor := range malformedCursors {
+		f.Add(cursor)
+	}
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.Add(EncodeTimeToCursor(createdAt))
+	f.Add(EncodeCursor(datalayer.Cursor{CreatedAfter: createdAt, Seen: []uuid.UUID{uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")}}))
+	f.Add(EncodeCursor(datalayer.Cursor{CreatedAfter: createdAt, AfterID: uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")}))
+
+	f.Fuzz(func(t *testing.T, cursor string) {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("unexpected error type for %q: %v", cursor, err)
+			}
+			if !decoded.CreatedAfter.IsZero() || decoded.AfterID != uuid.Nil || decoded.Seen != nil {
+				t.Fatalf("non-zero cursor %+v returned with error for %q", decoded, cursor)
+			}
+			return
+		}
+		if len(decoded.Seen) > datalayer.MaxCursorSeen {
+			t.Fatalf("cursor %q decoded to %d seen ids", cursor, len(decoded.Seen))
+		}
+
+		again, err := DecodeCursor(EncodeCursor(decoded))
+		if err != nil || !again.CreatedAfter.Equal(decoded.CreatedAfter) || again.AfterID != decoded.AfterID || !slices.Equal(again.Seen, decoded.Seen) {
+			t.Fatalf("cursor %q decoded to %+v which does not round trip: %+v, %v", cursor, decoded, again, err)
+		}
+	})
+}
+
+func FuzzEncodeDecodeCursor(f *testing.F) {
+	f.Add(int64(1672531200), int64(0), []byte{}, []byte{})
+	f.Add(int64(1672531200), int64(123456789), []byte("f2aa335f6f914d4d"), []byte("f2aa335f6f914d4d805753b0009bc376"))
+
+	f.Fuzz(func(t *testing.T, sec int64, nsec int64, afterID []byte, ids []byte) {
+		cursor := datalayer.Cursor{CreatedAfter: time.Unix(sec, nsec).UTC()}
+		if len(afterID) >= 16 {
+			cursor.AfterID = uuid.UUID(afterID[:16])
+		}
+		if cursor.CreatedAfter.Year() < 0 || cursor.CreatedAfter.Year() > 9999 {
+			t.Skip("RFC3339 only supports four digit years")
+		}
+		if cursor.CreatedAfter.IsZero() {
+			t.Skip("the zero time encodes to the empty cursor")
+		}
+		for len(ids) >= 16 && len(cursor.Seen) < datalayer.MaxCursorSeen {
+			cursor.Seen = append(cursor.Seen, uuid.UUID(ids[:16]))
+			ids = ids[16:]
+		}
+
+		decoded, err := DecodeCursor(EncodeCursor(cursor))
+		if err != nil {
+			t.Fatalf("encode/decode failed for %+v: %v", cursor, err)
+		}
+		if !decoded.CreatedAfter.Equal(cursor.CreatedAfter) || decoded.AfterID != cursor.AfterID || !slices.Equal(decoded.Seen, cursor.Seen) {
+			t.Fatalf("round trip mismatch: got %+v, want %+v", decoded, cursor)
+		}
+	})
+}
+
+func FuzzParseUUIDParam(f *testing.F) {
+	f.Add("f2aa335f-6f91-4d4d-8057-53b0009bc376")
+	f.Add("00000000-0000-0000-0000-000000000000")
+	f.Add("urn:uuid:f2aa335f-6f91-4d4d-8057-53b0009bc376")
+	f.Add("{f2aa335f-6f91-4d4d-8057-53b0009bc376}")
+	f.Add("f2aa335f6f914d4d805753b0009bc376")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.SetPathValue("id", raw)
+
+		id, err := ParseUUIDParam(req, "id")
+		if err != nil {
+			if !errors.Is(err, ErrInvalidUUID) {
+				t.Fatalf("unexpected error type for %q: %v", raw, err)
+			}
+			if id != uuid.Nil {
+				t.Fatalf("non-nil id %v returned with error for %q", id, raw)
+			}
+			return
+		}
+
+		if id == uuid.Nil {
+			t.Fatalf("nil id accepted for %q", raw)
+		}
+		if !strings.EqualFold(id.String(), raw) {
+			t.Fatalf("non canonical input %q accepted as %v", raw, id)
+		}
+	})
+}
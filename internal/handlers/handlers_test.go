@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testCategory = datalayer.Category{
+	ID:          uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376"),
+	Name:        "Test Category A",
+	Description: "Test category a description",
+	CreatedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var testProduct = datalayer.Product{
+	ID:          uuid.MustParse("b12f2176-28ca-4acf-85b9-cc97ca1b3cf6"),
+	Name:        "Test Product A",
+	Description: "Test product a description",
+	ImageURL:    "test/image/url",
+	CategoryID:  testCategory.ID,
+	Price:       234.85,
+	Currency:    "USD",
+	Quantity:    20,
+	Status:      datalayer.ProductActive,
+	CreatedAt:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var testDefinition = datalayer.AttributeDefinition{
+	ID:            uuid.MustParse("3f9d1c2e-5b7a-4e8f-9a6d-2c4b8e1f7a3d"),
+	CategoryID:    testCategory.ID,
+	Name:          "finish",
+	Type:          datalayer.AttributeEnum,
+	Required:      true,
+	AllowedValues: datalayer.EnumValues{"matte", "gloss"},
+	CreatedAt:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// lampDefinitions returns testDefinition and an optional number attribute
+func lampDefinitions() []*datalayer.AttributeDefinition {
+	finish := testDefinition
+	return []*datalayer.AttributeDefinition{
+		&finish,
+		{CategoryID: testCategory.ID, Name: "wattage", Type: datalayer.AttributeNumber, AllowedValues: datalayer.EnumValues{}},
+	}
+}
+
+type routeRegistrar interface {
+	RegisterRoutes(router *handlers.Router)
+}
+
+// serve routes a request through a router with h registered
+func serve(h routeRegistrar, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	return serveWithOptions(handlers.Options{}, h, method, target, body)
+}
+
+// serveWithOptions is serve through a router with opts
+func serveWithOptions(opts handlers.Options, h routeRegistrar, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	router := handlers.NewRouter(opts)
+	h.RegisterRoutes(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, body))
+	return rec
+}
+
+// serveWithHeader is serve with request headers
+func serveWithHeader(h routeRegistrar, method, target string, body io.Reader, header http.Header) *httptest.ResponseRecorder {
+	router := handlers.NewRouter(handlers.Options{})
+	h.RegisterRoutes(router)
+
+	req := httptest.NewRequest(method, target, body)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorCode decodes the error code from an error response body
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) int {
+	t.Helper()
+
+	var body struct {
+		Error struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Error.Code
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+type InventoryHandler struct {
+	repo   datalayer.InventoryRepoInterface
+	logger LoggerInterface
+}
+
+// adjustStockRequest is the body of POST /products/{id}/stock-adjustments
+type adjustStockRequest struct {
+	Delta  int                      `json:"delta"`
+	Reason datalayer.MovementReason `json:"reason"`
+	Note   *string                  `json:"note"`
+}
+
+// NewInventoryHandler creates a new inventory handler instance
+func NewInventoryHandler(repo datalayer.InventoryRepoInterface, logger LoggerInterface) *InventoryHandler {
+	return &InventoryHandler{repo: repo, logger: logger}
+}
+
+// RegisterRoutes registers the inventory endpoints on the router
+func (h *InventoryHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("POST /products/{id}/stock-adjustments", h.AdjustStock)
+	router.HandleFunc("GET /products/{id}/movements", h.ListMovements)
+}
+
+// AdjustStock changes a product's stock by delta and records the movement
+// with its reason code
+func (h *InventoryHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	productID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req adjustStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+	if req.Delta == 0 {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "delta must not be 0", h.logger)
+		return
+	}
+	if !req.Reason.Valid() {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, reasonMessage(), h.logger)
+		return
+	}
+
+	movement := &datalayer.InventoryMovement{
+		ProductID: productID,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+		Note:      req.Note,
+	}
+	if err := h.repo.AdjustStock(r.Context(), movement); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "stock adjusted", movement, h.logger)
+}
+
+// ListMovements returns a page of a product's stock movements, optionally
+// narrowed to a single ?reason
+func (h *InventoryHandler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	productID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	cursor, err := DecodeCursorToTime(r.URL.Query().Get("cursor"))
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "cursor is invalid", h.logger)
+		return
+	}
+
+	limit, err := ParseLimit(r, datalayer.MaxLimit)
+	if err != nil {
+		writeParamError(w, r, err, h.logger)
+		return
+	}
+
+	filter := datalayer.MovementFilter{Reason: datalayer.MovementReason(r.URL.Query().Get("reason"))}
+	if filter.Reason != "" && !filter.Reason.Valid() {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, reasonMessage(), h.logger)
+		return
+	}
+
+	page, err := h.repo.ListMovements(r.Context(), productID, filter, cursor, limit)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	var next string
+	if page.HasMore {
+		next = EncodeTimeToCursor(page.NextCursor)
+	}
+	pagination := newPagination(datalayer.ClampLimit(limit), next)
+	WriteListResponse(w, r, "movements retrieved", page.Movements, pagination, h.logger)
+}
+
+// reasonMessage lists the accepted reason codes for validation errors
+func reasonMessage() string {
+	reasons := make([]string, len(datalayer.MovementReasons))
+	for i, reason := range datalayer.MovementReasons {
+		reasons[i] = string(reason)
+	}
+	return "reason must be one of: " + strings.Join(reasons, ", ")
+}
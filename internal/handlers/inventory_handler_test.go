@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMovement = datalayer.InventoryMovement{
+	ID:            uuid.MustParse("6a1f0d3e-9c4b-4c8e-8f57-2d7b5e0c9a14"),
+	ProductID:     testProduct.ID,
+	Delta:         -2,
+	Reason:        datalayer.ReasonDamaged,
+	QuantityAfter: 8,
+	CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestInventoryHandlerAdjustStock(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/stock-adjustments"
+
+	t.Run("should record the movement", func(t *testing.T) {
+		repo := &mocks.MockInventoryRepo{
+			AdjustStockFunc: func(_ context.Context, movement *datalayer.InventoryMovement) error {
+				assert.Equal(t, testProduct.ID, movement.ProductID)
+				assert.Equal(t, -2, movement.Delta)
+				assert.Equal(t, datalayer.ReasonDamaged, movement.Reason)
+				*movement = testMovement
+				return nil
+			},
+		}
+		h := handlers.NewInventoryHandler(repo, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"delta":-2,"reason":"damaged"}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		testutil.AssertGolden(t, "adjust_stock", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if reason is unknown", func(t *testing.T) {
+		h := handlers.NewInventoryHandler(&mocks.MockInventoryRepo{}, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"delta":1,"reason":"lost"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "adjust_stock_invalid_reason", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if delta is 0", func(t *testing.T) {
+		h := handlers.NewInventoryHandler(&mocks.MockInventoryRepo{}, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"delta":0,"reason":"recount"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "delta must not be 0")
+	})
+
+	t.Run("should return 409 if stock would go below zero", func(t *testing.T) {
+		repo := &mocks.MockInventoryRepo{
+			AdjustStockFunc: func(context.Context, *datalayer.InventoryMovement) error {
+				return fmt.Errorf("adjustStock: %w: adjusting 1 by -2", datalayer.ErrInsufficientStock)
+			},
+		}
+		h := handlers.NewInventoryHandler(repo, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"delta":-2,"reason":"sold"}`))
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "insufficient stock")
+	})
+
+	t.Run("should return 404 if product not found", func(t *testing.T) {
+		repo := &mocks.MockInventoryRepo{
+			AdjustStockFunc: func(context.Context, *datalayer.InventoryMovement) error {
+				return fmt.Errorf("adjustStock: %w: product id `%s`", datalayer.ErrNotFound, testProduct.ID)
+			},
+		}
+		h := handlers.NewInventoryHandler(repo, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"delta":3,"reason":"received"}`))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestInventoryHandlerListMovements(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/movements"
+
+	t.Run("should pass the reason filter through", func(t *testing.T) {
+		repo := &mocks.MockInventoryRepo{
+			ListMovementsFunc: func(
+				_ context.Context,
+				productID uuid.UUID,
+				filter datalayer.MovementFilter,
+				_ time.Time,
+				limit int,
+			) (*datalayer.MovementPage, error) {
+				assert.Equal(t, testProduct.ID, productID)
+				assert.Equal(t, datalayer.ReasonDamaged, filter.Reason)
+				assert.Equal(t, 1, limit)
+				return &datalayer.MovementPage{
+					Movements:  []*datalayer.InventoryMovement{&testMovement},
+					NextCursor: testMovement.CreatedAt,
+					HasMore:    true,
+				}, nil
+			},
+		}
+		h := handlers.NewInventoryHandler(repo, handlermocks.NewLogger())
+		rec := serve(h, http.MethodGet, target+"?reason=damaged&limit=1", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_movements_has_more", rec.Body.Bytes())
+	})
+
+	t.Run("should not hand out a zero time cursor", func(t *testing.T) {
+		repo := &mocks.MockInventoryRepo{
+			ListMovementsFunc: func(
+				context.Context, uuid.UUID, datalayer.MovementFilter, time.Time, int,
+			) (*datalayer.MovementPage, error) {
+				return &datalayer.MovementPage{Movements: []*datalayer.InventoryMovement{}, HasMore: true}, nil
+			},
+		}
+		rec := serve(handlers.NewInventoryHandler(repo, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "nextCursor")
+		assert.Contains(t, rec.Body.String(), `"hasMore":false`)
+	})
+
+	t.Run("should return 400 if reason is unknown", func(t *testing.T) {
+		h := handlers.NewInventoryHandler(&mocks.MockInventoryRepo{}, handlermocks.NewLogger())
+		rec := serve(h, http.MethodGet, target+"?reason=lost", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "reason must be one of")
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &mocks.MockInventoryRepo{
+			ListMovementsFunc: func(
+				context.Context, uuid.UUID, datalayer.MovementFilter, time.Time, int,
+			) (*datalayer.MovementPage, error) {
+				return nil, errors.New("listMovements: select query failed: boom")
+			},
+		}
+		h := handlers.NewInventoryHandler(repo, handlermocks.NewLogger())
+		rec := serve(h, http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
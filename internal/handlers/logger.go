@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+)
+
+//go:generate go run github.com/matryer/moq@v0.5.3 -pkg mocks -out mocks/logger_mock.go . LoggerInterface
+
+type LoggerInterface interface {
+	LogInfo(op string, msg string)
+	LogError(op string, err error)
+	LogRequest(req RequestLogEntry)
+}
+
+// RequestLogEntry is one access log line: what was requested, by whom and
+// how the server responded
+type RequestLogEntry struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Duration   time.Duration
+	RequestID  string
+	RemoteAddr string
+	UserAgent  string
+}
+
+type Logger struct {
+	logger *slog.Logger
+}
+
+// NewLogger creates a JSON structured logger writing to w
+func NewLogger(w io.Writer) LoggerInterface {
+	return &Logger{logger: slog.New(slog.NewJSONHandler(w, nil))}
+}
+
+// LogInfo logs an informational message for the given operation
+func (l *Logger) LogInfo(op string, msg string) {
+	l.logger.Info(msg, slog.String("op", op))
+}
+
+// LogError logs an error for the given operation. Repo errors add the
+// repo method, entity and kind, so failures can be counted by class.
+func (l *Logger) LogError(op string, err error) {
+	attrs := []any{slog.String("op", op)}
+	var repoErr *datalayer.RepoError
+	if errors.As(err, &repoErr) {
+		attrs = append(attrs,
+			slog.String("repoOp", repoErr.Op),
+			slog.String("entity", repoErr.Entity),
+			slog.String("kind", string(repoErr.Kind)),
+		)
+	}
+	l.logger.Error(err.Error(), attrs...)
+}
+
+// LogRequest logs a completed HTTP request
+func (l *Logger) LogRequest(req RequestLogEntry) {
+	l.logger.Info("request",
+		slog.String("method", req.Method),
+		slog.String("path", req.Path),
+		slog.Int("status", req.StatusCode),
+		slog.Duration("duration", req.Duration),
+		slog.String("requestId", req.RequestID),
+		slog.String("remoteAddr", req.RemoteAddr),
+		slog.String("userAgent", req.UserAgent),
+	)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+// MaintenanceMode is the switch that makes the API refuse writes while
+// reads keep working. It is safe for concurrent use; a nil *MaintenanceMode
+// is always off.
+type MaintenanceMode struct {
+	enabled atomic.Bool
+}
+
+// Enabled reports whether maintenance mode is on
+func (m *MaintenanceMode) Enabled() bool {
+	return m != nil && m.enabled.Load()
+}
+
+// Set turns maintenance mode on or off
+func (m *MaintenanceMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+type MaintenanceHandler struct {
+	mode   *MaintenanceMode
+	logger LoggerInterface
+}
+
+// Maintenance is the body of GET and PUT /maintenance
+type Maintenance struct {
+	Enabled bool `json:"enabled"`
+}
+
+// maintenanceRequest is the body of PUT /maintenance
+type maintenanceRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// NewMaintenanceHandler creates a new maintenance handler instance
+// switching mode
+func NewMaintenanceHandler(mode *MaintenanceMode, logger LoggerInterface) *MaintenanceHandler {
+	return &MaintenanceHandler{mode: mode, logger: logger}
+}
+
+// RegisterRoutes registers the maintenance endpoints on the router
+func (h *MaintenanceHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("GET /maintenance", h.GetMaintenance)
+	router.HandleFunc("PUT /maintenance", h.SetMaintenance)
+}
+
+// GetMaintenance reports whether maintenance mode is on. It is only
+// available to admins.
+func (h *MaintenanceHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !IsAdmin(r.Context()) {
+		WriteErrorResponse(w, r, http.StatusForbidden, apierrors.ErrCodeForbidden, "admin role required to view maintenance mode", h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "maintenance mode retrieved", Maintenance{Enabled: h.mode.Enabled()}, h.logger)
+}
+
+// SetMaintenance turns maintenance mode on or off without a restart. It
+// is only available to admins.
+func (h *MaintenanceHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !IsAdmin(r.Context()) {
+		WriteErrorResponse(w, r, http.StatusForbidden, apierrors.ErrCodeForbidden, "admin role required to change maintenance mode", h.logger)
+		return
+	}
+
+	var req maintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+	if req.Enabled == nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "enabled is required", h.logger)
+		return
+	}
+
+	h.mode.Set(*req.Enabled)
+	if *req.Enabled {
+		h.logger.LogInfo(OpFromContext(r.Context()), "maintenance mode turned on")
+	} else {
+		h.logger.LogInfo(OpFromContext(r.Context()), "maintenance mode turned off")
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "maintenance mode updated", Maintenance{Enabled: *req.Enabled}, h.logger)
+}
@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveMaintenance is serve for requests made with the admin role
+func serveMaintenance(h *handlers.MaintenanceHandler, method string, body io.Reader) *httptest.ResponseRecorder {
+	router := handlers.NewRouter(handlers.Options{})
+	h.RegisterRoutes(router)
+
+	req := httptest.NewRequest(method, "/maintenance", body)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+	return rec
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	t.Run("should turn maintenance mode on and off", func(t *testing.T) {
+		mode := &handlers.MaintenanceMode{}
+		logger := mocks.NewLogger()
+		h := handlers.NewMaintenanceHandler(mode, logger)
+
+		rec := serveMaintenance(h, http.MethodPut, strings.NewReader(`{"enabled":true}`))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"enabled":true`)
+		assert.True(t, mode.Enabled())
+
+		rec = serveMaintenance(h, http.MethodGet, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"enabled":true`)
+
+		rec = serveMaintenance(h, http.MethodPut, strings.NewReader(`{"enabled":false}`))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, mode.Enabled())
+		assert.Len(t, logger.LogInfoCalls(), 2)
+	})
+
+	t.Run("should require enabled", func(t *testing.T) {
+		mode := &handlers.MaintenanceMode{}
+		mode.Set(true)
+
+		for _, body := range []string{`{}`, `[`} {
+			rec := serveMaintenance(handlers.NewMaintenanceHandler(mode, mocks.NewLogger()), http.MethodPut, strings.NewReader(body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+			assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		}
+		assert.True(t, mode.Enabled())
+	})
+
+	t.Run("should be forbidden to non-admins", func(t *testing.T) {
+		mode := &handlers.MaintenanceMode{}
+		h := handlers.NewMaintenanceHandler(mode, mocks.NewLogger())
+
+		rec := serve(h, http.MethodPut, "/maintenance", strings.NewReader(`{"enabled":true}`))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeForbidden, errorCode(t, rec))
+		assert.False(t, mode.Enabled())
+
+		rec = serve(h, http.MethodGet, "/maintenance", nil)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
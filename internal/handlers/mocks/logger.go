@@ -0,0 +1,13 @@
+package mocks
+
+import "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+
+// NewLogger returns a LoggerInterfaceMock accepting every call, for tests
+// that only assert on the calls it records
+func NewLogger() *LoggerInterfaceMock {
+	return &LoggerInterfaceMock{
+		LogErrorFunc:   func(string, error) {},
+		LogInfoFunc:    func(string, string) {},
+		LogRequestFunc: func(handlers.RequestLogEntry) {},
+	}
+}
@@ -0,0 +1,28 @@
+package mocks_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerInterfaceMock(t *testing.T) {
+	t.Run("should implement the logger interface", func(t *testing.T) {
+		assert.Implements(t, (*handlers.LoggerInterface)(nil), &mocks.LoggerInterfaceMock{})
+	})
+
+	t.Run("should record calls", func(t *testing.T) {
+		logger := &mocks.LoggerInterfaceMock{LogErrorFunc: func(string, error) {}}
+		err := errors.New("boom")
+
+		logger.LogError("getProduct", err)
+
+		require.Len(t, logger.LogErrorCalls(), 1)
+		assert.Equal(t, "getProduct", logger.LogErrorCalls()[0].Op)
+		assert.Equal(t, err, logger.LogErrorCalls()[0].Err)
+	})
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// JSON naming convention
+//
+// Every key in a request or response body is camelCase: "createdAt",
+// "imageUrl", "nextCursor". Acronyms are treated as words ("imageUrl", not
+// "imageURL") and snake_case keys are never used in struct tags. Query
+// parameters are snake_case ("include_total"). The constants below name the
+// keys shared across handlers so they are spelled the same everywhere.
+//
+// Clients that prefer snake_case bodies can opt in with the snake naming
+// strategy, which renames struct field keys when a response is encoded.
+// Keys of user-supplied maps, such as product metadata and attributes, are
+// data and keep their spelling. The strategy only affects responses:
+// request bodies are always camelCase.
+const (
+	JSONKeyStatus     = "status"
+	JSONKeyMessage    = "message"
+	JSONKeyData       = "data"
+	JSONKeyError      = "error"
+	JSONKeyPagination = "pagination"
+	JSONKeyNextCursor = "nextCursor"
+	JSONKeyHasMore    = "hasMore"
+	JSONKeyCreatedAt  = "createdAt"
+)
+
+// NamingStrategy selects the casing of JSON keys in response bodies
+type NamingStrategy string
+
+const (
+	NamingCamelCase NamingStrategy = "camel"
+	NamingSnakeCase NamingStrategy = "snake"
+)
+
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+// ParseNamingStrategy validates a configured naming strategy name
+func ParseNamingStrategy(name string) (NamingStrategy, error) {
+	switch strategy := NamingStrategy(name); strategy {
+	case NamingCamelCase, NamingSnakeCase:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported json naming strategy `%s`", name)
+	}
+}
+
+// marshalJSON encodes v using the naming strategy
+func marshalJSON(v any, naming NamingStrategy) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	if naming != NamingSnakeCase {
+		return buf.Bytes(), nil
+	}
+
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(snakeCaseKeys(reflect.ValueOf(v), generic)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// snakeCaseKeys renames the object keys of the decoded JSON v that come
+// from struct fields of rv, the value v was encoded from. Values with their
+// own MarshalJSON and map keys are left as encoded.
+func snakeCaseKeys(rv reflect.Value, v any) any {
+	if !rv.IsValid() {
+		return v
+	}
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return v
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type().Implements(marshalerType) || reflect.PointerTo(rv.Type()).Implements(marshalerType) {
+		return v
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		fields := make(map[string]reflect.Value)
+		jsonFields(rv, fields)
+		converted := make(map[string]any, len(obj))
+		for k, field := range obj {
+			if fv, ok := fields[k]; ok {
+				field = snakeCaseKeys(fv, field)
+			}
+			converted[toSnakeCase(k)] = field
+		}
+		return converted
+	case reflect.Map:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if field, ok := obj[key]; ok {
+				obj[key] = snakeCaseKeys(iter.Value(), field)
+			}
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]any)
+		if !ok || len(items) != rv.Len() {
+			return v
+		}
+		for i := range items {
+			items[i] = snakeCaseKeys(rv.Index(i), items[i])
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// jsonFields adds the exported fields of the struct rv to fields under their
+// JSON keys, then the promoted fields of its embedded structs, which the
+// struct's own fields shadow
+func jsonFields(rv reflect.Value, fields map[string]reflect.Value) {
+	var embedded []reflect.Value
+	typ := rv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				embedded = append(embedded, fv)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = fv
+	}
+	for _, fv := range embedded {
+		promoted := make(map[string]reflect.Value)
+		jsonFields(fv, promoted)
+		for name, pv := range promoted {
+			if _, ok := fields[name]; !ok {
+				fields[name] = pv
+			}
+		}
+	}
+}
+
+// toSnakeCase converts a camelCase key such as "imageUrl" to "image_url"
+func toSnakeCase(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	camelCase = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	snakeCase = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+func TestJSONKeysAreCamelCase(t *testing.T) {
+	responseTypes := []any{
+		datalayer.Category{},
+		datalayer.Product{},
+		datalayer.Reservation{},
+		datalayer.InventoryMovement{},
+		SuccessResponse{},
+		ErrorResponse{},
+		Error{},
+		Pagination{},
+		BatchErrorResponse{},
+		BatchItemError{},
+		batchItemResult{},
+		productResponse{},
+		availabilityResponse{},
+		productCountResponse{},
+	}
+
+	for _, v := range responseTypes {
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Anonymous {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			assert.NotEmpty(t, name, "%s.%s has no json tag", typ.Name(), field.Name)
+			assert.Regexp(t, camelCase, name, "%s.%s json key is not camelCase", typ.Name(), field.Name)
+		}
+	}
+}
+
+func TestNamingStrategy(t *testing.T) {
+	weight := 1.5
+	resources := []any{
+		datalayer.Category{ID: uuid.New(), Name: "Books", CreatedAt: time.Now()},
+		datalayer.Product{ID: uuid.New(), Name: "Pen", CategoryID: uuid.New(), Weight: &weight, CreatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		strategy NamingStrategy
+		pattern  *regexp.Regexp
+		keys     []string
+	}{
+		{NamingCamelCase, camelCase, []string{"createdAt", "imageUrl", "categoryId", "nextCursor"}},
+		{NamingSnakeCase, snakeCase, []string{"created_at", "image_url", "category_id", "next_cursor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(WithOptions(req.Context(), Options{Naming: tt.strategy}))
+			pagination := &Pagination{NextCursor: "abc", HasMore: true}
+			WriteListResponse(rec, req, "resources retrieved", resources, pagination, nil)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			keys := collectKeys(body)
+			for _, key := range keys {
+				assert.Regexp(t, tt.pattern, key)
+			}
+			for _, key := range tt.keys {
+				assert.Contains(t, keys, key)
+			}
+		})
+	}
+}
+
+func TestNamingStrategyKeepsMapKeys(t *testing.T) {
+	resources := []any{
+		datalayer.Category{ID: uuid.New(), Name: "Lamps", Attributes: datalayer.CategoryAttributes{"bulbType": []string{"led"}}},
+		datalayer.Product{
+			ID:         uuid.New(),
+			Name:       "Lamp",
+			CategoryID: uuid.New(),
+			Attributes: datalayer.ProductAttributes{"bulbType": "led"},
+			Metadata:   datalayer.ProductMetadata{"countryOfOrigin": "DE"},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(WithOptions(req.Context(), Options{Naming: NamingSnakeCase}))
+	WriteListResponse(rec, req, "resources retrieved", resources, nil, nil)
+
+	var body struct {
+		Data []map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 2)
+	assert.Contains(t, string(body.Data[0]["attributes"]), `"bulbType"`)
+	assert.Contains(t, body.Data[1], "category_id")
+	assert.Contains(t, string(body.Data[1]["attributes"]), `"bulbType"`)
+	assert.JSONEq(t, `{"countryOfOrigin":"DE"}`, string(body.Data[1]["metadata"]))
+}
+
+func TestSnakeCaseKeysFields(t *testing.T) {
+	type inner struct {
+		ImageURL string `json:"imageUrl"`
+	}
+	type outer struct {
+		inner
+		ByName   map[string]inner `json:"byName"`
+		Items    []inner          `json:"items"`
+		Nested   *inner           `json:"nested,omitempty"`
+		Untagged int
+		Skipped  int `json:"-"`
+	}
+	v := outer{
+		inner:  inner{ImageURL: "a"},
+		ByName: map[string]inner{"mainImage": {ImageURL: "b"}},
+		Items:  []inner{{ImageURL: "c"}},
+	}
+
+	body, err := marshalJSON(v, NamingSnakeCase)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"image_url": "a",
+		"by_name": {"mainImage": {"image_url": "b"}},
+		"items": [{"image_url": "c"}],
+		"untagged": 0
+	}`, string(body))
+}
+
+func TestParseNamingStrategy(t *testing.T) {
+	strategy, err := ParseNamingStrategy("snake")
+	assert.NoError(t, err)
+	assert.Equal(t, NamingSnakeCase, strategy)
+
+	_, err = ParseNamingStrategy("kebab")
+	assert.EqualError(t, err, "unsupported json naming strategy `kebab`")
+}
+
+func TestToSnakeCase(t *testing.T) {
+	assert.Equal(t, "image_url", toSnakeCase("imageUrl"))
+	assert.Equal(t, "has_more", toSnakeCase("hasMore"))
+	assert.Equal(t, "id", toSnakeCase("id"))
+}
+
+func collectKeys(v any) []string {
+	var keys []string
+	switch val := v.(type) {
+	case map[string]any:
+		for k, field := range val {
+			keys = append(keys, k)
+			keys = append(keys, collectKeys(field)...)
+		}
+	case []any:
+		for _, item := range val {
+			keys = append(keys, collectKeys(item)...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+)
+
+// unknownOp is logged for requests that were not dispatched through a route
+const unknownOp = "unknown"
+
+type opKey struct{}
+
+// WithOp returns a copy of ctx carrying op, the operation name used when
+// logging and writing responses
+func WithOp(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, opKey{}, op)
+}
+
+// OpFromContext returns the operation name stored by WithOp, or "unknown"
+// if there is none
+func OpFromContext(ctx context.Context) string {
+	if op, ok := ctx.Value(opKey{}).(string); ok {
+		return op
+	}
+	return unknownOp
+}
+
+// withRouteOp stores the route pattern as the request's op so handlers
+// don't need to name their operation themselves
+func withRouteOp(pattern string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(WithOp(r.Context(), pattern)))
+	}
+}
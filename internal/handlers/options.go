@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+)
+
+// Options control how every route of a router reads requests and writes
+// responses. The router hands them to handlers and middlewares through the
+// request context. The zero Options are the defaults.
+type Options struct {
+	// Naming is the casing of response body keys, camelCase when empty
+	Naming NamingStrategy
+	// OmitSuccessMessages drops the message of success responses, for
+	// clients that only read the data
+	OmitSuccessMessages bool
+	// DeleteStyle is how a successful delete without ?return=true answers,
+	// DeleteNoContent when empty
+	DeleteStyle DeleteStyle
+	// AlreadyDeletedOK answers a delete of a product or category that does
+	// not exist as a successful delete instead of 404. The idempotent mode
+	// suits clients that retry deletes after a timeout, where the row being
+	// gone is the outcome they asked for. The strict default suits clients
+	// that rely on the 404 to catch a wrong or stale ID.
+	AlreadyDeletedOK bool
+	// UUIDVersion is the version every UUID parameter must have, such as 4
+	// when all IDs are issued by uuid.New. Zero accepts any version.
+	UUIDVersion int
+	// MaxBatchSize is the most items a bulk create, batch get or product
+	// batch request may hold. Larger requests are rejected with 400 before
+	// they reach the repository. Zero means DefaultMaxBatchSize and sizes
+	// above datalayer.MaxBatchSize are lowered to it.
+	MaxBatchSize int
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a copy of ctx carrying opts
+func WithOptions(ctx context.Context, opts Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// OptionsFromContext returns the options stored by WithOptions, or the
+// zero Options if there are none
+func OptionsFromContext(ctx context.Context) Options {
+	opts, _ := ctx.Value(optionsKey{}).(Options)
+	return opts
+}
+
+// batchSizeLimit returns the most items a batch request may hold
+func (o Options) batchSizeLimit() int {
+	if o.MaxBatchSize > 0 {
+		return min(o.MaxBatchSize, datalayer.MaxBatchSize)
+	}
+	return DefaultMaxBatchSize
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+type PriceScheduleHandler struct {
+	repo   datalayer.PriceScheduleRepoInterface
+	logger LoggerInterface
+	now    func() time.Time
+}
+
+// schedulePriceRequest is the body of POST /products/{id}/price-schedules
+type schedulePriceRequest struct {
+	NewPrice    float64   `json:"newPrice"`
+	EffectiveAt time.Time `json:"effectiveAt"`
+}
+
+// NewPriceScheduleHandler creates a new price schedule handler instance.
+// now decides whether a requested effectiveAt is still in the future.
+func NewPriceScheduleHandler(
+	repo datalayer.PriceScheduleRepoInterface,
+	now func() time.Time,
+	logger LoggerInterface,
+) *PriceScheduleHandler {
+	return &PriceScheduleHandler{repo: repo, logger: logger, now: now}
+}
+
+// RegisterRoutes registers the price schedule endpoints on the router
+func (h *PriceScheduleHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("POST /products/{id}/price-schedules", h.CreatePriceSchedule)
+	router.HandleFunc("GET /products/{id}/price-schedules", h.ListPriceSchedules)
+	router.HandleFunc("DELETE /price-schedules/{id}", h.CancelPriceSchedule)
+}
+
+// CreatePriceSchedule schedules a product's price to change at effectiveAt,
+// which must be in the future
+func (h *PriceScheduleHandler) CreatePriceSchedule(w http.ResponseWriter, r *http.Request) {
+	productID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req schedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+	if req.NewPrice <= 0 {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "newPrice must be greater than 0", h.logger)
+		return
+	}
+	if req.EffectiveAt.IsZero() {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "effectiveAt is required", h.logger)
+		return
+	}
+	if !req.EffectiveAt.After(h.now()) {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "effectiveAt must be in the future", h.logger)
+		return
+	}
+
+	schedule := &datalayer.PriceSchedule{
+		ProductID:   productID,
+		NewPrice:    req.NewPrice,
+		EffectiveAt: req.EffectiveAt,
+	}
+	if err := h.repo.CreatePriceSchedule(r.Context(), schedule); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "price change scheduled", schedule, h.logger)
+}
+
+// ListPriceSchedules returns every scheduled price change of a product,
+// applied or pending, in effective order
+func (h *PriceScheduleHandler) ListPriceSchedules(w http.ResponseWriter, r *http.Request) {
+	productID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	schedules, err := h.repo.ListPriceSchedules(r.Context(), productID)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "price schedules retrieved", schedules, h.logger)
+}
+
+// CancelPriceSchedule deletes a pending price change and responds with an
+// empty 204
+func (h *PriceScheduleHandler) CancelPriceSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	if err := h.repo.CancelPriceSchedule(r.Context(), id); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
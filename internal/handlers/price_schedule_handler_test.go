@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// scheduleNow is the fake clock the schedule handlers compare effectiveAt to
+var scheduleNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var testSchedule = datalayer.PriceSchedule{
+	ID:          uuid.MustParse("0b6c2a4e-7d1f-4f3a-9e58-3c1d2b7a8e90"),
+	ProductID:   testProduct.ID,
+	NewPrice:    199.99,
+	EffectiveAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	CreatedAt:   scheduleNow,
+}
+
+func newTestPriceScheduleHandler(repo datalayer.PriceScheduleRepoInterface) *handlers.PriceScheduleHandler {
+	return handlers.NewPriceScheduleHandler(repo, func() time.Time { return scheduleNow }, handlermocks.NewLogger())
+}
+
+func TestPriceScheduleHandlerCreatePriceSchedule(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/price-schedules"
+
+	t.Run("should schedule the price change", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			CreatePriceScheduleFunc: func(_ context.Context, schedule *datalayer.PriceSchedule) error {
+				assert.Equal(t, testProduct.ID, schedule.ProductID)
+				assert.Equal(t, 199.99, schedule.NewPrice)
+				assert.True(t, testSchedule.EffectiveAt.Equal(schedule.EffectiveAt))
+				*schedule = testSchedule
+				return nil
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodPost, target,
+			strings.NewReader(`{"newPrice":199.99,"effectiveAt":"2024-02-01T00:00:00Z"}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		testutil.AssertGolden(t, "create_price_schedule", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if effectiveAt is not in the future", func(t *testing.T) {
+		for _, effectiveAt := range []string{"2024-01-01T11:59:59Z", "2024-01-01T12:00:00Z"} {
+			h := newTestPriceScheduleHandler(&mocks.MockPriceScheduleRepo{})
+			rec := serve(h, http.MethodPost, target,
+				strings.NewReader(`{"newPrice":10,"effectiveAt":"`+effectiveAt+`"}`))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, effectiveAt)
+			assert.Contains(t, rec.Body.String(), "effectiveAt must be in the future")
+		}
+	})
+
+	t.Run("should return 400 if the request is incomplete", func(t *testing.T) {
+		cases := map[string]string{
+			`{"effectiveAt":"2024-02-01T00:00:00Z"}`:               "newPrice must be greater than 0",
+			`{"newPrice":-1,"effectiveAt":"2024-02-01T00:00:00Z"}`: "newPrice must be greater than 0",
+			`{"newPrice":10}`:                          "effectiveAt is required",
+			`{"newPrice":10,"effectiveAt":"tomorrow"}`: "request body must be a JSON object",
+		}
+		for body, msg := range cases {
+			rec := serve(newTestPriceScheduleHandler(&mocks.MockPriceScheduleRepo{}), http.MethodPost, target, strings.NewReader(body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+			assert.Contains(t, rec.Body.String(), msg, body)
+		}
+	})
+
+	t.Run("should return 409 if a change is scheduled for the same instant", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			CreatePriceScheduleFunc: func(context.Context, *datalayer.PriceSchedule) error {
+				return fmt.Errorf("createPriceSchedule: %w: 2024-02-01T00:00:00Z", datalayer.ErrScheduleConflict)
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodPost, target,
+			strings.NewReader(`{"newPrice":10,"effectiveAt":"2024-02-01T00:00:00Z"}`))
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		testutil.AssertGolden(t, "create_price_schedule_conflict", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if product not found", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			CreatePriceScheduleFunc: func(context.Context, *datalayer.PriceSchedule) error {
+				return fmt.Errorf("createPriceSchedule: %w: product id `%s`", datalayer.ErrNotFound, testProduct.ID)
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodPost, target,
+			strings.NewReader(`{"newPrice":10,"effectiveAt":"2024-02-01T00:00:00Z"}`))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestPriceScheduleHandlerListPriceSchedules(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/price-schedules"
+
+	t.Run("should list the product's schedules", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			ListPriceSchedulesFunc: func(_ context.Context, productID uuid.UUID) ([]*datalayer.PriceSchedule, error) {
+				assert.Equal(t, testProduct.ID, productID)
+				return []*datalayer.PriceSchedule{&testSchedule}, nil
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_price_schedules", rec.Body.Bytes())
+	})
+
+	t.Run("should return 500 if the repo fails", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			ListPriceSchedulesFunc: func(context.Context, uuid.UUID) ([]*datalayer.PriceSchedule, error) {
+				return nil, errors.New("listPriceSchedules: select query failed: query error")
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestPriceScheduleHandlerCancelPriceSchedule(t *testing.T) {
+	target := "/price-schedules/" + testSchedule.ID.String()
+
+	t.Run("should return 204 once cancelled", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			CancelPriceScheduleFunc: func(_ context.Context, id uuid.UUID) error {
+				assert.Equal(t, testSchedule.ID, id)
+				return nil
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should return 409 if already applied", func(t *testing.T) {
+		repo := &mocks.MockPriceScheduleRepo{
+			CancelPriceScheduleFunc: func(context.Context, uuid.UUID) error {
+				return fmt.Errorf("cancelPriceSchedule: %w: id `%s`", datalayer.ErrScheduleApplied, testSchedule.ID)
+			},
+		}
+		rec := serve(newTestPriceScheduleHandler(repo), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "price schedule has already been applied")
+	})
+}
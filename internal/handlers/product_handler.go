@@ -0,0 +1,734 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/service"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultProductLimit = 20
+	maxProductLimit     = 100
+	// defaultProductsPerCategory and maxProductsPerCategory bound the
+	// products of each category in the grouped listing
+	defaultProductsPerCategory = 5
+	maxProductsPerCategory     = 20
+	// productEventBuffer is how many events a slow event stream client may
+	// fall behind before it starts missing them
+	productEventBuffer = 64
+)
+
+// ProductHandler serves the product endpoints. Reads go to the repo;
+// writes go through the service, which owns validation and business rules.
+type ProductHandler struct {
+	repo    datalayer.ProductRepoInterface
+	service *service.ProductService
+	events  *service.ProductEventStream
+	skew    time.Duration
+	logger  LoggerInterface
+}
+
+// patchProductRequest is the body of PATCH /products/{id}. Attributes, when
+// present, replace the product's attributes.
+type patchProductRequest struct {
+	Status     *datalayer.ProductStatus     `json:"status"`
+	Attributes *datalayer.ProductAttributes `json:"attributes"`
+}
+
+// setRelatedRequest is the body of PUT /products/{id}/related
+type setRelatedRequest struct {
+	Related []relatedProductRequest `json:"related"`
+}
+
+// batchItemResult is the outcome of one item of POST /products/batch.
+// Product is the product as written by a create or update; a delete only
+// reports the ID.
+type batchItemResult struct {
+	Index   int                `json:"index"`
+	Op      datalayer.BatchOp  `json:"op"`
+	Status  string             `json:"status"`
+	Product *datalayer.Product `json:"product,omitempty"`
+	ID      *uuid.UUID         `json:"id,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// productResponse is the body of GET /products/{id}. Available is derived
+// from the stock; Quantity shadows the product's own and is left nil to
+// hide the exact stock.
+type productResponse struct {
+	*datalayer.Product
+	Quantity  *int `json:"quantity,omitempty"`
+	Available bool `json:"available"`
+}
+
+// newProductResponse wraps product, hiding its quantity if hideQuantity is set.
+func newProductResponse(product *datalayer.Product, hideQuantity bool) productResponse {
+	resp := productResponse{Product: product, Available: product.Quantity > 0}
+	if !hideQuantity {
+		resp.Quantity = &product.Quantity
+	}
+	return resp
+}
+
+// availabilityResponse is the body of GET /products/{id}/availability
+type availabilityResponse struct {
+	InStock  bool `json:"inStock"`
+	Quantity int  `json:"quantity"`
+}
+
+// productCountResponse is the body of GET /products/count
+type productCountResponse struct {
+	Count int64 `json:"count"`
+}
+
+// relatedProductRequest is one entry of the ordered related set. An empty
+// relationType means related.
+type relatedProductRequest struct {
+	ProductID    uuid.UUID              `json:"productId"`
+	RelationType datalayer.RelationType `json:"relationType"`
+}
+
+// NewProductHandler creates a new product handler instance. categories is
+// used to check the category of new products and definitions to check
+// product attributes. Listing rewinds cursors by skew like
+// NewCategoryHandler. At most maxStreams event streams are open at once;
+// zero leaves them uncapped.
+func NewProductHandler(
+	repo datalayer.ProductRepoInterface,
+	categories datalayer.CategoryRepoInterface,
+	definitions datalayer.AttributeDefinitionRepoInterface,
+	skew time.Duration,
+	maxStreams int,
+	logger LoggerInterface,
+) *ProductHandler {
+	svc := service.NewProductService(repo, service.NewCategoryService(categories), service.NewAttributeService(definitions))
+	events := service.NewProductEventStream(maxStreams)
+	svc.OnChange(events.Publish)
+	return &ProductHandler{repo: repo, service: svc, events: events, skew: skew, logger: logger}
+}
+
+// Events returns the stream GET /products/stream serves, so product changes
+// made outside the handler, such as scheduled price changes, reach it too
+func (h *ProductHandler) Events() *service.ProductEventStream {
+	return h.events
+}
+
+// RegisterRoutes registers the product endpoints on the router
+func (h *ProductHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("GET /products", h.ListProducts)
+	router.HandleFunc("POST /products", h.CreateProduct)
+	router.HandleFunc("POST /products/batch", h.ApplyProductBatch)
+	router.HandleFunc("GET /products/count", h.CountProducts)
+	router.HandleFunc("GET /products/duplicates", h.ListDuplicateProducts)
+	router.HandleFunc("GET /products/grouped-by-category", h.ListProductsGroupedByCategory)
+	router.HandleFunc("GET /products/schema", h.GetProductSchema)
+	router.HandleFunc("GET /products/stream", h.StreamProductEvents)
+	router.HandleFunc("GET /products/{id}", h.GetProduct)
+	router.HandleFunc("GET /products/{id}/availability", h.GetProductAvailability)
+	router.HandleFunc("GET /products/{id}/related", h.ListRelatedProducts)
+	router.HandleFunc("PUT /products/{id}/related", h.SetRelatedProducts)
+	router.HandleFunc("PATCH /products/{id}", h.PatchProduct)
+	router.HandleFunc("DELETE /products/{id}", h.DeleteProduct)
+}
+
+// ListProducts returns a page of active products. ?status= lists another
+// status instead; only admins may list drafts or discontinued products.
+// ?attr.<name>=value keeps products whose attribute equals value,
+// ?category_id= keeps products of any of the given categories and
+// ?has_image=true or false keeps products with or without an image. Pages
+// hold 20 products unless ?limit asks for up to 100.
+func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	cursor, err := DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "cursor is invalid", h.logger)
+		return
+	}
+
+	limit, err := ParseLimit(r, maxProductLimit)
+	if err != nil {
+		writeParamError(w, r, err, h.logger)
+		return
+	}
+	if limit == 0 {
+		limit = defaultProductLimit
+	}
+
+	filter := datalayer.ProductFilter{Status: datalayer.ProductActive, ExcludeIDs: cursor.Seen}
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		filter.Status = datalayer.ProductStatus(raw)
+	}
+	if !filter.Status.Valid() {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, statusMessage(), h.logger)
+		return
+	}
+	if filter.Status != datalayer.ProductActive && !IsAdmin(r.Context()) {
+		WriteErrorResponse(w, r, http.StatusForbidden, apierrors.ErrCodeForbidden, fmt.Sprintf("admin role required to list %s products", filter.Status), h.logger)
+		return
+	}
+	filter.Attributes, err = parseAttributeFilter(r)
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+	categoryIDs, err := ParseMultiValueParam(r, "category_id", validUUIDs("category_id", OptionsFromContext(r.Context()).UUIDVersion))
+	if err != nil {
+		writeParamError(w, r, err, h.logger)
+		return
+	}
+	for _, id := range categoryIDs {
+		filter.CategoryIDs = append(filter.CategoryIDs, uuid.MustParse(id))
+	}
+	if raw := r.URL.Query().Get("has_image"); raw != "" {
+		hasImage, err := strconv.ParseBool(raw)
+		if err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "has_image must be a boolean", h.logger)
+			return
+		}
+		filter.HasImage = &hasImage
+	}
+
+	// one extra product tells whether another page follows
+	createdAfter, afterID := cursor.Rewind(h.skew)
+	filter.AfterID = afterID
+	products, err := h.repo.ListProducts(r.Context(), filter, createdAfter, limit+1)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	var next string
+	if len(products) > limit {
+		products = products[:limit]
+		next = EncodeCursor(datalayer.NextCursor(cursor, products, datalayer.ProductKey, h.skew))
+	}
+	WriteListResponse(w, r, "products retrieved", products, newPagination(limit, next), h.logger)
+}
+
+// CountProducts returns how many active products there are, only in the
+// category given by ?category_id= if set
+func (h *ProductHandler) CountProducts(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := ParseOptionalUUIDQuery(r, "category_id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	count, err := h.repo.CountAllProducts(r.Context(), datalayer.ProductCountFilter{CategoryID: categoryID})
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "products counted", productCountResponse{Count: count}, h.logger)
+}
+
+// ListProductsGroupedByCategory returns the newest active products of every
+// category, keyed by category ID. Each category holds 5 products unless
+// ?limit_per_category asks for up to 20.
+func (h *ProductHandler) ListProductsGroupedByCategory(w http.ResponseWriter, r *http.Request) {
+	limit := defaultProductsPerCategory
+	if raw := r.URL.Query().Get("limit_per_category"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxProductsPerCategory {
+			msg := fmt.Sprintf("limit_per_category must be an integer from 1 to %d", maxProductsPerCategory)
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, msg, h.logger)
+			return
+		}
+	}
+
+	groups, err := h.repo.ListProductsGroupedByCategory(r.Context(), limit)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "products grouped by category", groups, h.logger)
+}
+
+// ListDuplicateProducts returns a page of groups of products of one
+// category whose names differ only in case and white space. Groups have at
+// least two products unless ?min_group_size asks for more. Drafts are only
+// grouped for admins. Pages hold 20 groups unless ?limit asks for up to 100.
+func (h *ProductHandler) ListDuplicateProducts(w http.ResponseWriter, r *http.Request) {
+	cursor, err := DecodeGroupCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "cursor is invalid", h.logger)
+		return
+	}
+
+	limit, err := ParseLimit(r, maxProductLimit)
+	if err != nil {
+		writeParamError(w, r, err, h.logger)
+		return
+	}
+	if limit == 0 {
+		limit = defaultProductLimit
+	}
+
+	filter := datalayer.DuplicateFilter{MinGroupSize: datalayer.MinDuplicateGroupSize, IncludeDrafts: IsAdmin(r.Context())}
+	if raw := r.URL.Query().Get("min_group_size"); raw != "" {
+		filter.MinGroupSize, err = strconv.Atoi(raw)
+		if err != nil || filter.MinGroupSize < datalayer.MinDuplicateGroupSize {
+			msg := fmt.Sprintf("min_group_size must be an integer of at least %d", datalayer.MinDuplicateGroupSize)
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, msg, h.logger)
+			return
+		}
+	}
+
+	// one extra group tells whether another page follows
+	groups, err := h.repo.ListDuplicateGroups(r.Context(), filter, cursor, limit+1)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	var next string
+	if len(groups) > limit {
+		groups = groups[:limit]
+		next = EncodeGroupCursor(groups[limit-1].DuplicateGroupKey)
+	}
+	WriteListResponse(w, r, "duplicate products retrieved", groups, newPagination(limit, next), h.logger)
+}
+
+// StreamProductEvents pushes the products created, updated and deleted
+// through this server as server-sent events, one "data: <json>" message
+// per event, until the client disconnects. Events of draft products are
+// only sent to admins. Clients falling too far behind miss events. Streams
+// are capped by their own maximum rather than MAX_IN_FLIGHT, answering 503
+// once it is reached, and outlive the server's WriteTimeout.
+func (h *ProductHandler) StreamProductEvents(w http.ResponseWriter, r *http.Request) {
+	events, unsubscribe, err := h.events.Subscribe(productEventBuffer)
+	if err != nil {
+		WriteCodeResponse(w, r, apierrors.ErrCodeTooManyStreams, h.logger)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.LogError(OpFromContext(r.Context()), fmt.Errorf("failed to lift the write deadline: %w", err))
+		return
+	}
+	if err := controller.Flush(); err != nil {
+		h.logger.LogError(OpFromContext(r.Context()), fmt.Errorf("failed to start event stream: %w", err))
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if event.Status == datalayer.ProductDraft && !IsAdmin(r.Context()) {
+				continue
+			}
+			body, err := marshalJSON(event, OptionsFromContext(r.Context()).Naming)
+			if err != nil {
+				h.logger.LogError(OpFromContext(r.Context()), fmt.Errorf("failed to encode event: %w", err))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", bytes.TrimSpace(body)); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// CreateProduct creates a product from the JSON body. New products are
+// drafts unless the body sets a status.
+func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+
+	product, err := h.service.CreateProduct(r.Context(), req)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "product created", product, h.logger)
+}
+
+// GetProductSchema returns the JSON Schema of the CreateProduct body, so
+// clients can validate a product before sending it
+func (h *ProductHandler) GetProductSchema(w http.ResponseWriter, r *http.Request) {
+	WriteSuccessResponse(w, r, http.StatusOK, "product schema", service.ProductSchema(), h.logger)
+}
+
+// ApplyProductBatch applies a JSON array of create, update and delete
+// items, at most Options.MaxBatchSize of them, in one transaction. By
+// default any rejected item fails the whole batch with 422, listing the
+// rejected items, and nothing is written. With ?partial=true the other
+// items are still applied and the 200 response marks each item applied or
+// failed.
+func (h *ProductHandler) ApplyProductBatch(w http.ResponseWriter, r *http.Request) {
+	partial, err := parseBoolQuery(r, "partial")
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+
+	var reqs []service.BatchItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON array", h.logger)
+		return
+	}
+	if !checkBatchSize(w, r, len(reqs), "items", h.logger) {
+		return
+	}
+
+	results, err := h.service.ApplyBatch(r.Context(), reqs, partial)
+	if errors.Is(err, service.ErrBatchRejected) {
+		WriteBatchErrorResponse(w, r, h.batchItemErrors(r, reqs, results), h.logger)
+		return
+	}
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	items := make([]batchItemResult, len(results))
+	for i, result := range results {
+		item := batchItemResult{Index: i, Op: result.Op, Status: "applied"}
+		switch {
+		case result.Err != nil:
+			item.Status = "failed"
+			item.Error = h.batchItemMessage(r, result.Err)
+		case result.Op == datalayer.BatchDelete:
+			item.ID = &result.Product.ID
+		default:
+			item.Product = result.Product
+		}
+		items[i] = item
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "product batch applied", items, h.logger)
+}
+
+// batchItemErrors lists the rejected items of a batch, one entry per
+// rejected attribute where the service names them
+func (h *ProductHandler) batchItemErrors(r *http.Request, reqs []service.BatchItemRequest, results []service.BatchItemResult) []BatchItemError {
+	var errs []BatchItemError
+	for i, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		var validationErr *service.ValidationError
+		if errors.As(result.Err, &validationErr) {
+			for _, field := range validationErr.Fields {
+				errs = append(errs, BatchItemError{Index: i, Field: "product." + field.Field, Message: field.Message})
+			}
+			if len(validationErr.Fields) > 0 {
+				continue
+			}
+		}
+
+		field := "product"
+		switch {
+		case !reqs[i].Op.Valid():
+			field = "op"
+		case datalayer.KindOf(result.Err) == datalayer.KindNotFound:
+			field = "product.id"
+		}
+		errs = append(errs, BatchItemError{Index: i, Field: field, Message: h.batchItemMessage(r, result.Err)})
+	}
+	return errs
+}
+
+// batchItemMessage describes why an item failed. Errors other than
+// validation and missing products are logged and not shown.
+func (h *ProductHandler) batchItemMessage(r *http.Request, err error) string {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return validationErr.Error()
+	case datalayer.KindOf(err) == datalayer.KindNotFound:
+		return "product not found"
+	}
+	h.logger.LogError(OpFromContext(r.Context()), err)
+	return "internal server error"
+}
+
+// GetProduct returns one product with whether it is in stock.
+// ?hide_quantity=true leaves out the exact stock. Drafts are only visible
+// to admins; everyone else gets the same 404 as for a missing product. The
+// ETag is that of the stored product, whether or not quantity is hidden.
+func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+	hideQuantity, err := parseBoolQuery(r, "hide_quantity")
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+
+	product, err := h.repo.GetProductByID(r.Context(), id)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	if product.Status == datalayer.ProductDraft && !IsAdmin(r.Context()) {
+		writeNotFound(w, r, h.logger)
+		return
+	}
+	setResourceETag(w, product)
+	WriteSuccessResponse(w, r, http.StatusOK, "product retrieved", newProductResponse(product, hideQuantity), h.logger)
+}
+
+// GetProductAvailability returns whether an active product is in stock and
+// its quantity without the rest of the product. Drafts and discontinued
+// products are not found.
+func (h *ProductHandler) GetProductAvailability(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	quantity, err := h.repo.GetProductAvailability(r.Context(), id)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "product availability retrieved", availabilityResponse{
+		InStock:  quantity > 0,
+		Quantity: quantity,
+	}, h.logger)
+}
+
+// ListRelatedProducts returns the requested product's curated related set
+// in order, skipping products that are not active. Products without a
+// curated set fall back to other products in the same category. Like
+// GetProduct, a draft is a 404 for everyone but admins.
+func (h *ProductHandler) ListRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	limit, err := ParseLimit(r, datalayer.MaxLimit)
+	if err != nil {
+		writeParamError(w, r, err, h.logger)
+		return
+	}
+
+	source, err := h.repo.GetProductByID(r.Context(), id)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	if source.Status == datalayer.ProductDraft && !IsAdmin(r.Context()) {
+		writeNotFound(w, r, h.logger)
+		return
+	}
+
+	relations, err := h.repo.ListProductRelations(r.Context(), id)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	if len(relations) == 0 {
+		products, err := h.repo.ListRelatedProducts(r.Context(), id, limit)
+		if err != nil {
+			writeRepoError(w, r, err, h.logger)
+			return
+		}
+		WriteSuccessResponse(w, r, http.StatusOK, "related products retrieved", products, h.logger)
+		return
+	}
+
+	ids := make([]uuid.UUID, len(relations))
+	for i, relation := range relations {
+		ids[i] = relation.RelatedProductID
+	}
+	found, err := h.repo.GetProductsByIDs(r.Context(), ids)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+
+	products := make([]*datalayer.Product, 0, len(found))
+	for _, product := range found {
+		if product.Status != datalayer.ProductActive {
+			continue
+		}
+		if limit > 0 && len(products) == limit {
+			break
+		}
+		products = append(products, product)
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "related products retrieved", products, h.logger)
+}
+
+// SetRelatedProducts replaces a product's curated related set with the
+// ordered list in the body. The list holds at most 20 distinct products
+// other than the product itself; an empty list clears the set.
+func (h *ProductHandler) SetRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req setRelatedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+	if len(req.Related) > datalayer.MaxProductRelations {
+		msg := fmt.Sprintf("related must hold at most %d products", datalayer.MaxProductRelations)
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, msg, h.logger)
+		return
+	}
+
+	relations := make([]datalayer.ProductRelation, len(req.Related))
+	seen := make(map[uuid.UUID]bool, len(req.Related))
+	for i, related := range req.Related {
+		if related.ProductID == uuid.Nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "productId is required", h.logger)
+			return
+		}
+		if related.ProductID == id {
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "a product cannot be related to itself", h.logger)
+			return
+		}
+		if seen[related.ProductID] {
+			msg := fmt.Sprintf("product `%s` is listed more than once", related.ProductID)
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, msg, h.logger)
+			return
+		}
+		seen[related.ProductID] = true
+
+		if related.RelationType == "" {
+			related.RelationType = datalayer.RelationRelated
+		}
+		if !related.RelationType.Valid() {
+			WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, relationTypeMessage(), h.logger)
+			return
+		}
+		relations[i] = datalayer.ProductRelation{RelatedProductID: related.ProductID, RelationType: related.RelationType}
+	}
+
+	if err := h.repo.SetProductRelations(r.Context(), id, relations); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "related products updated", relations, h.logger)
+}
+
+// PatchProduct changes a product's status and/or replaces its attributes.
+// Only draft→active, active→discontinued and discontinued→active are
+// allowed; other transitions are rejected with 409. Publishing a product
+// with quantity 0 is rejected with 400, as are attributes that do not fit
+// the category's definitions, with one entry per rejected attribute.
+// Both are written in one update. With If-Match the update only proceeds
+// while the product's ETag is listed, otherwise it answers 412.
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req patchProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+	if req.Status == nil && req.Attributes == nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "status or attributes is required", h.logger)
+		return
+	}
+	if req.Status != nil && !req.Status.Valid() {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, statusMessage(), h.logger)
+		return
+	}
+
+	product, err := h.service.PatchProduct(r.Context(), id, datalayer.ProductPatch{
+		Status:       req.Status,
+		Attributes:   req.Attributes,
+		Precondition: ifMatchPrecondition[datalayer.Product](r),
+	})
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	setResourceETag(w, product)
+	WriteSuccessResponse(w, r, http.StatusOK, "product updated", product, h.logger)
+}
+
+// DeleteProduct removes a product. With ?return=true the deleted product
+// is returned with 200, otherwise the response follows the delete style.
+// A missing product answers 404 unless Options.AlreadyDeletedOK is on, in which
+// case it answers as deleted. ?return=true still answers 404 then, as
+// there is no product to return.
+func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	returnDeleted, err := parseBoolQuery(r, "return")
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, err.Error(), h.logger)
+		return
+	}
+
+	if returnDeleted {
+		product, err := h.service.DeleteProductReturning(r.Context(), id)
+		if err != nil {
+			writeRepoError(w, r, err, h.logger)
+			return
+		}
+		WriteSuccessResponse(w, r, http.StatusOK, "product deleted", product, h.logger)
+		return
+	}
+
+	if err := h.service.DeleteProduct(r.Context(), id); err != nil && !alreadyDeleted(r, err) {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	writeDeleted(w, r, "product deleted", h.logger)
+}
+
+// statusMessage lists the accepted product statuses for validation errors
+func statusMessage() string {
+	statuses := make([]string, len(datalayer.ProductStatuses))
+	for i, status := range datalayer.ProductStatuses {
+		statuses[i] = string(status)
+	}
+	return "status must be one of: " + strings.Join(statuses, ", ")
+}
+
+// relationTypeMessage lists the accepted relation types for validation
+// errors
+func relationTypeMessage() string {
+	types := make([]string, len(datalayer.RelationTypes))
+	for i, relationType := range datalayer.RelationTypes {
+		types[i] = string(relationType)
+	}
+	return "relationType must be one of: " + strings.Join(types, ", ")
+}
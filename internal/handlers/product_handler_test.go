@@ -0,0 +1,1757 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	repomocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer/mocks"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductHandlerDeleteProduct(t *testing.T) {
+	target := "/products/" + testProduct.ID.String()
+
+	t.Run("should return 204 with no body by default", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Product, error) {
+				assert.Equal(t, testProduct.ID, id)
+				product := testProduct
+				return &product, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should return 200 with the success envelope in envelope style", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				product := testProduct
+				return &product, nil
+			},
+		}
+		rec := serveWithOptions(handlers.Options{DeleteStyle: handlers.DeleteEnvelope}, handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "delete_product_envelope", rec.Body.Bytes())
+	})
+
+	t.Run("should return deleted product if return is true", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Product, error) {
+				assert.Equal(t, testProduct.ID, id)
+				product := testProduct
+				return &product, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target+"?return=true", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "delete_product_return_true", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, "/products/abc", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "delete_product_invalid_id", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if product not found with return true", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("deleteProductReturning: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target+"?return=true", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		testutil.AssertGolden(t, "delete_product_not_found", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if product not found in strict mode", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("deleteProductReturning: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should treat a missing product as deleted if already deleted is ok", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("deleteProductReturning: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serveWithOptions(handlers.Options{AlreadyDeletedOK: true}, handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should still return 404 with return true if already deleted is ok", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("deleteProductReturning: %w", datalayer.ErrNotFound)
+			},
+		}
+		rec := serveWithOptions(handlers.Options{AlreadyDeletedOK: true}, handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target+"?return=true", nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			DeleteProductReturningFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				return nil, errors.New("deleteProductReturning: delete query failed: database error")
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		testutil.AssertGolden(t, "delete_product_internal_error", rec.Body.Bytes())
+	})
+}
+
+// storedProduct stands in for a GetProductByID that finds product
+func storedProduct(product datalayer.Product) func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+	return func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+		return &product, nil
+	}
+}
+
+// noRelations stands in for a product without a curated related set
+func noRelations(context.Context, uuid.UUID) ([]datalayer.ProductRelation, error) {
+	return []datalayer.ProductRelation{}, nil
+}
+
+func TestProductHandlerListRelatedProducts(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/related"
+
+	t.Run("should return the curated set in order", func(t *testing.T) {
+		first, draft, second := testProduct, testProduct, testProduct
+		first.ID = uuid.MustParse("3b0e8f4a-7f0d-4f0a-9d7e-5c2a1e6b8d90")
+		draft.ID = uuid.MustParse("8c1d7e2f-4a5b-4c6d-9e8f-0a1b2c3d4e5f")
+		draft.Status = datalayer.ProductDraft
+		second.ID = uuid.MustParse("5d2e9f1a-6b7c-4d8e-af90-1b2c3d4e5f60")
+		second.Name = "Test Product B"
+
+		repo := &repomocks.ProductRepoInterfaceMock{
+			GetProductByIDFunc: storedProduct(testProduct),
+			ListProductRelationsFunc: func(context.Context, uuid.UUID) ([]datalayer.ProductRelation, error) {
+				return []datalayer.ProductRelation{
+					{ProductID: testProduct.ID, RelatedProductID: first.ID, RelationType: datalayer.RelationAccessory, Position: 0},
+					{ProductID: testProduct.ID, RelatedProductID: draft.ID, RelationType: datalayer.RelationRelated, Position: 1},
+					{ProductID: testProduct.ID, RelatedProductID: second.ID, RelationType: datalayer.RelationUpsell, Position: 2},
+				}, nil
+			},
+			GetProductsByIDsFunc: func(_ context.Context, ids []uuid.UUID) ([]*datalayer.Product, error) {
+				assert.Equal(t, []uuid.UUID{first.ID, draft.ID, second.ID}, ids)
+				return []*datalayer.Product{&first, &draft, &second}, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_curated_related_products", rec.Body.Bytes())
+
+		rec = serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target+"?limit=1", nil)
+		assert.Contains(t, rec.Body.String(), first.ID.String())
+		assert.NotContains(t, rec.Body.String(), second.ID.String())
+	})
+
+	t.Run("should fall back to products in the same category", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			GetProductByIDFunc:       storedProduct(testProduct),
+			ListProductRelationsFunc: noRelations,
+			ListRelatedProductsFunc: func(_ context.Context, id uuid.UUID, limit int) ([]*datalayer.Product, error) {
+				assert.Equal(t, testProduct.ID, id)
+				assert.Equal(t, 4, limit)
+				product := testProduct
+				product.ID = uuid.MustParse("3b0e8f4a-7f0d-4f0a-9d7e-5c2a1e6b8d90")
+				return []*datalayer.Product{&product}, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target+"?limit=4", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_related_products", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if source product not found", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			GetProductByIDFunc: func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("getProductByID: %w: id `%s`", datalayer.ErrNotFound, testProduct.ID)
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should return the same 404 as GetProduct for a draft source product", func(t *testing.T) {
+		draft := testProduct
+		draft.Status = datalayer.ProductDraft
+		repo := &repomocks.ProductRepoInterfaceMock{GetProductByIDFunc: storedProduct(draft)}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeResourceNotFound, errorCode(t, rec))
+
+		repo.ListProductRelationsFunc = noRelations
+		repo.ListRelatedProductsFunc = func(context.Context, uuid.UUID, int) ([]*datalayer.Product, error) {
+			return []*datalayer.Product{}, nil
+		}
+		router := handlers.NewRouter(handlers.Options{})
+		handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()).RegisterRoutes(router)
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should return 400 if limit is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target+"?limit=x", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "limit must be an integer")
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		logger := handlermocks.NewLogger()
+		repo := &repomocks.ProductRepoInterfaceMock{
+			GetProductByIDFunc:       storedProduct(testProduct),
+			ListProductRelationsFunc: noRelations,
+			ListRelatedProductsFunc: func(context.Context, uuid.UUID, int) ([]*datalayer.Product, error) {
+				return nil, errors.New("listRelatedProducts: select query failed: boom")
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, logger), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Len(t, logger.LogErrorCalls(), 1)
+	})
+}
+
+func TestProductHandlerSetRelatedProducts(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/related"
+	accessory := uuid.MustParse("3b0e8f4a-7f0d-4f0a-9d7e-5c2a1e6b8d90")
+	upsell := uuid.MustParse("5d2e9f1a-6b7c-4d8e-af90-1b2c3d4e5f60")
+
+	t.Run("should replace the set in request order", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			SetProductRelationsFunc: func(_ context.Context, id uuid.UUID, relations []datalayer.ProductRelation) error {
+				assert.Equal(t, testProduct.ID, id)
+				assert.Equal(t, []datalayer.ProductRelation{
+					{RelatedProductID: upsell, RelationType: datalayer.RelationUpsell},
+					{RelatedProductID: accessory, RelationType: datalayer.RelationRelated},
+				}, relations)
+				for i := range relations {
+					relations[i].ProductID = id
+					relations[i].Position = i
+				}
+				return nil
+			},
+		}
+		body := `{"related":[{"productId":"` + upsell.String() + `","relationType":"upsell"},{"productId":"` + accessory.String() + `"}]}`
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPut, target, strings.NewReader(body))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "set_related_products", rec.Body.Bytes())
+	})
+
+	t.Run("should clear the set with an empty list", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			SetProductRelationsFunc: func(_ context.Context, _ uuid.UUID, relations []datalayer.ProductRelation) error {
+				assert.Empty(t, relations)
+				return nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPut, target, strings.NewReader(`{"related":[]}`))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should return 400 for an invalid set", func(t *testing.T) {
+		tooMany := make([]string, datalayer.MaxProductRelations+1)
+		for i := range tooMany {
+			tooMany[i] = `{"productId":"` + uuid.NewString() + `"}`
+		}
+
+		cases := map[string]string{
+			`{"related":[{"productId":"` + testProduct.ID.String() + `"}]}`:                                 "a product cannot be related to itself",
+			`{"related":[{"productId":"` + upsell.String() + `"},{"productId":"` + upsell.String() + `"}]}`: "is listed more than once",
+			`{"related":[{"productId":"` + upsell.String() + `","relationType":"bundle"}]}`:                 "relationType must be one of: related, accessory, upsell",
+			`{"related":[{"relationType":"upsell"}]}`:                                                       "productId is required",
+			`{"related":[` + strings.Join(tooMany, ",") + `]}`:                                              "related must hold at most 20 products",
+		}
+		for body, msg := range cases {
+			rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPut, target, strings.NewReader(body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, msg)
+			assert.Contains(t, rec.Body.String(), msg)
+		}
+	})
+
+	t.Run("should return 404 if a related product does not exist", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			SetProductRelationsFunc: func(context.Context, uuid.UUID, []datalayer.ProductRelation) error {
+				return fmt.Errorf("setProductRelations: %w: related product id `%s`", datalayer.ErrNotFound, upsell)
+			},
+		}
+		body := `{"related":[{"productId":"` + upsell.String() + `"}]}`
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPut, target, strings.NewReader(body))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestProductHandlerListProducts(t *testing.T) {
+	t.Run("should list active products by default", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, _ time.Time, limit int) ([]*datalayer.Product, error) {
+				assert.Equal(t, datalayer.ProductActive, filter.Status)
+				assert.Equal(t, 21, limit)
+				product := testProduct
+				return []*datalayer.Product{&product}, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_products", rec.Body.Bytes())
+	})
+
+	t.Run("should report the effective limit in a header", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(context.Context, datalayer.ProductFilter, time.Time, int) ([]*datalayer.Product, error) {
+				return []*datalayer.Product{}, nil
+			},
+		}
+		handler := handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger())
+
+		assert.Equal(t, "20", serve(handler, http.MethodGet, "/products", nil).Header().Get("X-Page-Limit"))
+		assert.Equal(t, "100", serve(handler, http.MethodGet, "/products?limit=100", nil).Header().Get("X-Page-Limit"))
+	})
+
+	t.Run("should page and render in UTC a product written with a +05:30 timestamp", func(t *testing.T) {
+		ctx := context.Background()
+		ist := time.FixedZone("IST", 5*60*60+30*60)
+		repo := datalayer.NewMemoryProductRepo()
+		for i, createdAt := range []time.Time{
+			testProduct.CreatedAt,
+			testProduct.CreatedAt.Add(30 * time.Minute).In(ist),
+			testProduct.CreatedAt.Add(time.Hour),
+		} {
+			product := testProduct
+			product.ID = uuid.New()
+			product.Name = fmt.Sprintf("Product %d", i)
+			product.CreatedAt = createdAt
+			require.NoError(t, repo.CreateProduct(ctx, &product))
+		}
+		h := handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger())
+
+		var names []string
+		target := "/products?limit=1"
+		for target != "" {
+			rec := serve(h, http.MethodGet, target, nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "+05:30")
+
+			var body struct {
+				Data []struct {
+					Name      string `json:"name"`
+					CreatedAt string `json:"createdAt"`
+				} `json:"data"`
+				Pagination handlers.Pagination `json:"pagination"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			for _, product := range body.Data {
+				assert.True(t, strings.HasSuffix(product.CreatedAt, "Z"), product.CreatedAt)
+				names = append(names, product.Name)
+			}
+			target = ""
+			if body.Pagination.NextCursor != "" {
+				target = "/products?limit=1&cursor=" + body.Pagination.NextCursor
+			}
+		}
+		assert.Equal(t, []string{"Product 0", "Product 1", "Product 2"}, names)
+	})
+
+	t.Run("should filter by repeated and comma separated category ids", func(t *testing.T) {
+		other := uuid.New()
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, _ time.Time, _ int) ([]*datalayer.Product, error) {
+				assert.Equal(t, []uuid.UUID{testCategory.ID, other}, filter.CategoryIDs)
+				return []*datalayer.Product{}, nil
+			},
+		}
+		target := "/products?category_id=" + testCategory.ID.String() + "," + other.String() + "&category_id=" + testCategory.ID.String()
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should return 400 naming an invalid category id", func(t *testing.T) {
+		target := "/products?category_id=" + testCategory.ID.String() + ",abc"
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), "category_id value `abc` is invalid")
+	})
+
+	t.Run("should return 400 if a category id is the nil uuid", func(t *testing.T) {
+		target := "/products?category_id=" + uuid.Nil.String()
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "category_id value `"+uuid.Nil.String()+"` is invalid: must not be nil")
+	})
+
+	t.Run("should return 400 if limit is above the product ceiling", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?limit=101", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), `{"field":"limit","message":"limit must be an integer from 1 to 100"}`)
+	})
+
+	t.Run("should trim the extra product and return a cursor", func(t *testing.T) {
+		first, second := testProduct, testProduct
+		second.CreatedAt = first.CreatedAt.Add(time.Hour)
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, _ datalayer.ProductFilter, _ time.Time, limit int) ([]*datalayer.Product, error) {
+				assert.Equal(t, 2, limit)
+				return []*datalayer.Product{&first, &second}, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?limit=1", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		next := handlers.EncodeCursor(datalayer.Cursor{CreatedAfter: first.CreatedAt, AfterID: first.ID})
+		assert.Contains(t, rec.Body.String(), `"nextCursor":"`+next+`"`)
+		assert.Contains(t, rec.Body.String(), `"hasMore":true`)
+	})
+
+	t.Run("should return 403 listing discontinued products without the admin role", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?status=discontinued", nil)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeForbidden, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), "admin role required to list discontinued products")
+	})
+
+	t.Run("should list discontinued products for admins", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, _ time.Time, _ int) ([]*datalayer.Product, error) {
+				assert.Equal(t, datalayer.ProductDiscontinued, filter.Status)
+				return []*datalayer.Product{}, nil
+			},
+		}
+		router := handlers.NewRouter(handlers.Options{})
+		handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()).RegisterRoutes(router)
+		req := httptest.NewRequest(http.MethodGet, "/products?status=discontinued", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should return 403 listing drafts without the admin role", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?status=draft", nil)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		testutil.AssertGolden(t, "list_products_draft_forbidden", rec.Body.Bytes())
+	})
+
+	t.Run("should list drafts for admins", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, _ time.Time, _ int) ([]*datalayer.Product, error) {
+				assert.Equal(t, datalayer.ProductDraft, filter.Status)
+				return []*datalayer.Product{}, nil
+			},
+		}
+		router := handlers.NewRouter(handlers.Options{})
+		handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()).RegisterRoutes(router)
+		req := httptest.NewRequest(http.MethodGet, "/products?status=draft", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should pass attribute filters to the repo", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, _ time.Time, _ int) ([]*datalayer.Product, error) {
+				assert.Equal(t, map[string]string{"finish": "matte", "wattage": "40"}, filter.Attributes)
+				return []*datalayer.Product{}, nil
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?attr.finish=matte&attr.wattage=40", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should pass the image filter to the repo", func(t *testing.T) {
+		for query, want := range map[string]*bool{"": nil, "?has_image=true": boolPtr(true), "?has_image=false": boolPtr(false)} {
+			repo := &repomocks.ProductRepoInterfaceMock{
+				ListProductsFunc: func(_ context.Context, filter datalayer.ProductFilter, _ time.Time, _ int) ([]*datalayer.Product, error) {
+					assert.Equal(t, want, filter.HasImage, query)
+					return []*datalayer.Product{}, nil
+				},
+			}
+			rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products"+query, nil)
+
+			assert.Equal(t, http.StatusOK, rec.Code, query)
+		}
+	})
+
+	t.Run("should return 400 if has_image is not a boolean", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?has_image=maybe", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), "has_image must be a boolean")
+	})
+
+	t.Run("should return 400 if an attribute filter is repeated", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?attr.finish=matte&attr.finish=gloss", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "attr.finish may be given once")
+	})
+
+	t.Run("should return 400 if status is unknown", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products?status=archived", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "status must be one of: draft, active, discontinued")
+	})
+}
+
+func TestProductHandlerListDuplicateProducts(t *testing.T) {
+	newHandler := func(repo datalayer.ProductRepoInterface) *handlers.ProductHandler {
+		return handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger())
+	}
+	group := func(name string) *datalayer.DuplicateGroup {
+		first, second := testProduct, testProduct
+		second.ID = uuid.MustParse("3b0e8f4a-7f0d-4f0a-9d7e-5c2a1e6b8d90")
+		second.Name = "  " + strings.ToUpper(testProduct.Name)
+		return &datalayer.DuplicateGroup{
+			DuplicateGroupKey: datalayer.DuplicateGroupKey{CategoryID: testProduct.CategoryID, NormalizedName: name},
+			Products:          []*datalayer.Product{&first, &second},
+		}
+	}
+
+	t.Run("should return a page of groups with a cursor", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListDuplicateGroupsFunc: func(_ context.Context, filter datalayer.DuplicateFilter, after datalayer.DuplicateGroupKey, limit int) ([]*datalayer.DuplicateGroup, error) {
+				assert.Equal(t, datalayer.DuplicateFilter{MinGroupSize: 2}, filter)
+				assert.Equal(t, datalayer.DuplicateGroupKey{}, after)
+				assert.Equal(t, 2, limit)
+				return []*datalayer.DuplicateGroup{group("test product a"), group("test product b")}, nil
+			},
+		}
+		rec := serve(newHandler(repo), http.MethodGet, "/products/duplicates?limit=1", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_duplicate_products", rec.Body.Bytes())
+	})
+
+	t.Run("should pass the decoded cursor and minimum group size", func(t *testing.T) {
+		key := datalayer.DuplicateGroupKey{CategoryID: testProduct.CategoryID, NormalizedName: "desk  lamp"}
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListDuplicateGroupsFunc: func(_ context.Context, filter datalayer.DuplicateFilter, after datalayer.DuplicateGroupKey, limit int) ([]*datalayer.DuplicateGroup, error) {
+				assert.Equal(t, 3, filter.MinGroupSize)
+				assert.Equal(t, key, after)
+				assert.Equal(t, 21, limit)
+				return []*datalayer.DuplicateGroup{}, nil
+			},
+		}
+		rec := serve(newHandler(repo), http.MethodGet, "/products/duplicates?min_group_size=3&cursor="+handlers.EncodeGroupCursor(key), nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "nextCursor")
+	})
+
+	t.Run("should group drafts for admins", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListDuplicateGroupsFunc: func(_ context.Context, filter datalayer.DuplicateFilter, _ datalayer.DuplicateGroupKey, _ int) ([]*datalayer.DuplicateGroup, error) {
+				assert.True(t, filter.IncludeDrafts)
+				return []*datalayer.DuplicateGroup{}, nil
+			},
+		}
+		router := handlers.NewRouter(handlers.Options{})
+		newHandler(repo).RegisterRoutes(router)
+		req := httptest.NewRequest(http.MethodGet, "/products/duplicates", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	tests := []struct {
+		name    string
+		query   string
+		wantMsg string
+	}{
+		{name: "group size is below two", query: "min_group_size=1", wantMsg: "min_group_size must be an integer of at least 2"},
+		{name: "group size is not a number", query: "min_group_size=many", wantMsg: "min_group_size must be an integer of at least 2"},
+		{name: "cursor is malformed", query: "cursor=" + handlers.EncodeTimeToCursor(testProduct.CreatedAt), wantMsg: "cursor is invalid"},
+		{name: "limit is not a number", query: "limit=x", wantMsg: "limit must be an integer"},
+	}
+	for _, tt := range tests {
+		t.Run("should return 400 if "+tt.name, func(t *testing.T) {
+			rec := serve(newHandler(&repomocks.ProductRepoInterfaceMock{}), http.MethodGet, "/products/duplicates?"+tt.query, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+			assert.Contains(t, rec.Body.String(), tt.wantMsg)
+		})
+	}
+}
+
+func TestProductHandlerListProductsGroupedByCategory(t *testing.T) {
+	grouped := func(t *testing.T, wantLimit int) *repomocks.ProductRepoInterfaceMock {
+		other := testProduct
+		other.ID = uuid.MustParse("5d2e9f1a-6b7c-4d8e-af90-1b2c3d4e5f60")
+		other.Name = "Test Product B"
+		other.CategoryID = uuid.MustParse("8c1f4e2a-3b5d-4a6c-9e7f-0a1b2c3d4e5f")
+		return &repomocks.ProductRepoInterfaceMock{
+			ListProductsGroupedByCategoryFunc: func(_ context.Context, limitPerCategory int) (map[uuid.UUID][]*datalayer.Product, error) {
+				assert.Equal(t, wantLimit, limitPerCategory)
+				product := testProduct
+				return map[uuid.UUID][]*datalayer.Product{
+					product.CategoryID: {&product},
+					other.CategoryID:   {&other},
+				}, nil
+			},
+		}
+	}
+
+	t.Run("should key products by category and list 5 of each by default", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(grouped(t, 5), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/grouped-by-category", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "list_products_grouped_by_category", rec.Body.Bytes())
+	})
+
+	t.Run("should pass limit_per_category to the repo", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(grouped(t, 20), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/grouped-by-category?limit_per_category=20", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	for _, limit := range []string{"0", "21", "five"} {
+		t.Run("should return 400 if limit_per_category is "+limit, func(t *testing.T) {
+			rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/grouped-by-category?limit_per_category="+limit, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+			assert.Contains(t, rec.Body.String(), "limit_per_category must be an integer from 1 to 20")
+		})
+	}
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			ListProductsGroupedByCategoryFunc: func(context.Context, int) (map[uuid.UUID][]*datalayer.Product, error) {
+				return nil, errors.New("listProductsGroupedByCategory: select query failed: database error")
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/grouped-by-category", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestProductHandlerCountProducts(t *testing.T) {
+	count := func(t *testing.T, want datalayer.ProductCountFilter) *repomocks.ProductRepoInterfaceMock {
+		return &repomocks.ProductRepoInterfaceMock{
+			CountAllProductsFunc: func(_ context.Context, filter datalayer.ProductCountFilter) (int64, error) {
+				assert.Equal(t, want, filter)
+				return 1234, nil
+			},
+		}
+	}
+
+	t.Run("should count products of every category", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(count(t, datalayer.ProductCountFilter{}), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/count", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "count_products", rec.Body.Bytes())
+	})
+
+	t.Run("should count products of the given category", func(t *testing.T) {
+		categoryID := testProduct.CategoryID
+		repo := count(t, datalayer.ProductCountFilter{CategoryID: &categoryID})
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/count?category_id="+categoryID.String(), nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should return 400 if category_id is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/count?category_id=abc", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CountAllProductsFunc: func(context.Context, datalayer.ProductCountFilter) (int64, error) {
+				return 0, errors.New("countAllProducts: count query failed: database error")
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/count", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestProductHandlerGetProductAvailability(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/availability"
+	availability := func(quantity int, err error) *repomocks.ProductRepoInterfaceMock {
+		return &repomocks.ProductRepoInterfaceMock{
+			GetProductAvailabilityFunc: func(_ context.Context, id uuid.UUID) (int, error) {
+				assert.Equal(t, testProduct.ID, id)
+				return quantity, err
+			},
+		}
+	}
+
+	t.Run("should return the stock of an active product", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(availability(42, nil), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "get_product_availability", rec.Body.Bytes())
+	})
+
+	t.Run("should report out of stock products", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(availability(0, nil), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Data map[string]any `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, map[string]any{"inStock": false, "quantity": float64(0)}, body.Data)
+	})
+
+	t.Run("should return 404 if product is missing or not active", func(t *testing.T) {
+		err := fmt.Errorf("getProductAvailability: %w: id `%s`", datalayer.ErrNotFound, testProduct.ID)
+		rec := serve(handlers.NewProductHandler(availability(0, err), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeResourceNotFound, errorCode(t, rec))
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/abc/availability", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(availability(0, errors.New("getProductAvailability: select query failed: database error")), &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestProductHandlerGetProduct(t *testing.T) {
+	target := "/products/" + testProduct.ID.String()
+	draft := testProduct
+	draft.Status = datalayer.ProductDraft
+	getProduct := func(product datalayer.Product) func(context.Context, uuid.UUID) (*datalayer.Product, error) {
+		return func(_ context.Context, id uuid.UUID) (*datalayer.Product, error) {
+			assert.Equal(t, testProduct.ID, id)
+			return &product, nil
+		}
+	}
+
+	t.Run("should return the product", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{GetProductByIDFunc: getProduct(testProduct)}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "get_product", rec.Body.Bytes())
+	})
+
+	t.Run("should return 404 if product is missing", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			GetProductByIDFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("getProductByID: %w: id `%s`", datalayer.ErrNotFound, id)
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeResourceNotFound, errorCode(t, rec))
+		testutil.AssertGolden(t, "get_product_not_found", rec.Body.Bytes())
+	})
+
+	t.Run("should hide drafts from non-admins as not found", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{GetProductByIDFunc: getProduct(draft)}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeResourceNotFound, errorCode(t, rec))
+	})
+
+	t.Run("should show drafts to admins", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{GetProductByIDFunc: getProduct(draft)}
+		router := handlers.NewRouter(handlers.Options{})
+		handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()).RegisterRoutes(router)
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("should report availability from the stock", func(t *testing.T) {
+		outOfStock := testProduct
+		outOfStock.Quantity = 0
+
+		for _, tt := range []struct {
+			product   datalayer.Product
+			available bool
+		}{
+			{testProduct, true},
+			{outOfStock, false},
+		} {
+			repo := &repomocks.ProductRepoInterfaceMock{GetProductByIDFunc: getProduct(tt.product)}
+			rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target, nil)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var body struct {
+				Data map[string]any `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.available, body.Data["available"])
+			assert.Equal(t, float64(tt.product.Quantity), body.Data["quantity"])
+		}
+	})
+
+	t.Run("should hide the quantity", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{GetProductByIDFunc: getProduct(testProduct)}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target+"?hide_quantity=true", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "get_product_hide_quantity", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if hide_quantity is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, target+"?hide_quantity=maybe", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 400 if id is invalid", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/abc", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+// patchStored is a PatchProductFunc patching stored as the repos do,
+// calling the Precondition with it before applying the patch
+func patchStored(stored datalayer.Product) func(context.Context, uuid.UUID, datalayer.ProductPatch) (*datalayer.Product, error) {
+	return func(_ context.Context, _ uuid.UUID, patch datalayer.ProductPatch) (*datalayer.Product, error) {
+		product := stored
+		if patch.Precondition != nil {
+			if err := patch.Precondition(&product); err != nil {
+				return nil, err
+			}
+		}
+		if patch.Status != nil {
+			product.Status = *patch.Status
+		}
+		if patch.Attributes != nil {
+			product.Attributes = *patch.Attributes
+		}
+		return &product, nil
+	}
+}
+
+func TestProductHandlerPatchProduct(t *testing.T) {
+	target := "/products/" + testProduct.ID.String()
+
+	t.Run("should change status", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			PatchProductFunc: func(ctx context.Context, id uuid.UUID, patch datalayer.ProductPatch) (*datalayer.Product, error) {
+				assert.Equal(t, testProduct.ID, id)
+				assert.Equal(t, datalayer.ProductDiscontinued, *patch.Status)
+				assert.Nil(t, patch.Attributes)
+				return patchStored(testProduct)(ctx, id, patch)
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, strings.NewReader(`{"status":"discontinued"}`))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "patch_product_status", rec.Body.Bytes())
+	})
+
+	t.Run("should return 409 explaining an invalid transition", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			PatchProductFunc: func(context.Context, uuid.UUID, datalayer.ProductPatch) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("patchProduct: %w", &datalayer.StatusTransitionError{
+					From: datalayer.ProductDraft,
+					To:   datalayer.ProductDiscontinued,
+				})
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, strings.NewReader(`{"status":"discontinued"}`))
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		testutil.AssertGolden(t, "patch_product_invalid_transition", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if status and attributes are missing", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, strings.NewReader(`{}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "status or attributes is required")
+	})
+
+	definitions := &mocks.MockAttributeDefinitionRepo{
+		ListAttributeDefinitionsFunc: func(_ context.Context, categoryID uuid.UUID) ([]*datalayer.AttributeDefinition, error) {
+			assert.Equal(t, testCategory.ID, categoryID)
+			return lampDefinitions(), nil
+		},
+	}
+
+	t.Run("should replace attributes", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			PatchProductFunc: func(ctx context.Context, id uuid.UUID, patch datalayer.ProductPatch) (*datalayer.Product, error) {
+				assert.Equal(t, testProduct.ID, id)
+				assert.Nil(t, patch.Status)
+				return patchStored(testProduct)(ctx, id, patch)
+			},
+		}
+		body := strings.NewReader(`{"attributes":{"finish":"gloss","wattage":60}}`)
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "patch_product_attributes", rec.Body.Bytes())
+	})
+
+	t.Run("should return 422 with a detail per invalid attribute", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{PatchProductFunc: patchStored(testProduct)}
+		body := strings.NewReader(`{"attributes":{"finish":"satin","wattage":true}}`)
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		testutil.AssertGolden(t, "patch_product_invalid_attributes", rec.Body.Bytes())
+	})
+
+	t.Run("should not change status if attributes are rejected", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{PatchProductFunc: patchStored(testProduct)}
+		body := strings.NewReader(`{"status":"discontinued","attributes":{}}`)
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"attributes.finish","message":"is required"`)
+	})
+
+	t.Run("should return 400 if status is unknown", func(t *testing.T) {
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, strings.NewReader(`{"status":"archived"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "status must be one of")
+	})
+
+	t.Run("should return 404 if product not found", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			PatchProductFunc: func(context.Context, uuid.UUID, datalayer.ProductPatch) (*datalayer.Product, error) {
+				return nil, fmt.Errorf("patchProduct: %w: id `%s`", datalayer.ErrNotFound, testProduct.ID)
+			},
+		}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, strings.NewReader(`{"status":"active"}`))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should return 400 when publishing a product with quantity 0", func(t *testing.T) {
+		soldOut := testProduct
+		soldOut.Status = datalayer.ProductDraft
+		soldOut.Quantity = 0
+		repo := &repomocks.ProductRepoInterfaceMock{PatchProductFunc: patchStored(soldOut)}
+		rec := serve(handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodPatch, target, strings.NewReader(`{"status":"active"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "patch_product_publish_out_of_stock", rec.Body.Bytes())
+	})
+}
+
+func TestProductHandlerPatchProductIfMatch(t *testing.T) {
+	target := "/products/" + testProduct.ID.String()
+	body := `{"status":"discontinued"}`
+	// newHandler serves a memory repo holding testProduct
+	newHandler := func(t *testing.T) (*handlers.ProductHandler, *datalayer.MemoryProductRepo) {
+		t.Helper()
+		repo := datalayer.NewMemoryProductRepo()
+		product := testProduct
+		require.NoError(t, repo.CreateProduct(context.Background(), &product))
+		return handlers.NewProductHandler(repo, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), repo
+	}
+	storedStatus := func(t *testing.T, repo *datalayer.MemoryProductRepo) datalayer.ProductStatus {
+		t.Helper()
+		stored, err := repo.GetProductByID(context.Background(), testProduct.ID)
+		require.NoError(t, err)
+		return stored.Status
+	}
+
+	t.Run("should update when If-Match lists the current ETag", func(t *testing.T) {
+		h, repo := newHandler(t)
+		etag := serve(h, http.MethodGet, target, nil).Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {`"stale", ` + etag}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, datalayer.ProductDiscontinued, storedStatus(t, repo))
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+		assert.Equal(t, rec.Header().Get("ETag"), serve(h, http.MethodGet, target, nil).Header().Get("ETag"))
+	})
+
+	t.Run("should return 412 when If-Match does not match", func(t *testing.T) {
+		h, repo := newHandler(t)
+		etag := serve(h, http.MethodGet, target, nil).Header().Get("ETag")
+
+		for _, ifMatch := range []string{`"stale"`, "W/" + etag} {
+			rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {ifMatch}})
+
+			assert.Equal(t, http.StatusPreconditionFailed, rec.Code, ifMatch)
+			assert.Equal(t, apierrors.ErrCodePreconditionFailed, errorCode(t, rec))
+		}
+		assert.Equal(t, testProduct.Status, storedStatus(t, repo))
+	})
+
+	t.Run("should update when If-Match is a wildcard", func(t *testing.T) {
+		h, repo := newHandler(t)
+		rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {"*"}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, datalayer.ProductDiscontinued, storedStatus(t, repo))
+	})
+
+	t.Run("should return 404 when If-Match is sent for a missing product", func(t *testing.T) {
+		h, _ := newHandler(t)
+		missing := "/products/" + uuid.NewString()
+		rec := serveWithHeader(h, http.MethodPatch, missing, strings.NewReader(body), http.Header{"If-Match": {"*"}})
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should let one of concurrent updates with the same ETag through", func(t *testing.T) {
+		h, _ := newHandler(t)
+		etag := serve(h, http.MethodGet, target, nil).Header().Get("ETag")
+
+		const writers = 8
+		codes := make([]int, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := serveWithHeader(h, http.MethodPatch, target, strings.NewReader(body), http.Header{"If-Match": {etag}})
+				codes[i] = rec.Code
+			}()
+		}
+		wg.Wait()
+
+		var ok, failed int
+		for _, code := range codes {
+			switch code {
+			case http.StatusOK:
+				ok++
+			case http.StatusPreconditionFailed:
+				failed++
+			}
+		}
+		assert.Equal(t, 1, ok, codes)
+		assert.Equal(t, writers-1, failed, codes)
+	})
+}
+
+func TestProductHandlerCreateProduct(t *testing.T) {
+	categories := &repomocks.CategoryRepoInterfaceMock{
+		CategoryExistsFunc: func(_ context.Context, id uuid.UUID) (bool, error) {
+			return id == testCategory.ID, nil
+		},
+	}
+	definitions := &mocks.MockAttributeDefinitionRepo{
+		ListAttributeDefinitionsFunc: func(context.Context, uuid.UUID) ([]*datalayer.AttributeDefinition, error) {
+			return lampDefinitions(), nil
+		},
+	}
+
+	t.Run("should create a draft with a generated ID", func(t *testing.T) {
+		var created *datalayer.Product
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+				created = product
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"attributes":{"finish":"matte"}}`)
+		rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.NotEqual(t, uuid.Nil, created.ID)
+			assert.False(t, created.CreatedAt.IsZero())
+			assert.Equal(t, datalayer.ProductDraft, created.Status)
+			assert.Equal(t, testCategory.ID, created.CategoryID)
+			assert.Contains(t, rec.Body.String(), created.ID.String())
+		}
+	})
+
+	t.Run("should read camelCase bodies with the snake naming strategy", func(t *testing.T) {
+
+		var created *datalayer.Product
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+				created = product
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,` +
+			`"imageUrl":"https://example.com/lamp.png","attributes":{"finish":"matte"},"metadata":{"countryOfOrigin":"DE"}}`)
+		rec := serveWithOptions(handlers.Options{Naming: handlers.NamingSnakeCase}, handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.Equal(t, testCategory.ID, created.CategoryID)
+			assert.Equal(t, "https://example.com/lamp.png", created.ImageURL)
+		}
+		assert.Contains(t, rec.Body.String(), `"category_id":"`+testCategory.ID.String()+`"`)
+		assert.Contains(t, rec.Body.String(), `"image_url":"https://example.com/lamp.png"`)
+		assert.Contains(t, rec.Body.String(), `"metadata":{"countryOfOrigin":"DE"}`)
+	})
+
+	t.Run("should store metadata", func(t *testing.T) {
+		var created *datalayer.Product
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+				created = product
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,` +
+			`"attributes":{"finish":"matte"},"metadata":{"brand":"Acme","color":"red"}}`)
+		rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.Equal(t, datalayer.ProductMetadata{"brand": "Acme", "color": "red"}, created.Metadata)
+		}
+		assert.Contains(t, rec.Body.String(), `"metadata":{"brand":"Acme","color":"red"}`)
+	})
+
+	t.Run("should return 400 if metadata has a non-string value", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"metadata":{"weight":5}}`)
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 400 if metadata has too many keys", func(t *testing.T) {
+		pairs := make([]string, 21)
+		for i := range pairs {
+			pairs[i] = fmt.Sprintf(`"key%d":"x"`, i)
+		}
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"metadata":{` + strings.Join(pairs, ",") + `}}`)
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), "metadata must have at most 20 keys")
+	})
+
+	t.Run("should stamp created at on the server", func(t *testing.T) {
+		var created *datalayer.Product
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+				created = product
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,` +
+			`"attributes":{"finish":"matte"},"createdAt":"2999-01-01T00:00:00Z","created_at":"2999-01-01T00:00:00Z"}`)
+		before := time.Now().UTC()
+		rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.WithinRange(t, created.CreatedAt, before, time.Now().UTC())
+			assert.Equal(t, time.UTC, created.CreatedAt.Location())
+		}
+	})
+
+	t.Run("should return 400 if category does not exist", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testProduct.ID.String() + `","price":12.5}`)
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		testutil.AssertGolden(t, "create_product_unknown_category", rec.Body.Bytes())
+	})
+
+	t.Run("should price in the given currency or US dollars by default", func(t *testing.T) {
+		for _, tc := range []struct{ field, want string }{
+			{``, "USD"},
+			{`,"currency":"USD"`, "USD"},
+			{`,"currency":"EUR"`, "EUR"},
+		} {
+			var created *datalayer.Product
+			repo := &repomocks.ProductRepoInterfaceMock{
+				CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+					created = product
+					return nil
+				},
+			}
+			body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5` + tc.field + `,"attributes":{"finish":"matte"}}`)
+			rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+			assert.Equal(t, http.StatusCreated, rec.Code, tc.field)
+			if assert.NotNil(t, created, tc.field) {
+				assert.Equal(t, tc.want, created.Currency)
+				assert.Contains(t, rec.Body.String(), `"currency":"`+tc.want+`"`)
+			}
+		}
+	})
+
+	t.Run("should return 422 if the currency is not an ISO 4217 code", func(t *testing.T) {
+		for _, currency := range []string{"US", "USDX", "usd"} {
+			repo := &repomocks.ProductRepoInterfaceMock{
+				CreateProductFunc: func(context.Context, *datalayer.Product) error {
+					t.Fatal("should not store a product with an invalid currency")
+					return nil
+				},
+			}
+			body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"currency":"` + currency + `"}`)
+			rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, currency)
+			assert.Equal(t, apierrors.ErrCodeUnsupportedCurrency, errorCode(t, rec), currency)
+			assert.Contains(t, rec.Body.String(), "currency `"+currency+"` is not an ISO 4217 code")
+		}
+	})
+
+	t.Run("should store attributes that fit the category's definitions", func(t *testing.T) {
+		var created *datalayer.Product
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+				created = product
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"attributes":{"finish":"matte","wattage":40}}`)
+		rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		if assert.NotNil(t, created) {
+			assert.Equal(t, datalayer.ProductAttributes{"finish": "matte", "wattage": 40.0}, created.Attributes)
+		}
+	})
+
+	t.Run("should return 422 with a detail per invalid attribute", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"attributes":{"wattage":"forty","color":"red"}}`)
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		testutil.AssertGolden(t, "create_product_invalid_attributes", rec.Body.Bytes())
+	})
+
+	t.Run("should return 422 if weight is not positive", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"weight":-1,"attributes":{"finish":"matte"}}`)
+		rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		testutil.AssertGolden(t, "create_product_invalid_weight", rec.Body.Bytes())
+	})
+
+	t.Run("should pass every body field to the repo", func(t *testing.T) {
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(_ context.Context, product *datalayer.Product) error {
+				assert.Equal(t, "Lamp", product.Name)
+				assert.Equal(t, "A desk lamp", product.Description)
+				assert.Equal(t, "https://example.com/lamp.png", product.ImageURL)
+				assert.Equal(t, testCategory.ID, product.CategoryID)
+				assert.Equal(t, 12.5, product.Price)
+				assert.Equal(t, 3, product.Quantity)
+				return nil
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp","description":"A desk lamp","imageUrl":"https://example.com/lamp.png",` +
+			`"categoryId":"` + testCategory.ID.String() + `","price":12.5,"quantity":3,"attributes":{"finish":"matte"}}`)
+		rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"imageUrl":"https://example.com/lamp.png"`)
+	})
+
+	category := `"categoryId":"` + testCategory.ID.String() + `"`
+	tests := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{name: "body is not JSON", body: `[`, wantMsg: "request body must be a JSON object"},
+		{name: "name is missing", body: `{` + category + `,"price":12.5}`, wantMsg: "name is required"},
+		{name: "name is blank", body: `{"name":"  ",` + category + `,"price":12.5}`, wantMsg: "name is required"},
+		{name: "price is missing", body: `{"name":"Lamp",` + category + `}`, wantMsg: "price must be greater than zero"},
+		{name: "price is zero", body: `{"name":"Lamp",` + category + `,"price":0}`, wantMsg: "price must be greater than zero"},
+		{name: "price is negative", body: `{"name":"Lamp",` + category + `,"price":-1}`, wantMsg: "price must be greater than zero"},
+		{name: "quantity is negative", body: `{"name":"Lamp",` + category + `,"price":12.5,"quantity":-1}`, wantMsg: "quantity must not be negative"},
+		{name: "categoryId is missing", body: `{"name":"Lamp","price":12.5}`, wantMsg: "categoryId is required"},
+		{name: "categoryId is the nil UUID", body: `{"name":"Lamp","categoryId":"` + uuid.Nil.String() + `","price":12.5}`, wantMsg: "categoryId is required"},
+		{name: "categoryId is not a UUID", body: `{"name":"Lamp","categoryId":"lamps","price":12.5}`, wantMsg: "request body must be a JSON object"},
+		{name: "price is a string", body: `{"name":"Lamp",` + category + `,"price":"12.5"}`, wantMsg: "request body must be a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run("should return 400 if "+tt.name, func(t *testing.T) {
+			repo := &repomocks.ProductRepoInterfaceMock{
+				CreateProductFunc: func(context.Context, *datalayer.Product) error {
+					t.Error("repo must not be called for an invalid product")
+					return nil
+				},
+			}
+			rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, handlermocks.NewLogger()), http.MethodPost, "/products", strings.NewReader(tt.body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+			assert.Contains(t, rec.Body.String(), tt.wantMsg)
+		})
+	}
+
+	t.Run("should return 500 if repo fails", func(t *testing.T) {
+		logger := handlermocks.NewLogger()
+		repo := &repomocks.ProductRepoInterfaceMock{
+			CreateProductFunc: func(context.Context, *datalayer.Product) error {
+				return errors.New("createProduct: insert query failed: boom")
+			},
+		}
+		body := strings.NewReader(`{"name":"Lamp",` + category + `,"price":12.5,"attributes":{"finish":"matte"}}`)
+		rec := serve(handlers.NewProductHandler(repo, categories, definitions, 0, 0, logger), http.MethodPost, "/products", body)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInternalServerError, errorCode(t, rec))
+		assert.NotContains(t, rec.Body.String(), "boom")
+	})
+}
+
+func TestProductHandlerApplyProductBatch(t *testing.T) {
+	ctx := context.Background()
+	other := datalayer.Product{
+		ID:         uuid.MustParse("7d0e2a4c-1b3f-4e5a-8c6d-9f0a1b2c3d4e"),
+		Name:       "Test Product B",
+		CategoryID: testCategory.ID,
+		Price:      5,
+		Quantity:   1,
+		Status:     datalayer.ProductActive,
+		CreatedAt:  testProduct.CreatedAt,
+	}
+	newHandler := func(t *testing.T) (*handlers.ProductHandler, *datalayer.MemoryProductRepo) {
+		categories := datalayer.NewMemoryCategoryRepo()
+		category := testCategory
+		assert.NoError(t, categories.CreateCategory(ctx, &category))
+		products := datalayer.NewMemoryProductRepo()
+		for _, product := range []datalayer.Product{testProduct, other} {
+			assert.NoError(t, products.CreateProduct(ctx, &product))
+		}
+		definitions := datalayer.NewMemoryAttributeDefinitionRepo(categories)
+		return handlers.NewProductHandler(products, categories, definitions, 0, 0, handlermocks.NewLogger()), products
+	}
+	mixedBatch := func(deleteID uuid.UUID) string {
+		return `[` +
+			`{"op":"create","product":{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5}},` +
+			`{"op":"update","product":{"id":"` + testProduct.ID.String() + `","name":"Renamed","categoryId":"` + testCategory.ID.String() + `","price":99,"quantity":4}},` +
+			`{"op":"delete","product":{"id":"` + deleteID.String() + `"}}` +
+			`]`
+	}
+
+	t.Run("should apply a mixed batch and report every item", func(t *testing.T) {
+		h, products := newHandler(t)
+		rec := serve(h, http.MethodPost, "/products/batch", strings.NewReader(mixedBatch(other.ID)))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Data []struct {
+				Index   int                `json:"index"`
+				Op      string             `json:"op"`
+				Status  string             `json:"status"`
+				Product *datalayer.Product `json:"product"`
+				ID      *uuid.UUID         `json:"id"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		if assert.Len(t, body.Data, 3) {
+			assert.Equal(t, "create", body.Data[0].Op)
+			assert.Equal(t, "applied", body.Data[0].Status)
+			assert.Equal(t, datalayer.ProductDraft, body.Data[0].Product.Status)
+			assert.Equal(t, "Renamed", body.Data[1].Product.Name)
+			assert.Equal(t, datalayer.ProductActive, body.Data[1].Product.Status)
+			assert.Equal(t, 2, body.Data[2].Index)
+			assert.Equal(t, &other.ID, body.Data[2].ID)
+			assert.Nil(t, body.Data[2].Product)
+		}
+
+		updated, err := products.GetProductByID(ctx, testProduct.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Renamed", updated.Name)
+		_, err = products.GetProductByID(ctx, other.ID)
+		assert.ErrorIs(t, err, datalayer.ErrNotFound)
+	})
+
+	t.Run("should return 422 and roll back if an item fails", func(t *testing.T) {
+		h, products := newHandler(t)
+		missing := uuid.MustParse("0e1f2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b")
+		rec := serve(h, http.MethodPost, "/products/batch", strings.NewReader(mixedBatch(missing)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		testutil.AssertGolden(t, "product_batch_rolled_back", rec.Body.Bytes())
+
+		unchanged, err := products.GetProductByID(ctx, testProduct.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, testProduct.Name, unchanged.Name)
+		listed, err := products.ListProducts(ctx, datalayer.ProductFilter{}, time.Time{}, 10)
+		assert.NoError(t, err)
+		assert.Len(t, listed, 2, "the created product must be rolled back")
+	})
+
+	t.Run("should list every invalid item", func(t *testing.T) {
+		h, _ := newHandler(t)
+		body := `[{"op":"upsert","product":{}},{"op":"create","product":{"categoryId":"` + testCategory.ID.String() + `","price":1}},{"op":"delete","product":{}}]`
+		rec := serve(h, http.MethodPost, "/products/batch", strings.NewReader(body))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		testutil.AssertGolden(t, "product_batch_invalid_items", rec.Body.Bytes())
+	})
+
+	t.Run("should apply the other items if partial", func(t *testing.T) {
+		h, products := newHandler(t)
+		missing := uuid.MustParse("0e1f2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b")
+		rec := serve(h, http.MethodPost, "/products/batch?partial=true", strings.NewReader(mixedBatch(missing)))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `{"index":2,"op":"delete","status":"failed","error":"product not found"}`)
+		updated, err := products.GetProductByID(ctx, testProduct.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Renamed", updated.Name)
+	})
+
+	t.Run("should return 400 for a body that is not an array", func(t *testing.T) {
+		h, _ := newHandler(t)
+		rec := serve(h, http.MethodPost, "/products/batch", strings.NewReader(`{"op":"create"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+	})
+
+	t.Run("should return 400 for an empty batch or invalid partial", func(t *testing.T) {
+		h, _ := newHandler(t)
+
+		rec := serve(h, http.MethodPost, "/products/batch", strings.NewReader(`[]`))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = serve(h, http.MethodPost, "/products/batch?partial=maybe", strings.NewReader(`[]`))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("should return 400 for a batch over the configured size", func(t *testing.T) {
+		h, products := newHandler(t)
+
+		rec := serveWithOptions(handlers.Options{MaxBatchSize: 2}, h, http.MethodPost, "/products/batch", strings.NewReader(mixedBatch(other.ID)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeInvalidFieldFormat, errorCode(t, rec))
+		assert.Contains(t, rec.Body.String(), "batch has 3 items, at most 2 are allowed")
+		_, err := products.GetProductByID(ctx, other.ID)
+		assert.NoError(t, err, "nothing is applied")
+	})
+}
+
+func TestProductHandlerStreamProductEvents(t *testing.T) {
+	newHandler := func(t *testing.T) *handlers.ProductHandler {
+		categories := datalayer.NewMemoryCategoryRepo()
+		category := testCategory
+		assert.NoError(t, categories.CreateCategory(context.Background(), &category))
+		definitions := datalayer.NewMemoryAttributeDefinitionRepo(categories)
+		return handlers.NewProductHandler(datalayer.NewMemoryProductRepo(), categories, definitions, 0, 0, handlermocks.NewLogger())
+	}
+
+	t.Run("should push product changes as server-sent events", func(t *testing.T) {
+		router := handlers.NewRouter(handlers.Options{})
+		newHandler(t).RegisterRoutes(router)
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products/stream", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		resp, err := server.Client().Do(req)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		body := `{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"quantity":3,"status":"active"}`
+		created, err := server.Client().Post(server.URL+"/products", "application/json", strings.NewReader(body))
+		if !assert.NoError(t, err) {
+			return
+		}
+		created.Body.Close()
+		assert.Equal(t, http.StatusCreated, created.StatusCode)
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		assert.NoError(t, err)
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if assert.True(t, ok, "unexpected line %q", line) {
+			var event struct {
+				Type    string             `json:"type"`
+				Product *datalayer.Product `json:"product"`
+			}
+			assert.NoError(t, json.Unmarshal([]byte(data), &event))
+			assert.Equal(t, "product.created", event.Type)
+			assert.Equal(t, "Lamp", event.Product.Name)
+		}
+	})
+
+	for _, admin := range []bool{false, true} {
+		name := "should not send draft products to non-admins"
+		if admin {
+			name = "should send draft products to admins"
+		}
+		t.Run(name, func(t *testing.T) {
+			router := handlers.NewRouter(handlers.Options{})
+			newHandler(t).RegisterRoutes(router)
+			var handler http.Handler = router
+			if admin {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					router.ServeHTTP(w, r.WithContext(handlers.WithAdmin(r.Context())))
+				})
+			}
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products/stream", nil)
+			resp, err := server.Client().Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			for _, body := range []string{
+				`{"name":"Draft lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5}`,
+				`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"quantity":3,"status":"active"}`,
+			} {
+				created, err := server.Client().Post(server.URL+"/products", "application/json", strings.NewReader(body))
+				if !assert.NoError(t, err) {
+					return
+				}
+				created.Body.Close()
+				assert.Equal(t, http.StatusCreated, created.StatusCode)
+			}
+
+			line, err := bufio.NewReader(resp.Body).ReadString('\n')
+			assert.NoError(t, err)
+			if admin {
+				assert.Contains(t, line, `"name":"Draft lamp"`)
+			} else {
+				assert.Contains(t, line, `"name":"Lamp"`, "the draft is not sent to a non-admin")
+			}
+		})
+	}
+
+	for _, admin := range []bool{false, true} {
+		name := "should not send deletions of draft products to non-admins"
+		if admin {
+			name = "should send deletions of draft products to admins"
+		}
+		t.Run(name, func(t *testing.T) {
+			router := handlers.NewRouter(handlers.Options{})
+			newHandler(t).RegisterRoutes(router)
+			var handler http.Handler = router
+			if admin {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					router.ServeHTTP(w, r.WithContext(handlers.WithAdmin(r.Context())))
+				})
+			}
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			var ids []string
+			for _, body := range []string{
+				`{"name":"Draft lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5}`,
+				`{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"quantity":3,"status":"active"}`,
+			} {
+				created, err := server.Client().Post(server.URL+"/products", "application/json", strings.NewReader(body))
+				if !assert.NoError(t, err) {
+					return
+				}
+				var envelope struct {
+					Data datalayer.Product `json:"data"`
+				}
+				assert.NoError(t, json.NewDecoder(created.Body).Decode(&envelope))
+				created.Body.Close()
+				ids = append(ids, envelope.Data.ID.String())
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products/stream", nil)
+			resp, err := server.Client().Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			for _, id := range ids {
+				req, _ := http.NewRequest(http.MethodDelete, server.URL+"/products/"+id, nil)
+				deleted, err := server.Client().Do(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				deleted.Body.Close()
+				assert.Equal(t, http.StatusNoContent, deleted.StatusCode)
+			}
+
+			line, err := bufio.NewReader(resp.Body).ReadString('\n')
+			assert.NoError(t, err)
+			assert.Contains(t, line, `"type":"product.deleted"`)
+			if admin {
+				assert.Contains(t, line, `"productId":"`+ids[0]+`"`)
+			} else {
+				assert.Contains(t, line, `"productId":"`+ids[1]+`"`, "the draft's deletion is not sent to a non-admin")
+			}
+		})
+	}
+
+	t.Run("should outlive the server's write timeout", func(t *testing.T) {
+		router := handlers.NewRouter(handlers.Options{})
+		newHandler(t).RegisterRoutes(router)
+		server := httptest.NewUnstartedServer(router)
+		server.Config.WriteTimeout = 100 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/products/stream", nil)
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		time.Sleep(200 * time.Millisecond)
+
+		body := `{"name":"Lamp","categoryId":"` + testCategory.ID.String() + `","price":12.5,"quantity":3,"status":"active"}`
+		created, err := server.Client().Post(server.URL+"/products", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		created.Body.Close()
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		assert.Contains(t, line, `"name":"Lamp"`)
+	})
+
+	t.Run("should answer 503 once the maximum streams are open", func(t *testing.T) {
+		categories := datalayer.NewMemoryCategoryRepo()
+		definitions := datalayer.NewMemoryAttributeDefinitionRepo(categories)
+		h := handlers.NewProductHandler(datalayer.NewMemoryProductRepo(), categories, definitions, 0, 1, handlermocks.NewLogger())
+		_, unsubscribe, err := h.Events().Subscribe(1)
+		require.NoError(t, err)
+		defer unsubscribe()
+
+		rec := serve(h, http.MethodGet, "/products/stream", nil)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"status":"error","error":{"code":1604,"message":"too many open event streams, retry later"}}`, rec.Body.String())
+	})
+
+	t.Run("should return once the client disconnects", func(t *testing.T) {
+		h := newHandler(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/products/stream", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			h.StreamProductEvents(rec, req)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream did not end after the client disconnected")
+		}
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, rec.Flushed)
+		assert.Empty(t, rec.Body.String())
+	})
+}
+
+func TestProductHandlerGetProductSchema(t *testing.T) {
+	rec := serve(handlers.NewProductHandler(&repomocks.ProductRepoInterfaceMock{}, &repomocks.CategoryRepoInterfaceMock{}, &mocks.MockAttributeDefinitionRepo{}, 0, 0, handlermocks.NewLogger()), http.MethodGet, "/products/schema", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Data struct {
+			Type       string                    `json:"type"`
+			Required   []string                  `json:"required"`
+			Properties map[string]map[string]any `json:"properties"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	schema := body.Data
+
+	t.Run("should describe an object requiring a name", func(t *testing.T) {
+		assert.Equal(t, "object", schema.Type)
+		assert.Contains(t, schema.Required, "name")
+		assert.Equal(t, "string", schema.Properties["name"]["type"])
+	})
+
+	t.Run("should describe price as a number above 0", func(t *testing.T) {
+		assert.Contains(t, schema.Required, "price")
+		assert.Equal(t, "number", schema.Properties["price"]["type"])
+		assert.Equal(t, float64(0), schema.Properties["price"]["exclusiveMinimum"])
+	})
+
+	t.Run("should list the product statuses", func(t *testing.T) {
+		assert.Equal(t, []any{"draft", "active", "discontinued"}, schema.Properties["status"]["enum"])
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+// versionTTL is how long a database version read by the readiness check
+// is reused before the database is queried again
+const versionTTL = time.Minute
+
+// VersionQuerier is the database the readiness check reports on, as
+// *sqlx.DB is
+type VersionQuerier interface {
+	DriverName() string
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+type ReadinessHandler struct {
+	db     VersionQuerier
+	mode   *MaintenanceMode
+	logger LoggerInterface
+	now    func() time.Time
+
+	mu        sync.Mutex
+	version   string
+	checkedAt time.Time
+}
+
+// Readiness is the body of GET /ready. DB is omitted when the API runs
+// without a database. Maintenance reports whether writes are refused.
+type Readiness struct {
+	DB          *DBInfo `json:"db,omitempty"`
+	Maintenance bool    `json:"maintenance"`
+}
+
+// DBInfo names the database driver and server version
+type DBInfo struct {
+	Driver  string `json:"driver"`
+	Version string `json:"version"`
+}
+
+// NewReadinessHandler creates a new readiness handler instance. db may be
+// nil when there is no database. mode is reported as is; the API stays
+// ready during maintenance. now decides when a cached version expires.
+func NewReadinessHandler(db VersionQuerier, mode *MaintenanceMode, now func() time.Time, logger LoggerInterface) *ReadinessHandler {
+	return &ReadinessHandler{db: db, mode: mode, logger: logger, now: now}
+}
+
+// RegisterRoutes registers the readiness endpoint on the router
+func (h *ReadinessHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("GET /ready", h.GetReadiness)
+}
+
+// GetReadiness reports the database driver and server version and whether
+// maintenance mode is on. The version is read at most once a minute; a
+// failed read is not cached and answers 503.
+func (h *ReadinessHandler) GetReadiness(w http.ResponseWriter, r *http.Request) {
+	readiness := Readiness{Maintenance: h.mode.Enabled()}
+	if h.db != nil {
+		version, err := h.serverVersion(r.Context())
+		if err != nil {
+			h.logger.LogError(OpFromContext(r.Context()), err)
+			WriteErrorResponse(w, r, http.StatusServiceUnavailable, apierrors.ErrCodeDatabaseUnavailable, "database unavailable", h.logger)
+			return
+		}
+		readiness.DB = &DBInfo{Driver: h.db.DriverName(), Version: version}
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "ready", readiness, h.logger)
+}
+
+// serverVersion returns the cached server version, querying the database
+// when it is missing or older than versionTTL
+func (h *ReadinessHandler) serverVersion(ctx context.Context) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	if h.version != "" && now.Sub(h.checkedAt) < versionTTL {
+		return h.version, nil
+	}
+
+	var version string
+	if err := h.db.GetContext(ctx, &version, `SELECT version()`); err != nil {
+		return "", fmt.Errorf("serverVersion: select query failed: %w", err)
+	}
+	h.version, h.checkedAt = version, now
+	return version, nil
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const postgresVersion = "PostgreSQL 16.2 on x86_64-pc-linux-gnu, compiled by gcc (GCC) 12.2.0, 64-bit"
+
+func TestReadinessHandlerGetReadiness(t *testing.T) {
+	versionQuery := regexp.QuoteMeta(`SELECT version()`)
+
+	t.Run("should report the driver and server version", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+		mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(postgresVersion))
+
+		h := handlers.NewReadinessHandler(sqlx.NewDb(mockDB, "postgres"), nil, time.Now, mocks.NewLogger())
+		rec := serve(h, http.MethodGet, "/ready", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "get_readiness", rec.Body.Bytes())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should query the version again only after a minute", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+		mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+		mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.3"))
+
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		h := handlers.NewReadinessHandler(sqlx.NewDb(mockDB, "postgres"), nil, func() time.Time { return now }, mocks.NewLogger())
+
+		assert.Contains(t, serve(h, http.MethodGet, "/ready", nil).Body.String(), "PostgreSQL 16.2")
+		now = now.Add(59 * time.Second)
+		assert.Contains(t, serve(h, http.MethodGet, "/ready", nil).Body.String(), "PostgreSQL 16.2")
+		now = now.Add(time.Second)
+		assert.Contains(t, serve(h, http.MethodGet, "/ready", nil).Body.String(), "PostgreSQL 16.3")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return 503 and retry if the version query fails", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer mockDB.Close()
+		mock.ExpectQuery(versionQuery).WillReturnError(errors.New("connection refused"))
+		mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("PostgreSQL 16.2"))
+
+		logger := mocks.NewLogger()
+		h := handlers.NewReadinessHandler(sqlx.NewDb(mockDB, "postgres"), nil, time.Now, logger)
+
+		rec := serve(h, http.MethodGet, "/ready", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, apierrors.ErrCodeDatabaseUnavailable, errorCode(t, rec))
+		assert.NotContains(t, rec.Body.String(), "connection refused")
+		assert.Len(t, logger.LogErrorCalls(), 1)
+
+		rec = serve(h, http.MethodGet, "/ready", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should report maintenance mode", func(t *testing.T) {
+		mode := &handlers.MaintenanceMode{}
+		h := handlers.NewReadinessHandler(nil, mode, time.Now, mocks.NewLogger())
+
+		assert.Contains(t, serve(h, http.MethodGet, "/ready", nil).Body.String(), `"maintenance":false`)
+		mode.Set(true)
+		rec := serve(h, http.MethodGet, "/ready", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"maintenance":true`)
+	})
+
+	t.Run("should omit db info without a database", func(t *testing.T) {
+		rec := serve(handlers.NewReadinessHandler(nil, nil, time.Now, mocks.NewLogger()), http.MethodGet, "/ready", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"db"`)
+	})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+type ReservationHandler struct {
+	repo   datalayer.ReservationRepoInterface
+	logger LoggerInterface
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// reserveRequest is the body of POST /products/{id}/reservations
+type reserveRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// NewReservationHandler creates a new reservation handler instance. Holds
+// expire ttl after they are taken.
+func NewReservationHandler(
+	repo datalayer.ReservationRepoInterface,
+	ttl time.Duration,
+	logger LoggerInterface,
+) *ReservationHandler {
+	return &ReservationHandler{repo: repo, logger: logger, ttl: ttl, now: time.Now}
+}
+
+// RegisterRoutes registers the reservation endpoints on the router
+func (h *ReservationHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("POST /products/{id}/reservations", h.CreateReservation)
+	router.HandleFunc("DELETE /reservations/{id}", h.ReleaseReservation)
+	router.HandleFunc("POST /reservations/{id}/commit", h.CommitReservation)
+}
+
+// CreateReservation holds stock of a product until the hold expires or is
+// released or committed
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	productID, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	var req reserveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "request body must be a JSON object", h.logger)
+		return
+	}
+	if req.Quantity <= 0 {
+		WriteErrorResponse(w, r, http.StatusBadRequest, apierrors.ErrCodeInvalidFieldFormat, "quantity must be greater than 0", h.logger)
+		return
+	}
+
+	reservation := &datalayer.Reservation{
+		ProductID: productID,
+		Quantity:  req.Quantity,
+		ExpiresAt: h.now().UTC().Add(h.ttl),
+	}
+	if err := h.repo.CreateReservation(r.Context(), reservation); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusCreated, "reservation created", reservation, h.logger)
+}
+
+// ReleaseReservation returns a held reservation's stock and responds with
+// an empty 204
+func (h *ReservationHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	if _, err := h.repo.ReleaseReservation(r.Context(), id); err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CommitReservation finalizes a held reservation
+func (h *ReservationHandler) CommitReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseUUIDParam(r, "id")
+	if err != nil {
+		writeUUIDError(w, r, err, h.logger)
+		return
+	}
+
+	reservation, err := h.repo.CommitReservation(r.Context(), id)
+	if err != nil {
+		writeRepoError(w, r, err, h.logger)
+		return
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "reservation committed", reservation, h.logger)
+}
@@ -0,0 +1,158 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	handlermocks "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/mocks"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var testReservation = datalayer.Reservation{
+	ID:        uuid.MustParse("0d9d7b4a-3a7e-4f4c-9d65-1b1f8a4c2e11"),
+	ProductID: testProduct.ID,
+	Quantity:  2,
+	ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC),
+	Status:    datalayer.ReservationHeld,
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestReservationHandlerCreateReservation(t *testing.T) {
+	target := "/products/" + testProduct.ID.String() + "/reservations"
+
+	t.Run("should hold stock until the ttl elapses", func(t *testing.T) {
+		start := time.Now().UTC()
+		repo := &mocks.MockReservationRepo{
+			CreateReservationFunc: func(_ context.Context, reservation *datalayer.Reservation) error {
+				assert.Equal(t, testProduct.ID, reservation.ProductID)
+				assert.Equal(t, 2, reservation.Quantity)
+				assert.WithinDuration(t, start.Add(15*time.Minute), reservation.ExpiresAt, time.Minute)
+				*reservation = testReservation
+				return nil
+			},
+		}
+		h := handlers.NewReservationHandler(repo, 15*time.Minute, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"quantity":2}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		testutil.AssertGolden(t, "create_reservation", rec.Body.Bytes())
+	})
+
+	t.Run("should return 409 if stock is insufficient", func(t *testing.T) {
+		repo := &mocks.MockReservationRepo{
+			CreateReservationFunc: func(context.Context, *datalayer.Reservation) error {
+				return fmt.Errorf("createReservation: %w: 2 requested, 1 available", datalayer.ErrInsufficientStock)
+			},
+		}
+		h := handlers.NewReservationHandler(repo, time.Minute, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"quantity":2}`))
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		testutil.AssertGolden(t, "create_reservation_insufficient_stock", rec.Body.Bytes())
+	})
+
+	t.Run("should return 400 if quantity is not positive", func(t *testing.T) {
+		h := handlers.NewReservationHandler(&mocks.MockReservationRepo{}, time.Minute, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"quantity":0}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "quantity must be greater than 0")
+	})
+
+	t.Run("should return 404 if product not found", func(t *testing.T) {
+		repo := &mocks.MockReservationRepo{
+			CreateReservationFunc: func(context.Context, *datalayer.Reservation) error {
+				return fmt.Errorf("createReservation: %w: product id `%s`", datalayer.ErrNotFound, testProduct.ID)
+			},
+		}
+		h := handlers.NewReservationHandler(repo, time.Minute, handlermocks.NewLogger())
+		rec := serve(h, http.MethodPost, target, strings.NewReader(`{"quantity":1}`))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestReservationHandlerReleaseReservation(t *testing.T) {
+	target := "/reservations/" + testReservation.ID.String()
+
+	t.Run("should return 204 once released", func(t *testing.T) {
+		repo := &mocks.MockReservationRepo{
+			ReleaseReservationFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Reservation, error) {
+				assert.Equal(t, testReservation.ID, id)
+				reservation := testReservation
+				reservation.Status = datalayer.ReservationReleased
+				return &reservation, nil
+			},
+		}
+		rec := serve(handlers.NewReservationHandler(repo, time.Minute, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should return 409 if reservation is no longer held", func(t *testing.T) {
+		repo := &mocks.MockReservationRepo{
+			ReleaseReservationFunc: func(context.Context, uuid.UUID) (*datalayer.Reservation, error) {
+				return nil, fmt.Errorf("releaseReservation: %w: id `%s` is committed", datalayer.ErrReservationClosed, testReservation.ID)
+			},
+		}
+		rec := serve(handlers.NewReservationHandler(repo, time.Minute, handlermocks.NewLogger()), http.MethodDelete, target, nil)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		testutil.AssertGolden(t, "release_reservation_closed", rec.Body.Bytes())
+	})
+}
+
+func TestReservationHandlerCommitReservation(t *testing.T) {
+	target := "/reservations/" + testReservation.ID.String() + "/commit"
+
+	t.Run("should return committed reservation", func(t *testing.T) {
+		repo := &mocks.MockReservationRepo{
+			CommitReservationFunc: func(_ context.Context, id uuid.UUID) (*datalayer.Reservation, error) {
+				assert.Equal(t, testReservation.ID, id)
+				reservation := testReservation
+				reservation.Status = datalayer.ReservationCommitted
+				return &reservation, nil
+			},
+		}
+		rec := serve(handlers.NewReservationHandler(repo, time.Minute, handlermocks.NewLogger()), http.MethodPost, target, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		testutil.AssertGolden(t, "commit_reservation", rec.Body.Bytes())
+	})
+
+	t.Run("should return 409 if reservation expired", func(t *testing.T) {
+		repo := &mocks.MockReservationRepo{
+			CommitReservationFunc: func(context.Context, uuid.UUID) (*datalayer.Reservation, error) {
+				return nil, fmt.Errorf("commitReservation: %w: id `%s`", datalayer.ErrReservationExpired, testReservation.ID)
+			},
+		}
+		rec := serve(handlers.NewReservationHandler(repo, time.Minute, handlermocks.NewLogger()), http.MethodPost, target, nil)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("should return 500 and log if repo fails", func(t *testing.T) {
+		logger := handlermocks.NewLogger()
+		repo := &mocks.MockReservationRepo{
+			CommitReservationFunc: func(context.Context, uuid.UUID) (*datalayer.Reservation, error) {
+				return nil, errors.New("commitReservation: commit failed: commit error")
+			},
+		}
+		rec := serve(handlers.NewReservationHandler(repo, time.Minute, logger), http.MethodPost, target, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Len(t, logger.LogErrorCalls(), 1)
+		assert.Equal(t, "POST /reservations/{id}/commit", logger.LogErrorCalls()[0].Op)
+	})
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCachedResponses caps the entries of a ResponseCache so distinct
+// cursors and filters cannot grow it without bound
+const maxCachedResponses = 1000
+
+// ResponseCache serves repeated GET requests with identical query
+// parameters from a copy of the first response for a short TTL. Concurrent
+// misses for the same parameters wait for one call to the wrapped handler.
+// Only 200 responses are kept, and Invalidate drops them all.
+//
+// The cache is per process, so after a write other replicas may serve the
+// old response for up to the TTL.
+type ResponseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a response being filled or filled. done is closed once
+// resp is set; resp stays nil when the response was not cacheable.
+type cacheEntry struct {
+	done    chan struct{}
+	resp    *bufferedResponse
+	expires time.Time
+}
+
+// NewResponseCache creates a cache keeping responses for ttl. A zero ttl
+// disables caching.
+func NewResponseCache(ttl time.Duration, now func() time.Time) *ResponseCache {
+	return &ResponseCache{ttl: ttl, now: now, entries: map[string]*cacheEntry{}}
+}
+
+// Wrap serves next through the cache. Hits carry X-Cache: HIT and misses
+// X-Cache: MISS. Conditional headers are applied to the cached response, so
+// they never reach next.
+func (c *ResponseCache) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if c.ttl <= 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Encode()
+
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		if ok && entry.resp != nil && !c.now().Before(entry.expires) {
+			delete(c.entries, key)
+			ok = false
+		}
+		if !ok {
+			entry = &cacheEntry{done: make(chan struct{})}
+			c.entries[key] = entry
+		}
+		c.mu.Unlock()
+
+		if !ok {
+			c.fill(key, entry, next, r)
+			entry.resp.replay(w, r, "MISS")
+			return
+		}
+
+		select {
+		case <-entry.done:
+		case <-r.Context().Done():
+			return
+		}
+		if entry.resp.status != http.StatusOK {
+			next(w, r)
+			return
+		}
+		entry.resp.replay(w, r, "HIT")
+	}
+}
+
+// fill calls next for an unconditional copy of r and stores the response
+// in entry, unless it failed or the cache was invalidated meanwhile. When
+// next panics, entry is still completed, as a 500 the waiters retry on
+// their own, and dropped before the panic goes on.
+func (c *ResponseCache) fill(key string, entry *cacheEntry, next http.HandlerFunc, r *http.Request) {
+	unconditional := r.Clone(r.Context())
+	unconditional.Header.Del("If-None-Match")
+	unconditional.Header.Del("If-Modified-Since")
+
+	resp := &bufferedResponse{header: http.Header{}}
+	completed := false
+	defer func() {
+		if !completed {
+			resp = &bufferedResponse{header: http.Header{}, status: http.StatusInternalServerError}
+		}
+		c.store(key, entry, resp)
+	}()
+
+	next(resp, unconditional)
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	completed = true
+}
+
+// store completes entry with resp, keeping it only if it is a 200, the
+// cache has room and it was not invalidated meanwhile
+func (c *ResponseCache) store(key string, entry *cacheEntry, resp *bufferedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.resp = resp
+	entry.expires = c.now().Add(c.ttl)
+	close(entry.done)
+
+	if c.entries[key] != entry {
+		return
+	}
+	if resp.status != http.StatusOK || !c.makeRoom() {
+		delete(c.entries, key)
+	}
+}
+
+// makeRoom evicts expired entries once the cache is full and reports
+// whether another entry fits. The caller holds c.mu.
+func (c *ResponseCache) makeRoom() bool {
+	if len(c.entries) <= maxCachedResponses {
+		return true
+	}
+	now := c.now()
+	for key, entry := range c.entries {
+		if entry.resp != nil && !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	return len(c.entries) <= maxCachedResponses
+}
+
+// Invalidate drops every cached response. Misses already being filled are
+// not stored.
+func (c *ResponseCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// bufferedResponse is an http.ResponseWriter holding the response in memory
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// replay writes the response to w, or an empty 304 when r's conditional
+// headers still match its validators
+func (b *bufferedResponse) replay(w http.ResponseWriter, r *http.Request, cache string) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("X-Cache", cache)
+
+	if b.status == http.StatusOK {
+		lastModified, _ := http.ParseTime(b.header.Get("Last-Modified"))
+		if notModified(r, b.header.Get("ETag"), lastModified) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(b.status)
+	_, _ = w.Write(b.body.Bytes())
+}
@@ -0,0 +1,241 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHandler answers with the number of times it has been called
+type countingHandler struct {
+	calls  atomic.Int32
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	n := h.calls.Add(1)
+	w.Header().Set("ETag", `"v1"`)
+	w.WriteHeader(h.status)
+	_, _ = w.Write([]byte{byte('0' + n)})
+}
+
+func serveGet(h http.HandlerFunc, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("should serve identical queries from the cache", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusOK}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next.ServeHTTP)
+
+		miss := serveGet(cached, "/categories?limit=20&attr.color=red", nil)
+		hit := serveGet(cached, "/categories?attr.color=red&limit=20", nil)
+
+		assert.Equal(t, "MISS", miss.Header().Get("X-Cache"))
+		assert.Equal(t, "HIT", hit.Header().Get("X-Cache"))
+		assert.Equal(t, http.StatusOK, hit.Code)
+		assert.Equal(t, "1", hit.Body.String())
+		assert.Equal(t, `"v1"`, hit.Header().Get("ETag"))
+		assert.EqualValues(t, 1, next.calls.Load())
+	})
+
+	t.Run("should miss for different query parameters", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusOK}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next.ServeHTTP)
+
+		serveGet(cached, "/categories?limit=20", nil)
+		rec := serveGet(cached, "/categories?limit=10", nil)
+
+		assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+		assert.EqualValues(t, 2, next.calls.Load())
+	})
+
+	t.Run("should miss once the ttl expires", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusOK}
+		current := now
+		cached := handlers.NewResponseCache(time.Minute, func() time.Time { return current }).Wrap(next.ServeHTTP)
+
+		serveGet(cached, "/categories", nil)
+		current = current.Add(59 * time.Second)
+		assert.Equal(t, "HIT", serveGet(cached, "/categories", nil).Header().Get("X-Cache"))
+		current = current.Add(time.Second)
+		rec := serveGet(cached, "/categories", nil)
+
+		assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+		assert.Equal(t, "2", rec.Body.String())
+	})
+
+	t.Run("should miss after invalidation", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusOK}
+		cache := handlers.NewResponseCache(time.Minute, clock)
+		cached := cache.Wrap(next.ServeHTTP)
+
+		serveGet(cached, "/categories", nil)
+		cache.Invalidate()
+		rec := serveGet(cached, "/categories", nil)
+
+		assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+		assert.EqualValues(t, 2, next.calls.Load())
+	})
+
+	t.Run("should not cache failed responses", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusInternalServerError}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next.ServeHTTP)
+
+		serveGet(cached, "/categories", nil)
+		rec := serveGet(cached, "/categories", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.EqualValues(t, 2, next.calls.Load())
+	})
+
+	t.Run("should answer conditional requests from the cache", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusOK}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next.ServeHTTP)
+
+		miss := serveGet(cached, "/categories", http.Header{"If-None-Match": {`"v1"`}})
+		hit := serveGet(cached, "/categories", http.Header{"If-None-Match": {`"v1"`}})
+		stale := serveGet(cached, "/categories", http.Header{"If-None-Match": {`"v0"`}})
+
+		assert.Equal(t, http.StatusNotModified, miss.Code)
+		assert.Equal(t, http.StatusNotModified, hit.Code)
+		assert.Empty(t, hit.Body.String())
+		assert.Equal(t, http.StatusOK, stale.Code)
+		assert.Equal(t, "1", stale.Body.String())
+		assert.EqualValues(t, 1, next.calls.Load())
+	})
+
+	t.Run("should coalesce concurrent misses", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls atomic.Int32
+		next := func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			<-release
+			_, _ = w.Write([]byte("page"))
+		}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next)
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 5)
+		for i := range bodies {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bodies[i] = serveGet(cached, "/categories", nil).Body.String()
+			}()
+		}
+		assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.EqualValues(t, 1, calls.Load())
+		assert.Equal(t, []string{"page", "page", "page", "page", "page"}, bodies)
+	})
+
+	t.Run("should serve the next request after the handler panicked", func(t *testing.T) {
+		var calls atomic.Int32
+		next := func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				panic("boom")
+			}
+			_, _ = w.Write([]byte("page"))
+		}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next)
+
+		assert.Panics(t, func() { serveGet(cached, "/categories", nil) })
+		rec := serveGet(cached, "/categories", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+		assert.Equal(t, "page", rec.Body.String())
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("should release waiters when the filling handler panics", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls atomic.Int32
+		next := func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				<-release
+				panic("boom")
+			}
+			_, _ = w.Write([]byte("page"))
+		}
+		cached := handlers.NewResponseCache(time.Minute, clock).Wrap(next)
+
+		panicked := make(chan any)
+		go func() {
+			defer func() { panicked <- recover() }()
+			serveGet(cached, "/categories", nil)
+		}()
+		assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+		waiter := make(chan *httptest.ResponseRecorder)
+		go func() { waiter <- serveGet(cached, "/categories", nil) }()
+		close(release)
+
+		assert.Equal(t, "boom", <-panicked)
+		select {
+		case rec := <-waiter:
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "page", rec.Body.String())
+		case <-time.After(time.Second):
+			t.Fatal("waiter still blocked on the panicked fill")
+		}
+	})
+
+	t.Run("should pass through with a zero ttl", func(t *testing.T) {
+		next := &countingHandler{status: http.StatusOK}
+		cached := handlers.NewResponseCache(0, clock).Wrap(next.ServeHTTP)
+
+		serveGet(cached, "/categories", nil)
+		rec := serveGet(cached, "/categories", nil)
+
+		assert.Empty(t, rec.Header().Get("X-Cache"))
+		assert.EqualValues(t, 2, next.calls.Load())
+	})
+}
+
+func TestCategoryHandlerListCategoriesCache(t *testing.T) {
+	router := handlers.NewRouter(handlers.Options{})
+	handlers.NewCategoryHandler(datalayer.NewMemoryCategoryRepo(), 0, time.Minute, mocks.NewLogger()).RegisterRoutes(router)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "MISS", do(http.MethodGet, "/categories?limit=20", "").Header().Get("X-Cache"))
+	assert.Equal(t, "HIT", do(http.MethodGet, "/categories?limit=20", "").Header().Get("X-Cache"))
+
+	created := do(http.MethodPost, "/categories", `{"name":"Books"}`)
+	require.Equal(t, http.StatusCreated, created.Code)
+	afterCreate := do(http.MethodGet, "/categories?limit=20", "")
+	assert.Equal(t, "MISS", afterCreate.Header().Get("X-Cache"))
+	assert.Contains(t, afterCreate.Body.String(), `"Books"`)
+
+	id := strings.Split(strings.Split(created.Body.String(), `"id":"`)[1], `"`)[0]
+	require.Equal(t, http.StatusOK, do(http.MethodPatch, "/categories/"+id, `{"name":"Comics"}`).Code)
+	afterUpdate := do(http.MethodGet, "/categories?limit=20", "")
+	assert.Equal(t, "MISS", afterUpdate.Header().Get("X-Cache"))
+	assert.Contains(t, afterUpdate.Body.String(), `"Comics"`)
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import "context"
+
+type adminKey struct{}
+
+// WithAdmin returns a copy of ctx marking the request as made by an admin
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// IsAdmin reports whether WithAdmin marked the request as made by an admin
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey{}).(bool)
+	return admin
+}
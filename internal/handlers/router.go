@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+// routeMethods are the methods probed when answering OPTIONS requests
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// MiddlewareFunc wraps a handler with additional behavior
+type MiddlewareFunc func(http.Handler) http.Handler
+
+type Router struct {
+	mux         *http.ServeMux
+	routes      []string
+	middlewares []MiddlewareFunc
+	policies    map[string]CachePolicy
+	timeouts    map[string]time.Duration
+	opts        Options
+	logger      LoggerInterface
+}
+
+// CachePolicy is how long clients may reuse a route's successful responses.
+// Shared caches never store them. The zero policy forbids storing them.
+type CachePolicy struct {
+	MaxAge time.Duration
+}
+
+// NoStore is the policy of routes without one and of every error response
+var NoStore = CachePolicy{}
+
+// Header returns the Cache-Control value for the policy
+func (p CachePolicy) Header() string {
+	seconds := int(p.MaxAge / time.Second)
+	if seconds <= 0 {
+		return "no-store"
+	}
+	return fmt.Sprintf("private, max-age=%d", seconds)
+}
+
+// NewRouter creates a router backed by the standard library ServeMux. Its
+// handlers and middlewares see opts through OptionsFromContext.
+func NewRouter(opts Options) *Router {
+	return &Router{mux: http.NewServeMux(), policies: map[string]CachePolicy{}, timeouts: map[string]time.Duration{}, opts: opts}
+}
+
+// SetLogger sets the logger of the responses the router writes itself, for
+// requests no route matches. Without one their write failures go unlogged.
+func (r *Router) SetLogger(logger LoggerInterface) {
+	r.logger = logger
+}
+
+// HandleFunc registers a handler for a "METHOD /path" pattern. The pattern
+// is the op handlers see through OpFromContext.
+func (r *Router) HandleFunc(pattern string, handler http.HandlerFunc) {
+	r.mux.HandleFunc(pattern, withRouteOp(pattern, handler))
+	r.routes = append(r.routes, pattern)
+}
+
+// Walk calls fn for every registered pattern in registration order
+func (r *Router) Walk(fn func(pattern string) error) error {
+	for _, pattern := range r.routes {
+		if err := fn(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCachePolicy attaches policy to the routes registered under patterns
+func (r *Router) SetCachePolicy(policy CachePolicy, patterns ...string) {
+	for _, pattern := range patterns {
+		r.policies[pattern] = policy
+	}
+}
+
+// CachePolicy returns the policy of the route req matches, or NoStore
+func (r *Router) CachePolicy(req *http.Request) CachePolicy {
+	_, pattern := r.mux.Handler(req)
+	return r.policies[pattern]
+}
+
+// SetTimeout gives the routes registered under patterns their own request
+// timeout. A server-wide maximum still caps it, see
+// middleware.RequestTimeout.
+func (r *Router) SetTimeout(timeout time.Duration, patterns ...string) {
+	for _, pattern := range patterns {
+		r.timeouts[pattern] = timeout
+	}
+}
+
+// Timeout returns the timeout of the route req matches, zero if it has none
+func (r *Router) Timeout(req *http.Request) time.Duration {
+	_, pattern := r.mux.Handler(req)
+	return r.timeouts[pattern]
+}
+
+// Use appends middlewares that run, in order, before route matching
+func (r *Router) Use(middlewares ...MiddlewareFunc) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var handler http.Handler = http.HandlerFunc(r.route)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+	handler.ServeHTTP(w, req.WithContext(WithOptions(req.Context(), r.opts)))
+}
+
+// route answers OPTIONS requests for registered paths with the allowed
+// methods, so CORS preflights don't get a 405, and dispatches everything else
+func (r *Router) route(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodOptions {
+		if allowed := r.allowedMethods(req); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(append(allowed, http.MethodOptions), ", "))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	if _, pattern := r.mux.Handler(req); pattern == "" {
+		r.unmatched(w, req)
+		return
+	}
+	r.mux.ServeHTTP(w, req)
+}
+
+// unmatched answers a request no route matches in the error envelope
+// instead of the mux's plain text: 405 with the Allow header when the path
+// has routes for other methods, 404 otherwise.
+func (r *Router) unmatched(w http.ResponseWriter, req *http.Request) {
+	allowed := r.allowedMethods(req)
+	if len(allowed) == 0 {
+		WriteCodeResponse(w, req, apierrors.ErrCodeRouteNotFound, r.logger)
+		return
+	}
+
+	methods := strings.Join(append(allowed, http.MethodOptions), ", ")
+	w.Header().Set("Allow", methods)
+	msg := fmt.Sprintf("method %s is not allowed, use one of: %s", req.Method, methods)
+	WriteErrorResponse(w, req, http.StatusMethodNotAllowed, apierrors.ErrCodeMethodNotAllowed, msg, r.logger)
+}
+
+// allowedMethods returns the methods with a route matching the request path
+func (r *Router) allowedMethods(req *http.Request) []string {
+	var allowed []string
+	for _, method := range routeMethods {
+		probe := *req
+		probe.Method = method
+		if _, pattern := r.mux.Handler(&probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// ListRoutes returns the patterns registered on the router
+func ListRoutes(router *Router) []string {
+	routes := []string{}
+	_ = router.Walk(func(pattern string) error {
+		routes = append(routes, pattern)
+		return nil
+	})
+	return routes
+}
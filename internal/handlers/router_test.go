@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRoutes(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+
+	t.Run("should return registered routes in order", func(t *testing.T) {
+		router := NewRouter(Options{})
+		router.HandleFunc("GET /categories", noop)
+		router.HandleFunc("GET /categories/{id}", noop)
+
+		routes := ListRoutes(router)
+		assert.Equal(t, []string{"GET /categories", "GET /categories/{id}"}, routes)
+	})
+
+	t.Run("should return empty list if no routes registered", func(t *testing.T) {
+		routes := ListRoutes(NewRouter(Options{}))
+		assert.Equal(t, []string{}, routes)
+	})
+}
+
+func TestCachePolicyHeader(t *testing.T) {
+	assert.Equal(t, "no-store", NoStore.Header())
+	assert.Equal(t, "no-store", CachePolicy{MaxAge: 500 * time.Millisecond}.Header())
+	assert.Equal(t, "private, max-age=30", CachePolicy{MaxAge: 30 * time.Second}.Header())
+}
+
+func TestRouterUse(t *testing.T) {
+	t.Run("should run middlewares in registration order", func(t *testing.T) {
+		var calls []string
+		record := func(name string) MiddlewareFunc {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+
+		router := NewRouter(Options{})
+		router.HandleFunc("GET /categories", func(http.ResponseWriter, *http.Request) {
+			calls = append(calls, "handler")
+		})
+		router.Use(record("first"), record("second"))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+		assert.Equal(t, []string{"first", "second", "handler"}, calls)
+	})
+}
+
+func TestRouterOptions(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+	router := NewRouter(Options{})
+	router.HandleFunc("GET /categories", noop)
+	router.HandleFunc("POST /categories", noop)
+	router.HandleFunc("DELETE /categories/{id}", noop)
+
+	t.Run("should return allowed methods for registered path", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/categories", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "GET, HEAD, POST, OPTIONS", rec.Header().Get("Allow"))
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should match wildcard paths", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/categories/123", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "DELETE, OPTIONS", rec.Header().Get("Allow"))
+	})
+
+	t.Run("should return 404 for unknown path", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRouterUnmatched(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+	router := NewRouter(Options{})
+	router.HandleFunc("GET /categories/{id}", noop)
+	router.HandleFunc("PATCH /categories/{id}", noop)
+	router.HandleFunc("DELETE /categories/{id}", noop)
+
+	t.Run("should return 405 with the allowed methods for a wrong method", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/categories/123", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD, PATCH, DELETE, OPTIONS", rec.Header().Get("Allow"))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, apierrors.ErrCodeMethodNotAllowed, envelopeCode(t, rec))
+		testutil.AssertGolden(t, "router_method_not_allowed", rec.Body.Bytes())
+	})
+
+	t.Run("should return the 404 envelope for an unknown path", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Allow"))
+		assert.Equal(t, apierrors.ErrCodeRouteNotFound, envelopeCode(t, rec))
+		testutil.AssertGolden(t, "router_not_found", rec.Body.Bytes())
+	})
+
+	t.Run("should dispatch matched routes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/categories/123", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+}
+
+// failingWriter is a ResponseWriter whose body writes fail, as when the
+// client hung up
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestRouterUnmatchedWriteFailure(t *testing.T) {
+	t.Run("should not panic without a logger", func(t *testing.T) {
+		router := NewRouter(Options{})
+		w := failingWriter{httptest.NewRecorder()}
+
+		assert.NotPanics(t, func() {
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should log the failure to the router's logger", func(t *testing.T) {
+		logger := &opLogger{}
+		router := NewRouter(Options{})
+		router.SetLogger(logger)
+		router.HandleFunc("GET /categories", func(http.ResponseWriter, *http.Request) {})
+
+		router.ServeHTTP(failingWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodPost, "/categories", nil))
+
+		assert.Len(t, logger.ops, 1)
+	})
+}
+
+// envelopeCode returns the code of the error envelope in rec's body
+func envelopeCode(t *testing.T, rec *httptest.ResponseRecorder) int {
+	t.Helper()
+
+	var body ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Error.Code
+}
+
+// opLogger records the op of every logged error
+type opLogger struct {
+	ops []string
+}
+
+func (l *opLogger) LogInfo(string, string) {}
+
+func (l *opLogger) LogError(op string, _ error) {
+	l.ops = append(l.ops, op)
+}
+
+func (l *opLogger) LogRequest(RequestLogEntry) {}
+
+func TestRouterOp(t *testing.T) {
+	t.Run("should log errors under the matched route", func(t *testing.T) {
+		logger := &opLogger{}
+		router := NewRouter(Options{})
+		router.HandleFunc("GET /categories/{id}", func(w http.ResponseWriter, r *http.Request) {
+			writeRepoError(w, r, errors.New("boom"), logger)
+		})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/categories/1", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, []string{"GET /categories/{id}"}, logger.ops)
+	})
+
+	t.Run("should fall back to unknown outside a route", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Equal(t, "unknown", OpFromContext(req.Context()))
+		assert.Equal(t, "custom", OpFromContext(WithOp(req.Context(), "custom")))
+	})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime"
+	"time"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+)
+
+// DBStatser reports connection pool statistics, as *sql.DB does
+type DBStatser interface {
+	Stats() sql.DBStats
+}
+
+type StatsHandler struct {
+	db      DBStatser
+	logger  LoggerInterface
+	started time.Time
+	now     func() time.Time
+}
+
+// Stats is the body of GET /stats. DB is omitted when the API runs
+// without a database.
+type Stats struct {
+	UptimeSeconds float64  `json:"uptimeSeconds"`
+	Goroutines    int      `json:"goroutines"`
+	DB            *DBStats `json:"db,omitempty"`
+}
+
+// DBStats mirrors sql.DBStats with durations in seconds
+type DBStats struct {
+	MaxOpenConnections  int     `json:"maxOpenConnections"`
+	OpenConnections     int     `json:"openConnections"`
+	InUse               int     `json:"inUse"`
+	Idle                int     `json:"idle"`
+	WaitCount           int64   `json:"waitCount"`
+	WaitDurationSeconds float64 `json:"waitDurationSeconds"`
+	MaxIdleClosed       int64   `json:"maxIdleClosed"`
+	MaxIdleTimeClosed   int64   `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed   int64   `json:"maxLifetimeClosed"`
+}
+
+// NewStatsHandler creates a new stats handler instance. Uptime is counted
+// from its creation. db may be nil when there is no database.
+func NewStatsHandler(db DBStatser, logger LoggerInterface) *StatsHandler {
+	return &StatsHandler{db: db, logger: logger, started: time.Now(), now: time.Now}
+}
+
+// RegisterRoutes registers the stats endpoint on the router
+func (h *StatsHandler) RegisterRoutes(router *Router) {
+	router.HandleFunc("GET /stats", h.GetStats)
+}
+
+// GetStats returns runtime and connection pool statistics. It is only
+// available to admins.
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if !IsAdmin(r.Context()) {
+		WriteErrorResponse(w, r, http.StatusForbidden, apierrors.ErrCodeForbidden, "admin role required to view stats", h.logger)
+		return
+	}
+
+	stats := Stats{
+		UptimeSeconds: h.now().Sub(h.started).Seconds(),
+		Goroutines:    runtime.NumGoroutine(),
+	}
+	if h.db != nil {
+		db := h.db.Stats()
+		stats.DB = &DBStats{
+			MaxOpenConnections:  db.MaxOpenConnections,
+			OpenConnections:     db.OpenConnections,
+			InUse:               db.InUse,
+			Idle:                db.Idle,
+			WaitCount:           db.WaitCount,
+			WaitDurationSeconds: db.WaitDuration.Seconds(),
+			MaxIdleClosed:       db.MaxIdleClosed,
+			MaxIdleTimeClosed:   db.MaxIdleTimeClosed,
+			MaxLifetimeClosed:   db.MaxLifetimeClosed,
+		}
+	}
+	WriteSuccessResponse(w, r, http.StatusOK, "stats retrieved", stats, h.logger)
+}
@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDB struct {
+	stats sql.DBStats
+}
+
+func (f fakeDB) Stats() sql.DBStats {
+	return f.stats
+}
+
+// serveAdmin is serve for requests made with the admin role
+func serveAdmin(h *handlers.StatsHandler, opts handlers.Options, target string) *httptest.ResponseRecorder {
+	router := handlers.NewRouter(opts)
+	h.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req.WithContext(handlers.WithAdmin(req.Context())))
+	return rec
+}
+
+func TestStatsHandlerGetStats(t *testing.T) {
+	db := fakeDB{stats: sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2}}
+
+	t.Run("should return pool stats, goroutines and uptime", func(t *testing.T) {
+		rec := serveAdmin(handlers.NewStatsHandler(db, mocks.NewLogger()), handlers.Options{}, "/stats")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"openConnections":3`)
+		assert.Contains(t, rec.Body.String(), `"uptimeSeconds":`)
+		assert.Contains(t, rec.Body.String(), `"goroutines":`)
+	})
+
+	t.Run("should use snake case keys with the snake naming strategy", func(t *testing.T) {
+		rec := serveAdmin(handlers.NewStatsHandler(db, mocks.NewLogger()), handlers.Options{Naming: handlers.NamingSnakeCase}, "/stats")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"open_connections":3`)
+		assert.Contains(t, rec.Body.String(), `"uptime_seconds":`)
+	})
+
+	t.Run("should omit db stats without a database", func(t *testing.T) {
+		rec := serveAdmin(handlers.NewStatsHandler(nil, mocks.NewLogger()), handlers.Options{}, "/stats")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"db"`)
+	})
+
+	t.Run("should return 403 without the admin role", func(t *testing.T) {
+		rec := serve(handlers.NewStatsHandler(db, mocks.NewLogger()), http.MethodGet, "/stats", nil)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "admin role required to view stats")
+	})
+}
@@ -0,0 +1,13 @@
+package handlers
+
+import "fmt"
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// contentTypeEventStream is the media type of server-sent events
+const contentTypeEventStream = "text/event-stream"
+
+// RequireJSONAccept rejects requests whose Accept header rules out
+// application/json with 406 Not Acceptable. The body is plain text since the
+// client said it cannot take JSON. A missing or empty Accept header accepts
+// anything. The streamPaths answer with server-sent events, so their
+// requests may ask for text/event-stream instead.
+func RequireJSONAccept(streamPaths ...string) handlers.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := strings.Join(r.Header.Values("Accept"), ",")
+			acceptable := acceptsJSON(accept) ||
+				slices.Contains(streamPaths, r.URL.Path) && acceptsMediaType(accept, contentTypeEventStream)
+			if strings.TrimSpace(accept) != "" && !acceptable {
+				http.Error(w, "Not Acceptable: responses are "+contentTypeJSON, http.StatusNotAcceptable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acceptsJSON reports whether an Accept header lists application/json,
+// application/* or */* with a non-zero quality
+func acceptsJSON(accept string) bool {
+	return acceptsMediaType(accept, contentTypeJSON)
+}
+
+// acceptsMediaType reports whether an Accept header lists mediaType, its
+// type/* or */* with a non-zero quality
+func acceptsMediaType(accept, mediaType string) bool {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	for _, mediaRange := range strings.Split(accept, ",") {
+		listed, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if listed != mediaType && listed != typ+"/*" && listed != "*/*" {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if quality, err := strconv.ParseFloat(q, 64); err != nil || quality <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
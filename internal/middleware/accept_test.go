@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireJSONAccept(t *testing.T) {
+	handler := RequireJSONAccept()(okHandler)
+
+	tests := []struct {
+		name       string
+		accept     []string
+		wantStatus int
+	}{
+		{"missing accept passes", nil, http.StatusOK},
+		{"empty accept passes", []string{""}, http.StatusOK},
+		{"wildcard passes", []string{"*/*"}, http.StatusOK},
+		{"exact match passes", []string{"application/json"}, http.StatusOK},
+		{"application wildcard passes", []string{"application/*"}, http.StatusOK},
+		{"json among other types passes", []string{"text/html, application/json;q=0.9"}, http.StatusOK},
+		{"json in a later header passes", []string{"text/html", "application/json"}, http.StatusOK},
+		{"unsupported type is rejected", []string{"text/xml"}, http.StatusNotAcceptable},
+		{"json with zero quality is rejected", []string{"application/json;q=0, text/xml"}, http.StatusNotAcceptable},
+		{"malformed accept is rejected", []string{"json"}, http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+			for _, accept := range tt.accept {
+				req.Header.Add("Accept", accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusNotAcceptable {
+				assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+				assert.Equal(t, "Not Acceptable: responses are application/json\n", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireJSONAcceptStreamPaths(t *testing.T) {
+	handler := RequireJSONAccept("/products/stream")(okHandler)
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+	}{
+		{"event stream on a stream path passes", "/products/stream", "text/event-stream", http.StatusOK},
+		{"text wildcard on a stream path passes", "/products/stream", "text/*", http.StatusOK},
+		{"json on a stream path passes", "/products/stream", "application/json", http.StatusOK},
+		{"other types on a stream path are rejected", "/products/stream", "text/html", http.StatusNotAcceptable},
+		{"event stream elsewhere is rejected", "/products", "text/event-stream", http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
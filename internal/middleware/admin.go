@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// IdentifyAdmin marks requests carrying "Authorization: Bearer <token>" as
+// made by an admin. Other requests pass through unmarked. An empty token
+// disables admin access entirely.
+func IdentifyAdmin(token string) handlers.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" {
+				bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if ok && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+					r = r.WithContext(handlers.WithAdmin(r.Context()))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
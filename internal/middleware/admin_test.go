@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifyAdmin(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantAdmin     bool
+	}{
+		{"matching bearer token is admin", "secret", "Bearer secret", true},
+		{"wrong token is not admin", "secret", "Bearer guess", false},
+		{"missing header is not admin", "secret", "", false},
+		{"token without bearer scheme is not admin", "secret", "secret", false},
+		{"empty configured token disables admin", "", "Bearer ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAdmin bool
+			handler := IdentifyAdmin(tt.token)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotAdmin = handlers.IsAdmin(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/products?status=draft", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantAdmin, gotAdmin)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// CacheControl sets the Cache-Control header from the policy the router
+// attaches to the matched route. Only successful and 304 responses get it;
+// errors, including those of cacheable routes, are always no-store.
+func CacheControl(router *handlers.Router) handlers.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, policy: router.CachePolicy(r)}, r)
+		})
+	}
+}
+
+// cacheControlWriter sets Cache-Control once the status code is known
+type cacheControlWriter struct {
+	http.ResponseWriter
+	policy      handlers.CachePolicy
+	wroteHeader bool
+}
+
+func (c *cacheControlWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		policy := c.policy
+		if status >= http.StatusMultipleChoices && status != http.StatusNotModified {
+			policy = handlers.NoStore
+		}
+		c.Header().Set("Cache-Control", policy.Header())
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *cacheControlWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (c *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
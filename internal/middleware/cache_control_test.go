@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	status := func(code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(code) }
+	}
+	router := handlers.NewRouter(handlers.Options{})
+	router.HandleFunc("GET /categories", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("[]")) })
+	router.HandleFunc("GET /categories/{id}", status(http.StatusNotFound))
+	router.HandleFunc("GET /products", status(http.StatusNotModified))
+	router.HandleFunc("POST /categories", status(http.StatusCreated))
+	router.HandleFunc("GET /stats", status(http.StatusOK))
+	router.HandleFunc("GET /ready", status(http.StatusOK))
+	router.SetCachePolicy(handlers.CachePolicy{MaxAge: 30 * time.Second}, "GET /categories", "GET /categories/{id}", "GET /products")
+	router.Use(CacheControl(router))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   string
+	}{
+		{"list", http.MethodGet, "/categories", "private, max-age=30"},
+		{"head of a list", http.MethodHead, "/categories", "private, max-age=30"},
+		{"not modified", http.MethodGet, "/products", "private, max-age=30"},
+		{"error on a cacheable route", http.MethodGet, "/categories/abc", "no-store"},
+		{"mutation", http.MethodPost, "/categories", "no-store"},
+		{"route without a policy", http.MethodGet, "/ready", "no-store"},
+		{"unknown route", http.MethodGet, "/nowhere", "no-store"},
+		{"method not allowed", http.MethodDelete, "/stats", "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.want, rec.Header().Get("Cache-Control"))
+		})
+	}
+}
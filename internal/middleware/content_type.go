@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+const contentTypeJSON = "application/json"
+
+// RequireJSONContent rejects POST, PUT and PATCH requests whose body is not
+// declared as application/json with 415 Unsupported Media Type. Requests
+// without a body, such as action endpoints, are let through.
+func RequireJSONContent(logger handlers.LoggerInterface) handlers.MiddlewareFunc {
+	const op = "middleware.RequireJSONContent"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+				if r.ContentLength == 0 {
+					break
+				}
+				mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				if err != nil || mediaType != contentTypeJSON {
+					r = r.WithContext(handlers.WithOp(r.Context(), op))
+					handlers.WriteCodeResponse(w, r, apierrors.ErrCodeUnsupportedMedia, logger)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
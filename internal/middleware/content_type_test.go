@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestRequireJSONContent(t *testing.T) {
+	handler := RequireJSONContent(mocks.NewLogger())(okHandler)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"GET without content type passes", http.MethodGet, "", `{}`, http.StatusOK},
+		{"POST with json passes", http.MethodPost, "application/json", `{}`, http.StatusOK},
+		{"PUT with json and charset passes", http.MethodPut, "application/json; charset=utf-8", `{}`, http.StatusOK},
+		{"POST with text/plain is rejected", http.MethodPost, "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"PATCH without content type is rejected", http.MethodPatch, "", `{}`, http.StatusUnsupportedMediaType},
+		{"DELETE without content type passes", http.MethodDelete, "", `{}`, http.StatusOK},
+		{"POST without body or content type passes", http.MethodPost, "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/categories", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusUnsupportedMediaType {
+				assert.JSONEq(t, `{"status":"error","error":{"code":1004,"message":"Content-Type must be application/json"}}`, rec.Body.String())
+			}
+		})
+	}
+}
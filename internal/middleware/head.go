@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// DiscardHeadBody answers HEAD requests with the status and headers the
+// matching GET sends, without its body. The mux already routes HEAD to GET
+// patterns; this drops whatever the handler writes and sets Content-Length
+// to its size, which the server leaves out for bodies it does not buffer.
+//
+// The handlers of streamPaths never finish a GET on their own, so a HEAD of
+// one has its context canceled once the handler starts responding: the
+// headers are all it sends.
+func DiscardHeadBody(streamPaths ...string) handlers.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			head := &headWriter{ResponseWriter: w}
+			if slices.Contains(streamPaths, r.URL.Path) {
+				ctx, cancel := context.WithCancel(r.Context())
+				defer cancel()
+				head.started = cancel
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(head, r)
+			head.finish()
+		})
+	}
+}
+
+// headWriter holds back the status until the handler returns so the
+// length of the discarded body can still be sent
+type headWriter struct {
+	http.ResponseWriter
+	status int
+	length int
+	// started, when set, is called once the handler responds
+	started func()
+}
+
+func (h *headWriter) WriteHeader(status int) {
+	if h.status == 0 {
+		h.status = status
+	}
+	h.start()
+}
+
+func (h *headWriter) Write(b []byte) (int, error) {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	h.length += len(b)
+	h.start()
+	return len(b), nil
+}
+
+// FlushError keeps http.ResponseController from flushing the underlying
+// writer, which would send the headers before finish could complete them
+func (h *headWriter) FlushError() error {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	h.start()
+	return nil
+}
+
+func (h *headWriter) start() {
+	if h.started != nil {
+		h.started()
+	}
+}
+
+// finish sends the status, with the Content-Length of the discarded body
+// unless the handler set one
+func (h *headWriter) finish() {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	if h.length > 0 && h.Header().Get("Content-Length") == "" {
+		h.Header().Set("Content-Length", strconv.Itoa(h.length))
+	}
+	h.ResponseWriter.WriteHeader(h.status)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (h *headWriter) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
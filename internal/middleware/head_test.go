@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscardHeadBody(t *testing.T) {
+	body := strings.Repeat("x", 10000)
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(body[:4000]))
+		_, _ = w.Write([]byte(body[4000:]))
+	})
+	handler := DiscardHeadBody()(next)
+
+	t.Run("should send headers and status without the body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/categories", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, `"v1"`, rec.Header().Get("ETag"))
+		assert.Equal(t, "10000", rec.Header().Get("Content-Length"))
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should keep a Content-Length set by the handler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		DiscardHeadBody()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Length", "2")
+			_, _ = w.Write([]byte("[]"))
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/categories", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Content-Length"))
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("should send an empty 200 when nothing is written", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		DiscardHeadBody()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/ready", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Length"))
+	})
+
+	t.Run("should pass other methods through", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("should end a HEAD of a stream path with its headers", func(t *testing.T) {
+		stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			controller := http.NewResponseController(w)
+			for {
+				if err := controller.Flush(); err != nil {
+					return
+				}
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(time.Millisecond):
+					_, _ = w.Write([]byte("data: {}\n\n"))
+				}
+			}
+		})
+		rec := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			DiscardHeadBody("/products/stream")(stream).ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/products/stream", nil))
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("HEAD of the stream did not complete")
+		}
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+		assert.Empty(t, rec.Body.String())
+		assert.False(t, rec.Flushed)
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// busyRetryAfter is the Retry-After, in seconds, sent with 503 responses
+const busyRetryAfter = 1
+
+// MaxInFlight serves at most n requests at a time. Requests arriving while
+// n are in flight are rejected with 503 Service Unavailable and a
+// Retry-After header rather than queued. A slot is released when its
+// handler returns or panics. n <= 0 disables the limit. exemptPaths, such
+// as event streams that stay open, neither take a slot nor are turned away.
+func MaxInFlight(n int, logger handlers.LoggerInterface, exemptPaths ...string) handlers.MiddlewareFunc {
+	const op = "middleware.MaxInFlight"
+
+	if n <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	slots := make(chan struct{}, n)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(exemptPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			select {
+			case slots <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", strconv.Itoa(busyRetryAfter))
+				r = r.WithContext(handlers.WithOp(r.Context(), op))
+				handlers.WriteCodeResponse(w, r, apierrors.ErrCodeServerBusy, logger)
+				return
+			}
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	const limit = 3
+
+	t.Run("should reject requests beyond the limit with 503", func(t *testing.T) {
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		blocking := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			entered <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		})
+		handler := MaxInFlight(limit, mocks.NewLogger())(blocking)
+
+		const total = limit + 5
+		codes := make([]int, total)
+		var wg sync.WaitGroup
+		for i := range limit {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+				codes[i] = rec.Code
+			}()
+		}
+		for range limit {
+			<-entered
+		}
+
+		// every slot is taken, so the rest are turned away concurrently
+		var rejected atomic.Int32
+		var busy sync.WaitGroup
+		for i := limit; i < total; i++ {
+			busy.Add(1)
+			go func() {
+				defer busy.Done()
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+				codes[i] = rec.Code
+				if rec.Code == http.StatusServiceUnavailable {
+					rejected.Add(1)
+					assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+					assert.JSONEq(t, `{"status":"error","error":{"code":1601,"message":"server is busy, retry later"}}`, rec.Body.String())
+				}
+			}()
+		}
+		busy.Wait()
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(total-limit), rejected.Load())
+		for i := range limit {
+			assert.Equal(t, http.StatusOK, codes[i])
+		}
+	})
+
+	t.Run("should release the slot when the handler panics", func(t *testing.T) {
+		panicking := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		})
+		handler := MaxInFlight(1, mocks.NewLogger())(panicking)
+
+		for range 2 {
+			assert.PanicsWithValue(t, "boom", func() {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products", nil))
+			}, "a leaked slot would turn the second request into a 503")
+		}
+	})
+
+	t.Run("should not take a slot for exempt paths", func(t *testing.T) {
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		streaming := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/products/stream" {
+				entered <- struct{}{}
+				<-release
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		handler := MaxInFlight(1, mocks.NewLogger(), "/products/stream")(streaming)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/stream", nil))
+		}()
+		<-entered
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "the open stream must leave the only slot free")
+
+		close(release)
+		<-done
+	})
+
+	t.Run("should not limit when n is 0", func(t *testing.T) {
+		handler := MaxInFlight(0, mocks.NewLogger())(okHandler)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/google/uuid"
+)
+
+// requestIDHeader carries the request ID. A client-supplied ID is kept so
+// it can be traced across services; otherwise one is generated.
+const requestIDHeader = "X-Request-ID"
+
+// statusRecorder captures the status code written by the wrapped handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// LogRequests writes an access log entry for every request once it has been
+// served. The request ID is echoed in the X-Request-ID response header.
+func LogRequests(logger handlers.LoggerInterface) handlers.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(requestIDHeader)
+			if requestID == "" {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(requestIDHeader, requestID)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			logger.LogRequest(handlers.RequestLogEntry{
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				StatusCode: rec.status,
+				Duration:   time.Since(start),
+				RequestID:  requestID,
+				RemoteAddr: r.RemoteAddr,
+				UserAgent:  r.UserAgent(),
+			})
+		})
+	}
+}
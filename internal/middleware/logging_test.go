@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRequests(t *testing.T) {
+	t.Run("should log the request and the status written", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		notFound := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		req := httptest.NewRequest(http.MethodDelete, "/products/42?force=true", nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		req.Header.Set("User-Agent", "curl/8.5.0")
+		req.Header.Set("X-Request-ID", "req-1")
+		rec := httptest.NewRecorder()
+		LogRequests(logger)(notFound).ServeHTTP(rec, req)
+
+		require.Len(t, logger.LogRequestCalls(), 1)
+		entry := logger.LogRequestCalls()[0].Req
+		assert.Equal(t, http.MethodDelete, entry.Method)
+		assert.Equal(t, "/products/42", entry.Path)
+		assert.Equal(t, http.StatusNotFound, entry.StatusCode)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.Equal(t, "203.0.113.7:51234", entry.RemoteAddr)
+		assert.Equal(t, "curl/8.5.0", entry.UserAgent)
+		assert.GreaterOrEqual(t, entry.Duration, time.Duration(0))
+		assert.Equal(t, "req-1", rec.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("should default to 200 and generate a request ID", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		body := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+
+		rec := httptest.NewRecorder()
+		LogRequests(logger)(body).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+		require.Len(t, logger.LogRequestCalls(), 1)
+		assert.Equal(t, http.StatusOK, logger.LogRequestCalls()[0].Req.StatusCode)
+		_, err := uuid.Parse(logger.LogRequestCalls()[0].Req.RequestID)
+		assert.NoError(t, err)
+		assert.Equal(t, logger.LogRequestCalls()[0].Req.RequestID, rec.Header().Get("X-Request-ID"))
+	})
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// maintenanceRetryAfter is the Retry-After, in seconds, sent with writes
+// refused during maintenance
+const maintenanceRetryAfter = 60
+
+// RejectWritesInMaintenance refuses POST, PUT, PATCH and DELETE requests
+// with 503 Service Unavailable and a Retry-After header while mode is on.
+// Reads are always served. exemptPaths stay writable so maintenance mode
+// can be turned off again.
+func RejectWritesInMaintenance(mode *handlers.MaintenanceMode, logger handlers.LoggerInterface, exemptPaths ...string) handlers.MiddlewareFunc {
+	const op = "middleware.RejectWritesInMaintenance"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+				if mode.Enabled() && !slices.Contains(exemptPaths, r.URL.Path) {
+					w.Header().Set("Retry-After", strconv.Itoa(maintenanceRetryAfter))
+					r = r.WithContext(handlers.WithOp(r.Context(), op))
+					handlers.WriteCodeResponse(w, r, apierrors.ErrCodeMaintenance, logger)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectWritesInMaintenance(t *testing.T) {
+	mode := &handlers.MaintenanceMode{}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RejectWritesInMaintenance(mode, mocks.NewLogger(), "/maintenance")(ok)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+	writes := []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	reads := []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+	for _, method := range append(writes, reads...) {
+		assert.Equal(t, http.StatusOK, serve(method, "/products").Code, "%s before maintenance", method)
+	}
+
+	mode.Set(true)
+	for _, method := range writes {
+		rec := serve(method, "/products")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, method)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"status":"error","error":{"code":1603,"message":"the API is in maintenance mode and only serves reads, retry later"}}`, rec.Body.String())
+	}
+	for _, method := range reads {
+		assert.Equal(t, http.StatusOK, serve(method, "/products").Code, "%s during maintenance", method)
+	}
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/maintenance").Code, "exempt paths stay writable")
+
+	mode.Set(false)
+	for _, method := range writes {
+		assert.Equal(t, http.StatusOK, serve(method, "/products").Code, "%s after maintenance", method)
+	}
+}
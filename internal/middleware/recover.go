@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	apierrors "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/errors"
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// Recover turns a panic in the wrapped handler into a 500 error envelope
+// instead of letting net/http drop the connection. The panic is logged with
+// its stack: an error value is wrapped so errors.Is still sees it, a string
+// is logged as the message and anything else with its type. If the handler
+// had already started its response only the log entry is written.
+// http.ErrAbortHandler is re-panicked so net/http can abort quietly.
+func Recover(logger handlers.LoggerInterface) handlers.MiddlewareFunc {
+	const op = "middleware.Recover"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				logger.LogError(op, panicError(v, debug.Stack()))
+				if rec.status != 0 {
+					return
+				}
+				r = r.WithContext(handlers.WithOp(r.Context(), op))
+				handlers.WriteCodeResponse(rec, r, apierrors.ErrCodeInternalServerError, logger)
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// panicError describes a recovered panic value, keeping an error value in
+// the chain
+func panicError(v any, stack []byte) error {
+	var err error
+	switch v := v.(type) {
+	case error:
+		err = fmt.Errorf("panic: %w", v)
+	case string:
+		err = errors.New("panic: " + v)
+	default:
+		err = fmt.Errorf("panic: %T: %v", v, v)
+	}
+	return fmt.Errorf("%w\n%s", err, stack)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name  string
+		value any
+		log   string
+	}{
+		{"error", errBoom, "panic: boom\n"},
+		{"string", "out of range", "panic: out of range\n"},
+		{"other", 42, "panic: int: 42\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should answer a panic with an "+tt.name+" value with 500", func(t *testing.T) {
+			logger := mocks.NewLogger()
+			panicking := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				panic(tt.value)
+			})
+
+			rec := httptest.NewRecorder()
+			Recover(logger)(panicking).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.JSONEq(t, `{"status":"error","error":{"code":1600,"message":"internal server error"}}`, rec.Body.String())
+			require.NotEmpty(t, logger.LogErrorCalls())
+			entry := logger.LogErrorCalls()[0]
+			assert.Equal(t, "middleware.Recover", entry.Op)
+			assert.True(t, strings.HasPrefix(entry.Err.Error(), tt.log), entry.Err.Error())
+			assert.Contains(t, entry.Err.Error(), "recover_test.go", "the stack should be logged")
+			assert.Equal(t, tt.name == "error", errors.Is(entry.Err, errBoom))
+		})
+	}
+
+	t.Run("should only log if the response was started", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		partial := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("partial"))
+			panic("halfway")
+		})
+
+		rec := httptest.NewRecorder()
+		Recover(logger)(partial).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "partial", rec.Body.String())
+		require.Len(t, logger.LogErrorCalls(), 1)
+		assert.True(t, strings.HasPrefix(logger.LogErrorCalls()[0].Err.Error(), "panic: halfway"))
+	})
+
+	t.Run("should re-panic http.ErrAbortHandler", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		aborting := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		})
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			Recover(logger)(aborting).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products", nil))
+		})
+		assert.Empty(t, logger.LogErrorCalls())
+	})
+
+	t.Run("should pass through responses that do not panic", func(t *testing.T) {
+		logger := mocks.NewLogger()
+		ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		rec := httptest.NewRecorder()
+		Recover(logger)(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, logger.LogErrorCalls())
+	})
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+)
+
+// RequestTimeout bounds each request's context by the shorter of
+// maxTimeout and the timeout the router gives the matched route, and
+// reports it in milliseconds in the X-Request-Timeout-Ms header. A zero
+// maxTimeout leaves route timeouts uncapped. exemptPaths, such as event
+// streams that stay open, get no timeout.
+func RequestTimeout(router *handlers.Router, maxTimeout time.Duration, exemptPaths ...string) handlers.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(exemptPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timeout := effectiveTimeout(maxTimeout, router.Timeout(r))
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			w.Header().Set("X-Request-Timeout-Ms", strconv.FormatInt(timeout.Milliseconds(), 10))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// effectiveTimeout is the shorter of the two timeouts, where zero means
+// none
+func effectiveTimeout(maxTimeout, routeTimeout time.Duration) time.Duration {
+	switch {
+	case maxTimeout <= 0:
+		return routeTimeout
+	case routeTimeout <= 0:
+		return maxTimeout
+	}
+	return min(maxTimeout, routeTimeout)
+}
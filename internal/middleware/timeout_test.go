@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	newRouter := func(maxTimeout time.Duration) (*handlers.Router, *time.Duration) {
+		var remaining time.Duration
+		deadline := func(w http.ResponseWriter, r *http.Request) {
+			remaining = 0
+			if d, ok := r.Context().Deadline(); ok {
+				remaining = time.Until(d)
+			}
+		}
+		router := handlers.NewRouter(handlers.Options{})
+		router.HandleFunc("GET /products", deadline)
+		router.HandleFunc("POST /products/batch", deadline)
+		router.HandleFunc("GET /stats", deadline)
+		router.HandleFunc("GET /products/stream", deadline)
+		router.SetTimeout(time.Minute, "POST /products/batch")
+		router.SetTimeout(500*time.Millisecond, "GET /stats")
+		router.Use(RequestTimeout(router, maxTimeout, "/products/stream"))
+		return router, &remaining
+	}
+
+	tests := []struct {
+		name       string
+		maxTimeout time.Duration
+		method     string
+		target     string
+		want       time.Duration
+	}{
+		{"should cap a route timeout above the maximum", 2 * time.Second, http.MethodPost, "/products/batch", 2 * time.Second},
+		{"should keep a route timeout below the maximum", 2 * time.Second, http.MethodGet, "/stats", 500 * time.Millisecond},
+		{"should give a route without a timeout the maximum", 2 * time.Second, http.MethodGet, "/products", 2 * time.Second},
+		{"should keep the route timeout without a maximum", 0, http.MethodPost, "/products/batch", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, remaining := newRouter(tt.maxTimeout)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, strconv.FormatInt(tt.want.Milliseconds(), 10), rec.Header().Get("X-Request-Timeout-Ms"))
+			assert.InDelta(t, tt.want, *remaining, float64(time.Second/10))
+		})
+	}
+
+	t.Run("should leave exempt paths and requests without any timeout alone", func(t *testing.T) {
+		for _, c := range []struct {
+			maxTimeout time.Duration
+			target     string
+		}{{2 * time.Second, "/products/stream"}, {0, "/products"}} {
+			router, remaining := newRouter(c.maxTimeout)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.target, nil))
+
+			assert.Empty(t, rec.Header().Get("X-Request-Timeout-Ms"), c.target)
+			assert.Zero(t, *remaining, c.target)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+var _ datalayer.AttributeDefinitionRepoInterface = (*MockAttributeDefinitionRepo)(nil)
+
+// MockAttributeDefinitionRepo delegates each method to the matching func
+// field
+type MockAttributeDefinitionRepo struct {
+	CreateAttributeDefinitionFunc func(ctx context.Context, definition *datalayer.AttributeDefinition) error
+	ListAttributeDefinitionsFunc  func(ctx context.Context, categoryID uuid.UUID) ([]*datalayer.AttributeDefinition, error)
+	GetAttributeDefinitionFunc    func(ctx context.Context, id uuid.UUID) (*datalayer.AttributeDefinition, error)
+	UpdateAttributeDefinitionFunc func(ctx context.Context, definition *datalayer.AttributeDefinition) error
+	DeleteAttributeDefinitionFunc func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *MockAttributeDefinitionRepo) CreateAttributeDefinition(ctx context.Context, definition *datalayer.AttributeDefinition) error {
+	return m.CreateAttributeDefinitionFunc(ctx, definition)
+}
+
+func (m *MockAttributeDefinitionRepo) ListAttributeDefinitions(ctx context.Context, categoryID uuid.UUID) ([]*datalayer.AttributeDefinition, error) {
+	return m.ListAttributeDefinitionsFunc(ctx, categoryID)
+}
+
+func (m *MockAttributeDefinitionRepo) GetAttributeDefinition(ctx context.Context, id uuid.UUID) (*datalayer.AttributeDefinition, error) {
+	return m.GetAttributeDefinitionFunc(ctx, id)
+}
+
+func (m *MockAttributeDefinitionRepo) UpdateAttributeDefinition(ctx context.Context, definition *datalayer.AttributeDefinition) error {
+	return m.UpdateAttributeDefinitionFunc(ctx, definition)
+}
+
+func (m *MockAttributeDefinitionRepo) DeleteAttributeDefinition(ctx context.Context, id uuid.UUID) error {
+	return m.DeleteAttributeDefinitionFunc(ctx, id)
+}
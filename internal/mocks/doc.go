@@ -0,0 +1,9 @@
+// Package mocks holds hand-written test doubles for the repository
+// interfaces that have no generated mock yet. Each one asserts its interface
+// at compile time, so a changed interface fails the build until the mock
+// follows it.
+//
+// The category and product repositories and the logger are mocked with the
+// moq mocks generated by make mocks, which live next to the interfaces they
+// implement in internal/data_layer/mocks and internal/handlers/mocks.
+package mocks
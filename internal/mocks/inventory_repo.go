@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+var _ datalayer.InventoryRepoInterface = (*MockInventoryRepo)(nil)
+
+// MockInventoryRepo delegates each method to the matching func field
+type MockInventoryRepo struct {
+	AdjustStockFunc   func(ctx context.Context, movement *datalayer.InventoryMovement) error
+	ListMovementsFunc func(
+		ctx context.Context,
+		productID uuid.UUID,
+		filter datalayer.MovementFilter,
+		createdAfter time.Time,
+		limit int,
+	) (*datalayer.MovementPage, error)
+}
+
+func (m *MockInventoryRepo) AdjustStock(ctx context.Context, movement *datalayer.InventoryMovement) error {
+	return m.AdjustStockFunc(ctx, movement)
+}
+
+func (m *MockInventoryRepo) ListMovements(
+	ctx context.Context,
+	productID uuid.UUID,
+	filter datalayer.MovementFilter,
+	createdAfter time.Time,
+	limit int,
+) (*datalayer.MovementPage, error) {
+	return m.ListMovementsFunc(ctx, productID, filter, createdAfter, limit)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+var _ datalayer.PriceScheduleRepoInterface = (*MockPriceScheduleRepo)(nil)
+
+// MockPriceScheduleRepo delegates each method to the matching func field
+type MockPriceScheduleRepo struct {
+	CreatePriceScheduleFunc   func(ctx context.Context, schedule *datalayer.PriceSchedule) error
+	ListPriceSchedulesFunc    func(ctx context.Context, productID uuid.UUID) ([]*datalayer.PriceSchedule, error)
+	CancelPriceScheduleFunc   func(ctx context.Context, id uuid.UUID) error
+	ListDuePriceSchedulesFunc func(ctx context.Context, asOf time.Time, limit int) ([]*datalayer.PriceSchedule, error)
+	ApplyPriceScheduleFunc    func(ctx context.Context, id uuid.UUID) (*datalayer.Product, error)
+}
+
+func (m *MockPriceScheduleRepo) CreatePriceSchedule(ctx context.Context, schedule *datalayer.PriceSchedule) error {
+	return m.CreatePriceScheduleFunc(ctx, schedule)
+}
+
+func (m *MockPriceScheduleRepo) ListPriceSchedules(ctx context.Context, productID uuid.UUID) ([]*datalayer.PriceSchedule, error) {
+	return m.ListPriceSchedulesFunc(ctx, productID)
+}
+
+func (m *MockPriceScheduleRepo) CancelPriceSchedule(ctx context.Context, id uuid.UUID) error {
+	return m.CancelPriceScheduleFunc(ctx, id)
+}
+
+func (m *MockPriceScheduleRepo) ListDuePriceSchedules(ctx context.Context, asOf time.Time, limit int) ([]*datalayer.PriceSchedule, error) {
+	return m.ListDuePriceSchedulesFunc(ctx, asOf, limit)
+}
+
+func (m *MockPriceScheduleRepo) ApplyPriceSchedule(ctx context.Context, id uuid.UUID) (*datalayer.Product, error) {
+	return m.ApplyPriceScheduleFunc(ctx, id)
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+var _ datalayer.ReservationRepoInterface = (*MockReservationRepo)(nil)
+
+// MockReservationRepo delegates each method to the matching func field
+type MockReservationRepo struct {
+	CreateReservationFunc          func(ctx context.Context, reservation *datalayer.Reservation) error
+	ReleaseReservationFunc         func(ctx context.Context, id uuid.UUID) (*datalayer.Reservation, error)
+	CommitReservationFunc          func(ctx context.Context, id uuid.UUID) (*datalayer.Reservation, error)
+	ReleaseExpiredReservationsFunc func(ctx context.Context) (int, error)
+}
+
+func (m *MockReservationRepo) CreateReservation(ctx context.Context, reservation *datalayer.Reservation) error {
+	return m.CreateReservationFunc(ctx, reservation)
+}
+
+func (m *MockReservationRepo) ReleaseReservation(ctx context.Context, id uuid.UUID) (*datalayer.Reservation, error) {
+	return m.ReleaseReservationFunc(ctx, id)
+}
+
+func (m *MockReservationRepo) CommitReservation(ctx context.Context, id uuid.UUID) (*datalayer.Reservation, error) {
+	return m.CommitReservationFunc(ctx, id)
+}
+
+func (m *MockReservationRepo) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	return m.ReleaseExpiredReservationsFunc(ctx)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+// MaxAllowedValues caps the choices of one enum attribute
+const MaxAllowedValues = 100
+
+// AttributeDefinitionRequest is the input for creating or replacing an
+// attribute definition. AllowedValues is required for enum attributes and
+// must be empty for the other types.
+type AttributeDefinitionRequest struct {
+	Name          string                  `json:"name"`
+	Type          datalayer.AttributeType `json:"type"`
+	Required      bool                    `json:"required"`
+	AllowedValues []string                `json:"allowedValues"`
+}
+
+// AttributeService owns the attribute definitions of categories and checks
+// product attributes against them
+type AttributeService struct {
+	repo  datalayer.AttributeDefinitionRepoInterface
+	now   func() time.Time
+	newID func() uuid.UUID
+}
+
+// NewAttributeService creates an attribute service backed by repo
+func NewAttributeService(repo datalayer.AttributeDefinitionRepoInterface) *AttributeService {
+	return &AttributeService{repo: repo, now: time.Now, newID: uuid.New}
+}
+
+// ListDefinitions returns a category's definitions in name order
+func (s *AttributeService) ListDefinitions(ctx context.Context, categoryID uuid.UUID) ([]*datalayer.AttributeDefinition, error) {
+	return s.repo.ListAttributeDefinitions(ctx, categoryID)
+}
+
+// CreateDefinition validates req and stores it as a new definition of the
+// category
+func (s *AttributeService) CreateDefinition(
+	ctx context.Context,
+	categoryID uuid.UUID,
+	req AttributeDefinitionRequest,
+) (*datalayer.AttributeDefinition, error) {
+	if err := validateDefinition(req); err != nil {
+		return nil, err
+	}
+
+	definition := &datalayer.AttributeDefinition{
+		ID:            s.newID(),
+		CategoryID:    categoryID,
+		Name:          req.Name,
+		Type:          req.Type,
+		Required:      req.Required,
+		AllowedValues: allowedValues(req),
+		CreatedAt:     s.now().UTC(),
+	}
+	if err := s.repo.CreateAttributeDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// UpdateDefinition validates req and replaces the definition with it.
+// Products already carrying the attribute are not revalidated.
+func (s *AttributeService) UpdateDefinition(
+	ctx context.Context,
+	id uuid.UUID,
+	req AttributeDefinitionRequest,
+) (*datalayer.AttributeDefinition, error) {
+	if err := validateDefinition(req); err != nil {
+		return nil, err
+	}
+
+	definition := &datalayer.AttributeDefinition{
+		ID:            id,
+		Name:          req.Name,
+		Type:          req.Type,
+		Required:      req.Required,
+		AllowedValues: allowedValues(req),
+	}
+	if err := s.repo.UpdateAttributeDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// DeleteDefinition removes a definition
+func (s *AttributeService) DeleteDefinition(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteAttributeDefinition(ctx, id)
+}
+
+// ValidateProductAttributes checks attributes against the definitions of
+// the category. Every problem is reported as a field error on
+// attributes.<name>: unknown names, values of the wrong type, enum values
+// that are not allowed and missing required attributes.
+func (s *AttributeService) ValidateProductAttributes(
+	ctx context.Context,
+	categoryID uuid.UUID,
+	attributes datalayer.ProductAttributes,
+) error {
+	definitions, err := s.repo.ListAttributeDefinitions(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+
+	var fields []FieldError
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Name] = true
+		value, ok := attributes[definition.Name]
+		if !ok {
+			if definition.Required {
+				fields = append(fields, FieldError{Field: "attributes." + definition.Name, Message: "is required"})
+			}
+			continue
+		}
+		if msg := checkAttributeValue(definition, value); msg != "" {
+			fields = append(fields, FieldError{Field: "attributes." + definition.Name, Message: msg})
+		}
+	}
+	for name := range attributes {
+		if !defined[name] {
+			fields = append(fields, FieldError{Field: "attributes." + name, Message: "is not defined for the category"})
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	slices.SortFunc(fields, func(a, b FieldError) int {
+		return cmp.Compare(a.Field, b.Field)
+	})
+	return &ValidationError{Msg: "attributes are invalid", Fields: fields}
+}
+
+// validateDefinition rejects a blank or overlong name, an unknown type and
+// allowed values that do not fit the type
+func validateDefinition(req AttributeDefinitionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return &ValidationError{Msg: "name is required"}
+	}
+	if name != req.Name || utf8.RuneCountInString(name) > MaxAttributeKeyLength {
+		return &ValidationError{Msg: fmt.Sprintf("name must be 1 to %d characters without surrounding spaces", MaxAttributeKeyLength)}
+	}
+	if !req.Type.Valid() {
+		return &ValidationError{Msg: attributeTypeMessage()}
+	}
+
+	if req.Type != datalayer.AttributeEnum {
+		if len(req.AllowedValues) > 0 {
+			return &ValidationError{Msg: "allowedValues is only accepted for enum attributes"}
+		}
+		return nil
+	}
+	if len(req.AllowedValues) == 0 {
+		return &ValidationError{Msg: "allowedValues is required for enum attributes"}
+	}
+	if len(req.AllowedValues) > MaxAllowedValues {
+		return &ValidationError{Msg: fmt.Sprintf("allowedValues must have at most %d entries", MaxAllowedValues)}
+	}
+	seen := make(map[string]bool, len(req.AllowedValues))
+	for _, value := range req.AllowedValues {
+		if value == "" || utf8.RuneCountInString(value) > MaxAttributeValueLength {
+			return &ValidationError{Msg: fmt.Sprintf("allowedValues entries must be 1 to %d characters", MaxAttributeValueLength)}
+		}
+		if seen[value] {
+			return &ValidationError{Msg: fmt.Sprintf("allowedValues lists `%s` more than once", value)}
+		}
+		seen[value] = true
+	}
+	return nil
+}
+
+// allowedValues returns the values to store for req, none unless it is an
+// enum
+func allowedValues(req AttributeDefinitionRequest) datalayer.EnumValues {
+	if req.Type != datalayer.AttributeEnum {
+		return datalayer.EnumValues{}
+	}
+	return slices.Clone(datalayer.EnumValues(req.AllowedValues))
+}
+
+// checkAttributeValue returns why value does not fit definition, or ""
+// if it does. Values are as decoded from JSON, so numbers are float64.
+func checkAttributeValue(definition *datalayer.AttributeDefinition, value any) string {
+	switch definition.Type {
+	case datalayer.AttributeString:
+		if s, ok := value.(string); !ok {
+			return "must be a string"
+		} else if utf8.RuneCountInString(s) > MaxAttributeValueLength {
+			return fmt.Sprintf("must be at most %d characters", MaxAttributeValueLength)
+		}
+	case datalayer.AttributeNumber:
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case datalayer.AttributeBool:
+		if _, ok := value.(bool); !ok {
+			return "must be a bool"
+		}
+	case datalayer.AttributeEnum:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(definition.AllowedValues, s) {
+			return "must be one of: " + strings.Join(definition.AllowedValues, ", ")
+		}
+	}
+	return ""
+}
+
+// attributeTypeMessage lists the accepted attribute types for validation
+// errors
+func attributeTypeMessage() string {
+	types := make([]string, len(datalayer.AttributeTypes))
+	for i, attributeType := range datalayer.AttributeTypes {
+		types[i] = string(attributeType)
+	}
+	return "type must be one of: " + strings.Join(types, ", ")
+}
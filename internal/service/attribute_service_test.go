@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAttributeService(repo datalayer.AttributeDefinitionRepoInterface) *AttributeService {
+	return &AttributeService{
+		repo:  repo,
+		now:   func() time.Time { return testNow },
+		newID: func() uuid.UUID { return testID },
+	}
+}
+
+// newTestAttributeServiceWithCategory returns a service over an empty
+// memory repo whose only category has testCategoryID
+func newTestAttributeServiceWithCategory(t *testing.T) (*AttributeService, *datalayer.MemoryAttributeDefinitionRepo) {
+	t.Helper()
+
+	categories := datalayer.NewMemoryCategoryRepo()
+	require.NoError(t, categories.CreateCategory(context.Background(), &datalayer.Category{ID: testCategoryID, Name: "Lighting"}))
+
+	repo := datalayer.NewMemoryAttributeDefinitionRepo(categories)
+	return newTestAttributeService(repo), repo
+}
+
+func TestAttributeServiceCreateDefinition(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create an enum definition with an ID and UTC creation time", func(t *testing.T) {
+		svc, repo := newTestAttributeServiceWithCategory(t)
+		req := AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeEnum, Required: true, AllowedValues: []string{"matte", "gloss"}}
+
+		definition, err := svc.CreateDefinition(ctx, testCategoryID, req)
+		require.NoError(t, err)
+		assert.Equal(t, &datalayer.AttributeDefinition{
+			ID:            testID,
+			CategoryID:    testCategoryID,
+			Name:          "finish",
+			Type:          datalayer.AttributeEnum,
+			Required:      true,
+			AllowedValues: datalayer.EnumValues{"matte", "gloss"},
+			CreatedAt:     testNow.UTC(),
+		}, definition)
+
+		stored, err := repo.GetAttributeDefinition(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, definition, stored)
+	})
+
+	t.Run("should store no allowed values for other types", func(t *testing.T) {
+		svc, _ := newTestAttributeServiceWithCategory(t)
+
+		definition, err := svc.CreateDefinition(ctx, testCategoryID, AttributeDefinitionRequest{Name: "wattage", Type: datalayer.AttributeNumber})
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.EnumValues{}, definition.AllowedValues)
+	})
+
+	tooMany := make([]string, MaxAllowedValues+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("v", i+1)
+	}
+	tests := []struct {
+		name    string
+		req     AttributeDefinitionRequest
+		wantErr string
+	}{
+		{
+			name:    "blank name",
+			req:     AttributeDefinitionRequest{Name: " ", Type: datalayer.AttributeString},
+			wantErr: "name is required",
+		},
+		{
+			name:    "name with surrounding spaces",
+			req:     AttributeDefinitionRequest{Name: " finish", Type: datalayer.AttributeString},
+			wantErr: "name must be 1 to 64 characters without surrounding spaces",
+		},
+		{
+			name:    "overlong name",
+			req:     AttributeDefinitionRequest{Name: strings.Repeat("n", MaxAttributeKeyLength+1), Type: datalayer.AttributeString},
+			wantErr: "name must be 1 to 64 characters without surrounding spaces",
+		},
+		{
+			name:    "missing type",
+			req:     AttributeDefinitionRequest{Name: "finish"},
+			wantErr: "type must be one of: string, number, bool, enum",
+		},
+		{
+			name:    "unknown type",
+			req:     AttributeDefinitionRequest{Name: "finish", Type: "date"},
+			wantErr: "type must be one of: string, number, bool, enum",
+		},
+		{
+			name:    "enum without values",
+			req:     AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeEnum},
+			wantErr: "allowedValues is required for enum attributes",
+		},
+		{
+			name:    "enum with too many values",
+			req:     AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeEnum, AllowedValues: tooMany},
+			wantErr: "allowedValues must have at most 100 entries",
+		},
+		{
+			name:    "enum with an empty value",
+			req:     AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeEnum, AllowedValues: []string{"matte", ""}},
+			wantErr: "allowedValues entries must be 1 to 256 characters",
+		},
+		{
+			name:    "enum with a duplicate value",
+			req:     AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeEnum, AllowedValues: []string{"matte", "matte"}},
+			wantErr: "allowedValues lists `matte` more than once",
+		},
+		{
+			name:    "values for a non-enum",
+			req:     AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeBool, AllowedValues: []string{"yes"}},
+			wantErr: "allowedValues is only accepted for enum attributes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run("should reject "+tt.name, func(t *testing.T) {
+			svc, repo := newTestAttributeServiceWithCategory(t)
+
+			definition, err := svc.CreateDefinition(ctx, testCategoryID, tt.req)
+			assert.Nil(t, definition)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.True(t, errors.Is(err, ErrValidation))
+
+			_, err = repo.GetAttributeDefinition(ctx, testID)
+			assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+		})
+	}
+
+	t.Run("should pass repo errors through", func(t *testing.T) {
+		svc, _ := newTestAttributeServiceWithCategory(t)
+		req := AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeString}
+
+		_, err := svc.CreateDefinition(ctx, testID, req)
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+
+		_, err = svc.CreateDefinition(ctx, testCategoryID, req)
+		require.NoError(t, err)
+		svc.newID = uuid.New
+		_, err = svc.CreateDefinition(ctx, testCategoryID, req)
+		assert.True(t, errors.Is(err, datalayer.ErrAttributeExists))
+	})
+}
+
+func TestAttributeServiceUpdateDefinition(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should replace the definition", func(t *testing.T) {
+		svc, _ := newTestAttributeServiceWithCategory(t)
+		_, err := svc.CreateDefinition(ctx, testCategoryID, AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeString})
+		require.NoError(t, err)
+
+		definition, err := svc.UpdateDefinition(ctx, testID, AttributeDefinitionRequest{
+			Name: "finish", Type: datalayer.AttributeEnum, AllowedValues: []string{"matte"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testCategoryID, definition.CategoryID)
+		assert.Equal(t, testNow.UTC(), definition.CreatedAt)
+		assert.Equal(t, datalayer.EnumValues{"matte"}, definition.AllowedValues)
+	})
+
+	t.Run("should validate before updating", func(t *testing.T) {
+		svc, _ := newTestAttributeServiceWithCategory(t)
+
+		_, err := svc.UpdateDefinition(ctx, testID, AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeEnum})
+		assert.EqualError(t, err, "allowedValues is required for enum attributes")
+	})
+
+	t.Run("should pass not found through", func(t *testing.T) {
+		svc, _ := newTestAttributeServiceWithCategory(t)
+
+		_, err := svc.UpdateDefinition(ctx, testID, AttributeDefinitionRequest{Name: "finish", Type: datalayer.AttributeString})
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+	})
+}
+
+func TestAttributeServiceValidateProductAttributes(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestAttributeServiceWithCategory(t)
+	for _, req := range []AttributeDefinitionRequest{
+		{Name: "finish", Type: datalayer.AttributeEnum, Required: true, AllowedValues: []string{"matte", "gloss"}},
+		{Name: "wattage", Type: datalayer.AttributeNumber},
+		{Name: "dimmable", Type: datalayer.AttributeBool},
+		{Name: "bulb", Type: datalayer.AttributeString},
+	} {
+		svc.newID = uuid.New
+		_, err := svc.CreateDefinition(ctx, testCategoryID, req)
+		require.NoError(t, err)
+	}
+
+	valid := []datalayer.ProductAttributes{
+		{"finish": "matte"},
+		{"finish": "gloss", "wattage": 40.0, "dimmable": false, "bulb": "E27"},
+		{"finish": "gloss", "wattage": -1.5, "bulb": ""},
+	}
+	for _, attributes := range valid {
+		assert.NoError(t, svc.ValidateProductAttributes(ctx, testCategoryID, attributes), "%v", attributes)
+	}
+
+	tests := []struct {
+		name       string
+		attributes datalayer.ProductAttributes
+		want       []FieldError
+	}{
+		{
+			name:       "missing required attribute",
+			attributes: nil,
+			want:       []FieldError{{Field: "attributes.finish", Message: "is required"}},
+		},
+		{
+			name:       "enum value not allowed",
+			attributes: datalayer.ProductAttributes{"finish": "satin"},
+			want:       []FieldError{{Field: "attributes.finish", Message: "must be one of: matte, gloss"}},
+		},
+		{
+			name:       "enum value of the wrong type",
+			attributes: datalayer.ProductAttributes{"finish": 1.0},
+			want:       []FieldError{{Field: "attributes.finish", Message: "must be one of: matte, gloss"}},
+		},
+		{
+			name:       "number given as a string",
+			attributes: datalayer.ProductAttributes{"finish": "matte", "wattage": "40"},
+			want:       []FieldError{{Field: "attributes.wattage", Message: "must be a number"}},
+		},
+		{
+			name:       "bool given as a string",
+			attributes: datalayer.ProductAttributes{"finish": "matte", "dimmable": "true"},
+			want:       []FieldError{{Field: "attributes.dimmable", Message: "must be a bool"}},
+		},
+		{
+			name:       "string given as a number",
+			attributes: datalayer.ProductAttributes{"finish": "matte", "bulb": 27.0},
+			want:       []FieldError{{Field: "attributes.bulb", Message: "must be a string"}},
+		},
+		{
+			name:       "overlong string",
+			attributes: datalayer.ProductAttributes{"finish": "matte", "bulb": strings.Repeat("b", MaxAttributeValueLength+1)},
+			want:       []FieldError{{Field: "attributes.bulb", Message: "must be at most 256 characters"}},
+		},
+		{
+			name:       "null value",
+			attributes: datalayer.ProductAttributes{"finish": "matte", "wattage": nil},
+			want:       []FieldError{{Field: "attributes.wattage", Message: "must be a number"}},
+		},
+		{
+			name:       "undefined attribute",
+			attributes: datalayer.ProductAttributes{"finish": "matte", "color": "red"},
+			want:       []FieldError{{Field: "attributes.color", Message: "is not defined for the category"}},
+		},
+		{
+			name:       "every problem at once in field order",
+			attributes: datalayer.ProductAttributes{"wattage": true, "color": "red", "dimmable": 1.0},
+			want: []FieldError{
+				{Field: "attributes.color", Message: "is not defined for the category"},
+				{Field: "attributes.dimmable", Message: "must be a bool"},
+				{Field: "attributes.finish", Message: "is required"},
+				{Field: "attributes.wattage", Message: "must be a number"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("should reject "+tt.name, func(t *testing.T) {
+			err := svc.ValidateProductAttributes(ctx, testCategoryID, tt.attributes)
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "attributes are invalid", validationErr.Error())
+			assert.Equal(t, tt.want, validationErr.Fields)
+			assert.True(t, errors.Is(err, ErrValidation))
+		})
+	}
+
+	t.Run("should reject every attribute of a category without definitions", func(t *testing.T) {
+		svc, _ := newTestAttributeServiceWithCategory(t)
+
+		assert.NoError(t, svc.ValidateProductAttributes(ctx, testCategoryID, nil))
+		err := svc.ValidateProductAttributes(ctx, testCategoryID, datalayer.ProductAttributes{"color": "red"})
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []FieldError{{Field: "attributes.color", Message: "is not defined for the category"}}, validationErr.Fields)
+	})
+
+	t.Run("should pass definition lookup errors through", func(t *testing.T) {
+		err := svc.ValidateProductAttributes(ctx, testID, nil)
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+		assert.False(t, errors.Is(err, ErrValidation))
+	})
+}
@@ -0,0 +1,345 @@
+// Package service holds business rules that sit between the HTTP handlers
+// and the repositories: validation, defaults and ID and timestamp
+// assignment.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+var ErrValidation = errors.New("validation failed")
+
+// Limits on category names and descriptions, in characters
+const (
+	MaxCategoryNameLength        = 100
+	MaxCategoryDescriptionLength = 1000
+)
+
+// MaxBulkCategories caps the categories of one bulk create
+const MaxBulkCategories = datalayer.MaxBatchSize
+
+// Limits on category attributes, so one category cannot carry an
+// unbounded document
+const (
+	MaxCategoryAttributes   = 20
+	MaxAttributeKeyLength   = 64
+	MaxAttributeValueLength = 256
+)
+
+// ValidationError reports input the service refuses before it reaches the
+// repo. It matches ErrValidation with errors.Is. Fields, when set, names
+// each rejected field so clients can show every problem at once.
+type ValidationError struct {
+	Msg    string
+	Fields []FieldError
+}
+
+// FieldError describes why one field of the input was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// CreateCategoryRequest is the input for a new category. Like
+// CreateProductRequest it has no creation time; the service stamps it.
+// ParentID, when set, must name an existing category.
+type CreateCategoryRequest struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Attributes  datalayer.CategoryAttributes `json:"attributes"`
+	ParentID    *uuid.UUID                   `json:"parentId"`
+}
+
+// BulkCategoryRequest is one category of a bulk create. Ref names it
+// within the batch so a later category can set it as its ParentRef, since
+// the IDs are only assigned on create. A category sets at most one of
+// ParentID and ParentRef.
+type BulkCategoryRequest struct {
+	CreateCategoryRequest
+	Ref       string `json:"ref"`
+	ParentRef string `json:"parentRef"`
+}
+
+type CategoryService struct {
+	repo     datalayer.CategoryRepoInterface
+	now      func() time.Time
+	newID    func() uuid.UUID
+	onChange []func()
+}
+
+// NewCategoryService creates a category service backed by repo
+func NewCategoryService(repo datalayer.CategoryRepoInterface) *CategoryService {
+	return &CategoryService{repo: repo, now: time.Now, newID: uuid.New}
+}
+
+// OnChange registers fn to be called after every category the service
+// creates, updates or deletes, e.g. to invalidate cached listings
+func (s *CategoryService) OnChange(fn func()) {
+	s.onChange = append(s.onChange, fn)
+}
+
+// changed notifies the OnChange callbacks
+func (s *CategoryService) changed() {
+	for _, fn := range s.onChange {
+		fn()
+	}
+}
+
+// CreateCategory validates req, assigns an ID and creation time and stores
+// the new category
+func (s *CategoryService) CreateCategory(ctx context.Context, req CreateCategoryRequest) (*datalayer.Category, error) {
+	category, err := s.newCategory(req)
+	if err != nil {
+		return nil, err
+	}
+	if category.ParentID != nil {
+		exists, err := s.repo.CategoryExists(ctx, *category.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, parentNotFound(*category.ParentID)
+		}
+	}
+	if err := s.repo.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	s.changed()
+	return category, nil
+}
+
+// BulkCreateCategories validates every request like CreateCategory and
+// stores them all in one transaction. A ParentRef must match the Ref of an
+// earlier request, so parents are inserted before their children. If any
+// request is invalid or has a dangling parent nothing is stored and
+// ErrBatchRejected is returned with the item errors, which line up with
+// reqs and are nil for valid items.
+func (s *CategoryService) BulkCreateCategories(ctx context.Context, reqs []BulkCategoryRequest) ([]*datalayer.Category, []error, error) {
+	if len(reqs) == 0 {
+		return nil, nil, &ValidationError{Msg: "bulk create must have at least one category"}
+	}
+	if len(reqs) > MaxBulkCategories {
+		return nil, nil, &ValidationError{Msg: fmt.Sprintf("bulk create must have at most %d categories", MaxBulkCategories)}
+	}
+
+	categories := make([]*datalayer.Category, len(reqs))
+	itemErrs := make([]error, len(reqs))
+	refs := make(map[string]int, len(reqs))
+	for i, req := range reqs {
+		categories[i], itemErrs[i] = s.newCategory(req.CreateCategoryRequest)
+		if itemErrs[i] == nil {
+			itemErrs[i] = resolveParentRef(categories, refs, i, req)
+		}
+		if req.Ref == "" {
+			continue
+		}
+		if _, ok := refs[req.Ref]; ok {
+			if itemErrs[i] == nil {
+				itemErrs[i] = &ValidationError{Msg: fmt.Sprintf("ref `%s` is already used by an earlier category", req.Ref)}
+			}
+			continue
+		}
+		refs[req.Ref] = i
+	}
+	if err := s.checkParentIDs(ctx, categories, itemErrs); err != nil {
+		return nil, nil, err
+	}
+	if slices.ContainsFunc(itemErrs, func(err error) bool { return err != nil }) {
+		return nil, itemErrs, ErrBatchRejected
+	}
+
+	if err := s.repo.BulkCreateCategories(ctx, categories); err != nil {
+		return nil, nil, err
+	}
+	s.changed()
+	return categories, nil, nil
+}
+
+// resolveParentRef sets the parent of categories[i] to the earlier
+// category whose Ref is req.ParentRef
+func resolveParentRef(categories []*datalayer.Category, refs map[string]int, i int, req BulkCategoryRequest) error {
+	if req.ParentRef == "" {
+		return nil
+	}
+	if req.ParentID != nil {
+		return &ValidationError{Msg: "only one of parentId and parentRef may be set"}
+	}
+	parent, ok := refs[req.ParentRef]
+	if !ok {
+		return &ValidationError{Msg: fmt.Sprintf("parentRef `%s` does not match the ref of an earlier category", req.ParentRef)}
+	}
+	if categories[parent] != nil {
+		categories[i].ParentID = &categories[parent].ID
+	}
+	return nil
+}
+
+// checkParentIDs sets the error of every valid category whose ParentID
+// names no stored category. Parents set from a ParentRef are in the batch
+// and not looked up.
+func (s *CategoryService) checkParentIDs(ctx context.Context, categories []*datalayer.Category, itemErrs []error) error {
+	inBatch := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		if category != nil {
+			inBatch[category.ID] = true
+		}
+	}
+	var ids []uuid.UUID
+	for i, category := range categories {
+		if itemErrs[i] == nil && category.ParentID != nil && !inBatch[*category.ParentID] {
+			ids = append(ids, *category.ParentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	parents, err := s.repo.GetCategoriesByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, category := range categories {
+		if itemErrs[i] != nil || category.ParentID == nil || inBatch[*category.ParentID] {
+			continue
+		}
+		if _, ok := parents[*category.ParentID]; !ok {
+			itemErrs[i] = parentNotFound(*category.ParentID)
+		}
+	}
+	return nil
+}
+
+// parentNotFound is the error for a ParentID without a category
+func parentNotFound(id uuid.UUID) error {
+	return &ValidationError{Msg: fmt.Sprintf("parent category `%s` not found", id)}
+}
+
+// newCategory validates req and builds the category it creates
+func (s *CategoryService) newCategory(req CreateCategoryRequest) (*datalayer.Category, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, &ValidationError{Msg: "name is required"}
+	}
+	if err := validateCategoryText(&req.Name, &req.Description); err != nil {
+		return nil, err
+	}
+	if err := ValidateAttributes(req.Attributes); err != nil {
+		return nil, err
+	}
+
+	category := &datalayer.Category{
+		ID:          s.newID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Attributes:  req.Attributes,
+		ParentID:    req.ParentID,
+		CreatedAt:   s.now().UTC(),
+	}
+	if category.Attributes == nil {
+		category.Attributes = datalayer.CategoryAttributes{}
+	}
+	return category, nil
+}
+
+// UpdateCategory applies the fields set in patch and returns the category
+// as stored afterwards. The patch must change something and may not blank
+// the name.
+func (s *CategoryService) UpdateCategory(ctx context.Context, id uuid.UUID, patch datalayer.CategoryPatch) (*datalayer.Category, error) {
+	if patch.IsEmpty() {
+		return nil, &ValidationError{Msg: "name, description or attributes is required"}
+	}
+	if patch.Name != nil && strings.TrimSpace(*patch.Name) == "" {
+		return nil, &ValidationError{Msg: "name must not be empty"}
+	}
+	if err := validateCategoryText(patch.Name, patch.Description); err != nil {
+		return nil, err
+	}
+	if patch.Attributes != nil {
+		if err := ValidateAttributes(*patch.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	category, err := s.repo.PatchCategory(ctx, id, patch)
+	if err != nil {
+		return nil, err
+	}
+	s.changed()
+	return category, nil
+}
+
+// Exists reports whether a category with id exists
+func (s *CategoryService) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.repo.CategoryExists(ctx, id)
+}
+
+// validateCategoryText rejects a name or description over its length
+// limit. Nil fields are not checked.
+func validateCategoryText(name, description *string) error {
+	if name != nil && utf8.RuneCountInString(*name) > MaxCategoryNameLength {
+		return &ValidationError{Msg: fmt.Sprintf("name must be at most %d characters", MaxCategoryNameLength)}
+	}
+	if description != nil && utf8.RuneCountInString(*description) > MaxCategoryDescriptionLength {
+		return &ValidationError{Msg: fmt.Sprintf("description must be at most %d characters", MaxCategoryDescriptionLength)}
+	}
+	return nil
+}
+
+// ValidateAttributes checks attributes against the key count and length
+// limits. Values must be strings, numbers or bools; nested objects, arrays
+// and nulls are rejected.
+func ValidateAttributes(attributes datalayer.CategoryAttributes) error {
+	if len(attributes) > MaxCategoryAttributes {
+		return &ValidationError{Msg: fmt.Sprintf("attributes must have at most %d keys", MaxCategoryAttributes)}
+	}
+	for key, value := range attributes {
+		if strings.TrimSpace(key) == "" || utf8.RuneCountInString(key) > MaxAttributeKeyLength {
+			return &ValidationError{Msg: fmt.Sprintf("attribute keys must be 1 to %d characters", MaxAttributeKeyLength)}
+		}
+		switch v := value.(type) {
+		case string:
+			if utf8.RuneCountInString(v) > MaxAttributeValueLength {
+				return &ValidationError{Msg: fmt.Sprintf("attribute `%s` must be at most %d characters", key, MaxAttributeValueLength)}
+			}
+		case float64, bool:
+		default:
+			return &ValidationError{Msg: fmt.Sprintf("attribute `%s` must be a string, number or bool", key)}
+		}
+	}
+	return nil
+}
+
+// DeleteCategory removes a category
+func (s *CategoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteCategory(ctx, id); err != nil {
+		return err
+	}
+	s.changed()
+	return nil
+}
+
+// DeleteCategoryReturning removes a category and returns it as it was
+// before deletion
+func (s *CategoryService) DeleteCategoryReturning(ctx context.Context, id uuid.UUID) (*datalayer.Category, error) {
+	category, err := s.repo.DeleteCategoryReturning(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.changed()
+	return category, nil
+}
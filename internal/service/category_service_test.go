@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testID  = uuid.MustParse("f2aa335f-6f91-4d4d-8057-53b0009bc376")
+	testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+)
+
+// failingRepo fails every create so repo errors can be checked
+type failingRepo struct {
+	datalayer.CategoryRepoInterface
+	err error
+}
+
+func (r failingRepo) CreateCategory(context.Context, *datalayer.Category) error {
+	return r.err
+}
+
+func newTestCategoryService(repo datalayer.CategoryRepoInterface) *CategoryService {
+	return &CategoryService{
+		repo:  repo,
+		now:   func() time.Time { return testNow },
+		newID: func() uuid.UUID { return testID },
+	}
+}
+
+func TestCategoryServiceCreateCategory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should assign an ID and UTC creation time", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+
+		category, err := newTestCategoryService(repo).CreateCategory(ctx, CreateCategoryRequest{Name: "Books", Description: "Paper"})
+		require.NoError(t, err)
+		assert.Equal(t, &datalayer.Category{
+			ID:          testID,
+			Name:        "Books",
+			Description: "Paper",
+			Attributes:  datalayer.CategoryAttributes{},
+			CreatedAt:   testNow.UTC(),
+		}, category)
+		assert.Equal(t, time.UTC, category.CreatedAt.Location())
+
+		stored, err := repo.GetCategoryByID(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, "Books", stored.Name)
+	})
+
+	t.Run("should reject a blank name without calling the repo", func(t *testing.T) {
+		category, err := newTestCategoryService(failingRepo{}).CreateCategory(ctx, CreateCategoryRequest{Name: "  "})
+		assert.Nil(t, category)
+		assert.True(t, errors.Is(err, ErrValidation))
+		assert.EqualError(t, err, "name is required")
+	})
+
+	t.Run("should reject an overlong name or description", func(t *testing.T) {
+		_, err := newTestCategoryService(failingRepo{}).CreateCategory(ctx, CreateCategoryRequest{Name: strings.Repeat("n", MaxCategoryNameLength+1)})
+		assert.EqualError(t, err, "name must be at most 100 characters")
+
+		_, err = newTestCategoryService(failingRepo{}).CreateCategory(ctx, CreateCategoryRequest{Name: "Books", Description: strings.Repeat("d", MaxCategoryDescriptionLength+1)})
+		assert.EqualError(t, err, "description must be at most 1000 characters")
+		assert.True(t, errors.Is(err, ErrValidation))
+	})
+
+	t.Run("should count characters rather than bytes", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+
+		_, err := newTestCategoryService(repo).CreateCategory(ctx, CreateCategoryRequest{Name: strings.Repeat("é", MaxCategoryNameLength)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return repo errors", func(t *testing.T) {
+		repoErr := errors.New("createCategory: insert query failed: boom")
+
+		category, err := newTestCategoryService(failingRepo{err: repoErr}).CreateCategory(ctx, CreateCategoryRequest{Name: "Books"})
+		assert.Nil(t, category)
+		assert.Equal(t, repoErr, err)
+	})
+
+	t.Run("should store the parent and reject a missing one", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+		parent := &datalayer.Category{Name: "Books"}
+		require.NoError(t, repo.CreateCategory(ctx, parent))
+		svc := NewCategoryService(repo)
+
+		category, err := svc.CreateCategory(ctx, CreateCategoryRequest{Name: "Fiction", ParentID: &parent.ID})
+		require.NoError(t, err)
+		assert.Equal(t, &parent.ID, category.ParentID)
+
+		missing := uuid.New()
+		_, err = svc.CreateCategory(ctx, CreateCategoryRequest{Name: "Poetry", ParentID: &missing})
+		assert.EqualError(t, err, fmt.Sprintf("parent category `%s` not found", missing))
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestCategoryServiceBulkCreateCategories(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create every category", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+		changes := 0
+		svc := NewCategoryService(repo)
+		svc.OnChange(func() { changes++ })
+
+		categories, itemErrs, err := svc.BulkCreateCategories(ctx, []BulkCategoryRequest{
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Fiction"}},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Poetry"}},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, itemErrs)
+		require.Len(t, categories, 2)
+		assert.NotEqual(t, categories[0].ID, categories[1].ID)
+		assert.Equal(t, 1, changes)
+
+		for _, category := range categories {
+			stored, err := repo.GetCategoryByID(ctx, category.ID)
+			require.NoError(t, err)
+			assert.Equal(t, category.Name, stored.Name)
+		}
+	})
+
+	t.Run("should reject the batch if one category is invalid", func(t *testing.T) {
+		categories, itemErrs, err := newTestCategoryService(failingRepo{}).BulkCreateCategories(ctx, []BulkCategoryRequest{
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Fiction"}},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: " "}},
+		})
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		assert.Nil(t, categories)
+		require.Len(t, itemErrs, 2)
+		assert.NoError(t, itemErrs[0])
+		assert.EqualError(t, itemErrs[1], "name is required")
+	})
+
+	t.Run("should create a child after its parent in the same batch", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+		existing := &datalayer.Category{Name: "Media"}
+		require.NoError(t, repo.CreateCategory(ctx, existing))
+
+		categories, itemErrs, err := NewCategoryService(repo).BulkCreateCategories(ctx, []BulkCategoryRequest{
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Books", ParentID: &existing.ID}, Ref: "books"},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Fiction"}, ParentRef: "books"},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, itemErrs)
+		require.Len(t, categories, 2)
+		assert.Equal(t, &existing.ID, categories[0].ParentID)
+		assert.Equal(t, &categories[0].ID, categories[1].ParentID)
+
+		stored, err := repo.GetCategoryByID(ctx, categories[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, &categories[0].ID, stored.ParentID)
+	})
+
+	t.Run("should reject the batch if a parent reference dangles", func(t *testing.T) {
+		repo := datalayer.NewMemoryCategoryRepo()
+		missing := uuid.New()
+		categories, itemErrs, err := NewCategoryService(repo).BulkCreateCategories(ctx, []BulkCategoryRequest{
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Fiction"}, ParentRef: "books"},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Books"}, Ref: "books"},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Poetry", ParentID: &missing}},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Comics"}, Ref: "books"},
+			{CreateCategoryRequest: CreateCategoryRequest{Name: "Essays", ParentID: &missing}, ParentRef: "books"},
+		})
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		assert.Nil(t, categories)
+		require.Len(t, itemErrs, 5)
+		assert.EqualError(t, itemErrs[0], "parentRef `books` does not match the ref of an earlier category")
+		assert.NoError(t, itemErrs[1])
+		assert.EqualError(t, itemErrs[2], fmt.Sprintf("parent category `%s` not found", missing))
+		assert.EqualError(t, itemErrs[3], "ref `books` is already used by an earlier category")
+		assert.EqualError(t, itemErrs[4], "only one of parentId and parentRef may be set")
+
+		page, err := repo.ListCategories(ctx, datalayer.CategoryFilter{}, time.Time{}, 10)
+		require.NoError(t, err)
+		assert.Empty(t, page.Categories)
+	})
+
+	t.Run("should reject an empty or oversized batch", func(t *testing.T) {
+		_, _, err := newTestCategoryService(failingRepo{}).BulkCreateCategories(ctx, nil)
+		assert.EqualError(t, err, "bulk create must have at least one category")
+
+		_, _, err = newTestCategoryService(failingRepo{}).BulkCreateCategories(ctx, make([]BulkCategoryRequest, MaxBulkCategories+1))
+		assert.EqualError(t, err, "bulk create must have at most 1000 categories")
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+}
+
+func TestCategoryServiceUpdateCategory(t *testing.T) {
+	ctx := context.Background()
+	name, blank := "Comics", " "
+
+	repo := datalayer.NewMemoryCategoryRepo()
+	svc := newTestCategoryService(repo)
+	_, err := svc.CreateCategory(ctx, CreateCategoryRequest{Name: "Books"})
+	require.NoError(t, err)
+
+	t.Run("should patch the category", func(t *testing.T) {
+		category, err := svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, "Comics", category.Name)
+	})
+
+	t.Run("should reject invalid patches", func(t *testing.T) {
+		_, err := svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{})
+		assert.EqualError(t, err, "name, description or attributes is required")
+
+		_, err = svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Name: &blank})
+		assert.EqualError(t, err, "name must not be empty")
+		assert.True(t, errors.Is(err, ErrValidation))
+
+		longName := strings.Repeat("n", MaxCategoryNameLength+1)
+		_, err = svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Name: &longName})
+		assert.EqualError(t, err, "name must be at most 100 characters")
+
+		longDescription := strings.Repeat("d", MaxCategoryDescriptionLength+1)
+		_, err = svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Description: &longDescription})
+		assert.EqualError(t, err, "description must be at most 1000 characters")
+	})
+
+	t.Run("should replace attributes", func(t *testing.T) {
+		attributes := datalayer.CategoryAttributes{"icon": "comic"}
+		category, err := svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Attributes: &attributes})
+		require.NoError(t, err)
+		assert.Equal(t, attributes, category.Attributes)
+	})
+
+	t.Run("should reject invalid attributes", func(t *testing.T) {
+		attributes := datalayer.CategoryAttributes{"tags": []any{"a"}}
+		_, err := svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Attributes: &attributes})
+		assert.EqualError(t, err, "attribute `tags` must be a string, number or bool")
+	})
+
+	t.Run("should pass not found through", func(t *testing.T) {
+		_, err := svc.UpdateCategory(ctx, uuid.New(), datalayer.CategoryPatch{Name: &name})
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+	})
+}
+
+func TestValidateAttributes(t *testing.T) {
+	tooMany := datalayer.CategoryAttributes{}
+	for i := range MaxCategoryAttributes + 1 {
+		tooMany[fmt.Sprintf("key%d", i)] = i
+	}
+
+	tests := []struct {
+		name       string
+		attributes datalayer.CategoryAttributes
+		wantErr    string
+	}{
+		{name: "nil", attributes: nil},
+		{name: "scalar values", attributes: datalayer.CategoryAttributes{"icon": "book", "order": 3.0, "featured": true}},
+		{name: "too many keys", attributes: tooMany, wantErr: "attributes must have at most 20 keys"},
+		{name: "blank key", attributes: datalayer.CategoryAttributes{" ": "x"}, wantErr: "attribute keys must be 1 to 64 characters"},
+		{name: "long key", attributes: datalayer.CategoryAttributes{strings.Repeat("k", 65): "x"}, wantErr: "attribute keys must be 1 to 64 characters"},
+		{name: "long value", attributes: datalayer.CategoryAttributes{"seo_title": strings.Repeat("v", 257)}, wantErr: "attribute `seo_title` must be at most 256 characters"},
+		{name: "null value", attributes: datalayer.CategoryAttributes{"icon": nil}, wantErr: "attribute `icon` must be a string, number or bool"},
+		{name: "object value", attributes: datalayer.CategoryAttributes{"icon": map[string]any{}}, wantErr: "attribute `icon` must be a string, number or bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAttributes(tt.attributes)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+			assert.True(t, errors.Is(err, ErrValidation))
+		})
+	}
+}
+
+func TestCategoryServiceDeleteCategoryReturning(t *testing.T) {
+	ctx := context.Background()
+	repo := datalayer.NewMemoryCategoryRepo()
+	svc := newTestCategoryService(repo)
+	_, err := svc.CreateCategory(ctx, CreateCategoryRequest{Name: "Books"})
+	require.NoError(t, err)
+
+	category, err := svc.DeleteCategoryReturning(ctx, testID)
+	require.NoError(t, err)
+	assert.Equal(t, "Books", category.Name)
+
+	assert.True(t, errors.Is(svc.DeleteCategory(ctx, testID), datalayer.ErrNotFound))
+}
+
+func TestCategoryServiceOnChange(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestCategoryService(datalayer.NewMemoryCategoryRepo())
+	changes := 0
+	svc.OnChange(func() { changes++ })
+	name := "Comics"
+
+	_, err := svc.CreateCategory(ctx, CreateCategoryRequest{Name: "Books"})
+	require.NoError(t, err)
+	_, err = svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Name: &name})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteCategory(ctx, testID))
+	assert.Equal(t, 3, changes)
+
+	_, err = svc.UpdateCategory(ctx, testID, datalayer.CategoryPatch{Name: &name})
+	assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+	assert.Error(t, svc.DeleteCategory(ctx, testID))
+	_, err = svc.CreateCategory(ctx, CreateCategoryRequest{Name: " "})
+	assert.Error(t, err)
+	assert.Equal(t, 3, changes, "failed writes must not notify")
+}
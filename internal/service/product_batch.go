@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+// MaxBatchItems caps the items of one product batch
+const MaxBatchItems = datalayer.MaxBatchSize
+
+// ErrBatchRejected reports a batch that was not applied because some of
+// its items failed. The item results say which and why.
+var ErrBatchRejected = errors.New("batch rejected")
+
+// BatchItemRequest is one write of a product batch
+type BatchItemRequest struct {
+	Op      datalayer.BatchOp   `json:"op"`
+	Product BatchProductRequest `json:"product"`
+}
+
+// BatchProductRequest is the product of a batch item. A create reads it
+// like CreateProductRequest and ignores ID. An update replaces the plain
+// fields of the product with that ID, keeping its currency and metadata
+// when none is given, and a delete only reads ID.
+type BatchProductRequest struct {
+	ID uuid.UUID `json:"id"`
+	CreateProductRequest
+}
+
+// BatchItemResult is the outcome of one batch item. Product is the product
+// as written, holding only the ID for a delete. Err is nil when the item
+// was applied.
+type BatchItemResult struct {
+	Op      datalayer.BatchOp
+	Product *datalayer.Product
+	Err     error
+}
+
+// ApplyBatch checks every item and applies them in order in one
+// transaction. Unless partial, an invalid item, or one whose product is
+// gone, rejects the whole batch with ErrBatchRejected. With partial the
+// other items are still applied. Either way the results line up with reqs.
+func (s *ProductService) ApplyBatch(ctx context.Context, reqs []BatchItemRequest, partial bool) ([]BatchItemResult, error) {
+	if len(reqs) == 0 {
+		return nil, &ValidationError{Msg: "batch must have at least one item"}
+	}
+	if len(reqs) > MaxBatchItems {
+		return nil, &ValidationError{Msg: fmt.Sprintf("batch must have at most %d items", MaxBatchItems)}
+	}
+
+	results := make([]BatchItemResult, len(reqs))
+	items := make([]datalayer.ProductBatchItem, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs)) // the request index of each item
+	rejected := false
+	for i, req := range reqs {
+		product, err := s.batchProduct(ctx, req)
+		results[i] = BatchItemResult{Op: req.Op, Product: product, Err: err}
+		if err != nil {
+			if !isItemError(err) {
+				return nil, err
+			}
+			rejected = true
+			continue
+		}
+		items = append(items, datalayer.ProductBatchItem{Op: req.Op, Product: product})
+		indexes = append(indexes, i)
+	}
+	if rejected && !partial {
+		return results, ErrBatchRejected
+	}
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	itemErrs, err := s.repo.ApplyProductBatch(ctx, items, partial)
+	var batchErr *datalayer.ProductBatchError
+	if errors.As(err, &batchErr) && isItemError(batchErr.Err) {
+		results[indexes[batchErr.Index]].Err = batchErr.Err
+		return results, ErrBatchRejected
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, itemErr := range itemErrs {
+		results[indexes[i]].Err = itemErr
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			s.batchChanged(result)
+		}
+	}
+	return results, nil
+}
+
+// batchChanged notifies the OnChange callbacks of an applied batch item
+func (s *ProductService) batchChanged(result BatchItemResult) {
+	switch result.Op {
+	case datalayer.BatchCreate:
+		s.changed(ProductCreated, result.Product)
+	case datalayer.BatchUpdate:
+		s.changed(ProductUpdated, result.Product)
+	case datalayer.BatchDelete:
+		s.changed(ProductDeleted, result.Product)
+	}
+}
+
+// batchProduct validates req and builds the product its op writes
+func (s *ProductService) batchProduct(ctx context.Context, req BatchItemRequest) (*datalayer.Product, error) {
+	switch req.Op {
+	case datalayer.BatchCreate:
+		return s.newProduct(ctx, req.Product.CreateProductRequest)
+	case datalayer.BatchUpdate:
+		return s.replacedProduct(ctx, req.Product)
+	case datalayer.BatchDelete:
+		if req.Product.ID == uuid.Nil {
+			return nil, &ValidationError{Msg: "product.id is required"}
+		}
+		return &datalayer.Product{ID: req.Product.ID}, nil
+	default:
+		return nil, &ValidationError{Msg: fmt.Sprintf("op must be one of: %s, %s, %s",
+			datalayer.BatchCreate, datalayer.BatchUpdate, datalayer.BatchDelete)}
+	}
+}
+
+// replacedProduct validates a batch update against the product it
+// replaces. Status and attributes keep their own endpoints, so that their
+// rules are enforced, but the stored ones must still fit the new quantity
+// and category.
+func (s *ProductService) replacedProduct(ctx context.Context, req BatchProductRequest) (*datalayer.Product, error) {
+	if req.ID == uuid.Nil {
+		return nil, &ValidationError{Msg: "product.id is required"}
+	}
+	if req.Status != "" || req.Attributes != nil {
+		return nil, &ValidationError{Msg: "status and attributes cannot be changed by a batch update"}
+	}
+	if err := validateProductFields(req.CreateProductRequest); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetProductByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPublishable(current.Status, req.Quantity); err != nil {
+		return nil, err
+	}
+	if req.CategoryID != current.CategoryID {
+		if err := s.attributes.ValidateProductAttributes(ctx, req.CategoryID, current.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = current.Currency
+	}
+	metadata := req.Metadata
+	if metadata == nil {
+		metadata = current.Metadata
+	}
+
+	return &datalayer.Product{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		ImageURL:    req.ImageURL,
+		CategoryID:  req.CategoryID,
+		Price:       req.Price,
+		Currency:    currency,
+		Quantity:    req.Quantity,
+		Weight:      req.Weight,
+		Metadata:    metadata,
+	}, nil
+}
+
+// isItemError reports whether err is about a batch item itself rather
+// than the database
+func isItemError(err error) bool {
+	return errors.Is(err, ErrValidation) || errors.Is(err, datalayer.ErrNotFound)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductServiceApplyBatch(t *testing.T) {
+	ctx := context.Background()
+
+	// newBatchService returns a service holding one active product
+	newBatchService := func(t *testing.T) (*ProductService, *datalayer.MemoryProductRepo, *datalayer.Product) {
+		svc, repo := newTestProductService(t)
+		svc.newID = uuid.New
+		existing := &datalayer.Product{Name: "Lamp", CategoryID: testCategoryID, Price: 10, Quantity: 2, Status: datalayer.ProductActive}
+		require.NoError(t, repo.CreateProduct(ctx, existing))
+		return svc, repo, existing
+	}
+	create := func(name string) BatchItemRequest {
+		return BatchItemRequest{Op: datalayer.BatchCreate, Product: BatchProductRequest{
+			CreateProductRequest: CreateProductRequest{Name: name, CategoryID: testCategoryID, Price: 5},
+		}}
+	}
+	update := func(id uuid.UUID, name string, quantity int) BatchItemRequest {
+		return BatchItemRequest{Op: datalayer.BatchUpdate, Product: BatchProductRequest{
+			ID:                   id,
+			CreateProductRequest: CreateProductRequest{Name: name, CategoryID: testCategoryID, Price: 15, Quantity: quantity},
+		}}
+	}
+	remove := func(id uuid.UUID) BatchItemRequest {
+		return BatchItemRequest{Op: datalayer.BatchDelete, Product: BatchProductRequest{ID: id}}
+	}
+
+	t.Run("should apply a mixed batch", func(t *testing.T) {
+		svc, repo, existing := newBatchService(t)
+		other := &datalayer.Product{Name: "Shade", CategoryID: testCategoryID, Price: 3, Status: datalayer.ProductActive}
+		require.NoError(t, repo.CreateProduct(ctx, other))
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{
+			create("Bulb"),
+			update(existing.ID, "Desk lamp", 4),
+			remove(other.ID),
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		for _, result := range results {
+			assert.NoError(t, result.Err)
+		}
+		assert.Equal(t, datalayer.ProductDraft, results[0].Product.Status)
+		assert.Equal(t, "Desk lamp", results[1].Product.Name)
+		assert.Equal(t, datalayer.ProductActive, results[1].Product.Status)
+
+		_, err = repo.GetProductByID(ctx, results[0].Product.ID)
+		assert.NoError(t, err)
+		_, err = repo.GetProductByID(ctx, other.ID)
+		assert.ErrorIs(t, err, datalayer.ErrNotFound)
+	})
+
+	t.Run("should keep the stored currency unless an update sets one", func(t *testing.T) {
+		svc, _, existing := newBatchService(t)
+		repriced := update(existing.ID, "Lamp", 2)
+		repriced.Product.Currency = "EUR"
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{update(existing.ID, "Lamp", 2)}, false)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.DefaultCurrency, results[0].Product.Currency)
+
+		results, err = svc.ApplyBatch(ctx, []BatchItemRequest{repriced}, false)
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", results[0].Product.Currency)
+	})
+
+	t.Run("should keep the stored metadata unless an update sets it", func(t *testing.T) {
+		svc, repo, existing := newBatchService(t)
+		existing.Metadata = datalayer.ProductMetadata{"brand": "Acme"}
+		require.NoError(t, repo.UpdateProduct(ctx, existing))
+		relabelled := update(existing.ID, "Lamp", 2)
+		relabelled.Product.Metadata = datalayer.ProductMetadata{"brand": "Globex"}
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{update(existing.ID, "Lamp", 2)}, false)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductMetadata{"brand": "Acme"}, results[0].Product.Metadata)
+
+		results, err = svc.ApplyBatch(ctx, []BatchItemRequest{relabelled}, false)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductMetadata{"brand": "Globex"}, results[0].Product.Metadata)
+	})
+
+	t.Run("should reject the whole batch if an item is invalid", func(t *testing.T) {
+		svc, repo, existing := newBatchService(t)
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{
+			remove(existing.ID),
+			create(""),
+			{Op: "upsert"},
+		}, false)
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		require.Len(t, results, 3)
+		assert.NoError(t, results[0].Err)
+		assert.EqualError(t, results[1].Err, "name is required")
+		assert.EqualError(t, results[2].Err, "op must be one of: create, update, delete")
+
+		_, err = repo.GetProductByID(ctx, existing.ID)
+		assert.NoError(t, err, "a rejected batch must not delete anything")
+	})
+
+	t.Run("should reject the whole batch if a product is missing", func(t *testing.T) {
+		svc, repo, existing := newBatchService(t)
+		missing := uuid.New()
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{remove(existing.ID), remove(missing)}, false)
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, datalayer.ErrNotFound)
+
+		_, err = repo.GetProductByID(ctx, existing.ID)
+		assert.NoError(t, err, "a rejected batch must not delete anything")
+	})
+
+	t.Run("should apply the valid items of a partial batch", func(t *testing.T) {
+		svc, repo, existing := newBatchService(t)
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{
+			update(existing.ID, "", 1),
+			remove(uuid.New()),
+			create("Bulb"),
+		}, true)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, ErrValidation)
+		assert.ErrorIs(t, results[1].Err, datalayer.ErrNotFound)
+		assert.NoError(t, results[2].Err)
+
+		_, err = repo.GetProductByID(ctx, results[2].Product.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should keep the publish rule on update", func(t *testing.T) {
+		svc, _, existing := newBatchService(t)
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{update(existing.ID, "Lamp", 0)}, false)
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		assert.ErrorIs(t, results[0].Err, ErrValidation)
+	})
+
+	t.Run("should not change status or attributes on update", func(t *testing.T) {
+		svc, _, existing := newBatchService(t)
+		req := update(existing.ID, "Lamp", 1)
+		req.Product.Status = datalayer.ProductDiscontinued
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{req}, false)
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		assert.EqualError(t, results[0].Err, "status and attributes cannot be changed by a batch update")
+	})
+
+	t.Run("should require an id to update or delete", func(t *testing.T) {
+		svc, _, _ := newBatchService(t)
+
+		results, err := svc.ApplyBatch(ctx, []BatchItemRequest{update(uuid.Nil, "Lamp", 1), remove(uuid.Nil)}, false)
+		assert.ErrorIs(t, err, ErrBatchRejected)
+		assert.EqualError(t, results[0].Err, "product.id is required")
+		assert.EqualError(t, results[1].Err, "product.id is required")
+	})
+
+	t.Run("should reject empty and oversized batches", func(t *testing.T) {
+		svc, _, _ := newBatchService(t)
+
+		_, err := svc.ApplyBatch(ctx, nil, false)
+		assert.EqualError(t, err, "batch must have at least one item")
+
+		_, err = svc.ApplyBatch(ctx, make([]BatchItemRequest, MaxBatchItems+1), false)
+		assert.EqualError(t, err, "batch must have at most 1000 items")
+	})
+
+	t.Run("should return lookup errors", func(t *testing.T) {
+		svc, _, _ := newBatchService(t)
+		svc.categories = newTestCategoryService(brokenCategoryRepo{})
+
+		_, err := svc.ApplyBatch(ctx, []BatchItemRequest{create("Bulb")}, false)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrBatchRejected))
+	})
+}
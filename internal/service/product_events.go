@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+// ProductEventType says what happened to a product
+type ProductEventType string
+
+const (
+	ProductCreated ProductEventType = "product.created"
+	ProductUpdated ProductEventType = "product.updated"
+	ProductDeleted ProductEventType = "product.deleted"
+)
+
+// ProductEvent is a product change made through the ProductService.
+// Product is the product as stored afterwards and is nil for deletions.
+// Status is the product's status afterwards, or as it was deleted, so
+// subscribers can filter deletions too; it is not encoded.
+type ProductEvent struct {
+	Type       ProductEventType        `json:"type"`
+	ProductID  uuid.UUID               `json:"productId"`
+	Product    *datalayer.Product      `json:"product,omitempty"`
+	Status     datalayer.ProductStatus `json:"-"`
+	OccurredAt time.Time               `json:"occurredAt"`
+}
+
+// ErrTooManySubscribers is returned by Subscribe when the stream already
+// has its maximum number of subscribers
+var ErrTooManySubscribers = errors.New("too many subscribers")
+
+// ProductEventStream fans product events out to subscribers, such as open
+// event stream connections. Publish never blocks: a subscriber whose
+// buffer is full misses the event rather than stalling the write behind
+// it.
+//
+// Events only cover changes made through the service in this process;
+// stock movements and reservations change quantities without one.
+type ProductEventStream struct {
+	mu             sync.Mutex
+	subscribers    map[chan ProductEvent]struct{}
+	maxSubscribers int
+}
+
+// NewProductEventStream creates a stream without subscribers that takes at
+// most maxSubscribers of them at once. Zero leaves subscribers uncapped.
+func NewProductEventStream(maxSubscribers int) *ProductEventStream {
+	return &ProductEventStream{subscribers: map[chan ProductEvent]struct{}{}, maxSubscribers: maxSubscribers}
+}
+
+// Subscribe returns a channel receiving the events published from now on,
+// holding up to buffer of them, and a func that unsubscribes and closes
+// the channel. It fails with ErrTooManySubscribers when the stream is full.
+func (s *ProductEventStream) Subscribe(buffer int) (<-chan ProductEvent, func(), error) {
+	events := make(chan ProductEvent, buffer)
+
+	s.mu.Lock()
+	if s.maxSubscribers > 0 && len(s.subscribers) >= s.maxSubscribers {
+		s.mu.Unlock()
+		return nil, nil, ErrTooManySubscribers
+	}
+	s.subscribers[events] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, events)
+			close(events)
+		})
+	}, nil
+}
+
+// Publish sends event to every subscriber with room for it
+func (s *ProductEventStream) Publish(event ProductEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for events := range s.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of current subscribers
+func (s *ProductEventStream) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductEventStream(t *testing.T) {
+	event := ProductEvent{Type: ProductDeleted, ProductID: testID, OccurredAt: testNow}
+
+	t.Run("should deliver events to every subscriber", func(t *testing.T) {
+		stream := NewProductEventStream(0)
+		first, unsubscribeFirst, _ := stream.Subscribe(1)
+		defer unsubscribeFirst()
+		second, unsubscribeSecond, _ := stream.Subscribe(1)
+		defer unsubscribeSecond()
+
+		stream.Publish(event)
+
+		assert.Equal(t, event, <-first)
+		assert.Equal(t, event, <-second)
+	})
+
+	t.Run("should drop events for a full subscriber without blocking", func(t *testing.T) {
+		stream := NewProductEventStream(0)
+		events, unsubscribe, _ := stream.Subscribe(1)
+		defer unsubscribe()
+
+		stream.Publish(event)
+		stream.Publish(ProductEvent{Type: ProductCreated})
+
+		assert.Equal(t, event, <-events)
+		assert.Empty(t, events)
+	})
+
+	t.Run("should stop delivering and close the channel once unsubscribed", func(t *testing.T) {
+		stream := NewProductEventStream(0)
+		events, unsubscribe, _ := stream.Subscribe(1)
+		assert.Equal(t, 1, stream.Subscribers())
+
+		unsubscribe()
+		unsubscribe()
+		stream.Publish(event)
+
+		_, open := <-events
+		assert.False(t, open)
+		assert.Zero(t, stream.Subscribers())
+	})
+
+	t.Run("should refuse subscribers beyond the maximum until one leaves", func(t *testing.T) {
+		stream := NewProductEventStream(1)
+		_, unsubscribe, err := stream.Subscribe(1)
+		require.NoError(t, err)
+
+		_, _, err = stream.Subscribe(1)
+		assert.ErrorIs(t, err, ErrTooManySubscribers)
+
+		unsubscribe()
+		_, unsubscribe, err = stream.Subscribe(1)
+		require.NoError(t, err)
+		unsubscribe()
+	})
+}
+
+func TestProductServiceOnChange(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestProductService(t)
+	var events []ProductEvent
+	svc.OnChange(func(event ProductEvent) { events = append(events, event) })
+
+	created, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5, Quantity: 1})
+	require.NoError(t, err)
+	_, err = svc.ChangeStatus(ctx, created.ID, datalayer.ProductActive)
+	require.NoError(t, err)
+	_, err = svc.ChangeStatus(ctx, created.ID, datalayer.ProductDraft)
+	require.Error(t, err, "failed changes must not be announced")
+	require.NoError(t, svc.DeleteProduct(ctx, created.ID))
+
+	require.Len(t, events, 3)
+	assert.Equal(t, ProductEvent{Type: ProductCreated, ProductID: created.ID, Product: created, Status: datalayer.ProductDraft, OccurredAt: testNow.UTC()}, events[0])
+	assert.Equal(t, ProductUpdated, events[1].Type)
+	assert.Equal(t, datalayer.ProductActive, events[1].Product.Status)
+	assert.Equal(t, ProductEvent{Type: ProductDeleted, ProductID: created.ID, Status: datalayer.ProductActive, OccurredAt: testNow.UTC()}, events[2])
+
+	t.Run("should announce each applied batch item", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		svc.newID = uuid.New
+		var types []ProductEventType
+		svc.OnChange(func(event ProductEvent) { types = append(types, event.Type) })
+
+		_, err := svc.ApplyBatch(ctx, []BatchItemRequest{
+			{Op: datalayer.BatchCreate, Product: BatchProductRequest{CreateProductRequest: CreateProductRequest{Name: "Bulb", CategoryID: testCategoryID, Price: 2}}},
+			{Op: datalayer.BatchDelete, Product: BatchProductRequest{ID: uuid.New()}},
+		}, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ProductEventType{ProductCreated}, types)
+	})
+}
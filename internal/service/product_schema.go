@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect ProductSchema is written in
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEnums are the value sets a schema:"enum=<name>" tag refers to.
+// They are read from the same data validation uses.
+var schemaEnums = map[string]func() []string{
+	"currencies": func() []string {
+		codes := make([]string, 0, len(currencies))
+		for code := range currencies {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+		return codes
+	},
+	"statuses": func() []string {
+		statuses := make([]string, len(datalayer.ProductStatuses))
+		for i, status := range datalayer.ProductStatuses {
+			statuses[i] = string(status)
+		}
+		return statuses
+	},
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// ProductSchema returns a JSON Schema document describing the
+// CreateProductRequest body. Properties come from the json tags and
+// constraints from the schema tags, so the document follows the struct.
+func ProductSchema() map[string]any {
+	schema, err := objectSchema(reflect.TypeOf(CreateProductRequest{}))
+	if err != nil {
+		// The tags are fixed at compile time; TestProductSchema covers them
+		panic(err)
+	}
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "CreateProductRequest"
+	return schema
+}
+
+// objectSchema describes the exported json fields of struct type t
+func objectSchema(t reflect.Type) (map[string]any, error) {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := map[string]any{"type": schemaType(field.Type)}
+		if tag := field.Tag.Get("schema"); tag != "" {
+			for _, option := range strings.Split(tag, ",") {
+				key, value, _ := strings.Cut(option, "=")
+				switch key {
+				case "required":
+					required = append(required, name)
+				case "minimum", "exclusiveMinimum", "maximum":
+					bound, err := strconv.ParseFloat(value, 64)
+					if err != nil {
+						return nil, fmt.Errorf("field %s: %s `%s` is not a number", field.Name, key, value)
+					}
+					property[key] = bound
+				case "format", "pattern":
+					property[key] = value
+				case "enum":
+					values, ok := schemaEnums[value]
+					if !ok {
+						return nil, fmt.Errorf("field %s: unknown enum `%s`", field.Name, value)
+					}
+					property[key] = values()
+				default:
+					return nil, fmt.Errorf("field %s: unknown schema option `%s`", field.Name, key)
+				}
+			}
+		}
+		properties[name] = property
+	}
+	return map[string]any{"type": "object", "properties": properties, "required": required}, nil
+}
+
+// schemaType returns the JSON Schema type of values of t. Pointers may
+// also be null.
+func schemaType(t reflect.Type) any {
+	if t.Kind() == reflect.Pointer {
+		return []any{schemaType(t.Elem()), "null"}
+	}
+	if t.Implements(textMarshalerType) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductSchema(t *testing.T) {
+	schema := ProductSchema()
+	properties := schema["properties"].(map[string]any)
+
+	t.Run("should describe every field of the create request", func(t *testing.T) {
+		fields := reflect.TypeOf(CreateProductRequest{})
+		assert.Len(t, properties, fields.NumField())
+		for i := 0; i < fields.NumField(); i++ {
+			name, _, _ := strings.Cut(fields.Field(i).Tag.Get("json"), ",")
+			assert.Contains(t, properties, name)
+		}
+	})
+
+	t.Run("should follow the field validation", func(t *testing.T) {
+		assert.Equal(t, []string{"name", "categoryId", "price"}, schema["required"])
+		assert.Equal(t, map[string]any{"type": "number", "exclusiveMinimum": 0.0}, properties["price"])
+		assert.Equal(t, map[string]any{"type": "integer", "minimum": 0.0}, properties["quantity"])
+		assert.Equal(t, map[string]any{"type": []any{"number", "null"}, "exclusiveMinimum": 0.0, "maximum": 10000.0}, properties["weight"])
+		assert.Equal(t, map[string]any{"type": "string", "format": "uuid"}, properties["categoryId"])
+		assert.Equal(t, "object", properties["attributes"].(map[string]any)["type"])
+	})
+
+	t.Run("should list every accepted currency", func(t *testing.T) {
+		codes := properties["currency"].(map[string]any)["enum"].([]string)
+		assert.Len(t, codes, len(currencies))
+		for _, code := range codes {
+			assert.True(t, validCurrency(code), code)
+		}
+	})
+
+	t.Run("should reject unknown schema options", func(t *testing.T) {
+		_, err := objectSchema(reflect.TypeOf(struct {
+			Name string `json:"name" schema:"maxLength=3"`
+		}{}))
+		require.Error(t, err)
+		assert.Equal(t, "field Name: unknown schema option `maxLength`", err.Error())
+	})
+}
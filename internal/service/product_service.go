@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+)
+
+// CreateProductRequest is the input for a new product. An empty Status
+// creates a draft and an empty Currency prices it in US dollars.
+// Attributes are checked against the category's attribute definitions. It
+// has no creation time on purpose: the service stamps it, so clients
+// cannot place products ahead of list cursors. The schema tags describe
+// validateProductFields for ProductSchema.
+type CreateProductRequest struct {
+	Name        string                      `json:"name" schema:"required,pattern=\\S"`
+	Description string                      `json:"description"`
+	ImageURL    string                      `json:"imageUrl"`
+	CategoryID  uuid.UUID                   `json:"categoryId" schema:"required,format=uuid"`
+	Price       float64                     `json:"price" schema:"required,exclusiveMinimum=0"`
+	Currency    string                      `json:"currency" schema:"enum=currencies"`
+	Quantity    int                         `json:"quantity" schema:"minimum=0"`
+	Weight      *float64                    `json:"weight" schema:"exclusiveMinimum=0,maximum=10000"`
+	Status      datalayer.ProductStatus     `json:"status" schema:"enum=statuses"`
+	Attributes  datalayer.ProductAttributes `json:"attributes"`
+	Metadata    datalayer.ProductMetadata   `json:"metadata"`
+}
+
+// Limits on product metadata, so one product cannot carry an unbounded
+// document
+const (
+	MaxProductMetadataKeys = 20
+	MaxMetadataLength      = 255
+)
+
+// MaxProductWeight is the heaviest product weight accepted, in kg
+const MaxProductWeight = 10000
+
+type ProductService struct {
+	repo       datalayer.ProductRepoInterface
+	categories *CategoryService
+	attributes *AttributeService
+	now        func() time.Time
+	newID      func() uuid.UUID
+	onChange   []func(ProductEvent)
+}
+
+// NewProductService creates a product service backed by repo. Category
+// references are checked through categories and product attributes
+// through attributes.
+func NewProductService(
+	repo datalayer.ProductRepoInterface,
+	categories *CategoryService,
+	attributes *AttributeService,
+) *ProductService {
+	return &ProductService{repo: repo, categories: categories, attributes: attributes, now: time.Now, newID: uuid.New}
+}
+
+// OnChange registers fn to be called with an event after every product
+// the service creates, updates or deletes
+func (s *ProductService) OnChange(fn func(ProductEvent)) {
+	s.onChange = append(s.onChange, fn)
+}
+
+// changed notifies the OnChange callbacks that product was changed.
+// product is the product as stored afterwards, or as it was deleted.
+func (s *ProductService) changed(eventType ProductEventType, product *datalayer.Product) {
+	if len(s.onChange) == 0 {
+		return
+	}
+	event := ProductEvent{Type: eventType, ProductID: product.ID, Product: product, Status: product.Status, OccurredAt: s.now().UTC()}
+	if eventType == ProductDeleted {
+		event.Product = nil
+	}
+	for _, fn := range s.onChange {
+		fn(event)
+	}
+}
+
+// CreateProduct validates req, checks that its category exists and that
+// its attributes fit the category's definitions, assigns an ID and
+// creation time and stores the new product
+func (s *ProductService) CreateProduct(ctx context.Context, req CreateProductRequest) (*datalayer.Product, error) {
+	product, err := s.newProduct(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateProduct(ctx, product); err != nil {
+		return nil, err
+	}
+	s.changed(ProductCreated, product)
+	return product, nil
+}
+
+// newProduct validates req and builds the product CreateProduct stores
+func (s *ProductService) newProduct(ctx context.Context, req CreateProductRequest) (*datalayer.Product, error) {
+	if err := validateProductFields(req); err != nil {
+		return nil, err
+	}
+	if req.Status == "" {
+		req.Status = datalayer.DefaultProductStatus
+	}
+	if req.Currency == "" {
+		req.Currency = datalayer.DefaultCurrency
+	}
+	if !req.Status.Valid() {
+		return nil, &ValidationError{Msg: fmt.Sprintf("status `%s` is not a product status", req.Status)}
+	}
+	if err := checkPublishable(req.Status, req.Quantity); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.attributes.ValidateProductAttributes(ctx, req.CategoryID, req.Attributes); err != nil {
+		return nil, err
+	}
+	if req.Attributes == nil {
+		req.Attributes = datalayer.ProductAttributes{}
+	}
+
+	return &datalayer.Product{
+		ID:          s.newID(),
+		Name:        req.Name,
+		Description: req.Description,
+		ImageURL:    req.ImageURL,
+		CategoryID:  req.CategoryID,
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Quantity:    req.Quantity,
+		Weight:      req.Weight,
+		Status:      req.Status,
+		Attributes:  req.Attributes,
+		Metadata:    req.Metadata,
+		CreatedAt:   s.now().UTC(),
+	}, nil
+}
+
+// ChangeStatus moves a product to status. On top of the transitions the
+// repo enforces, a product cannot be published while out of stock.
+func (s *ProductService) ChangeStatus(ctx context.Context, id uuid.UUID, status datalayer.ProductStatus) (*datalayer.Product, error) {
+	return s.PatchProduct(ctx, id, datalayer.ProductPatch{Status: &status})
+}
+
+// UpdateAttributes replaces a product's attributes after checking them
+// against the definitions of its category
+func (s *ProductService) UpdateAttributes(
+	ctx context.Context,
+	id uuid.UUID,
+	attributes datalayer.ProductAttributes,
+) (*datalayer.Product, error) {
+	return s.PatchProduct(ctx, id, datalayer.ProductPatch{Attributes: &attributes})
+}
+
+// PatchProduct changes a product's status and attributes in one update,
+// checked as ChangeStatus and UpdateAttributes check them. The checks run
+// after the Precondition of patch, within the repo's Precondition, so
+// they see the product the update overwrites rather than an earlier read
+// a concurrent stock change may have made stale.
+func (s *ProductService) PatchProduct(ctx context.Context, id uuid.UUID, patch datalayer.ProductPatch) (*datalayer.Product, error) {
+	if patch.Status != nil && !patch.Status.Valid() {
+		return nil, &ValidationError{Msg: fmt.Sprintf("status `%s` is not a product status", *patch.Status)}
+	}
+	precondition := patch.Precondition
+	patch.Precondition = func(current *datalayer.Product) error {
+		if precondition != nil {
+			if err := precondition(current); err != nil {
+				return err
+			}
+		}
+		return s.checkPatch(ctx, current, patch)
+	}
+
+	product, err := s.repo.PatchProduct(ctx, id, patch)
+	if err != nil {
+		return nil, err
+	}
+	s.changed(ProductUpdated, product)
+	return product, nil
+}
+
+// checkPatch checks patch against current, the product it is about to
+// change: a draft or discontinued product is only published while in
+// stock, and new attributes must match the definitions of its category
+func (s *ProductService) checkPatch(ctx context.Context, current *datalayer.Product, patch datalayer.ProductPatch) error {
+	if patch.Status != nil && current.Status != datalayer.ProductActive {
+		if err := checkPublishable(*patch.Status, current.Quantity); err != nil {
+			return err
+		}
+	}
+	if patch.Attributes != nil {
+		return s.attributes.ValidateProductAttributes(ctx, current.CategoryID, *patch.Attributes)
+	}
+	return nil
+}
+
+// DeleteProduct removes a product. It is read back as it is deleted so the
+// event announcing it carries its status.
+func (s *ProductService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	_, err := s.DeleteProductReturning(ctx, id)
+	return err
+}
+
+// DeleteProductReturning removes a product and returns it as it was
+// before deletion
+func (s *ProductService) DeleteProductReturning(ctx context.Context, id uuid.UUID) (*datalayer.Product, error) {
+	product, err := s.repo.DeleteProductReturning(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.changed(ProductDeleted, product)
+	return product, nil
+}
+
+// checkCategory rejects a missing or unknown category ID
+func (s *ProductService) checkCategory(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &ValidationError{Msg: "categoryId is required"}
+	}
+	exists, err := s.categories.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ValidationError{Msg: fmt.Sprintf("category `%s` does not exist", id)}
+	}
+	return nil
+}
+
+// validateProductFields checks the plain fields of a new or replaced
+// product
+func validateProductFields(req CreateProductRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return &ValidationError{Msg: "name is required"}
+	}
+	if req.Price <= 0 {
+		return &ValidationError{Msg: "price must be greater than zero"}
+	}
+	if req.Currency != "" && !validCurrency(req.Currency) {
+		return &CurrencyError{Currency: req.Currency}
+	}
+	if req.Quantity < 0 {
+		return &ValidationError{Msg: "quantity must not be negative"}
+	}
+	if req.Weight != nil && (*req.Weight <= 0 || *req.Weight > MaxProductWeight) {
+		return &ValidationError{Msg: "weight is invalid", Fields: []FieldError{{
+			Field:   "weight",
+			Message: fmt.Sprintf("must be greater than 0 and at most %d", MaxProductWeight),
+		}}}
+	}
+	return ValidateMetadata(req.Metadata)
+}
+
+// ValidateMetadata checks product metadata against the key count and
+// length limits
+func ValidateMetadata(metadata datalayer.ProductMetadata) error {
+	if len(metadata) > MaxProductMetadataKeys {
+		return &ValidationError{Msg: fmt.Sprintf("metadata must have at most %d keys", MaxProductMetadataKeys)}
+	}
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		if strings.TrimSpace(key) == "" || utf8.RuneCountInString(key) > MaxMetadataLength {
+			return &ValidationError{Msg: fmt.Sprintf("metadata keys must be 1 to %d characters", MaxMetadataLength)}
+		}
+		if utf8.RuneCountInString(metadata[key]) > MaxMetadataLength {
+			return &ValidationError{Msg: fmt.Sprintf("metadata `%s` must be at most %d characters", key, MaxMetadataLength)}
+		}
+	}
+	return nil
+}
+
+// checkPublishable rejects an active product with nothing in stock
+func checkPublishable(status datalayer.ProductStatus, quantity int) error {
+	if status == datalayer.ProductActive && quantity == 0 {
+		return &ValidationError{Msg: "cannot publish a product with quantity 0"}
+	}
+	return nil
+}
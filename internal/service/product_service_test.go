@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	datalayer "github.com/GenAI-Application-Engineering-Project/sample-go-rest-api-for-automation/internal/data_layer"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCategoryID = uuid.MustParse("b12f2176-28ca-4acf-85b9-cc97ca1b3cf6")
+
+// soldOutBeforePatchRepo sells out the product being patched right before
+// the patch takes its lock, as a concurrent stock adjustment landing
+// between a read and the update would
+type soldOutBeforePatchRepo struct {
+	*datalayer.MemoryProductRepo
+}
+
+func (r soldOutBeforePatchRepo) PatchProduct(ctx context.Context, id uuid.UUID, patch datalayer.ProductPatch) (*datalayer.Product, error) {
+	product, err := r.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	product.Quantity = 0
+	if err := r.UpdateProduct(ctx, product); err != nil {
+		return nil, err
+	}
+	return r.MemoryProductRepo.PatchProduct(ctx, id, patch)
+}
+
+// brokenCategoryRepo fails every lookup so lookup errors can be checked
+type brokenCategoryRepo struct {
+	datalayer.CategoryRepoInterface
+}
+
+func (brokenCategoryRepo) CategoryExists(context.Context, uuid.UUID) (bool, error) {
+	return false, errors.New("categoryExists: select query failed: boom")
+}
+
+// newTestProductService returns a service over empty memory repos holding
+// one category with testCategoryID and no attribute definitions
+func newTestProductService(t *testing.T) (*ProductService, *datalayer.MemoryProductRepo) {
+	t.Helper()
+
+	categories := datalayer.NewMemoryCategoryRepo()
+	require.NoError(t, categories.CreateCategory(context.Background(), &datalayer.Category{ID: testCategoryID, Name: "Lighting"}))
+
+	products := datalayer.NewMemoryProductRepo()
+	return &ProductService{
+		repo:       products,
+		categories: newTestCategoryService(categories),
+		attributes: newTestAttributeService(datalayer.NewMemoryAttributeDefinitionRepo(categories)),
+		now:        func() time.Time { return testNow },
+		newID:      func() uuid.UUID { return testID },
+	}, products
+}
+
+func TestProductServiceCreateProduct(t *testing.T) {
+	ctx := context.Background()
+	valid := CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5}
+
+	t.Run("should create a draft with an ID and UTC creation time", func(t *testing.T) {
+		svc, repo := newTestProductService(t)
+
+		product, err := svc.CreateProduct(ctx, valid)
+		require.NoError(t, err)
+		assert.Equal(t, testID, product.ID)
+		assert.Equal(t, testNow.UTC(), product.CreatedAt)
+		assert.Equal(t, datalayer.ProductDraft, product.Status)
+
+		stored, err := repo.GetProductByID(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, product, stored)
+	})
+
+	t.Run("should create an active product that is in stock", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		req := valid
+		req.Status = datalayer.ProductActive
+		req.Quantity = 3
+
+		product, err := svc.CreateProduct(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductActive, product.Status)
+	})
+
+	t.Run("should accept a weight at the maximum", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		req := valid
+		weight := float64(MaxProductWeight)
+		req.Weight = &weight
+
+		product, err := svc.CreateProduct(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, &weight, product.Weight)
+	})
+
+	negative, zero, heavy := -1.0, 0.0, MaxProductWeight+0.01
+	tests := []struct {
+		name    string
+		modify  func(*CreateProductRequest)
+		wantErr string
+	}{
+		{name: "blank name", modify: func(r *CreateProductRequest) { r.Name = " " }, wantErr: "name is required"},
+		{name: "zero price", modify: func(r *CreateProductRequest) { r.Price = 0 }, wantErr: "price must be greater than zero"},
+		{name: "negative price", modify: func(r *CreateProductRequest) { r.Price = -1 }, wantErr: "price must be greater than zero"},
+		{name: "negative quantity", modify: func(r *CreateProductRequest) { r.Quantity = -1 }, wantErr: "quantity must not be negative"},
+		{name: "unknown currency", modify: func(r *CreateProductRequest) { r.Currency = "usd" }, wantErr: "currency `usd` is not an ISO 4217 code"},
+		{name: "negative weight", modify: func(r *CreateProductRequest) { r.Weight = &negative }, wantErr: "weight is invalid"},
+		{name: "zero weight", modify: func(r *CreateProductRequest) { r.Weight = &zero }, wantErr: "weight is invalid"},
+		{name: "weight above maximum", modify: func(r *CreateProductRequest) { r.Weight = &heavy }, wantErr: "weight is invalid"},
+		{name: "unknown status", modify: func(r *CreateProductRequest) { r.Status = "archived" }, wantErr: "status `archived` is not a product status"},
+		{name: "active without stock", modify: func(r *CreateProductRequest) { r.Status = datalayer.ProductActive }, wantErr: "cannot publish a product with quantity 0"},
+		{name: "missing category", modify: func(r *CreateProductRequest) { r.CategoryID = uuid.Nil }, wantErr: "categoryId is required"},
+		{name: "unknown category", modify: func(r *CreateProductRequest) { r.CategoryID = testID }, wantErr: "category `f2aa335f-6f91-4d4d-8057-53b0009bc376` does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run("should reject "+tt.name, func(t *testing.T) {
+			svc, repo := newTestProductService(t)
+			req := valid
+			tt.modify(&req)
+
+			product, err := svc.CreateProduct(ctx, req)
+			assert.Nil(t, product)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.True(t, errors.Is(err, ErrValidation))
+
+			_, err = repo.GetProductByID(ctx, testID)
+			assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+		})
+	}
+
+	t.Run("should return category lookup errors", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		svc.categories = newTestCategoryService(brokenCategoryRepo{})
+
+		_, err := svc.CreateProduct(ctx, valid)
+		assert.EqualError(t, err, "categoryExists: select query failed: boom")
+		assert.False(t, errors.Is(err, ErrValidation))
+	})
+}
+
+func TestProductServiceChangeStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should publish a draft that is in stock", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5, Quantity: 1})
+		require.NoError(t, err)
+
+		product, err := svc.ChangeStatus(ctx, testID, datalayer.ProductActive)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductActive, product.Status)
+	})
+
+	t.Run("should not publish a draft with quantity 0", func(t *testing.T) {
+		svc, repo := newTestProductService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5})
+		require.NoError(t, err)
+
+		_, err = svc.ChangeStatus(ctx, testID, datalayer.ProductActive)
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.EqualError(t, validationErr, "cannot publish a product with quantity 0")
+
+		stored, err := repo.GetProductByID(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductDraft, stored.Status)
+	})
+
+	t.Run("should check the stock the update overwrites", func(t *testing.T) {
+		svc, repo := newTestProductService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5, Quantity: 1})
+		require.NoError(t, err)
+		svc.repo = soldOutBeforePatchRepo{repo}
+
+		_, err = svc.ChangeStatus(ctx, testID, datalayer.ProductActive)
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.EqualError(t, validationErr, "cannot publish a product with quantity 0")
+
+		stored, err := repo.GetProductByID(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductDraft, stored.Status)
+	})
+
+	t.Run("should check If-Match before the stock", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5})
+		require.NoError(t, err)
+		active := datalayer.ProductActive
+
+		_, err = svc.PatchProduct(ctx, testID, datalayer.ProductPatch{
+			Status:       &active,
+			Precondition: func(*datalayer.Product) error { return datalayer.ErrPreconditionFailed },
+		})
+		assert.ErrorIs(t, err, datalayer.ErrPreconditionFailed)
+	})
+
+	t.Run("should discontinue regardless of stock", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5, Quantity: 1, Status: datalayer.ProductActive})
+		require.NoError(t, err)
+
+		product, err := svc.ChangeStatus(ctx, testID, datalayer.ProductDiscontinued)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductDiscontinued, product.Status)
+	})
+
+	t.Run("should pass not found through", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+
+		_, err := svc.ChangeStatus(ctx, testID, datalayer.ProductActive)
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+	})
+}
+
+func TestProductServiceAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	// newService returns a product service whose category requires a
+	// finish and accepts an optional wattage
+	newService := func(t *testing.T) (*ProductService, *datalayer.MemoryProductRepo) {
+		t.Helper()
+		svc, repo := newTestProductService(t)
+		_, err := svc.attributes.CreateDefinition(ctx, testCategoryID, AttributeDefinitionRequest{
+			Name: "finish", Type: datalayer.AttributeEnum, Required: true, AllowedValues: []string{"matte", "gloss"},
+		})
+		require.NoError(t, err)
+		svc.attributes.newID = uuid.New
+		_, err = svc.attributes.CreateDefinition(ctx, testCategoryID, AttributeDefinitionRequest{Name: "wattage", Type: datalayer.AttributeNumber})
+		require.NoError(t, err)
+		return svc, repo
+	}
+
+	t.Run("should create a product with valid attributes", func(t *testing.T) {
+		svc, repo := newService(t)
+		attributes := datalayer.ProductAttributes{"finish": "matte", "wattage": 40.0}
+
+		product, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5, Attributes: attributes})
+		require.NoError(t, err)
+		assert.Equal(t, attributes, product.Attributes)
+
+		stored, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, attributes, stored.Attributes)
+	})
+
+	t.Run("should default attributes to an empty object", func(t *testing.T) {
+		svc, _ := newTestProductService(t)
+
+		product, err := svc.CreateProduct(ctx, CreateProductRequest{Name: "Lamp", CategoryID: testCategoryID, Price: 12.5})
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductAttributes{}, product.Attributes)
+	})
+
+	t.Run("should not create a product with invalid attributes", func(t *testing.T) {
+		svc, repo := newService(t)
+
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{
+			Name:       "Lamp",
+			CategoryID: testCategoryID,
+			Price:      12.5,
+			Attributes: datalayer.ProductAttributes{"wattage": "40"},
+		})
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []FieldError{
+			{Field: "attributes.finish", Message: "is required"},
+			{Field: "attributes.wattage", Message: "must be a number"},
+		}, validationErr.Fields)
+
+		_, err = repo.GetProductByID(ctx, testID)
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+	})
+
+	t.Run("should replace attributes of an existing product", func(t *testing.T) {
+		svc, _ := newService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{
+			Name:       "Lamp",
+			CategoryID: testCategoryID,
+			Price:      12.5,
+			Attributes: datalayer.ProductAttributes{"finish": "matte", "wattage": 40.0},
+		})
+		require.NoError(t, err)
+
+		product, err := svc.UpdateAttributes(ctx, testID, datalayer.ProductAttributes{"finish": "gloss"})
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductAttributes{"finish": "gloss"}, product.Attributes)
+	})
+
+	t.Run("should keep attributes if the replacement is invalid", func(t *testing.T) {
+		svc, repo := newService(t)
+		_, err := svc.CreateProduct(ctx, CreateProductRequest{
+			Name:       "Lamp",
+			CategoryID: testCategoryID,
+			Price:      12.5,
+			Attributes: datalayer.ProductAttributes{"finish": "matte"},
+		})
+		require.NoError(t, err)
+
+		_, err = svc.UpdateAttributes(ctx, testID, datalayer.ProductAttributes{"finish": "satin"})
+		assert.True(t, errors.Is(err, ErrValidation))
+
+		stored, err := repo.GetProductByID(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, datalayer.ProductAttributes{"finish": "matte"}, stored.Attributes)
+	})
+
+	t.Run("should pass not found through when updating attributes", func(t *testing.T) {
+		svc, _ := newService(t)
+
+		_, err := svc.UpdateAttributes(ctx, testID, datalayer.ProductAttributes{})
+		assert.True(t, errors.Is(err, datalayer.ErrNotFound))
+	})
+}
+
+func TestValidateMetadata(t *testing.T) {
+	tooMany := datalayer.ProductMetadata{}
+	for i := range MaxProductMetadataKeys + 1 {
+		tooMany[fmt.Sprintf("key%d", i)] = "x"
+	}
+
+	tests := []struct {
+		name     string
+		metadata datalayer.ProductMetadata
+		wantErr  string
+	}{
+		{name: "nil", metadata: nil},
+		{name: "string values", metadata: datalayer.ProductMetadata{"brand": "Acme", "color": "red"}},
+		{name: "longest key and value", metadata: datalayer.ProductMetadata{strings.Repeat("k", 255): strings.Repeat("v", 255)}},
+		{name: "too many keys", metadata: tooMany, wantErr: "metadata must have at most 20 keys"},
+		{name: "blank key", metadata: datalayer.ProductMetadata{" ": "x"}, wantErr: "metadata keys must be 1 to 255 characters"},
+		{name: "long key", metadata: datalayer.ProductMetadata{strings.Repeat("k", 256): "x"}, wantErr: "metadata keys must be 1 to 255 characters"},
+		{name: "long value", metadata: datalayer.ProductMetadata{"brand": strings.Repeat("v", 256)}, wantErr: "metadata `brand` must be at most 255 characters"},
+		{name: "multibyte value", metadata: datalayer.ProductMetadata{"brand": strings.Repeat("é", 255)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.metadata)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+			assert.True(t, errors.Is(err, ErrValidation))
+		})
+	}
+}
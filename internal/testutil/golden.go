@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	goldenDir       = "testdata"
+	goldenExt       = ".golden"
+	normalizedValue = "<normalized>"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// volatileFields are JSON keys whose values change between runs and are
+// replaced before comparing against a golden file
+var volatileFields = map[string]struct{}{
+	"requestId":    {},
+	"duration":     {},
+	"durationMs":   {},
+	"generatedAt":  {},
+	"generated_at": {},
+}
+
+// AssertGolden compares a JSON response body against testdata/<name>.golden.
+// Run tests with -update to rewrite the golden files from the current output.
+func AssertGolden(t *testing.T, name string, body []byte) {
+	t.Helper()
+
+	actual, err := NormalizeJSON(body)
+	require.NoError(t, err, "golden %s: invalid json body", name)
+
+	path := filepath.Join(goldenDir, name+goldenExt)
+	if *update {
+		require.NoError(t, os.MkdirAll(goldenDir, 0o755))
+		require.NoError(t, os.WriteFile(path, actual, 0o600))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "golden %s: run tests with -update to create it", name)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+// NormalizeJSON re-encodes body with indentation and volatile fields replaced
+func NormalizeJSON(body []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(normalize(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func normalize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, field := range val {
+			if _, ok := volatileFields[k]; ok {
+				val[k] = normalizedValue
+				continue
+			}
+			val[k] = normalize(field)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = normalize(item)
+		}
+	}
+	return v
+}
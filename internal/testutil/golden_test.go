@@ -0,0 +1,27 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeJSON(t *testing.T) {
+	t.Run("should replace volatile fields at any depth", func(t *testing.T) {
+		body := []byte(`{"requestId":"abc","data":[{"name":"a","duration":12}]}`)
+		normalized, err := NormalizeJSON(body)
+		assert.NoError(t, err)
+		expected := "{\n  \"data\": [\n    {\n      \"duration\": \"<normalized>\",\n      \"name\": \"a\"\n    }\n  ],\n  \"requestId\": \"<normalized>\"\n}\n"
+		assert.Equal(t, expected, string(normalized))
+	})
+
+	t.Run("should return error for invalid json", func(t *testing.T) {
+		normalized, err := NormalizeJSON([]byte(`{`))
+		assert.Error(t, err)
+		assert.Nil(t, normalized)
+	})
+}
+
+func TestAssertGolden(t *testing.T) {
+	AssertGolden(t, "example", []byte(`{"status":"success","requestId":"f00"}`))
+}
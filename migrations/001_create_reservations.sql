@@ -0,0 +1,14 @@
+-- Stock holds taken while a customer pays. Reserving decrements
+-- products.quantity, releasing adds it back and committing keeps it removed.
+CREATE TABLE IF NOT EXISTS reservations (
+    id          UUID PRIMARY KEY,
+    product_id  UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity    INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    status      TEXT NOT NULL CHECK (status IN ('held', 'released', 'committed')),
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS reservations_held_expires_at_idx
+    ON reservations (expires_at)
+    WHERE status = 'held';
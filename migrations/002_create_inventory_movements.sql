@@ -0,0 +1,14 @@
+-- Stock corrections, one row per adjustment, written in the same
+-- transaction as the products.quantity change.
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id              UUID PRIMARY KEY,
+    product_id      UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    delta           INTEGER NOT NULL CHECK (delta <> 0),
+    reason          TEXT NOT NULL CHECK (reason IN ('received', 'damaged', 'recount', 'sold', 'returned')),
+    note            TEXT,
+    quantity_after  INTEGER NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS inventory_movements_product_created_at_idx
+    ON inventory_movements (product_id, created_at);
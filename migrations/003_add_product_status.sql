@@ -0,0 +1,8 @@
+-- Product lifecycle. Existing products stay visible as active; drafts are
+-- hidden from the public listing until they are activated.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('draft', 'active', 'discontinued'));
+
+CREATE INDEX IF NOT EXISTS products_status_created_at_idx
+    ON products (status, created_at);
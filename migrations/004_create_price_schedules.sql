@@ -0,0 +1,16 @@
+-- Price changes scheduled ahead of time. The scheduler applies due rows
+-- through the normal product update and marks them applied; pending rows
+-- can be cancelled by deleting them.
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id            UUID PRIMARY KEY,
+    product_id    UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    new_price     NUMERIC(12, 2) NOT NULL CHECK (new_price > 0),
+    effective_at  TIMESTAMPTZ NOT NULL,
+    applied       BOOLEAN NOT NULL DEFAULT false,
+    created_at    TIMESTAMPTZ NOT NULL,
+    UNIQUE (product_id, effective_at)
+);
+
+CREATE INDEX IF NOT EXISTS price_schedules_pending_effective_at_idx
+    ON price_schedules (effective_at)
+    WHERE NOT applied;
@@ -0,0 +1,15 @@
+-- Merchandiser-curated links between products, e.g. accessories or
+-- "customers also bought". position orders a product's related set.
+-- Rows are removed with either product.
+CREATE TABLE IF NOT EXISTS product_relations (
+    product_id          UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_product_id  UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    relation_type       TEXT NOT NULL CHECK (relation_type IN ('related', 'accessory', 'upsell')),
+    position            INTEGER NOT NULL CHECK (position >= 0),
+    PRIMARY KEY (product_id, related_product_id),
+    UNIQUE (product_id, position),
+    CHECK (product_id <> related_product_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_relations_related_product_id_idx
+    ON product_relations (related_product_id);
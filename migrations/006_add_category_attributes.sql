@@ -0,0 +1,9 @@
+-- Free-form display metadata on categories (icon, banner color, SEO
+-- title). Values are scalars; the service caps the key count and lengths.
+-- The GIN index serves the ?attr.<key>=value containment filter.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb
+    CHECK (jsonb_typeof(attributes) = 'object');
+
+CREATE INDEX IF NOT EXISTS categories_attributes_idx
+    ON categories USING GIN (attributes jsonb_path_ops);
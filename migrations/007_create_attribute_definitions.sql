@@ -0,0 +1,24 @@
+-- Typed attributes a category declares for its products, e.g. a lamp's
+-- wattage or finish. allowed_values lists the choices of an enum
+-- attribute and is empty for the other types. Definitions go with their
+-- category.
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id              UUID PRIMARY KEY,
+    category_id     UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    type            TEXT NOT NULL CHECK (type IN ('string', 'number', 'bool', 'enum')),
+    required        BOOLEAN NOT NULL DEFAULT FALSE,
+    allowed_values  JSONB NOT NULL DEFAULT '[]'::jsonb CHECK (jsonb_typeof(allowed_values) = 'array'),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (category_id, name)
+);
+
+-- Product values for those attributes, keyed by definition name. The
+-- service checks them against the category's definitions; the GIN index
+-- serves the ?attr.<name>=value containment filter.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb
+    CHECK (jsonb_typeof(attributes) = 'object');
+
+CREATE INDEX IF NOT EXISTS products_attributes_idx
+    ON products USING GIN (attributes jsonb_path_ops);
@@ -0,0 +1,19 @@
+-- The name products are grouped by when looking for duplicates: lowercased,
+-- trimmed and with runs of white space collapsed to one space. The class
+-- lists the characters Go's unicode.IsSpace accepts so the in-memory repo
+-- groups alike; zero-width characters are not white space and are kept.
+CREATE OR REPLACE FUNCTION normalize_product_name(name TEXT) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE STRICT PARALLEL SAFE
+    AS $$
+        SELECT lower(btrim(regexp_replace(
+            name,
+            '[\s\u0085\u00a0\u1680\u2000-\u200a\u2028\u2029\u202f\u205f\u3000]+',
+            ' ',
+            'g'
+        )))
+    $$;
+
+-- Serves GET /products/duplicates, which groups and pages by this key.
+-- Names compare bytewise so pages follow the same order on every locale.
+CREATE INDEX IF NOT EXISTS products_normalized_name_idx
+    ON products (category_id, (normalize_product_name(name) COLLATE "C"));
@@ -0,0 +1,6 @@
+-- The ISO 4217 currency a product's price is denominated in. Existing
+-- products were priced in US dollars; the service checks codes against
+-- its list of active currencies, the constraint only their shape.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD'
+    CHECK (currency ~ '^[A-Z]{3}$');
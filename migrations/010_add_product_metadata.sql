@@ -0,0 +1,5 @@
+-- Free-form string metadata on products (brand, color) for what has no
+-- column of its own. The service caps the key count and lengths.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb
+    CHECK (jsonb_typeof(metadata) = 'object');
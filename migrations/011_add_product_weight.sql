@@ -0,0 +1,5 @@
+-- Shipping weight of a product in kg, null when unknown. The check matches
+-- the range the service accepts.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION
+    CHECK (weight > 0 AND weight <= 10000);
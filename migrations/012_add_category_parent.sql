@@ -0,0 +1,7 @@
+-- Category tree. A category with a NULL parent_id is top level. Deleting
+-- a category makes its children top level rather than deleting them.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx
+    ON categories (parent_id);